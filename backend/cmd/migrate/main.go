@@ -0,0 +1,56 @@
+// Command migrate applies the SQL files in migrations/ to DATABASE_URL.
+//
+//	go run ./cmd/migrate            # apply pending migrations
+//	go run ./cmd/migrate -status    # show current and expected version
+//	go run ./cmd/migrate -baseline 012  # mark 001 to 012 as applied (databases set up with psql)
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/config"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/db"
+	"github.com/Sabari-Vijayan/DBMS-project/migrations"
+)
+
+func main() {
+	configPath := flag.String("config", "", "path to a YAML or TOML config file (default $CONFIG_FILE)")
+	baseline := flag.String("baseline", "", "record the migrations up to this one (a file name or number) as applied without running them")
+	status := flag.Bool("status", false, "print the migration status and exit")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatal("Failed to load config: ", err)
+	}
+	if cfg.Database.URL == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+
+	ctx := context.Background()
+	pool, err := db.Connect(ctx, cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer pool.Close()
+
+	switch {
+	case *status:
+	case *baseline != "":
+		err = db.Baseline(ctx, pool, migrations.FS, *baseline)
+	default:
+		err = db.Migrate(ctx, pool, migrations.FS)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	current, expected, err := db.MigrationStatus(ctx, pool, migrations.FS)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("current: %s\nexpected: %s\n", current, expected)
+}
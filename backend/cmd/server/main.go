@@ -1,85 +1,187 @@
 package main
 
 import (
-    "log"
-    "os"
-
-    "github.com/gin-gonic/gin"
-    "github.com/gin-contrib/cors"
-    "github.com/joho/godotenv"
-    "github.com/Sabari-Vijayan/DBMS-project/internal/db"
-    "github.com/Sabari-Vijayan/DBMS-project/internal/handlers"
-    "github.com/Sabari-Vijayan/DBMS-project/internal/middleware"  // Add this
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	// Workers' time zones must resolve even where the OS has no zoneinfo
+	_ "time/tzdata"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/auth"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/config"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/db"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/health"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/logging"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/metrics"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/payments"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/ratelimit"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/server"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/storage"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/tracing"
+	"github.com/Sabari-Vijayan/DBMS-project/migrations"
+	"github.com/gin-gonic/gin"
 )
 
+var logger = logging.For("main")
+
+// fatal logs err and exits; deferred calls do not run
+func fatal(msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
+
 func main() {
-    // Load environment variables
-    if err := godotenv.Load(); err != nil {
-        log.Println("No .env file found")
-    }
-
-    // Initialize database
-    database, err := db.Connect()
-    if err != nil {
-        log.Fatal("Failed to connect to database:", err)
-    }
-    defer database.Close()
-
-    // Create handlers
-    authHandler := &handlers.AuthHandler{DB: database}
-    profileHandler := &handlers.ProfileHandler{DB: database}
-    jobHandler := &handlers.JobHandler{DB: database}
-    applicationHandler := &handlers.ApplicationHandler{DB: database}
-
-    // Create Gin router
-    router := gin.Default()
-
-    // CORS middleware
-    router.Use(cors.New(cors.Config{
-        AllowOrigins:     []string{"http://localhost:5173"},
-        AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-        AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-        ExposeHeaders:    []string{"Content-Length"},
-        AllowCredentials: true,
-    }))
-
-    // Health check
-    router.GET("/health", func(c *gin.Context) {
-        c.JSON(200, gin.H{"status": "ok"})
-    })
-
-    // Public routes (no authentication required)
-    router.POST("/api/register", authHandler.Register)
-    router.POST("/api/login", authHandler.Login)
-    router.GET("/api/jobs", jobHandler.GetJobs)              // Anyone can view jobs
-    router.GET("/api/jobs/:id", jobHandler.GetJob)           // Anyone can view job details
-
-    // Protected routes (authentication required)
-    protected := router.Group("/api")
-    protected.Use(middleware.AuthRequired())
-    {
-        // Profile routes
-        protected.GET("/profile/:id", profileHandler.GetProfile)
-        protected.PUT("/profile/:id", profileHandler.UpdateProfile)
-
-        // Job routes (employers only)
-        protected.POST("/jobs", middleware.EmployerOnly(), jobHandler.CreateJob)
-
-        // Application routes (workers only)
-        protected.POST("/applications", middleware.WorkerOnly(), applicationHandler.ApplyToJob)
-        protected.GET("/applications/worker/:workerId", middleware.WorkerOnly(), applicationHandler.GetWorkerApplications)
-
-        // Application routes (employers only)
-        protected.GET("/applications/job/:jobId", middleware.EmployerOnly(), applicationHandler.GetJobApplications)
-        protected.PUT("/applications/:id", middleware.EmployerOnly(), applicationHandler.UpdateApplicationStatus)
-    }
-
-    // Get port from environment or default to 8080
-    port := os.Getenv("PORT")
-    if port == "" {
-        port = "8080"
-    }
-
-    log.Printf("Server starting on port %s", port)
-    router.Run(":" + port)
+	configPath := flag.String("config", "", "path to a YAML or TOML config file (default $CONFIG_FILE)")
+	flag.Parse()
+
+	// Load and validate configuration before touching anything else
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fatal("Failed to load config", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		fatal("Invalid config", err)
+	}
+	if err := logging.Setup(cfg.Log); err != nil {
+		fatal("Failed to set up logging", err)
+	}
+	for _, warning := range cfg.Warnings() {
+		logger.Warn("Config warning", "warning", warning)
+	}
+	logger.Info("Config loaded", slog.Any("config", cfg.Redacted()))
+
+	if cfg.IsProduction() {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// Tracing must be set up before the pool so query spans are exported
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Telemetry)
+	if err != nil {
+		fatal("Failed to set up tracing", err)
+	}
+
+	// Initialize database
+	database, err := db.Connect(context.Background(), cfg.Database)
+	if err != nil {
+		fatal("Failed to connect to database", err)
+	}
+
+	if cfg.Database.AutoMigrate {
+		if err := db.Migrate(context.Background(), database, migrations.FS); err != nil {
+			fatal("Failed to apply migrations", err)
+		}
+	}
+
+	// Make sure the roles table knows every role the code uses
+	if err := db.EnsureRoles(context.Background(), database); err != nil {
+		fatal("Failed to sync roles", err)
+	}
+
+	if cfg.Telemetry.MetricsEnabled {
+		if err := metrics.RegisterPool(database); err != nil {
+			fatal("Failed to register pool metrics", err)
+		}
+	}
+
+	// Create stores; every query gets its own deadline on top of the request context
+	userStore := store.NewPgUserStore(database, cfg.Database.QueryTimeout)
+	jobStore := store.NewPgJobStore(database, cfg.Database.QueryTimeout)
+	applicationStore := store.NewPgApplicationStore(database, cfg.Database.QueryTimeout)
+	auditStore := store.NewPgAuditStore(database, cfg.Database.QueryTimeout)
+
+	documentStore := store.NewPgDocumentStore(database, cfg.Database.QueryTimeout)
+	availabilityStore := store.NewPgAvailabilityStore(database, cfg.Database.QueryTimeout)
+	bookingStore := store.NewPgBookingStore(database, cfg.Database.QueryTimeout)
+	paymentStore := store.NewPgPaymentStore(database, cfg.Database.QueryTimeout)
+	invoiceStore := store.NewPgInvoiceStore(database, cfg.Database.QueryTimeout)
+	organizationStore := store.NewPgOrganizationStore(database, cfg.Database.QueryTimeout)
+	workerStore := store.NewPgWorkerStore(database, cfg.Database.QueryTimeout)
+
+	blobs, err := storage.New(context.Background(), cfg.Storage, cfg.Auth.JWTSecret)
+	if err != nil {
+		fatal("Failed to set up file storage", err)
+	}
+
+	paymentProvider, err := payments.New(cfg.Payments, cfg.Auth.JWTSecret)
+	if err != nil {
+		fatal("Failed to set up payments", err)
+	}
+
+	var rateLimits ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimit.Store == "postgres" {
+		rateLimits = ratelimit.NewPgStore(database, cfg.Database.QueryTimeout)
+	}
+
+	router, err := server.New(cfg, server.Deps{
+		Users:        userStore,
+		Jobs:         jobStore,
+		Applications: applicationStore,
+		Audit:        auditStore,
+		Documents:    documentStore,
+		Availability: availabilityStore,
+		Bookings:     bookingStore,
+		Payments:     paymentStore,
+		Invoices:     invoiceStore,
+		Blobs:        blobs,
+
+		Organizations: organizationStore,
+		Workers:       workerStore,
+
+		PaymentProvider: paymentProvider,
+		Tokens:          auth.NewManager(cfg.Auth.JWTSecret, cfg.Auth.TokenTTL),
+		Health:          health.NewChecker(database, migrations.FS),
+		RateLimits:      rateLimits,
+	})
+	if err != nil {
+		fatal("Failed to build router", err)
+	}
+
+	httpServer := &http.Server{
+		Addr:              ":" + cfg.HTTP.Port,
+		Handler:           router,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+
+	// Stop on Ctrl+C or SIGTERM (sent by docker/kubernetes on deploy)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Buckets idle for an hour are full; forget them
+	go ratelimit.SweepEvery(ctx, rateLimits, 10*time.Minute, time.Hour, func(err error) {
+		logger.Warn("Failed to sweep rate limit buckets", "error", err)
+	})
+
+	go func() {
+		logger.Info("Server starting", "port", cfg.HTTP.Port)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("Server failed", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	logger.Info("Shutting down, draining in-flight requests")
+
+	// Let in-flight requests finish before the pool goes away
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("Graceful shutdown did not finish", "error", err)
+	}
+
+	database.Close()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Warn("Failed to flush traces", "error", err)
+	}
+	logger.Info("Server stopped")
 }
@@ -0,0 +1,140 @@
+// Package apierror defines the error responses the API sends to clients.
+//
+// Handlers report failures with c.Error(apierror.X(...)) and return; the
+// Middleware renders them as
+//
+//	{"code": "job_closed", "error": "This job is no longer accepting applications", "request_id": "..."}
+//
+// Clients should branch on code, which is stable. The error text is for
+// humans and may change. Causes (database errors etc.) are logged, never sent.
+package apierror
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Code is a stable, machine-readable error identifier
+type Code string
+
+const (
+	CodeBadRequest         Code = "bad_request"
+	CodeValidation         Code = "validation_failed"
+	CodeUnauthorized       Code = "unauthorized"
+	CodeInvalidCredentials Code = "invalid_credentials"
+	CodeForbidden          Code = "forbidden"
+	CodeNotFound           Code = "not_found"
+	CodeConflict           Code = "conflict"
+	CodeInvalidReference   Code = "invalid_reference"
+	CodeConstraint         Code = "constraint_violation"
+	CodeRateLimited        Code = "rate_limited"
+	CodeTooLarge           Code = "payload_too_large"
+	CodeUnsupportedType    Code = "unsupported_media_type"
+	CodeInternal           Code = "internal_error"
+
+	// Domain specific codes
+	CodeEmailTaken     Code = "email_taken"
+	CodeAlreadyApplied Code = "already_applied"
+	CodeJobClosed      Code = "job_closed"
+	CodeJobExpired     Code = "job_expired"
+	CodeQuotaExceeded  Code = "quota_exceeded"
+	// The worker is already booked for an overlapping job
+	CodeScheduleConflict Code = "schedule_conflict"
+	// The booking's state doesn't allow the action
+	CodeInvalidTransition Code = "invalid_transition"
+	// The booking already has a pending or successful payment
+	CodePaymentExists Code = "payment_exists"
+	// The payment provider refused the charge
+	CodePaymentFailed Code = "payment_failed"
+	// There is no money in escrow to refund
+	CodeNothingHeld Code = "nothing_held"
+	// The change would leave an organization without an owner
+	CodeLastOwner Code = "last_owner"
+	// The user is already on the organization's team
+	CodeAlreadyMember Code = "already_member"
+	// The user already has the role they asked for
+	CodeRoleHeld Code = "role_held"
+	// The job has a booking under way or money held for one
+	CodeJobBooked Code = "job_booked"
+)
+
+// FieldError describes one invalid request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error is an API error with the HTTP status it maps to
+type Error struct {
+	Status  int          `json:"-"`
+	Code    Code         `json:"code"`
+	Message string       `json:"error"`
+	Fields  []FieldError `json:"fields,omitempty"`
+	// Set when rendered so users can quote it in bug reports
+	RequestID string `json:"request_id,omitempty"`
+	Cause     error  `json:"-"`
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Cause)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// WithCause returns a copy of e that records the underlying error for logs
+func (e *Error) WithCause(err error) *Error {
+	copied := *e
+	copied.Cause = err
+	return &copied
+}
+
+func New(status int, code Code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func BadRequest(message string) *Error {
+	return New(http.StatusBadRequest, CodeBadRequest, message)
+}
+
+func Unauthorized(message string) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+func Forbidden(message string) *Error {
+	return New(http.StatusForbidden, CodeForbidden, message)
+}
+
+func NotFound(message string) *Error {
+	return New(http.StatusNotFound, CodeNotFound, message)
+}
+
+func Conflict(code Code, message string) *Error {
+	return New(http.StatusConflict, code, message)
+}
+
+// TooManyRequests is for rate limits and quotas; set Retry-After yourself
+func TooManyRequests(code Code, message string) *Error {
+	return New(http.StatusTooManyRequests, code, message)
+}
+
+// TooLarge is for uploads over their size limit
+func TooLarge(message string) *Error {
+	return New(http.StatusRequestEntityTooLarge, CodeTooLarge, message)
+}
+
+// UnsupportedType is for uploads of a file type the endpoint doesn't accept
+func UnsupportedType(message string) *Error {
+	return New(http.StatusUnsupportedMediaType, CodeUnsupportedType, message)
+}
+
+// Internal hides err from the client but keeps it for the logs
+func Internal(message string, err error) *Error {
+	return New(http.StatusInternalServerError, CodeInternal, message).WithCause(err)
+}
@@ -0,0 +1,56 @@
+package apierror
+
+import (
+	"errors"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/logging"
+	"github.com/gin-gonic/gin"
+)
+
+var logger = logging.For("apierror")
+
+// From converts any error into an API error. Unknown errors become a
+// generic 500 so their text never reaches the client.
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	if pgErr := FromPg(err); pgErr != nil {
+		return pgErr
+	}
+	return Internal("Internal server error", err)
+}
+
+// Middleware renders the last error a handler attached with c.Error
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		apiErr := From(c.Errors.Last().Err)
+		ctx := c.Request.Context()
+		if apiErr.Status >= 500 {
+			logger.ErrorContext(ctx, "request failed", "method", c.Request.Method, "path", c.Request.URL.Path, "error", apiErr)
+		} else {
+			logger.DebugContext(ctx, "request rejected", "method", c.Request.Method, "path", c.Request.URL.Path, "error", apiErr)
+		}
+
+		// Copy: handlers may return shared package-level errors
+		rendered := *apiErr
+		rendered.RequestID = logging.RequestID(ctx)
+		c.JSON(rendered.Status, &rendered)
+	}
+}
+
+// Wrap keeps constraint violations as client errors and turns anything
+// else into a 500 with the given message
+func Wrap(err error, message string) *Error {
+	if pgErr := FromPg(err); pgErr != nil {
+		return pgErr
+	}
+	return Internal(message, err)
+}
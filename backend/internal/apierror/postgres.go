@@ -0,0 +1,44 @@
+package apierror
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SQLSTATE codes we translate into client errors
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgCheckViolation      = "23514"
+)
+
+// FromPg maps constraint violations to 409/422 responses. It returns nil
+// for anything that isn't a constraint violation.
+func FromPg(err error) *Error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		return New(http.StatusConflict, CodeConflict, "Resource already exists").WithCause(err)
+	case pgForeignKeyViolation:
+		return New(http.StatusUnprocessableEntity, CodeInvalidReference, "Referenced resource does not exist").WithCause(err)
+	case pgCheckViolation:
+		return New(http.StatusUnprocessableEntity, CodeConstraint, "Value is not allowed").WithCause(err)
+	}
+	return nil
+}
+
+// IsUniqueViolation reports whether err is a unique constraint violation,
+// optionally on the named constraint
+func IsUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != pgUniqueViolation {
+		return false
+	}
+	return constraint == "" || pgErr.ConstraintName == constraint
+}
@@ -0,0 +1,77 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FromBinding turns a ShouldBindJSON error into a 400 with per-field details
+func FromBinding(err error) *Error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, FieldError{
+				Field:   fe.Field(),
+				Rule:    fe.Tag(),
+				Param:   fe.Param(),
+				Message: fieldMessage(fe),
+			})
+		}
+		e := New(http.StatusBadRequest, CodeValidation, "Request validation failed").WithCause(err)
+		e.Fields = fields
+		return e
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		e := New(http.StatusBadRequest, CodeValidation, "Request validation failed").WithCause(err)
+		e.Fields = []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Param:   typeErr.Type.String(),
+			Message: fmt.Sprintf("%s must be of type %s", typeErr.Field, typeErr.Type),
+		}}
+		return e
+	}
+
+	return BadRequest("Malformed request body").WithCause(err)
+}
+
+// Invalid is a validation failure of one field found by a handler rather
+// than by binding, e.g. a rule spanning two fields
+func Invalid(field, rule, message string) *Error {
+	e := New(http.StatusBadRequest, CodeValidation, "Request validation failed")
+	e.Fields = []FieldError{{Field: field, Rule: rule, Message: message}}
+	return e
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return fe.Field() + " is required"
+	case "email":
+		return fe.Field() + " must be a valid email address"
+	case "min":
+		return fmt.Sprintf("%s must be at least %s", fe.Field(), fe.Param())
+	case "max":
+		return fmt.Sprintf("%s must be at most %s", fe.Field(), fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", fe.Field(), fe.Param())
+	case "role":
+		return fe.Field() + " must be a valid role"
+	case "signup_role":
+		return fe.Field() + " must be worker or employer"
+	case "clock":
+		return fe.Field() + " must be a time of day as HH:MM"
+	case "question_kind":
+		return fe.Field() + " must be yes_no, number, text or choice"
+	case "timezone":
+		return fe.Field() + " must be a time zone such as Asia/Kolkata"
+	}
+	return fmt.Sprintf("%s failed the %s rule", fe.Field(), fe.Tag())
+}
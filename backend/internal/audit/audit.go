@@ -0,0 +1,170 @@
+// Package audit carries who is making a request down to the stores, which
+// write an audit_events row in the same transaction as each mutation.
+//
+// Middleware puts the client's IP, user agent and request ID in the
+// context; AuthRequired adds the actor. Stores read it with FromContext.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+)
+
+// Actions recorded in audit_events.action
+const (
+	UserRegistered               = "user.registered"
+	LoginSucceeded               = "auth.login"
+	LoginFailed                  = "auth.login_failed"
+	ProfileUpdated               = "profile.updated"
+	RoleAdded                    = "user.role_added"
+	ActiveRoleSwitched           = "user.active_role_switched"
+	AvatarUpdated                = "profile.avatar_updated"
+	SkillsUpdated                = "profile.skills_updated"
+	DocumentUploaded             = "document.uploaded"
+	DocumentDeleted              = "document.deleted"
+	AvailabilityUpdated          = "availability.updated"
+	AvailabilityExceptionAdded   = "availability.exception_added"
+	AvailabilityExceptionDeleted = "availability.exception_deleted"
+	JobCreated                   = "job.created"
+	JobUpdated                   = "job.updated"
+	JobDeleted                   = "job.deleted"
+	JobAttachmentAdded           = "job.attachment_added"
+	JobAttachmentDeleted         = "job.attachment_deleted"
+	ApplicationSubmitted         = "application.submitted"
+	ApplicationInvited           = "application.invited"
+	ApplicationReviewed          = "application.reviewed"
+	ApplicationScreenedOut       = "application.screened_out"
+	BookingCreated               = "booking.created"
+	PaymentCreated               = "payment.created"
+	InvoiceIssued                = "invoice.issued"
+	WorkExperienceAdded          = "work_experience.added"
+	OrganizationCreated          = "organization.created"
+	OrganizationUpdated          = "organization.updated"
+	MemberAdded                  = "organization.member_added"
+	MemberRoleChanged            = "organization.member_role_changed"
+	MemberRemoved                = "organization.member_removed"
+	InvitationCreated            = "organization.invitation_created"
+	InvitationRevoked            = "organization.invitation_revoked"
+	// Followed by the new status, e.g. "application.accepted"
+	ApplicationStatusPrefix = "application."
+	// Followed by the action, e.g. "booking.check_in"
+	BookingActionPrefix = "booking."
+	// Followed by the new status, e.g. "payment.succeeded"
+	PaymentStatusPrefix = "payment."
+)
+
+// Target types recorded in audit_events.target_type
+const (
+	TargetUser        = "user"
+	TargetJob         = "job"
+	TargetApplication = "application"
+	TargetDocument    = "document"
+	TargetBooking     = "booking"
+	TargetPayment     = "payment"
+	TargetInvoice     = "invoice"
+	// Membership and invitation events target the organization
+	TargetOrganization = "organization"
+)
+
+// Request describes where a mutation came from
+type Request struct {
+	// ActorID is 0 until the user is authenticated
+	ActorID   int
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+type requestKey struct{}
+
+// WithRequest stores r in ctx
+func WithRequest(ctx context.Context, r Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, r)
+}
+
+// WithActor records the authenticated user on top of the request details
+func WithActor(ctx context.Context, userID int) context.Context {
+	r := FromContext(ctx)
+	r.ActorID = userID
+	return WithRequest(ctx, r)
+}
+
+// FromContext returns the details stored by WithRequest; background jobs
+// and tests get the zero Request
+func FromContext(ctx context.Context) Request {
+	r, _ := ctx.Value(requestKey{}).(Request)
+	return r
+}
+
+// Diff compares the JSON forms of before and after and returns only the
+// fields that differ, as two objects. A nil before means a creation: every
+// field of after is returned and before is JSON null.
+func Diff(before, after any) (json.RawMessage, json.RawMessage, error) {
+	afterFields, err := fields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	if isNil(before) {
+		out, err := json.Marshal(afterFields)
+		return json.RawMessage("null"), out, err
+	}
+
+	beforeFields, err := fields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	changedBefore := map[string]json.RawMessage{}
+	changedAfter := map[string]json.RawMessage{}
+	for key, value := range afterFields {
+		if old, ok := beforeFields[key]; !ok || string(old) != string(value) {
+			changedBefore[key] = orNull(old)
+			changedAfter[key] = value
+		}
+	}
+	for key, old := range beforeFields {
+		if _, ok := afterFields[key]; !ok {
+			changedBefore[key] = old
+			changedAfter[key] = json.RawMessage("null")
+		}
+	}
+
+	b, err := json.Marshal(changedBefore)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := json.Marshal(changedAfter)
+	return b, a, err
+}
+
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// fields marshals v and splits the resulting object into its fields
+func fields(v any) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func orNull(v json.RawMessage) json.RawMessage {
+	if v == nil {
+		return json.RawMessage("null")
+	}
+	return v
+}
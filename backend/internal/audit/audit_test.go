@@ -0,0 +1,50 @@
+package audit
+
+import (
+	"context"
+	"testing"
+)
+
+type profile struct {
+	Name string  `json:"name"`
+	Bio  *string `json:"bio"`
+	Age  int     `json:"age"`
+}
+
+func TestDiff(t *testing.T) {
+	bio := "Plumber"
+	tests := []struct {
+		name          string
+		before, after any
+		wantBefore    string
+		wantAfter     string
+	}{
+		{"creation", (*profile)(nil), &profile{Name: "A", Age: 3}, `null`, `{"age":3,"bio":null,"name":"A"}`},
+		{"changed fields only", &profile{Name: "A", Age: 3}, &profile{Name: "A", Bio: &bio, Age: 4}, `{"age":3,"bio":null}`, `{"age":4,"bio":"Plumber"}`},
+		{"no change", profile{Name: "A"}, profile{Name: "A"}, `{}`, `{}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, after, err := Diff(tt.before, tt.after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(before) != tt.wantBefore || string(after) != tt.wantAfter {
+				t.Errorf("Diff = %s, %s; want %s, %s", before, after, tt.wantBefore, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	ctx := WithRequest(context.Background(), Request{IP: "10.0.0.1", RequestID: "r1"})
+	ctx = WithActor(ctx, 7)
+
+	got := FromContext(ctx)
+	if got != (Request{ActorID: 7, IP: "10.0.0.1", RequestID: "r1"}) {
+		t.Errorf("FromContext = %+v", got)
+	}
+	if FromContext(context.Background()) != (Request{}) {
+		t.Error("empty context should give the zero Request")
+	}
+}
@@ -2,32 +2,48 @@ package auth
 
 import (
     "errors"
-    "os"
+    "slices"
     "time"
 
     "github.com/golang-jwt/jwt/v5"
+    "github.com/Sabari-Vijayan/DBMS-project/internal/models"
 )
 
 type Claims struct {
-    UserID   int    `json:"user_id"`
-    Email    string `json:"email"`
-    UserType string `json:"user_type"`
+    UserID   int         `json:"user_id"`
+    Email    string      `json:"email"`
+    // The active role, which the user switches between
+    UserType models.Role `json:"user_type"`
+    // Every role the user has
+    Roles    []models.Role `json:"roles,omitempty"`
     jwt.RegisteredClaims
 }
 
-// Generate JWT token
-func GenerateToken(userID int, email string, userType string) (string, error) {
-    secret := os.Getenv("JWT_SECRET")
-    if secret == "" {
-        secret = "your-default-secret-key" // Fallback for development
-    }
+// HasRole reports whether the token's user has role. Tokens issued before
+// users could hold several roles only carry the active one.
+func (c *Claims) HasRole(role models.Role) bool {
+    return c.UserType == role || slices.Contains(c.Roles, role)
+}
+
+// Manager signs and verifies tokens with one secret
+type Manager struct {
+    secret []byte
+    ttl    time.Duration
+}
 
-    expirationTime := time.Now().Add(24 * time.Hour) // Token expires in 24 hours
+func NewManager(secret string, ttl time.Duration) *Manager {
+    return &Manager{secret: []byte(secret), ttl: ttl}
+}
+
+// Generate JWT token for a user acting as userType, one of their roles
+func (m *Manager) GenerateToken(userID int, email string, userType models.Role, roles []models.Role) (string, error) {
+    expirationTime := time.Now().Add(m.ttl)
 
     claims := &Claims{
         UserID:   userID,
         Email:    email,
         UserType: userType,
+        Roles:    roles,
         RegisteredClaims: jwt.RegisteredClaims{
             ExpiresAt: jwt.NewNumericDate(expirationTime),
             IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -35,7 +51,7 @@ func GenerateToken(userID int, email string, userType string) (string, error) {
     }
 
     token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    tokenString, err := token.SignedString([]byte(secret))
+    tokenString, err := token.SignedString(m.secret)
     if err != nil {
         return "", err
     }
@@ -44,17 +60,12 @@ func GenerateToken(userID int, email string, userType string) (string, error) {
 }
 
 // Verify JWT token
-func VerifyToken(tokenString string) (*Claims, error) {
-    secret := os.Getenv("JWT_SECRET")
-    if secret == "" {
-        secret = "your-default-secret-key"
-    }
-
+func (m *Manager) VerifyToken(tokenString string) (*Claims, error) {
     claims := &Claims{}
 
     token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-        return []byte(secret), nil
-    })
+        return m.secret, nil
+    }, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
     if err != nil {
         return nil, err
@@ -0,0 +1,205 @@
+// Package booking is the state machine a hire goes through after an
+// employer accepts an application.
+//
+// A booking starts scheduled. The first check-in, by either side, moves it
+// to in_progress; it completes once both sides confirm. A scheduled booking
+// can instead be cancelled by either side, or marked no_show by the
+// employer once the job's start has passed. The employer can rate the
+// worker once a booking completes. Everything here is pure, so
+// the rules can be tested without a database.
+package booking
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+)
+
+// Statuses a booking moves through
+const (
+	Scheduled  = "scheduled"
+	InProgress = "in_progress"
+	Completed  = "completed"
+	Cancelled  = "cancelled"
+	NoShow     = "no_show"
+)
+
+// CheckInOpens is how long before a scheduled job's start check-in opens
+const CheckInOpens = time.Hour
+
+// ErrForbidden is returned for a user who isn't the booking's worker or
+// employer, or who is on the wrong side for the action
+var ErrForbidden = errors.New("not allowed on this booking")
+
+// TransitionError explains why an action isn't allowed in the booking's
+// current state
+type TransitionError struct {
+	Reason string
+}
+
+func (e *TransitionError) Error() string {
+	return e.Reason
+}
+
+func invalid(format string, args ...any) error {
+	return &TransitionError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// Action is something one side does to a booking
+type Action string
+
+const (
+	CheckIn      Action = "check_in"
+	CheckOut     Action = "check_out"
+	Confirm      Action = "confirm"
+	Cancel       Action = "cancel"
+	ReportNoShow Action = "no_show"
+	Rate         Action = "rate"
+)
+
+// Event is a user doing an action at a time
+type Event struct {
+	Action Action
+	UserID int
+	At     time.Time
+	// Why a booking was cancelled, or the review with a rating; optional
+	Reason string
+	// The worker's rating, 1 to 5, for Rate
+	Rating int
+}
+
+// side holds the fields one party of a booking writes
+type side struct {
+	checkedIn, checkedOut, confirmed **time.Time
+}
+
+// Apply checks e is allowed and records it on b. It returns ErrForbidden
+// if the user may not do the action, and a *TransitionError if the
+// booking's state doesn't allow it.
+func Apply(b *models.Booking, e Event) error {
+	var own side
+	isWorker := e.UserID == b.WorkerID
+	switch {
+	case isWorker:
+		own = side{&b.WorkerCheckedInAt, &b.WorkerCheckedOutAt, &b.WorkerConfirmedAt}
+	case e.UserID == b.EmployerID:
+		own = side{&b.EmployerCheckedInAt, &b.EmployerCheckedOutAt, &b.EmployerConfirmedAt}
+	default:
+		return ErrForbidden
+	}
+	at := e.At
+
+	switch e.Action {
+	case CheckIn:
+		if b.Status != Scheduled && b.Status != InProgress {
+			return invalid("You can't check in to a %s booking", label(b.Status))
+		}
+		if *own.checkedIn != nil {
+			return invalid("You have already checked in")
+		}
+		if b.StartsAt != nil && at.Before(b.StartsAt.Add(-CheckInOpens)) {
+			return invalid("Check-in opens an hour before the job starts")
+		}
+		*own.checkedIn = &at
+		b.Status = InProgress
+
+	case CheckOut:
+		if b.Status != InProgress {
+			return invalid("You can't check out of a %s booking", label(b.Status))
+		}
+		if *own.checkedIn == nil {
+			return invalid("You haven't checked in")
+		}
+		if *own.checkedOut != nil {
+			return invalid("You have already checked out")
+		}
+		*own.checkedOut = &at
+
+	case Confirm:
+		if b.Status != InProgress {
+			return invalid("Only a booking in progress can be confirmed complete")
+		}
+		if *own.confirmed != nil {
+			return invalid("You have already confirmed this booking")
+		}
+		*own.confirmed = &at
+		if b.WorkerConfirmedAt != nil && b.EmployerConfirmedAt != nil {
+			complete(b, at)
+		}
+
+	case Cancel:
+		if b.Status != Scheduled {
+			return invalid("Only a scheduled booking can be cancelled")
+		}
+		b.Status = Cancelled
+		b.CancelledBy = &e.UserID
+		if e.Reason != "" {
+			b.CancelReason = &e.Reason
+		}
+
+	case ReportNoShow:
+		if isWorker {
+			return fmt.Errorf("%w: only the employer can report a no-show", ErrForbidden)
+		}
+		if b.Status != Scheduled {
+			return invalid("Only a scheduled booking can be marked as a no-show")
+		}
+		if b.StartsAt != nil && at.Before(*b.StartsAt) {
+			return invalid("The job hasn't started yet")
+		}
+		b.Status = NoShow
+
+	case Rate:
+		if isWorker {
+			return fmt.Errorf("%w: only the employer can rate the worker", ErrForbidden)
+		}
+		if b.Status != Completed {
+			return invalid("Only a completed booking can be rated")
+		}
+		if b.WorkerRating != nil {
+			return invalid("You have already rated this worker for this booking")
+		}
+		if e.Rating < 1 || e.Rating > 5 {
+			return fmt.Errorf("rating %d is not between 1 and 5", e.Rating)
+		}
+		b.WorkerRating = &e.Rating
+		b.WorkerRatedAt = &at
+		if e.Reason != "" {
+			b.WorkerReview = &e.Reason
+		}
+
+	default:
+		return fmt.Errorf("unknown booking action %q", e.Action)
+	}
+	return nil
+}
+
+// complete marks b completed, checking out anyone still checked in
+func complete(b *models.Booking, at time.Time) {
+	b.Status = Completed
+	b.CompletedAt = &at
+	if b.WorkerCheckedInAt != nil && b.WorkerCheckedOutAt == nil {
+		b.WorkerCheckedOutAt = &at
+	}
+	if b.EmployerCheckedInAt != nil && b.EmployerCheckedOutAt == nil {
+		b.EmployerCheckedOutAt = &at
+	}
+}
+
+// label is a status for messages, e.g. "no-show"
+func label(status string) string {
+	switch status {
+	case InProgress:
+		return "in-progress"
+	case NoShow:
+		return "no-show"
+	}
+	return status
+}
+
+// Active reports whether the booking still holds the worker's time
+func Active(status string) bool {
+	return status == Scheduled || status == InProgress
+}
@@ -0,0 +1,155 @@
+package booking
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+)
+
+const (
+	worker   = 2
+	employer = 1
+)
+
+var start = time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+func newBooking() *models.Booking {
+	ends := start.Add(3 * time.Hour)
+	return &models.Booking{ID: 1, WorkerID: worker, EmployerID: employer, Status: Scheduled, StartsAt: &start, EndsAt: &ends}
+}
+
+func at(minutes int) time.Time {
+	return start.Add(time.Duration(minutes) * time.Minute)
+}
+
+func TestHappyPath(t *testing.T) {
+	b := newBooking()
+	steps := []struct {
+		event  Event
+		status string
+	}{
+		{Event{Action: CheckIn, UserID: worker, At: at(-10)}, InProgress},
+		{Event{Action: CheckIn, UserID: employer, At: at(0)}, InProgress},
+		{Event{Action: CheckOut, UserID: worker, At: at(180)}, InProgress},
+		{Event{Action: Confirm, UserID: worker, At: at(181)}, InProgress},
+		{Event{Action: Confirm, UserID: employer, At: at(200)}, Completed},
+	}
+	for _, step := range steps {
+		if err := Apply(b, step.event); err != nil {
+			t.Fatalf("%s by %d: %v", step.event.Action, step.event.UserID, err)
+		}
+		if b.Status != step.status {
+			t.Fatalf("after %s by %d: status = %s, want %s", step.event.Action, step.event.UserID, b.Status, step.status)
+		}
+	}
+	if b.CompletedAt == nil || !b.CompletedAt.Equal(at(200)) {
+		t.Errorf("CompletedAt = %v", b.CompletedAt)
+	}
+	// The employer never checked out; completing does it for them
+	if b.EmployerCheckedOutAt == nil || !b.EmployerCheckedOutAt.Equal(at(200)) {
+		t.Errorf("EmployerCheckedOutAt = %v", b.EmployerCheckedOutAt)
+	}
+	if !b.WorkerCheckedOutAt.Equal(at(180)) {
+		t.Errorf("WorkerCheckedOutAt = %v", b.WorkerCheckedOutAt)
+	}
+}
+
+func TestRejectedTransitions(t *testing.T) {
+	inProgress := func() *models.Booking {
+		b := newBooking()
+		if err := Apply(b, Event{Action: CheckIn, UserID: worker, At: at(0)}); err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	withStatus := func(status string) func() *models.Booking {
+		return func() *models.Booking { b := newBooking(); b.Status = status; return b }
+	}
+
+	tests := []struct {
+		name    string
+		booking func() *models.Booking
+		event   Event
+		want    error // nil means a *TransitionError
+	}{
+		{"stranger", newBooking, Event{Action: CheckIn, UserID: 9, At: at(0)}, ErrForbidden},
+		{"check in too early", newBooking, Event{Action: CheckIn, UserID: worker, At: at(-61)}, nil},
+		{"check in twice", inProgress, Event{Action: CheckIn, UserID: worker, At: at(5)}, nil},
+		{"check in to cancelled", withStatus(Cancelled), Event{Action: CheckIn, UserID: worker, At: at(0)}, nil},
+		{"check out before checking in", inProgress, Event{Action: CheckOut, UserID: employer, At: at(5)}, nil},
+		{"check out of scheduled", newBooking, Event{Action: CheckOut, UserID: worker, At: at(5)}, nil},
+		{"confirm scheduled", newBooking, Event{Action: Confirm, UserID: worker, At: at(5)}, nil},
+		{"cancel in progress", inProgress, Event{Action: Cancel, UserID: employer, At: at(5)}, nil},
+		{"worker reports no-show", newBooking, Event{Action: ReportNoShow, UserID: worker, At: at(5)}, ErrForbidden},
+		{"no-show before start", newBooking, Event{Action: ReportNoShow, UserID: employer, At: at(-5)}, nil},
+		{"no-show once checked in", inProgress, Event{Action: ReportNoShow, UserID: employer, At: at(30)}, nil},
+		{"anything once completed", withStatus(Completed), Event{Action: Confirm, UserID: worker, At: at(5)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.booking()
+			before := *b
+			err := Apply(b, tt.event)
+			if tt.want != nil {
+				if !errors.Is(err, tt.want) {
+					t.Errorf("err = %v, want %v", err, tt.want)
+				}
+			} else if _, ok := err.(*TransitionError); !ok {
+				t.Errorf("err = %v, want a TransitionError", err)
+			}
+			if b.Status != before.Status {
+				t.Errorf("status changed to %s", b.Status)
+			}
+		})
+	}
+}
+
+func TestCancelAndNoShow(t *testing.T) {
+	b := newBooking()
+	if err := Apply(b, Event{Action: Cancel, UserID: worker, At: at(-600), Reason: "Sick"}); err != nil {
+		t.Fatal(err)
+	}
+	if b.Status != Cancelled || *b.CancelledBy != worker || *b.CancelReason != "Sick" {
+		t.Errorf("cancelled booking = %+v", b)
+	}
+
+	b = newBooking()
+	if err := Apply(b, Event{Action: ReportNoShow, UserID: employer, At: at(30)}); err != nil {
+		t.Fatal(err)
+	}
+	if b.Status != NoShow || Active(b.Status) {
+		t.Errorf("status = %s", b.Status)
+	}
+
+	// Unscheduled jobs can be checked into or marked no-show any time
+	b = newBooking()
+	b.StartsAt, b.EndsAt = nil, nil
+	if err := Apply(b, Event{Action: CheckIn, UserID: worker, At: at(-6000)}); err != nil {
+		t.Errorf("check in to unscheduled job: %v", err)
+	}
+}
+
+func TestRate(t *testing.T) {
+	b := newBooking()
+	if err := Apply(b, Event{Action: Rate, UserID: employer, At: at(0), Rating: 5}); err == nil {
+		t.Error("rated a scheduled booking")
+	}
+
+	b.Status = Completed
+	if err := Apply(b, Event{Action: Rate, UserID: worker, At: at(300), Rating: 5}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("worker rating themselves: err = %v, want ErrForbidden", err)
+	}
+	if err := Apply(b, Event{Action: Rate, UserID: employer, At: at(300), Rating: 4, Reason: "Tidy work"}); err != nil {
+		t.Fatal(err)
+	}
+	if *b.WorkerRating != 4 || *b.WorkerReview != "Tidy work" || !b.WorkerRatedAt.Equal(at(300)) {
+		t.Errorf("rated booking = %+v", b)
+	}
+
+	var transition *TransitionError
+	if err := Apply(b, Event{Action: Rate, UserID: employer, At: at(400), Rating: 1}); !errors.As(err, &transition) {
+		t.Errorf("rating twice: err = %v, want a TransitionError", err)
+	}
+}
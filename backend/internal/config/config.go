@@ -0,0 +1,269 @@
+// Package config loads the server configuration into one typed struct.
+//
+// Values are layered, later layers winning:
+//
+//  1. defaults (Default)
+//  2. an optional YAML or TOML file (CONFIG_FILE or the -config flag)
+//  3. a .env file in the working directory
+//  4. the process environment
+//
+// Each field names its file key with `config:"section.key"` and its
+// environment variable with `env:"NAME"`. Fields tagged `secret:"true"`
+// are masked by Redacted so the config can be logged safely.
+package config
+
+import (
+	"time"
+)
+
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+type Config struct {
+	Env       string `config:"env" env:"APP_ENV"`
+	HTTP      HTTPConfig
+	Log       LogConfig
+	Database  DatabaseConfig
+	Auth      AuthConfig
+	CORS      CORSConfig
+	RateLimit RateLimitConfig
+	Quota     QuotaConfig
+	Storage   StorageConfig
+	Payments  PaymentsConfig
+	Recommend RecommendConfig
+	Telemetry TelemetryConfig
+	Features  FeatureFlags
+
+	warnings []string
+}
+
+type HTTPConfig struct {
+	Port              string        `config:"http.port" env:"PORT"`
+	ReadHeaderTimeout time.Duration `config:"http.read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `config:"http.read_timeout" env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout      time.Duration `config:"http.write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `config:"http.idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout   time.Duration `config:"http.shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	// Addresses or CIDRs of the reverse proxies whose X-Forwarded-For is
+	// believed. Empty trusts none, so clients are always the peer address
+	// and can't pick their own rate limit bucket.
+	TrustedProxies []string `config:"http.trusted_proxies" env:"TRUSTED_PROXIES"`
+}
+
+type LogConfig struct {
+	// debug, info, warn or error
+	Level string `config:"log.level" env:"LOG_LEVEL"`
+	// json or text
+	Format string `config:"log.format" env:"LOG_FORMAT"`
+	// Per-package overrides such as "db=debug"
+	Levels []string `config:"log.levels" env:"LOG_LEVELS"`
+}
+
+type DatabaseConfig struct {
+	URL          string        `config:"database.url" env:"DATABASE_URL" secret:"true"`
+	QueryTimeout time.Duration `config:"database.query_timeout" env:"DB_QUERY_TIMEOUT"`
+	MaxConns     int32         `config:"database.max_conns" env:"DB_MAX_CONNS"`
+	MinConns     int32         `config:"database.min_conns" env:"DB_MIN_CONNS"`
+	// Connections are closed after this long, and after being idle this long
+	MaxConnLifetime time.Duration `config:"database.max_conn_lifetime" env:"DB_MAX_CONN_LIFETIME"`
+	MaxConnIdleTime time.Duration `config:"database.max_conn_idle_time" env:"DB_MAX_CONN_IDLE_TIME"`
+	// How often idle connections are checked and the minimum is topped up
+	HealthCheckPeriod time.Duration `config:"database.health_check_period" env:"DB_HEALTH_CHECK_PERIOD"`
+	// Apply pending migrations on startup
+	AutoMigrate bool `config:"database.auto_migrate" env:"DB_AUTO_MIGRATE"`
+}
+
+type AuthConfig struct {
+	JWTSecret string        `config:"auth.jwt_secret" env:"JWT_SECRET" secret:"true"`
+	TokenTTL  time.Duration `config:"auth.token_ttl" env:"JWT_TTL"`
+	// How long an invitation to join an organization can be accepted for
+	InvitationTTL time.Duration `config:"auth.invitation_ttl" env:"INVITATION_TTL"`
+}
+
+type CORSConfig struct {
+	AllowedOrigins []string `config:"cors.allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+}
+
+type RateLimitConfig struct {
+	Enabled bool `config:"rate_limit.enabled" env:"RATE_LIMIT_ENABLED"`
+	// memory (per instance) or postgres (shared by all instances)
+	Store string `config:"rate_limit.store" env:"RATE_LIMIT_STORE"`
+	// Sustained requests per minute and burst size per client, for all of /api
+	RequestsPerMinute int `config:"rate_limit.requests_per_minute" env:"RATE_LIMIT_RPM"`
+	Burst             int `config:"rate_limit.burst" env:"RATE_LIMIT_BURST"`
+	// Login and registration, per IP; slows down password guessing
+	AuthRequestsPerMinute int `config:"rate_limit.auth_requests_per_minute" env:"RATE_LIMIT_AUTH_RPM"`
+	AuthBurst             int `config:"rate_limit.auth_burst" env:"RATE_LIMIT_AUTH_BURST"`
+	// Posting jobs and applying, per user
+	WriteRequestsPerMinute int `config:"rate_limit.write_requests_per_minute" env:"RATE_LIMIT_WRITE_RPM"`
+	WriteBurst             int `config:"rate_limit.write_burst" env:"RATE_LIMIT_WRITE_BURST"`
+}
+
+// QuotaConfig caps what one account may have or do; 0 means no cap
+type QuotaConfig struct {
+	MaxOpenJobsPerEmployer int `config:"quota.max_open_jobs_per_employer" env:"QUOTA_MAX_OPEN_JOBS"`
+	// Counted over the last 24 hours
+	MaxApplicationsPerDay int `config:"quota.max_applications_per_day" env:"QUOTA_MAX_APPLICATIONS_PER_DAY"`
+}
+
+// StorageConfig says where uploads go and how big they may be
+type StorageConfig struct {
+	// local or s3
+	Backend string `config:"storage.backend" env:"STORAGE_BACKEND"`
+	// Directory for the local backend
+	LocalDir string `config:"storage.local_dir" env:"STORAGE_LOCAL_DIR"`
+	// Prefix for the local backend's signed URLs, e.g.
+	// https://api.example.com; empty gives URLs relative to the API
+	PublicURL string `config:"storage.public_url" env:"STORAGE_PUBLIC_URL"`
+	// How long signed file URLs stay valid
+	URLTTL time.Duration `config:"storage.url_ttl" env:"STORAGE_URL_TTL"`
+
+	// S3 or a compatible server such as MinIO; the endpoint is host:port
+	S3Endpoint  string `config:"storage.s3_endpoint" env:"S3_ENDPOINT"`
+	S3Region    string `config:"storage.s3_region" env:"S3_REGION"`
+	S3Bucket    string `config:"storage.s3_bucket" env:"S3_BUCKET"`
+	S3AccessKey string `config:"storage.s3_access_key" env:"S3_ACCESS_KEY" secret:"true"`
+	S3SecretKey string `config:"storage.s3_secret_key" env:"S3_SECRET_KEY" secret:"true"`
+	S3UseSSL    bool   `config:"storage.s3_use_ssl" env:"S3_USE_SSL"`
+
+	// Upload size limits in bytes
+	MaxAvatarBytes     int64 `config:"storage.max_avatar_bytes" env:"UPLOAD_MAX_AVATAR_BYTES"`
+	MaxDocumentBytes   int64 `config:"storage.max_document_bytes" env:"UPLOAD_MAX_DOCUMENT_BYTES"`
+	MaxAttachmentBytes int64 `config:"storage.max_attachment_bytes" env:"UPLOAD_MAX_ATTACHMENT_BYTES"`
+	// Photos and PDFs per job
+	MaxJobAttachments int `config:"storage.max_job_attachments" env:"UPLOAD_MAX_JOB_ATTACHMENTS"`
+}
+
+// PaymentsConfig chooses the payment provider that funds escrow
+type PaymentsConfig struct {
+	// Only fake, an in-memory stand-in, so far
+	Provider string `config:"payments.provider" env:"PAYMENT_PROVIDER"`
+	// ISO 4217 code for jobs posted without a currency
+	Currency string `config:"payments.currency" env:"PAYMENT_CURRENCY"`
+	// Signs provider webhooks; derived from JWT_SECRET when empty
+	WebhookSecret string `config:"payments.webhook_secret" env:"PAYMENT_WEBHOOK_SECRET" secret:"true"`
+	// Webhooks signed longer ago than this are rejected as possible replays
+	WebhookTolerance time.Duration `config:"payments.webhook_tolerance" env:"PAYMENT_WEBHOOK_TOLERANCE"`
+}
+
+// RecommendConfig weighs the factors of a job recommendation; only the
+// ratios between the four weights matter
+type RecommendConfig struct {
+	CategoryWeight  float64 `config:"recommend.category_weight" env:"RECOMMEND_CATEGORY_WEIGHT"`
+	DistanceWeight  float64 `config:"recommend.distance_weight" env:"RECOMMEND_DISTANCE_WEIGHT"`
+	PayWeight       float64 `config:"recommend.pay_weight" env:"RECOMMEND_PAY_WEIGHT"`
+	FreshnessWeight float64 `config:"recommend.freshness_weight" env:"RECOMMEND_FRESHNESS_WEIGHT"`
+	// Distance at which a job's distance score falls to a half
+	DistanceHalfKm float64 `config:"recommend.distance_half_km" env:"RECOMMEND_DISTANCE_HALF_KM"`
+	// Age at which a job's freshness score falls to a half
+	FreshnessHalfLife time.Duration `config:"recommend.freshness_half_life" env:"RECOMMEND_FRESHNESS_HALF_LIFE"`
+}
+
+type TelemetryConfig struct {
+	// Serve Prometheus metrics at /metrics
+	MetricsEnabled bool `config:"telemetry.metrics_enabled" env:"METRICS_ENABLED"`
+	// Where spans go: none, stdout or otlp
+	TracingExporter string `config:"telemetry.tracing_exporter" env:"TRACING_EXPORTER"`
+	// OTLP/HTTP collector URL, e.g. http://localhost:4318
+	OTLPEndpoint string `config:"telemetry.otlp_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	ServiceName  string `config:"telemetry.service_name" env:"OTEL_SERVICE_NAME"`
+	// Fraction of new traces to keep, 0 to 1
+	SampleRatio float64 `config:"telemetry.sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// FeatureFlags switch optional behaviour on or off without a deploy
+type FeatureFlags struct {
+	// Serve the Swagger UI at /api/docs (the JSON spec is always served)
+	DocsUI bool `config:"features.docs_ui" env:"FEATURE_DOCS_UI"`
+}
+
+// Default returns the development defaults. DATABASE_URL and JWT_SECRET
+// have no defaults; see Validate.
+func Default() Config {
+	return Config{
+		Env: EnvDevelopment,
+		HTTP: HTTPConfig{
+			Port:              "8080",
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Database: DatabaseConfig{
+			QueryTimeout: 5 * time.Second,
+			MaxConns:     10,
+			MinConns:     0,
+
+			MaxConnLifetime:   time.Hour,
+			MaxConnIdleTime:   30 * time.Minute,
+			HealthCheckPeriod: time.Minute,
+		},
+		Auth: AuthConfig{
+			TokenTTL:      24 * time.Hour,
+			InvitationTTL: 7 * 24 * time.Hour,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"http://localhost:5173"},
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:           true,
+			Store:             "memory",
+			RequestsPerMinute: 120,
+			Burst:             30,
+
+			AuthRequestsPerMinute:  10,
+			AuthBurst:              5,
+			WriteRequestsPerMinute: 10,
+			WriteBurst:             5,
+		},
+		Quota: QuotaConfig{
+			MaxOpenJobsPerEmployer: 25,
+			MaxApplicationsPerDay:  30,
+		},
+		Storage: StorageConfig{
+			Backend:  "local",
+			LocalDir: "uploads",
+			URLTTL:   15 * time.Minute,
+			S3Region: "us-east-1",
+			S3UseSSL: true,
+
+			MaxAvatarBytes:     5 << 20,
+			MaxDocumentBytes:   10 << 20,
+			MaxAttachmentBytes: 10 << 20,
+			MaxJobAttachments:  5,
+		},
+		Payments: PaymentsConfig{
+			Provider:         "fake",
+			Currency:         "INR",
+			WebhookTolerance: 5 * time.Minute,
+		},
+		Recommend: RecommendConfig{
+			CategoryWeight:    0.4,
+			DistanceWeight:    0.25,
+			PayWeight:         0.2,
+			FreshnessWeight:   0.15,
+			DistanceHalfKm:    10,
+			FreshnessHalfLife: 48 * time.Hour,
+		},
+		Telemetry: TelemetryConfig{
+			MetricsEnabled:  true,
+			TracingExporter: "none",
+			ServiceName:     "jobseeker-api",
+			SampleRatio:     1,
+		},
+		Features: FeatureFlags{
+			DocsUI: true,
+		},
+	}
+}
+
+func (c Config) IsProduction() bool {
+	return c.Env == EnvProduction
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func validConfig() Config {
+	cfg := Default()
+	cfg.Database.URL = "postgres://app:hunter2@db:5432/app"
+	cfg.Auth.JWTSecret = "dev-secret"
+	return cfg
+}
+
+func TestLoadLayers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	err := os.WriteFile(path, []byte(`
+http:
+  port: "9000"
+  read_timeout: 20s
+database:
+  url: postgres://file@db/app
+  max_conns: 25
+cors:
+  allowed_origins: [https://a.example, https://b.example]
+features:
+  docs_ui: false
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Chdir(dir) // no .env here
+	t.Setenv("DB_MAX_CONNS", "40")
+	t.Setenv("JWT_SECRET", "from-env")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.HTTP.Port != "9000" || cfg.HTTP.ReadTimeout != 20*time.Second {
+		t.Errorf("file values not applied: %+v", cfg.HTTP)
+	}
+	if cfg.Database.MaxConns != 40 {
+		t.Errorf("env should override file: max_conns = %d", cfg.Database.MaxConns)
+	}
+	if cfg.Database.URL != "postgres://file@db/app" || cfg.Auth.JWTSecret != "from-env" {
+		t.Errorf("unexpected values: %+v", cfg)
+	}
+	if strings.Join(cfg.CORS.AllowedOrigins, " ") != "https://a.example https://b.example" {
+		t.Errorf("origins = %v", cfg.CORS.AllowedOrigins)
+	}
+	if cfg.Features.DocsUI {
+		t.Error("feature flag from file not applied")
+	}
+	if cfg.HTTP.WriteTimeout != Default().HTTP.WriteTimeout {
+		t.Error("unset values should keep their defaults")
+	}
+}
+
+func TestLoadGeneratesDevelopmentSecret(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("JWT_SECRET", "")
+	os.Unsetenv("JWT_SECRET")
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Auth.JWTSecret) < minProductionSecretLength || len(cfg.Warnings()) == 0 {
+		t.Fatalf("want a random secret and a warning, got %q %v", cfg.Auth.JWTSecret, cfg.Warnings())
+	}
+}
+
+func TestValidateProductionRefusesInsecureDefaults(t *testing.T) {
+	cfg := validConfig()
+	cfg.Env = EnvProduction
+	cfg.Auth.JWTSecret = "your-default-secret-key"
+	cfg.CORS.AllowedOrigins = []string{"http://localhost:5173", "*"}
+	cfg.RateLimit.Enabled = false
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("production config with insecure defaults was accepted")
+	}
+	for _, want := range []string{"JWT_SECRET must be at least", "placeholder", "localhost", "must not contain *", "RATE_LIMIT_ENABLED"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should mention %q:\n%v", want, err)
+		}
+	}
+
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("development config rejected: %v", err)
+	}
+}
+
+func TestValidateRequiresDatabaseURL(t *testing.T) {
+	cfg := validConfig()
+	cfg.Database.URL = ""
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "DATABASE_URL is required") {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestValidateS3NeedsBucketAndKeys(t *testing.T) {
+	cfg := validConfig()
+	cfg.Storage.Backend = "s3"
+	cfg.Storage.S3Endpoint = "localhost:9000"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "S3_BUCKET") {
+		t.Fatalf("got %v", err)
+	}
+
+	cfg.Storage.S3Bucket, cfg.Storage.S3AccessKey, cfg.Storage.S3SecretKey = "uploads", "minio", "minio-secret"
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if redacted := cfg.Redacted(); redacted.Storage.S3SecretKey != "REDACTED" {
+		t.Errorf("S3 secret leaked: %s", redacted.Storage.S3SecretKey)
+	}
+}
+
+func TestValidatePayments(t *testing.T) {
+	cfg := validConfig()
+	cfg.Payments.Provider = "stripe"
+	cfg.Payments.Currency = "rupees"
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "PAYMENT_PROVIDER") || !strings.Contains(err.Error(), "PAYMENT_CURRENCY") {
+		t.Fatalf("got %v", err)
+	}
+
+	cfg.Payments.Provider, cfg.Payments.Currency = "fake", "USD"
+	cfg.Payments.WebhookSecret = "whsec"
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if redacted := cfg.Redacted(); redacted.Payments.WebhookSecret != "REDACTED" {
+		t.Errorf("webhook secret leaked: %s", redacted.Payments.WebhookSecret)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := validConfig()
+	redacted := cfg.Redacted()
+
+	if strings.Contains(redacted.Database.URL, "hunter2") || !strings.Contains(redacted.Database.URL, "db:5432") {
+		t.Errorf("database URL not redacted properly: %s", redacted.Database.URL)
+	}
+	if redacted.Auth.JWTSecret != "REDACTED" {
+		t.Errorf("secret leaked: %s", redacted.Auth.JWTSecret)
+	}
+	if cfg.Auth.JWTSecret != "dev-secret" {
+		t.Error("Redacted modified the original")
+	}
+}
+
+func TestValidateRecommendWeights(t *testing.T) {
+	cfg := validConfig()
+	cfg.Recommend.CategoryWeight, cfg.Recommend.DistanceWeight, cfg.Recommend.PayWeight, cfg.Recommend.FreshnessWeight = 0, 0, 0, 0
+	cfg.Recommend.FreshnessHalfLife = 0
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "RECOMMEND_*_WEIGHT") || !strings.Contains(err.Error(), "RECOMMEND_FRESHNESS_HALF_LIFE") {
+		t.Fatalf("got %v", err)
+	}
+
+	cfg.Recommend.PayWeight, cfg.Recommend.FreshnessHalfLife = 1, time.Hour
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateTrustedProxies(t *testing.T) {
+	cfg := validConfig()
+	cfg.HTTP.TrustedProxies = []string{"10.0.0.0/8", "192.0.2.7", "proxy.internal"}
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), `TRUSTED_PROXIES entries must be IP addresses or CIDRs, got "proxy.internal"`) {
+		t.Fatalf("err = %v", err)
+	}
+
+	cfg.HTTP.TrustedProxies = cfg.HTTP.TrustedProxies[:2]
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("valid proxies rejected: %v", err)
+	}
+}
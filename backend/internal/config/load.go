@@ -0,0 +1,244 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Load builds the config from defaults, the optional file at path (or
+// CONFIG_FILE when path is empty), .env and the environment. It does not
+// validate; call Validate before using the result.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	// .env never overrides variables that are already set
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return cfg, fmt.Errorf("read .env: %w", err)
+	}
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return cfg, fmt.Errorf("read config file %s: %w", path, err)
+		}
+		err = apply(&cfg, func(f field) (string, bool) {
+			v, ok := values[f.key]
+			return v, ok
+		})
+		if err != nil {
+			return cfg, fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+
+	err := apply(&cfg, func(f field) (string, bool) {
+		if f.env == "" {
+			return "", false
+		}
+		return os.LookupEnv(f.env)
+	})
+	if err != nil {
+		return cfg, err
+	}
+
+	// Development runs without a configured secret get a random one, so
+	// there is no shared default secret to forget about. Tokens then stop
+	// working on restart, which is fine locally.
+	if cfg.Auth.JWTSecret == "" && !cfg.IsProduction() {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return cfg, err
+		}
+		cfg.Auth.JWTSecret = hex.EncodeToString(secret)
+		cfg.warnings = append(cfg.warnings, "JWT_SECRET is not set; using a random secret, tokens will not survive a restart")
+	}
+
+	return cfg, nil
+}
+
+// Warnings are non-fatal problems found while loading
+func (c Config) Warnings() []string {
+	return c.warnings
+}
+
+// readFile decodes a YAML or TOML file into dotted keys ("http.port")
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("unsupported config file type %q (use .yaml or .toml)", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	flatten("", raw, values)
+	return values, nil
+}
+
+func flatten(prefix string, raw map[string]any, out map[string]string) {
+	for k, v := range raw {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch v := v.(type) {
+		case map[string]any:
+			flatten(key, v, out)
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			out[key] = strings.Join(items, ",")
+		default:
+			out[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// field is one configurable leaf of Config
+type field struct {
+	key    string // file key, e.g. "http.port"
+	env    string // environment variable, e.g. "PORT"
+	secret bool
+	value  reflect.Value
+}
+
+func fields(cfg *Config) []field {
+	var out []field
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			fv := v.Field(i)
+			if sf.Type.Kind() == reflect.Struct && sf.Type != reflect.TypeOf(time.Duration(0)) {
+				walk(fv)
+				continue
+			}
+			out = append(out, field{
+				key:    sf.Tag.Get("config"),
+				env:    sf.Tag.Get("env"),
+				secret: sf.Tag.Get("secret") == "true",
+				value:  fv,
+			})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem())
+	return out
+}
+
+// apply sets every field lookup has a value for
+func apply(cfg *Config, lookup func(field) (string, bool)) error {
+	for _, f := range fields(cfg) {
+		raw, ok := lookup(f)
+		if !ok {
+			continue
+		}
+		if err := set(f.value, strings.TrimSpace(raw)); err != nil {
+			name := f.env
+			if name == "" {
+				name = f.key
+			}
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func set(v reflect.Value, raw string) error {
+	switch v.Interface().(type) {
+	case time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case []string:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported config type %s", v.Type())
+	}
+	return nil
+}
+
+// Redacted returns a copy that is safe to log: secrets are masked and
+// database URLs keep everything but the password
+func (c Config) Redacted() Config {
+	copied := c
+	copied.CORS.AllowedOrigins = append([]string(nil), c.CORS.AllowedOrigins...)
+	copied.HTTP.TrustedProxies = append([]string(nil), c.HTTP.TrustedProxies...)
+	copied.Log.Levels = append([]string(nil), c.Log.Levels...)
+	for _, f := range fields(&copied) {
+		if !f.secret || f.value.Kind() != reflect.String || f.value.String() == "" {
+			continue
+		}
+		if u, err := url.Parse(f.value.String()); err == nil && u.User != nil {
+			f.value.SetString(u.Redacted())
+			continue
+		}
+		f.value.SetString("REDACTED")
+	}
+	return copied
+}
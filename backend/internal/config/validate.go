@@ -0,0 +1,206 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Secrets that must never protect a production deployment
+var knownWeakSecrets = []string{
+	"your-default-secret-key",
+	"your-super-secret-jwt-key-change-this-in-production",
+	"secret",
+	"changeme",
+}
+
+const minProductionSecretLength = 32
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Validate reports every problem at once so a bad deploy shows the whole
+// list instead of failing one variable at a time
+func (c Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.Env != EnvDevelopment && c.Env != EnvProduction {
+		add("APP_ENV must be %q or %q, got %q", EnvDevelopment, EnvProduction, c.Env)
+	}
+
+	if port, err := strconv.Atoi(c.HTTP.Port); err != nil || port < 1 || port > 65535 {
+		add("PORT must be a TCP port number, got %q", c.HTTP.Port)
+	}
+	for _, proxy := range c.HTTP.TrustedProxies {
+		if _, err := netip.ParsePrefix(proxy); err != nil {
+			if _, err := netip.ParseAddr(proxy); err != nil {
+				add("TRUSTED_PROXIES entries must be IP addresses or CIDRs, got %q", proxy)
+			}
+		}
+	}
+
+	if !validLogLevel(c.Log.Level) {
+		add("LOG_LEVEL must be debug, info, warn or error, got %q", c.Log.Level)
+	}
+	for _, entry := range c.Log.Levels {
+		if name, level, ok := strings.Cut(entry, "="); !ok || strings.TrimSpace(name) == "" || !validLogLevel(level) {
+			add("LOG_LEVELS entries must look like package=level, got %q", entry)
+		}
+	}
+	if c.Log.Format != "json" && c.Log.Format != "text" {
+		add("LOG_FORMAT must be json or text, got %q", c.Log.Format)
+	}
+
+	if c.Database.URL == "" {
+		add("DATABASE_URL is required")
+	} else if u, err := url.Parse(c.Database.URL); err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+		add("DATABASE_URL must be a postgres:// URL")
+	}
+	if c.Database.MaxConns < 1 {
+		add("DB_MAX_CONNS must be at least 1")
+	}
+	if c.Database.MinConns < 0 || c.Database.MinConns > c.Database.MaxConns {
+		add("DB_MIN_CONNS must be between 0 and DB_MAX_CONNS")
+	}
+	if c.Database.MaxConnLifetime <= 0 || c.Database.MaxConnIdleTime <= 0 || c.Database.HealthCheckPeriod <= 0 {
+		add("DB_MAX_CONN_LIFETIME, DB_MAX_CONN_IDLE_TIME and DB_HEALTH_CHECK_PERIOD must be positive")
+	}
+	if c.Database.QueryTimeout <= 0 {
+		add("DB_QUERY_TIMEOUT must be positive")
+	}
+
+	if c.Auth.JWTSecret == "" {
+		add("JWT_SECRET is required")
+	}
+	if c.Auth.TokenTTL <= 0 {
+		add("JWT_TTL must be positive")
+	}
+	if c.Auth.InvitationTTL <= 0 {
+		add("INVITATION_TTL must be positive")
+	}
+
+	if c.RateLimit.Enabled {
+		rl := c.RateLimit
+		if rl.RequestsPerMinute < 1 || rl.Burst < 1 || rl.AuthRequestsPerMinute < 1 || rl.AuthBurst < 1 ||
+			rl.WriteRequestsPerMinute < 1 || rl.WriteBurst < 1 {
+			add("RATE_LIMIT_*_RPM and RATE_LIMIT_*_BURST must be at least 1 when rate limiting is enabled")
+		}
+		if rl.Store != "memory" && rl.Store != "postgres" {
+			add("RATE_LIMIT_STORE must be memory or postgres, got %q", rl.Store)
+		}
+	}
+	if c.Quota.MaxOpenJobsPerEmployer < 0 || c.Quota.MaxApplicationsPerDay < 0 {
+		add("QUOTA_MAX_OPEN_JOBS and QUOTA_MAX_APPLICATIONS_PER_DAY must not be negative")
+	}
+
+	switch c.Storage.Backend {
+	case "local":
+		if c.Storage.LocalDir == "" {
+			add("STORAGE_LOCAL_DIR is required for the local storage backend")
+		}
+	case "s3":
+		if c.Storage.S3Endpoint == "" || c.Storage.S3Bucket == "" || c.Storage.S3AccessKey == "" || c.Storage.S3SecretKey == "" {
+			add("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY and S3_SECRET_KEY are required for the s3 storage backend")
+		}
+	default:
+		add("STORAGE_BACKEND must be local or s3, got %q", c.Storage.Backend)
+	}
+	// Presigned S3 URLs can't outlive a week
+	if c.Storage.URLTTL < time.Second || c.Storage.URLTTL > 7*24*time.Hour {
+		add("STORAGE_URL_TTL must be between 1s and 168h")
+	}
+	if c.Storage.MaxAvatarBytes < 1 || c.Storage.MaxDocumentBytes < 1 || c.Storage.MaxAttachmentBytes < 1 {
+		add("UPLOAD_MAX_AVATAR_BYTES, UPLOAD_MAX_DOCUMENT_BYTES and UPLOAD_MAX_ATTACHMENT_BYTES must be positive")
+	}
+	if c.Storage.MaxJobAttachments < 0 {
+		add("UPLOAD_MAX_JOB_ATTACHMENTS must not be negative")
+	}
+
+	if c.Payments.Provider != "fake" {
+		add("PAYMENT_PROVIDER must be fake, got %q", c.Payments.Provider)
+	}
+	if !currencyCode.MatchString(c.Payments.Currency) {
+		add("PAYMENT_CURRENCY must be an ISO 4217 code such as INR, got %q", c.Payments.Currency)
+	}
+	if c.Payments.WebhookTolerance <= 0 {
+		add("PAYMENT_WEBHOOK_TOLERANCE must be positive")
+	}
+
+	r := c.Recommend
+	if r.CategoryWeight < 0 || r.DistanceWeight < 0 || r.PayWeight < 0 || r.FreshnessWeight < 0 {
+		add("RECOMMEND_*_WEIGHT must not be negative")
+	} else if r.CategoryWeight+r.DistanceWeight+r.PayWeight+r.FreshnessWeight == 0 {
+		add("at least one RECOMMEND_*_WEIGHT must be positive")
+	}
+	if r.DistanceHalfKm <= 0 {
+		add("RECOMMEND_DISTANCE_HALF_KM must be positive")
+	}
+	if r.FreshnessHalfLife <= 0 {
+		add("RECOMMEND_FRESHNESS_HALF_LIFE must be positive")
+	}
+
+	switch c.Telemetry.TracingExporter {
+	case "none", "stdout", "otlp":
+	default:
+		add("TRACING_EXPORTER must be none, stdout or otlp, got %q", c.Telemetry.TracingExporter)
+	}
+	if c.Telemetry.SampleRatio < 0 || c.Telemetry.SampleRatio > 1 {
+		add("TRACING_SAMPLE_RATIO must be between 0 and 1")
+	}
+	if c.Telemetry.TracingExporter != "none" && c.Telemetry.ServiceName == "" {
+		add("OTEL_SERVICE_NAME is required when tracing is enabled")
+	}
+
+	if c.IsProduction() {
+		problems = append(problems, c.productionProblems()...)
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration:\n  " + strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+func validLogLevel(s string) bool {
+	var l slog.Level
+	return l.UnmarshalText([]byte(strings.TrimSpace(s))) == nil
+}
+
+// productionProblems lists defaults that are fine on a laptop but unsafe
+// on a public server
+func (c Config) productionProblems() []string {
+	var problems []string
+
+	secret := c.Auth.JWTSecret
+	if len(secret) < minProductionSecretLength {
+		problems = append(problems, fmt.Sprintf("JWT_SECRET must be at least %d characters in production", minProductionSecretLength))
+	}
+	for _, weak := range knownWeakSecrets {
+		if strings.EqualFold(secret, weak) {
+			problems = append(problems, "JWT_SECRET is a well-known placeholder")
+		}
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			problems = append(problems, "CORS_ALLOWED_ORIGINS must not contain * in production")
+		}
+		if u, err := url.Parse(origin); err == nil && (u.Hostname() == "localhost" || u.Hostname() == "127.0.0.1") {
+			problems = append(problems, fmt.Sprintf("CORS_ALLOWED_ORIGINS must not contain %s in production", origin))
+		}
+	}
+
+	if !c.RateLimit.Enabled {
+		problems = append(problems, "RATE_LIMIT_ENABLED must be true in production")
+	}
+
+	return problems
+}
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Migrate applies every .sql file in migrations, in file name order, that
+// isn't recorded in schema_migrations yet. Each file runs in its own
+// transaction together with its schema_migrations row.
+func Migrate(ctx context.Context, pool *pgxpool.Pool, migrations fs.FS) error {
+	if err := ensureMigrationsTable(ctx, pool); err != nil {
+		return err
+	}
+
+	files, err := migrationFiles(migrations)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range files {
+		var applied bool
+		err := pool.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, name,
+		).Scan(&applied)
+		if err != nil {
+			return err
+		}
+		if applied {
+			continue
+		}
+
+		sql, err := fs.ReadFile(migrations, name)
+		if err != nil {
+			return err
+		}
+
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, string(sql)); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("migration %s: %w", name, err)
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, name); err != nil {
+			tx.Rollback(ctx)
+			return err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func migrationFiles(migrations fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(migrations, ".")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".sql") {
+			files = append(files, e.Name())
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// MigrationStatus returns the newest applied migration and the newest one
+// in migrations. They differ when the database is behind the code (or
+// ahead of it, during a rollback).
+func MigrationStatus(ctx context.Context, pool *pgxpool.Pool, migrations fs.FS) (current, expected string, err error) {
+	files, err := migrationFiles(migrations)
+	if err != nil {
+		return "", "", err
+	}
+	if len(files) > 0 {
+		expected = files[len(files)-1]
+	}
+
+	var exists bool
+	err = pool.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists)
+	if err != nil || !exists {
+		return "", expected, err
+	}
+
+	err = pool.QueryRow(ctx, `SELECT COALESCE(MAX(version), '') FROM schema_migrations`).Scan(&current)
+	return current, expected, err
+}
+
+// Baseline records the migrations up to and including through as applied
+// without running them, for databases that were set up by running the
+// files with psql. through is a file name or its number, e.g. "012"; the
+// files after it are left for Migrate, so a database that is only partly
+// set up isn't marked as complete.
+func Baseline(ctx context.Context, pool *pgxpool.Pool, migrations fs.FS, through string) error {
+	files, err := migrationFiles(migrations)
+	if err != nil {
+		return err
+	}
+	last := slices.IndexFunc(files, func(name string) bool {
+		return name == through || strings.HasPrefix(name, through+"_")
+	})
+	if through == "" || last < 0 {
+		return fmt.Errorf("no migration %q to baseline through", through)
+	}
+
+	if err := ensureMigrationsTable(ctx, pool); err != nil {
+		return err
+	}
+	for _, name := range files[:last+1] {
+		_, err := pool.Exec(ctx,
+			`INSERT INTO schema_migrations (version) VALUES ($1) ON CONFLICT (version) DO NOTHING`, name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ensureMigrationsTable(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version VARCHAR(255) PRIMARY KEY,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`)
+	return err
+}
@@ -0,0 +1,23 @@
+package db
+
+import (
+	"context"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// EnsureRoles makes sure every role known to the code exists in the roles
+// table, so the users.user_type foreign key always accepts models.Roles.
+func EnsureRoles(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, role := range models.Roles {
+		_, err := pool.Exec(ctx,
+			`INSERT INTO roles (name) VALUES ($1) ON CONFLICT (name) DO NOTHING`,
+			string(role),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
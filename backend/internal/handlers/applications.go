@@ -1,376 +1,677 @@
 package handlers
 
 import (
-    "context"
-    "database/sql"
-    "net/http"
-    "time"
-    "github.com/gin-gonic/gin"
-    "github.com/jackc/pgx/v5/pgxpool"
-		"strings"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/booking"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/metrics"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/payments"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/recommend"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/schedule"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/screening"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+	"github.com/gin-gonic/gin"
 )
 
 type ApplicationHandler struct {
-    DB *pgxpool.Pool
+	Applications store.ApplicationStore
+	Jobs         store.JobStore
+	Users        store.UserStore
+	Availability store.AvailabilityStore
+	Bookings     store.BookingStore
+	// Users who are also employers can't apply to their own team's jobs
+	Organizations store.OrganizationStore
+	// Charges employers into escrow when they hire
+	Escrow *payments.Escrow
+	// Cap on applications per worker in any 24 hours; 0 means no cap
+	MaxApplicationsPerDay int
+	// How applicants are ranked by match
+	MatchWeights recommend.MatchWeights
 }
 
 type CreateApplicationRequest struct {
-    JobID       int    `json:"job_id" binding:"required"`
-    CoverLetter string `json:"cover_letter"`
+	JobID       int    `json:"job_id" binding:"required"`
+	CoverLetter string `json:"cover_letter"`
+	// Answers to the job's screening questions; required ones must be
+	// answered
+	Answers []AnswerRequest `json:"answers" binding:"omitempty,max=10,unique=QuestionID,dive"`
+}
+
+type AnswerRequest struct {
+	QuestionID int `json:"question_id" binding:"required,min=1"`
+	// "yes" or "no", a number, free text or one of the choices
+	Answer string `json:"answer" binding:"max=1000"`
+}
+
+type InviteWorkerRequest struct {
+	WorkerID int `json:"worker_id" binding:"required"`
+}
+
+type InvitationResponseRequest struct {
+	Response string `json:"response" binding:"required,oneof=accept decline"`
+	// Answers to the job's screening questions, checked like an
+	// application's when accepting and ignored when declining
+	Answers []AnswerRequest `json:"answers" binding:"omitempty,max=10,unique=QuestionID,dive"`
 }
 
 type UpdateApplicationRequest struct {
-    Status string `json:"status" binding:"required,oneof=accepted rejected"`
+	Status string `json:"status" binding:"required,oneof=accepted rejected"`
 }
 
-// Worker applies to a job
-/*func (h *ApplicationHandler) ApplyToJob(c *gin.Context) {
-
-    workerID, exists := c.Get("user_id")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-        return
-    }
-
-    var req CreateApplicationRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-        return
-    }
-
-    // Verify worker exists and is actually a worker
-    var userType string
-    checkQuery := `SELECT user_type FROM users WHERE id = $1`
-    err := h.DB.QueryRow(context.Background(), checkQuery, req.WorkerID).Scan(&userType)
-
-		workID := workerID.(int)
-    
-
-    // Check if job exists and is still open
-    var jobStatus string
-    var expiresAt time.Time
-    jobQuery := `SELECT status, expires_at FROM jobs WHERE id = $1`
-    err = h.DB.QueryRow(context.Background(), jobQuery, req.JobID).Scan(&jobStatus, &expiresAt)
-    
-    if err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
-        return
-    }
-
-    if jobStatus != "open" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "This job is no longer accepting applications"})
-        return
-    }
-
-    if expiresAt.Before(time.Now()) {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "This job has expired"})
-        return
-    }
-
-    // Create application
-    query := `
-        INSERT INTO applications (job_id, worker_id, cover_letter)
-        VALUES ($1, $2, $3)
-        RETURNING id, job_id, worker_id, cover_letter, status, applied_at
-    `
-
-    var application struct {
-        ID          int
-        JobID       int
-        WorkerID    int
-        CoverLetter sql.NullString
-        Status      string
-        AppliedAt   time.Time
-    }
-
-    err = h.DB.QueryRow(context.Background(), query,
-        req.JobID, workID, req.CoverLetter,
-    ).Scan(
-        &application.ID,
-        &application.JobID,
-        &application.WorkerID,
-        &application.CoverLetter,
-        &application.Status,
-        &application.AppliedAt,
-    )
-
-    if err != nil {
-        // Check if it's a duplicate application error
-        if err.Error() == "ERROR: duplicate key value violates unique constraint \"applications_job_id_worker_id_key\" (SQLSTATE 23505)" {
-            c.JSON(http.StatusConflict, gin.H{"error": "You have already applied to this job"})
-            return
-        }
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit application", "details": err.Error()})
-        return
-    }
-
-    c.JSON(http.StatusCreated, gin.H{
-        "message": "Application submitted successfully",
-        "application": application,
-    })
-}*/
+type BulkUpdateApplicationsRequest struct {
+	ApplicationIDs []int  `json:"application_ids" binding:"required,min=1,max=100,unique,dive,min=1"`
+	Status         string `json:"status" binding:"required,oneof=accepted rejected"`
+}
+
+// ReviewApplicationRequest changes the fields it has; the rest are kept
+type ReviewApplicationRequest struct {
+	Shortlisted *bool `json:"shortlisted"`
+	// Replace the tags; an empty list clears them
+	Tags []string `json:"tags" binding:"omitempty,max=10,dive,min=1,max=30"`
+	// An empty string clears the notes
+	Notes *string `json:"notes" binding:"omitempty,max=2000"`
+}
+
+// RankedApplicant is an applicant with how well they match the job
+type RankedApplicant struct {
+	models.JobApplicant
+	Match ApplicantMatch `json:"match"`
+}
+
+type ApplicantMatch struct {
+	Score   float64            `json:"score"`
+	Factors []recommend.Factor `json:"factors"`
+	// The factors' reasons, strongest first
+	Reasons []string `json:"reasons"`
+}
+
+// BulkResult is the outcome for one application of a bulk update
+type BulkResult struct {
+	ApplicationID int                 `json:"application_id"`
+	Application   *models.Application `json:"application,omitempty"`
+	// What became of the booking's escrow, when anything was to be held
+	// or refunded
+	Escrow string          `json:"escrow,omitempty"`
+	Error  *apierror.Error `json:"error,omitempty"`
+}
 
 // Worker applies to a job
 func (h *ApplicationHandler) ApplyToJob(c *gin.Context) {
-    // Get worker ID from JWT token
-    workerID, exists := c.Get("user_id")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-        return
-    }
-
-    var req CreateApplicationRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-        return
-    }
-
-    workID := workerID.(int)
-
-    // Check if job exists and is still open
-    var jobStatus string
-    var expiresAt time.Time
-    jobQuery := `SELECT status, expires_at FROM jobs WHERE id = $1`
-    err := h.DB.QueryRow(context.Background(), jobQuery, req.JobID).Scan(&jobStatus, &expiresAt)
-    
-    if err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
-        return
-    }
-
-    if jobStatus != "open" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "This job is no longer accepting applications"})
-        return
-    }
-
-    if expiresAt.Before(time.Now()) {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "This job has expired"})
-        return
-    }
-
-    // Create application
-    query := `
-        INSERT INTO applications (job_id, worker_id, cover_letter)
-        VALUES ($1, $2, $3)
-        RETURNING id, job_id, worker_id, cover_letter, status, applied_at
-    `
-
-    var application struct {
-        ID          int
-        JobID       int
-        WorkerID    int
-        CoverLetter sql.NullString
-        Status      string
-        AppliedAt   time.Time
-    }
-
-    err = h.DB.QueryRow(context.Background(), query,
-        req.JobID, workID, req.CoverLetter,
-    ).Scan(
-        &application.ID,
-        &application.JobID,
-        &application.WorkerID,
-        &application.CoverLetter,
-        &application.Status,
-        &application.AppliedAt,
-    )
-
-    if err != nil {
-        if strings.Contains(err.Error(), "duplicate key") {
-            c.JSON(http.StatusConflict, gin.H{"error": "You have already applied to this job"})
-            return
-        }
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit application", "details": err.Error()})
-        return
-    }
-
-    c.JSON(http.StatusCreated, gin.H{
-        "message": "Application submitted successfully",
-        "application": application,
-    })
+	// Get worker ID from JWT token
+	workerID := c.GetInt("user_id")
+	if workerID == 0 {
+		c.Error(apierror.Unauthorized("User not authenticated"))
+		return
+	}
+
+	var req CreateApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	// Check if job exists and is still open
+	job, ok := h.openJob(c, req.JobID)
+	if !ok {
+		return
+	}
+
+	// Workers who also hire would otherwise be paying themselves
+	if hasRole(c, models.RoleEmployer) {
+		_, err := h.Organizations.Role(c.Request.Context(), job.OrganizationID, workerID)
+		switch {
+		case err == nil:
+			c.Error(apierror.BadRequest("You can't apply to your own organization's jobs"))
+			return
+		case !errors.Is(err, store.ErrNotFound):
+			c.Error(apierror.Internal("Failed to check organization membership", err))
+			return
+		}
+	}
+
+	answers, ok := h.screen(c, job.ID, req.Answers)
+	if !ok {
+		return
+	}
+
+	if h.MaxApplicationsPerDay > 0 {
+		count, nextSlot, err := h.Applications.CountRecentByWorker(c.Request.Context(), workerID, 24*time.Hour)
+		if err != nil {
+			c.Error(apierror.Internal("Failed to submit application", err))
+			return
+		}
+		if count >= h.MaxApplicationsPerDay {
+			metrics.QuotaExceeded.WithLabelValues("applications_per_day").Inc()
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(nextSlot.Seconds()))))
+			c.Error(apierror.TooManyRequests(apierror.CodeQuotaExceeded,
+				fmt.Sprintf("You can apply to at most %d jobs a day", h.MaxApplicationsPerDay)))
+			return
+		}
+	}
+
+	application := models.Application{
+		JobID:       req.JobID,
+		WorkerID:    workerID,
+		CoverLetter: &req.CoverLetter,
+	}
+	if err := h.Applications.Create(c.Request.Context(), &application, answers...); err != nil {
+		if apierror.IsUniqueViolation(err, "applications_job_id_worker_id_key") {
+			c.Error(apierror.Conflict(apierror.CodeAlreadyApplied, "You have already applied to this job"))
+			return
+		}
+		c.Error(apierror.Wrap(err, "Failed to submit application"))
+		return
+	}
+	metrics.ApplicationsSubmitted.Inc()
+
+	// A knocked out answer rejects the application straight away
+	message := "Application submitted successfully"
+	if application.Status == models.ApplicationRejected {
+		message = "Application submitted, but it doesn't meet this job's requirements"
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     message,
+		"application": application,
+	})
+}
+
+// Employer invites a worker to one of their jobs. The invitation is an
+// application the worker accepts or declines before it can be decided.
+func (h *ApplicationHandler) InviteWorker(c *gin.Context) {
+	jobID, ok := paramInt(c, "id")
+	if !ok {
+		return
+	}
+	var req InviteWorkerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	ctx := c.Request.Context()
+	job, ok := h.openJob(c, jobID)
+	if !ok {
+		return
+	}
+	worker, err := h.Users.GetByID(ctx, req.WorkerID)
+	if err != nil {
+		c.Error(lookupError(err, "Worker not found"))
+		return
+	}
+	if !worker.HasRole(models.RoleWorker) {
+		c.Error(apierror.NotFound("Worker not found"))
+		return
+	}
+	_, err = h.Organizations.Role(ctx, job.OrganizationID, worker.ID)
+	switch {
+	case err == nil:
+		c.Error(apierror.BadRequest("You can't invite your own organization's members"))
+		return
+	case !errors.Is(err, store.ErrNotFound):
+		c.Error(apierror.Internal("Failed to check organization membership", err))
+		return
+	}
+
+	inviterID := c.GetInt("user_id")
+	application := models.Application{JobID: jobID, WorkerID: worker.ID, InvitedBy: &inviterID}
+	if err := h.Applications.Create(ctx, &application); err != nil {
+		if apierror.IsUniqueViolation(err, "applications_job_id_worker_id_key") {
+			c.Error(apierror.Conflict(apierror.CodeAlreadyApplied, "This worker has already applied or been invited to this job"))
+			return
+		}
+		c.Error(apierror.Wrap(err, "Failed to invite worker"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "Worker invited",
+		"application": application,
+	})
+}
+
+// Worker accepts or declines an invitation. Accepting makes it a pending
+// application the employer decides on as usual.
+func (h *ApplicationHandler) RespondToInvitation(c *gin.Context) {
+	applicationID, ok := paramInt(c, "id")
+	if !ok {
+		return
+	}
+	var req InvitationResponseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	ctx := c.Request.Context()
+	workerID := c.GetInt("user_id")
+	accept := req.Response == "accept"
+	message := "Invitation declined"
+	var answers []models.Answer
+	if accept {
+		message = "Invitation accepted"
+		invitation, err := h.Applications.Get(ctx, applicationID)
+		if err != nil {
+			c.Error(lookupError(err, "Application not found"))
+			return
+		}
+		if invitation.WorkerID != workerID {
+			c.Error(apierror.NotFound("Application not found"))
+			return
+		}
+		job, ok := h.openJob(c, invitation.JobID)
+		if !ok {
+			return
+		}
+		// The invited worker answers the screening questions like any
+		// applicant, just not until they accept
+		if answers, ok = h.screen(c, job.ID, req.Answers); !ok {
+			return
+		}
+	}
+
+	application, err := h.Applications.Respond(ctx, applicationID, workerID, accept, answers...)
+	if err != nil {
+		if errors.Is(err, store.ErrNotInvited) {
+			c.Error(apierror.Conflict(apierror.CodeInvalidTransition, "This application isn't an open invitation"))
+			return
+		}
+		c.Error(lookupError(err, "Application not found"))
+		return
+	}
+	// A knocked out answer rejects the application straight away
+	if application.Status == models.ApplicationRejected {
+		message = "Invitation accepted, but you don't meet this job's requirements"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     message,
+		"application": application,
+	})
+}
+
+// screen checks the worker's answers against the job's screening
+// questions, failing the request if one is missing or malformed
+func (h *ApplicationHandler) screen(c *gin.Context, jobID int, req []AnswerRequest) ([]models.Answer, bool) {
+	questions, err := h.Jobs.ListQuestions(c.Request.Context(), jobID)
+	if err != nil {
+		c.Error(apierror.Internal("Failed to fetch screening questions", err))
+		return nil, false
+	}
+	given := make([]screening.Given, len(req))
+	for i, a := range req {
+		given[i] = screening.Given{QuestionID: a.QuestionID, Answer: a.Answer}
+	}
+	answers, invalid := screening.Check(questions, given)
+	if invalid != nil {
+		c.Error(apierror.Invalid(invalid.Field, invalid.Rule, invalid.Message))
+		return nil, false
+	}
+	return answers, true
+}
+
+// openJob loads the job, failing the request unless it is open and
+// unexpired
+func (h *ApplicationHandler) openJob(c *gin.Context, id int) (*models.JobWithDetails, bool) {
+	job, err := h.Jobs.Get(c.Request.Context(), id)
+	if err != nil {
+		c.Error(lookupError(err, "Job not found"))
+		return nil, false
+	}
+	if job.Status != "open" {
+		c.Error(apierror.New(http.StatusBadRequest, apierror.CodeJobClosed, "This job is no longer accepting applications"))
+		return nil, false
+	}
+	if job.ExpiresAt.Before(time.Now()) {
+		c.Error(apierror.New(http.StatusBadRequest, apierror.CodeJobExpired, "This job has expired"))
+		return nil, false
+	}
+	return job, true
 }
 
 // Get all applications for a worker
 func (h *ApplicationHandler) GetWorkerApplications(c *gin.Context) {
-    workerID := c.Param("workerId")
-
-    query := `
-        SELECT a.id, a.job_id, a.worker_id, a.cover_letter, a.status, a.applied_at,
-               j.title as job_title, j.location, j.salary_min, j.salary_max,
-               u.full_name as employer_name
-        FROM applications a
-        JOIN jobs j ON a.job_id = j.id
-        JOIN users u ON j.employer_id = u.id
-        WHERE a.worker_id = $1
-        ORDER BY a.applied_at DESC
-    `
-
-    rows, err := h.DB.Query(context.Background(), query, workerID)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch applications"})
-        return
-    }
-    defer rows.Close()
-
-    var applications []map[string]interface{}
-
-    for rows.Next() {
-        var (
-            id, jobID, workerID int
-            status, jobTitle, location, employerName string
-            coverLetter sql.NullString
-            salaryMin, salaryMax sql.NullFloat64
-            appliedAt time.Time
-        )
-
-        err := rows.Scan(
-            &id, &jobID, &workerID, &coverLetter, &status, &appliedAt,
-            &jobTitle, &location, &salaryMin, &salaryMax, &employerName,
-        )
-
-        if err != nil {
-            continue
-        }
-
-        app := map[string]interface{}{
-            "id":            id,
-            "job_id":        jobID,
-            "worker_id":     workerID,
-            "status":        status,
-            "applied_at":    appliedAt,
-            "job_title":     jobTitle,
-            "location":      location,
-            "employer_name": employerName,
-        }
-
-        if coverLetter.Valid {
-            app["cover_letter"] = coverLetter.String
-        }
-        if salaryMin.Valid {
-            app["salary_min"] = salaryMin.Float64
-        }
-        if salaryMax.Valid {
-            app["salary_max"] = salaryMax.Float64
-        }
-
-        applications = append(applications, app)
-    }
-
-    c.JSON(http.StatusOK, gin.H{
-        "applications": applications,
-        "count": len(applications),
-    })
+	workerID, ok := paramInt(c, "workerId")
+	if !ok || !selfOrAdmin(c, workerID, "You can only see your own applications") {
+		return
+	}
+
+	applications, err := h.Applications.ListByWorker(c.Request.Context(), workerID)
+	if err != nil {
+		c.Error(apierror.Wrap(err, "Failed to fetch applications"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"applications": applications,
+		"count":        len(applications),
+	})
+}
+
+type ApplicantFilter struct {
+	// Only applicants free for the whole job
+	Available bool `form:"available"`
+	// Only shortlisted applicants, or those with a tag
+	Shortlisted bool   `form:"shortlisted"`
+	Tag         string `form:"tag" binding:"max=30"`
+	// applied (newest first, the default) or match (best first)
+	Sort string `form:"sort" binding:"omitempty,oneof=applied match"`
 }
 
-// Get all applications for a specific job (for employers)
+// Get all applications for a specific job (for employers). Applicants of
+// a scheduled job are marked with whether they are free for it, and every
+// applicant is scored on how well they match the job.
 func (h *ApplicationHandler) GetJobApplications(c *gin.Context) {
-    jobID := c.Param("jobId")
-
-    query := `
-        SELECT a.id, a.job_id, a.worker_id, a.cover_letter, a.status, a.applied_at,
-               u.full_name as worker_name, u.email as worker_email, 
-               u.phone as worker_phone, u.location as worker_location
-        FROM applications a
-        JOIN users u ON a.worker_id = u.id
-        WHERE a.job_id = $1
-        ORDER BY a.applied_at DESC
-    `
-
-    rows, err := h.DB.Query(context.Background(), query, jobID)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch applications"})
-        return
-    }
-    defer rows.Close()
-
-    var applications []map[string]interface{}
-
-    for rows.Next() {
-        var (
-            id, jobID, workerID int
-            status, workerName, workerEmail string
-            coverLetter, workerPhone, workerLocation sql.NullString
-            appliedAt time.Time
-        )
-
-        err := rows.Scan(
-            &id, &jobID, &workerID, &coverLetter, &status, &appliedAt,
-            &workerName, &workerEmail, &workerPhone, &workerLocation,
-        )
-
-        if err != nil {
-            continue
-        }
-
-        app := map[string]interface{}{
-            "id":           id,
-            "job_id":       jobID,
-            "worker_id":    workerID,
-            "status":       status,
-            "applied_at":   appliedAt,
-            "worker_name":  workerName,
-            "worker_email": workerEmail,
-        }
-
-        if coverLetter.Valid {
-            app["cover_letter"] = coverLetter.String
-        }
-        if workerPhone.Valid {
-            app["worker_phone"] = workerPhone.String
-        }
-        if workerLocation.Valid {
-            app["worker_location"] = workerLocation.String
-        }
-
-        applications = append(applications, app)
-    }
-
-    c.JSON(http.StatusOK, gin.H{
-        "applications": applications,
-        "count": len(applications),
-    })
+	jobID, ok := paramInt(c, "jobId")
+	if !ok {
+		return
+	}
+	var filter ApplicantFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	ctx := c.Request.Context()
+	job, err := h.Jobs.Get(ctx, jobID)
+	if err != nil {
+		c.Error(lookupError(err, "Job not found"))
+		return
+	}
+	if filter.Available && job.StartsAt == nil {
+		c.Error(apierror.BadRequest("This job has no starts_at and ends_at to check availability against"))
+		return
+	}
+
+	applications, err := h.Applications.ListByJob(ctx, jobID)
+	if err != nil {
+		c.Error(apierror.Wrap(err, "Failed to fetch applications"))
+		return
+	}
+	if job.StartsAt != nil {
+		if err := h.markAvailable(ctx, &job.Job, applications); err != nil {
+			c.Error(apierror.Internal("Failed to check availability", err))
+			return
+		}
+	}
+	tag := normalizeTag(filter.Tag)
+	target := toRecommendJob(*job)
+	ranked := []RankedApplicant{}
+	for _, a := range applications {
+		if filter.Available && !*a.Available ||
+			filter.Shortlisted && !a.Review.Shortlisted ||
+			tag != "" && !slices.Contains(a.Review.Tags, tag) {
+			continue
+		}
+		result := recommend.Match(h.MatchWeights, toApplicant(a), target)
+		ranked = append(ranked, RankedApplicant{
+			JobApplicant: a,
+			Match:        ApplicantMatch{Score: result.Score, Factors: result.Factors, Reasons: result.Reasons()},
+		})
+	}
+	if filter.Sort == "match" {
+		slices.SortStableFunc(ranked, func(a, b RankedApplicant) int {
+			return cmp.Compare(b.Match.Score, a.Match.Score)
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"applications": ranked,
+		"count":        len(ranked),
+	})
+}
+
+// Shortlist, tag or take notes on an applicant. Reviews are private to
+// the job's organization.
+func (h *ApplicationHandler) ReviewApplication(c *gin.Context) {
+	applicationID, ok := paramInt(c, "id")
+	if !ok {
+		return
+	}
+	var req ReviewApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	update := store.ReviewUpdate{Shortlisted: req.Shortlisted, Notes: req.Notes}
+	if req.Tags != nil {
+		update.Tags = []string{}
+		for _, t := range req.Tags {
+			if t = normalizeTag(t); t != "" && !slices.Contains(update.Tags, t) {
+				update.Tags = append(update.Tags, t)
+			}
+		}
+	}
+	review, err := h.Applications.Review(c.Request.Context(), applicationID, update, c.GetInt("user_id"))
+	if err != nil {
+		c.Error(lookupError(err, "Application not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Review saved",
+		"review":  review,
+	})
 }
 
-// Update application status (accept/reject)
+// Accept or reject many of a job's applicants at once. Each is updated
+// as UpdateApplicationStatus would, and one failing doesn't stop the rest.
+func (h *ApplicationHandler) BulkUpdateApplications(c *gin.Context) {
+	jobID, ok := paramInt(c, "id")
+	if !ok {
+		return
+	}
+	var req BulkUpdateApplicationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	ctx := c.Request.Context()
+	results := make([]BulkResult, len(req.ApplicationIDs))
+	failed := 0
+	for i, id := range req.ApplicationIDs {
+		results[i].ApplicationID = id
+		application, escrow, err := h.updateJobApplication(ctx, jobID, id, req.Status)
+		if err != nil {
+			results[i].Error = err
+			failed++
+			if err.Status >= http.StatusInternalServerError {
+				logger.ErrorContext(ctx, "bulk application update failed", "application_id", id, "error", err)
+			}
+			continue
+		}
+		results[i].Application, results[i].Escrow = application, escrow
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+		"updated": len(results) - failed,
+		"failed":  failed,
+	})
+}
+
+// updateJobApplication sets the status of one of jobID's applications
+// and settles its escrow, returning what became of it
+func (h *ApplicationHandler) updateJobApplication(ctx context.Context, jobID, id int, status string) (*models.Application, string, *apierror.Error) {
+	application, err := h.Applications.Get(ctx, id)
+	if err != nil {
+		return nil, "", lookupError(err, "Application not found")
+	}
+	if application.JobID != jobID {
+		return nil, "", apierror.NotFound("Application not found")
+	}
+	if application, err = h.Applications.UpdateStatus(ctx, id, status); err != nil {
+		return nil, "", statusError(err)
+	}
+	if application.Status == "accepted" {
+		metrics.Hires.Inc()
+	}
+	return application, h.settleEscrow(ctx, application), nil
+}
+
+// Update application status (accept/reject). Accepting books the worker
+// and charges the job's pay into escrow; rejecting an accepted worker
+// cancels their booking if it hasn't started and refunds the escrow.
 func (h *ApplicationHandler) UpdateApplicationStatus(c *gin.Context) {
-    applicationID := c.Param("id")
-
-    var req UpdateApplicationRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-        return
-    }
-
-    query := `
-        UPDATE applications
-        SET status = $1, updated_at = NOW()
-        WHERE id = $2
-        RETURNING id, job_id, worker_id, status, updated_at
-    `
-
-    var application struct {
-        ID        int
-        JobID     int
-        WorkerID  int
-        Status    string
-        UpdatedAt time.Time
-    }
-
-    err := h.DB.QueryRow(context.Background(), query, req.Status, applicationID).Scan(
-        &application.ID,
-        &application.JobID,
-        &application.WorkerID,
-        &application.Status,
-        &application.UpdatedAt,
-    )
-
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update application"})
-        return
-    }
-
-    c.JSON(http.StatusOK, gin.H{
-        "message": "Application status updated",
-        "application": application,
-    })
+	applicationID, ok := paramInt(c, "id")
+	if !ok {
+		return
+	}
+
+	var req UpdateApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	application, err := h.Applications.UpdateStatus(c.Request.Context(), applicationID, req.Status)
+	if err != nil {
+		c.Error(statusError(err))
+		return
+	}
+	if application.Status == "accepted" {
+		metrics.Hires.Inc()
+	}
+	resp := gin.H{
+		"message":     "Application status updated",
+		"application": application,
+	}
+	if escrow := h.settleEscrow(c.Request.Context(), application); escrow != "" {
+		resp["escrow"] = escrow
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// statusError maps a failed UpdateStatus to the error the client sees
+func statusError(err error) *apierror.Error {
+	if errors.Is(err, store.ErrNotFound) {
+		return apierror.NotFound("Application not found")
+	}
+	if errors.Is(err, store.ErrScheduleConflict) {
+		return apierror.Conflict(apierror.CodeScheduleConflict, "This worker is already booked for an overlapping job")
+	}
+	if errors.Is(err, store.ErrAwaitingWorker) {
+		return apierror.Conflict(apierror.CodeInvalidTransition, "The worker hasn't accepted this invitation")
+	}
+	var transition *booking.TransitionError
+	if errors.As(err, &transition) {
+		return apierror.Conflict(apierror.CodeInvalidTransition, transition.Reason)
+	}
+	return apierror.Wrap(err, "Failed to update application")
+}
+
+// toApplicant converts what the employer sees of an applicant for
+// recommend.Match
+func toApplicant(a models.JobApplicant) recommend.Applicant {
+	applicant := recommend.Applicant{
+		Point:       toPoint(a.WorkerLatitude, a.WorkerLongitude),
+		Rating:      a.WorkerRating,
+		RatingCount: a.RatingCount,
+		Completed:   a.CompletedJobs,
+		Dropped:     a.DroppedJobs,
+	}
+	if a.WorkerLocation != nil {
+		applicant.Location = *a.WorkerLocation
+	}
+	for _, s := range a.WorkerSkills {
+		applicant.Skills = append(applicant.Skills, recommend.Skill{CategoryID: s.CategoryID, CategoryName: s.CategoryName, Years: s.ExperienceYears})
+	}
+	return applicant
+}
+
+// normalizeTag trims and lower-cases a tag so "Urgent " and "urgent" match
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// settleEscrow holds the pay for a newly accepted application and refunds
+// it for a rejected one, returning the outcome for the response
+func (h *ApplicationHandler) settleEscrow(ctx context.Context, application *models.Application) string {
+	failed := escrowRefundFailed
+	if application.Status == "accepted" {
+		failed = escrowFailed
+	}
+	b, err := h.Bookings.GetByApplication(ctx, application.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		return ""
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "booking lookup failed", "application_id", application.ID, "error", err)
+		return failed
+	}
+
+	if application.Status != "accepted" {
+		return refundEscrow(ctx, h.Escrow, b.ID)
+	}
+	job, err := h.Jobs.Get(ctx, b.JobID)
+	if err != nil {
+		logger.ErrorContext(ctx, "job lookup failed", "job_id", b.JobID, "error", err)
+		return failed
+	}
+	return holdEscrow(ctx, h.Escrow, b, &job.Job)
+}
+
+// markAvailable sets Available on each applicant: whether their calendar
+// covers the job and they aren't booked for another job at the time
+func (h *ApplicationHandler) markAvailable(ctx context.Context, job *models.Job, applicants []models.JobApplicant) error {
+	workerIDs := make([]int, len(applicants))
+	for i, a := range applicants {
+		workerIDs[i] = a.WorkerID
+	}
+	calendars, err := h.Availability.ListByWorkers(ctx, workerIDs, *job.StartsAt, *job.EndsAt)
+	if err != nil {
+		return err
+	}
+
+	want := schedule.Interval{Start: *job.StartsAt, End: *job.EndsAt}
+	for i := range applicants {
+		free := false
+		if calendar := calendars[applicants[i].WorkerID]; calendar != nil {
+			free = toSchedule(calendar, job.ID).Covers(want)
+		}
+		applicants[i].Available = &free
+	}
+	return nil
+}
+
+// toSchedule converts a stored calendar for the schedule package, leaving
+// out any booking for skipJobID
+func toSchedule(a *models.Availability, skipJobID int) schedule.Availability {
+	loc, err := time.LoadLocation(a.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	out := schedule.Availability{Location: loc}
+	for _, s := range a.Weekly {
+		start, err1 := schedule.ParseClock(s.StartTime)
+		end, err2 := schedule.ParseClock(s.EndTime)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		out.Weekly = append(out.Weekly, schedule.Slot{Weekday: time.Weekday(s.Weekday), Start: start, End: end})
+	}
+	for _, e := range a.Exceptions {
+		out.Exceptions = append(out.Exceptions, schedule.Exception{
+			Interval:  schedule.Interval{Start: e.StartsAt, End: e.EndsAt},
+			Available: e.Available,
+		})
+	}
+	for _, b := range a.Booked {
+		if b.JobID != skipJobID {
+			out.Busy = append(out.Busy, schedule.Interval{Start: b.StartsAt, End: b.EndsAt})
+		}
+	}
+	return out
 }
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	Audit store.AuditStore
+}
+
+type AuditQuery struct {
+	ActorID    int       `form:"actor_id" binding:"omitempty,min=1"`
+	Action     string    `form:"action"`
+	TargetType string    `form:"target_type"`
+	TargetID   int       `form:"target_id" binding:"omitempty,min=1"`
+	Since      time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until      time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page       int       `form:"page,default=1" binding:"min=1"`
+	PerPage    int       `form:"per_page,default=50" binding:"min=1,max=200"`
+}
+
+// List audit events, newest first (admins only)
+func (h *AuditHandler) ListEvents(c *gin.Context) {
+	var q AuditQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	events, total, err := h.Audit.List(c.Request.Context(), store.AuditFilter{
+		ActorID:    q.ActorID,
+		Action:     q.Action,
+		TargetType: q.TargetType,
+		TargetID:   q.TargetID,
+		Since:      q.Since,
+		Until:      q.Until,
+		Limit:      q.PerPage,
+		Offset:     (q.Page - 1) * q.PerPage,
+	})
+	if err != nil {
+		c.Error(apierror.Internal("Failed to fetch audit events", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events":   events,
+		"page":     q.Page,
+		"per_page": q.PerPage,
+		"total":    total,
+	})
+}
@@ -1,142 +1,204 @@
 package handlers
 
 import (
-    "context"
-    "net/http"
-		"time"
-    "golang.org/x/crypto/bcrypt"
-    "github.com/gin-gonic/gin"
-    "github.com/jackc/pgx/v5/pgxpool"
-		"github.com/Sabari-Vijayan/DBMS-project/internal/auth"
+	"errors"
+	"net/http"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/audit"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/auth"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthHandler struct {
-    DB *pgxpool.Pool
+	Users  store.UserStore
+	Tokens *auth.Manager
+	Audit  store.AuditStore
 }
 
 type RegisterRequest struct {
-    Email    string `json:"email" binding:"required,email"`
-    Password string `json:"password" binding:"required,min=6"`
-    FullName string `json:"full_name" binding:"required"`
-    UserType string `json:"user_type" binding:"required,oneof=worker employer"`
-    Phone    string `json:"phone"`
-    Location string `json:"location"`
+	Email    string      `json:"email" binding:"required,email"`
+	Password string      `json:"password" binding:"required,min=6"`
+	FullName string      `json:"full_name" binding:"required"`
+	UserType models.Role `json:"user_type" binding:"required,signup_role"`
+	Phone    string      `json:"phone"`
+	Location string      `json:"location"`
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
-    var req RegisterRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-        return
-    }
-
-    // Hash password
-    hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
-        return
-    }
-
-    // Insert user into database
-    query := `
-        INSERT INTO users (email, password_hash, full_name, user_type, phone, location)
-        VALUES ($1, $2, $3, $4, $5, $6)
-        RETURNING id, email, full_name, user_type, created_at
-    `
-    
-    var user struct {
-        ID        int    `json:"id"`
-        Email     string `json:"email"`
-        FullName  string `json:"full_name"`
-        UserType  string `json:"user_type"`
-        CreatedAt time.Time `json:"created_at"`
-    }
-
-    err = h.DB.QueryRow(context.Background(), query, 
-        req.Email, string(hashedPassword), req.FullName, req.UserType, req.Phone, req.Location,
-    ).Scan(&user.ID, &user.Email, &user.FullName, &user.UserType, &user.CreatedAt)
-
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user", "details": err.Error()})
-        return
-    }
-
-    c.JSON(http.StatusCreated, gin.H{
-        "message": "User registered successfully",
-        "user": user,
-    })
+	var req RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	// Hash password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.Error(apierror.Wrap(err, "Failed to hash password"))
+		return
+	}
+
+	user := models.User{
+		Email:        req.Email,
+		PasswordHash: string(hashedPassword),
+		FullName:     req.FullName,
+		UserType:     req.UserType,
+		Phone:        optional(req.Phone),
+		Location:     optional(req.Location),
+	}
+	if err := h.Users.Create(c.Request.Context(), &user); err != nil {
+		if apierror.IsUniqueViolation(err, "users_email_key") {
+			c.Error(apierror.Conflict(apierror.CodeEmailTaken, "An account with this email already exists"))
+			return
+		}
+		c.Error(apierror.Wrap(err, "Failed to create user"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "User registered successfully",
+		"user":    user,
+	})
 }
 
+// Same response for unknown email and wrong password
+var invalidCredentials = apierror.New(http.StatusUnauthorized, apierror.CodeInvalidCredentials, "Invalid email or password")
+
 type LoginRequest struct {
-    Email    string `json:"email" binding:"required,email"`
-    Password string `json:"password" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
 }
 
-type LoginResponse struct {
-    Message string `json:"message"`
-    User    struct {
-        ID       int    `json:"id"`
-        Email    string `json:"email"`
-        FullName string `json:"full_name"`
-        UserType string `json:"user_type"`
-    } `json:"user"`
-    Token string `json:"token"` // We'll add JWT later
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	// Get user from database
+	user, err := h.Users.GetByEmail(c.Request.Context(), req.Email)
+	if errors.Is(err, store.ErrNotFound) {
+		h.recordLogin(c, audit.LoginFailed, nil)
+		c.Error(invalidCredentials)
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal("Login failed", err))
+		return
+	}
+
+	// Compare password
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
+	if err != nil {
+		h.recordLogin(c, audit.LoginFailed, user)
+		c.Error(invalidCredentials)
+		return
+	}
+
+	// Generate JWT token
+	token, err := h.Tokens.GenerateToken(user.ID, user.Email, user.UserType, user.Roles)
+	if err != nil {
+		c.Error(apierror.Wrap(err, "Failed to generate token"))
+		return
+	}
+	h.recordLogin(c, audit.LoginSucceeded, user)
+
+	c.JSON(http.StatusOK, session("Login successful", token, user))
 }
 
-func (h *AuthHandler) Login(c *gin.Context) {
-    var req LoginRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-        return
-    }
-
-    // Get user from database
-    query := `
-        SELECT id, email, password_hash, full_name, user_type 
-        FROM users 
-        WHERE email = $1
-    `
-    
-    var user struct {
-        ID           int
-        Email        string
-        PasswordHash string
-        FullName     string
-        UserType     string
-    }
-
-    err := h.DB.QueryRow(context.Background(), query, req.Email).Scan(
-        &user.ID, &user.Email, &user.PasswordHash, &user.FullName, &user.UserType,
-    )
-
-    if err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
-        return
-    }
-
-    // Compare password
-    err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
-    if err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
-        return
-    }
-
-		// Generate JWT token
-    token, err := auth.GenerateToken(user.ID, user.Email, user.UserType)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
-        return
-    }
-
-    // Success! Return user data (we'll add JWT token later)
-    c.JSON(http.StatusOK, gin.H{
-        "message": "Login successful",
-				"token":   token,
-        "user": gin.H{
-            "id":        user.ID,
-            "email":     user.Email,
-            "full_name": user.FullName,
-            "user_type": user.UserType,
-        },
-    })
+// RoleRequest adds a role; admins are only ever made by hand
+type RoleRequest struct {
+	Role models.Role `json:"role" binding:"required,signup_role"`
+}
+
+// ActiveRoleRequest switches to any role the caller holds, admin included
+type ActiveRoleRequest struct {
+	Role models.Role `json:"role" binding:"required,role"`
+}
+
+// Give the caller another role, e.g. a worker who also hires. The new
+// token carries it; the active role stays the same.
+func (h *AuthHandler) AddRole(c *gin.Context) {
+	var req RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	user, err := h.Users.AddRole(c.Request.Context(), c.GetInt("user_id"), req.Role)
+	switch {
+	case errors.Is(err, store.ErrRoleHeld):
+		c.Error(apierror.Conflict(apierror.CodeRoleHeld, "You already have this role"))
+		return
+	case err != nil:
+		c.Error(lookupError(err, "User not found"))
+		return
+	}
+	h.reissue(c, http.StatusCreated, "Role added", user)
+}
+
+// Switch the caller's active role to another of theirs, which decides the
+// side of the marketplace they see first and statements show
+func (h *AuthHandler) SwitchRole(c *gin.Context) {
+	var req ActiveRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	user, err := h.Users.SetActiveRole(c.Request.Context(), c.GetInt("user_id"), req.Role)
+	switch {
+	case errors.Is(err, store.ErrRoleNotHeld):
+		c.Error(apierror.Forbidden("You don't have this role; add it first"))
+		return
+	case err != nil:
+		c.Error(lookupError(err, "User not found"))
+		return
+	}
+	h.reissue(c, http.StatusOK, "Active role switched", user)
+}
+
+// reissue responds with a new token for user, whose roles changed
+func (h *AuthHandler) reissue(c *gin.Context, status int, message string, user *models.User) {
+	token, err := h.Tokens.GenerateToken(user.ID, user.Email, user.UserType, user.Roles)
+	if err != nil {
+		c.Error(apierror.Wrap(err, "Failed to generate token"))
+		return
+	}
+	c.JSON(status, session(message, token, user))
+}
+
+func session(message, token string, user *models.User) gin.H {
+	return gin.H{
+		"message": message,
+		"token":   token,
+		"user": gin.H{
+			"id":        user.ID,
+			"email":     user.Email,
+			"full_name": user.FullName,
+			"user_type": user.UserType,
+			"roles":     user.Roles,
+		},
+	}
+}
+
+// recordLogin audits a login attempt. A failure to write the event is
+// logged but does not fail the login. Failed attempts have no actor; the
+// target is the account when the email matched one.
+func (h *AuthHandler) recordLogin(c *gin.Context, action string, user *models.User) {
+	event := &models.AuditEvent{Action: action, TargetType: audit.TargetUser}
+	if user != nil {
+		event.TargetID = &user.ID
+		if action == audit.LoginSucceeded {
+			event.ActorID = &user.ID
+		}
+	}
+	if err := h.Audit.Record(c.Request.Context(), event); err != nil {
+		logger.ErrorContext(c.Request.Context(), "failed to record login", "action", action, "error", err)
+	}
 }
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/schedule"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// AvailabilityHandler lets workers say when they are free
+type AvailabilityHandler struct {
+	Availability store.AvailabilityStore
+}
+
+type SlotRequest struct {
+	Weekday   *int   `json:"weekday" binding:"required,min=0,max=6"` // 0 is Sunday
+	StartTime string `json:"start_time" binding:"required,clock"`
+	EndTime   string `json:"end_time" binding:"required,clock"`
+}
+
+type UpdateAvailabilityRequest struct {
+	Timezone string        `json:"timezone" binding:"required,timezone"`
+	Weekly   []SlotRequest `json:"weekly" binding:"max=50,dive"`
+}
+
+type CreateExceptionRequest struct {
+	StartsAt  time.Time `json:"starts_at" binding:"required"`
+	EndsAt    time.Time `json:"ends_at" binding:"required"`
+	Available *bool     `json:"available" binding:"required"`
+	Note      string    `json:"note" binding:"max=255"`
+}
+
+// The caller's calendar
+func (h *AvailabilityHandler) GetAvailability(c *gin.Context) {
+	avail, err := h.Availability.Get(c.Request.Context(), c.GetInt("user_id"))
+	if err != nil {
+		c.Error(lookupError(err, "User not found"))
+		return
+	}
+	c.JSON(http.StatusOK, avail)
+}
+
+// Replace the caller's weekly slots and time zone
+func (h *AvailabilityHandler) UpdateAvailability(c *gin.Context) {
+	var req UpdateAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	slots := make([]models.AvailabilitySlot, 0, len(req.Weekly))
+	for _, s := range req.Weekly {
+		// Both parse: binding checked them
+		start, _ := schedule.ParseClock(s.StartTime)
+		end, _ := schedule.ParseClock(s.EndTime)
+		if end <= start {
+			c.Error(apierror.Invalid("end_time", "gtfield", "Each slot's end_time must be after its start_time; split slots that run past midnight"))
+			return
+		}
+		slots = append(slots, models.AvailabilitySlot{Weekday: *s.Weekday, StartTime: start.String(), EndTime: end.String()})
+	}
+
+	avail, err := h.Availability.ReplaceWeekly(c.Request.Context(), c.GetInt("user_id"), req.Timezone, slots)
+	if err != nil {
+		c.Error(lookupError(err, "User not found"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Availability updated",
+		"availability": avail,
+	})
+}
+
+// Block out time or add extra hours
+func (h *AvailabilityHandler) CreateException(c *gin.Context) {
+	var req CreateExceptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+	if !req.EndsAt.After(req.StartsAt) {
+		c.Error(apierror.Invalid("ends_at", "gtfield", "ends_at must be after starts_at"))
+		return
+	}
+	if !req.EndsAt.After(time.Now()) {
+		c.Error(apierror.Invalid("ends_at", "future", "ends_at must be in the future"))
+		return
+	}
+
+	exception := models.AvailabilityException{
+		WorkerID:  c.GetInt("user_id"),
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		Available: *req.Available,
+	}
+	if req.Note != "" {
+		exception.Note = &req.Note
+	}
+	if err := h.Availability.AddException(c.Request.Context(), &exception); err != nil {
+		c.Error(apierror.Wrap(err, "Failed to save exception"))
+		return
+	}
+	c.JSON(http.StatusCreated, exception)
+}
+
+// Remove one of the caller's exceptions
+func (h *AvailabilityHandler) DeleteException(c *gin.Context) {
+	id, ok := paramInt(c, "id")
+	if !ok {
+		return
+	}
+	if _, err := h.Availability.DeleteException(c.Request.Context(), id, c.GetInt("user_id")); err != nil {
+		c.Error(lookupError(err, "Exception not found"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Exception deleted"})
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/booking"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/payments"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// BookingHandler serves the engagements created when workers are hired
+type BookingHandler struct {
+	Bookings store.BookingStore
+	// Members of the job's organization act for its employer
+	Organizations store.OrganizationStore
+	// Refunds the employer when a booking falls through
+	Escrow *payments.Escrow
+}
+
+type BookingFilter struct {
+	Status string `form:"status" binding:"omitempty,oneof=scheduled in_progress completed cancelled no_show"`
+}
+
+type CancelBookingRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+type RateWorkerRequest struct {
+	Rating int    `json:"rating" binding:"required,min=1,max=5"`
+	Review string `json:"review" binding:"max=1000"`
+}
+
+// The caller's bookings, as worker, employer or a member of the job's
+// organization
+func (h *BookingHandler) ListBookings(c *gin.Context) {
+	var filter BookingFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	bookings, err := h.Bookings.ListByUser(c.Request.Context(), c.GetInt("user_id"), filter.Status)
+	if err != nil {
+		c.Error(apierror.Wrap(err, "Failed to fetch bookings"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bookings": bookings,
+		"count":    len(bookings),
+	})
+}
+
+// One booking the caller is part of, or whose job belongs to their
+// organization
+func (h *BookingHandler) GetBooking(c *gin.Context) {
+	id, ok := paramInt(c, "id")
+	if !ok {
+		return
+	}
+
+	b, err := h.Bookings.Get(c.Request.Context(), id)
+	if err != nil {
+		c.Error(lookupError(err, "Booking not found"))
+		return
+	}
+	if userID := c.GetInt("user_id"); userID != b.WorkerID && userID != b.EmployerID {
+		member, err := onTeam(c.Request.Context(), h.Organizations, b, userID, models.OrgViewer)
+		if err != nil {
+			c.Error(apierror.Internal("Failed to check organization membership", err))
+			return
+		}
+		if !member {
+			c.Error(apierror.Forbidden("You are not part of this booking"))
+			return
+		}
+	}
+	c.JSON(http.StatusOK, b)
+}
+
+func (h *BookingHandler) CheckIn(c *gin.Context) {
+	h.apply(c, booking.Event{Action: booking.CheckIn}, "Checked in")
+}
+
+func (h *BookingHandler) CheckOut(c *gin.Context) {
+	h.apply(c, booking.Event{Action: booking.CheckOut}, "Checked out")
+}
+
+// Confirm the work is done; the booking completes once both sides have
+func (h *BookingHandler) Confirm(c *gin.Context) {
+	h.apply(c, booking.Event{Action: booking.Confirm}, "Completion confirmed")
+}
+
+// Cancel a booking before the work starts; the body is optional
+func (h *BookingHandler) Cancel(c *gin.Context) {
+	var req CancelBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+	h.apply(c, booking.Event{Action: booking.Cancel, Reason: req.Reason}, "Booking cancelled")
+}
+
+// The worker didn't turn up (employers only)
+func (h *BookingHandler) ReportNoShow(c *gin.Context) {
+	h.apply(c, booking.Event{Action: booking.ReportNoShow}, "No-show recorded")
+}
+
+// Rate the worker on a completed booking (employers only, once)
+func (h *BookingHandler) Rate(c *gin.Context) {
+	var req RateWorkerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+	h.apply(c, booking.Event{Action: booking.Rate, Rating: req.Rating, Reason: req.Review}, "Rating saved")
+}
+
+// apply records the caller doing e's action on the booking in the path,
+// now. Managers of the job's organization act as its employer.
+func (h *BookingHandler) apply(c *gin.Context, e booking.Event, message string) {
+	id, ok := paramInt(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := h.actingAs(c, id)
+	if !ok {
+		return
+	}
+
+	e.UserID, e.At = userID, time.Now()
+	b, err := h.Bookings.Apply(c.Request.Context(), id, e)
+	if err != nil {
+		c.Error(bookingError(err, "Failed to update booking"))
+		return
+	}
+	resp := gin.H{
+		"message": message,
+		"booking": b,
+	}
+	if b.Status == booking.Cancelled || b.Status == booking.NoShow {
+		if escrow := refundEscrow(c.Request.Context(), h.Escrow, b.ID); escrow != "" {
+			resp["escrow"] = escrow
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// actingAs is who the caller acts as on the booking: the booking's
+// employer when they manage the job's organization, otherwise themselves,
+// which booking.Apply refuses unless they are on the booking
+func (h *BookingHandler) actingAs(c *gin.Context, id int) (int, bool) {
+	userID := c.GetInt("user_id")
+	b, err := h.Bookings.Get(c.Request.Context(), id)
+	if err != nil {
+		c.Error(lookupError(err, "Booking not found"))
+		return 0, false
+	}
+	if userID == b.WorkerID || userID == b.EmployerID {
+		return userID, true
+	}
+	manager, err := onTeam(c.Request.Context(), h.Organizations, b, userID, models.OrgManager)
+	if err != nil {
+		c.Error(apierror.Internal("Failed to check organization membership", err))
+		return 0, false
+	}
+	if manager {
+		return b.EmployerID, true
+	}
+	return userID, true
+}
+
+// bookingError maps the booking state machine's errors to responses
+func bookingError(err error, message string) *apierror.Error {
+	var transition *booking.TransitionError
+	switch {
+	case errors.As(err, &transition):
+		return apierror.Conflict(apierror.CodeInvalidTransition, transition.Reason)
+	case errors.Is(err, booking.ErrForbidden):
+		return apierror.Forbidden("You can't do that on this booking")
+	case errors.Is(err, store.ErrNotFound):
+		return apierror.NotFound("Booking not found")
+	}
+	return apierror.Wrap(err, message)
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/media"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// Scanned documents and job photos stay legible at this size
+	documentSide = 2400
+
+	// Room for multipart headers and the other form fields
+	multipartOverhead = 64 << 10
+)
+
+// limitBody caps the request body at max bytes of files, so an oversized
+// upload fails while it is read instead of filling the disk
+func limitBody(c *gin.Context, max int64) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, max+multipartOverhead)
+}
+
+func tooLarge(max int64) *apierror.Error {
+	return apierror.TooLarge(fmt.Sprintf("File must be at most %d bytes", max))
+}
+
+// formError reports a failure to parse a multipart body cut off by limitBody
+func formError(err error, max int64, message string) *apierror.Error {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return tooLarge(max)
+	}
+	return apierror.BadRequest(message).WithCause(err)
+}
+
+// readFile reads one uploaded file, refusing anything over max bytes
+func readFile(header *multipart.FileHeader, max int64) ([]byte, *apierror.Error) {
+	if header.Size > max {
+		return nil, tooLarge(max)
+	}
+	f, err := header.Open()
+	if err != nil {
+		return nil, apierror.Internal("Failed to read upload", err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, max+1))
+	if err != nil {
+		return nil, apierror.Internal("Failed to read upload", err)
+	}
+	if int64(len(data)) > max {
+		return nil, tooLarge(max)
+	}
+	if len(data) == 0 {
+		return nil, apierror.BadRequest("File is empty")
+	}
+	return data, nil
+}
+
+// readUpload reads the multipart field "file", refusing anything over max
+// bytes without reading all of it
+func readUpload(c *gin.Context, max int64) (data []byte, name string, ok bool) {
+	limitBody(c, max)
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.Error(formError(err, max, `Send the file as the multipart form field "file"`))
+		return nil, "", false
+	}
+	data, apiErr := readFile(header, max)
+	if apiErr != nil {
+		c.Error(apiErr)
+		return nil, "", false
+	}
+	return data, header.Filename, true
+}
+
+// storableDocument checks an uploaded document is a PDF or an image and
+// re-encodes images as JPEG. It returns what to store and its type.
+func storableDocument(data []byte) ([]byte, string, *apierror.Error) {
+	contentType := media.DetectType(data)
+	switch {
+	case media.IsImage(contentType):
+		reencoded, err := media.Reencode(data, documentSide)
+		if err != nil {
+			return nil, "", imageError(err)
+		}
+		return reencoded, media.JPEG, nil
+	case contentType == media.PDF:
+		return data, contentType, nil
+	}
+	return nil, "", apierror.UnsupportedType("Files must be a PDF or an image")
+}
+
+// imageError maps a media error to the response for it
+func imageError(err error) *apierror.Error {
+	switch {
+	case errors.Is(err, media.ErrTooManyPixels):
+		return apierror.TooLarge(fmt.Sprintf("Images may have at most %d pixels", media.MaxPixels))
+	case errors.Is(err, media.ErrUnsupported):
+		return apierror.UnsupportedType("Image could not be read")
+	}
+	return apierror.Internal("Failed to process image", err)
+}
+
+// putBlob stores data under key
+func putBlob(ctx context.Context, blobs storage.BlobStore, key string, data []byte, contentType string) error {
+	return blobs.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType)
+}
+
+// deleteBlobs removes blobs nothing points at any more. Failures only
+// leave orphaned files behind, so they are logged rather than returned.
+func deleteBlobs(ctx context.Context, blobs storage.BlobStore, keys ...*string) {
+	for _, key := range keys {
+		if key == nil {
+			continue
+		}
+		if err := blobs.Delete(ctx, *key); err != nil {
+			logger.WarnContext(ctx, "delete blob failed", "key", *key, "error", err)
+		}
+	}
+}
+
+// displayName keeps the base name of what the client called the file,
+// trimmed to fit the column
+func displayName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == "/" {
+		return "document"
+	}
+	if runes := []rune(name); len(runes) > 255 {
+		name = string(runes[:255])
+	}
+	return name
+}
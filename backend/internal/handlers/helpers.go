@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/auth"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/logging"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+var logger = logging.For("handlers")
+
+// Parse an integer path parameter, responding with 400 if it isn't one
+func paramInt(c *gin.Context, name string) (int, bool) {
+	value, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(apierror.BadRequest("Invalid " + name))
+		return 0, false
+	}
+	return value, true
+}
+
+// Turn an empty string into a NULL column value
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// Report a failed lookup: 404 if nothing matched, 500 for anything else
+func lookupError(err error, notFound string) *apierror.Error {
+	if errors.Is(err, store.ErrNotFound) {
+		return apierror.NotFound(notFound)
+	}
+	return apierror.Internal("Lookup failed", err)
+}
+
+// Whether the caller has role, whichever of theirs is active
+func hasRole(c *gin.Context, role models.Role) bool {
+	value, _ := c.Get("claims")
+	claims, ok := value.(*auth.Claims)
+	return ok && claims.HasRole(role)
+}
+
+// Whether the caller is userID or an admin, failing with 403 otherwise
+func selfOrAdmin(c *gin.Context, userID int, message string) bool {
+	if userID == c.GetInt("user_id") || hasRole(c, models.RoleAdmin) {
+		return true
+	}
+	c.Error(apierror.Forbidden(message))
+	return false
+}
@@ -1,300 +1,591 @@
 package handlers
 
 import (
-    "context"
-    "database/sql"
-    "net/http"
-    "time"
-    "github.com/gin-gonic/gin"
-    "github.com/jackc/pgx/v5/pgxpool"
-		//"strconv"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/media"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/metrics"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/screening"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/storage"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+	"github.com/gin-gonic/gin"
 )
 
 type JobHandler struct {
-    DB *pgxpool.Pool
+	Jobs  store.JobStore
+	Users store.UserStore
+	// Cap on an employer's open jobs; 0 means no cap
+	MaxOpenJobs int
+	// ISO 4217 code for jobs posted without a currency
+	DefaultCurrency string
+
+	// Attachments are stored in Blobs and returned as URLs valid for URLTTL
+	Blobs              storage.BlobStore
+	URLTTL             time.Duration
+	MaxAttachments     int
+	MaxAttachmentBytes int64
 }
 
+// JobFields are what an employer fills in, both when posting and editing.
+// Requests are JSON, or multipart when they carry attachments.
+type JobFields struct {
+	Title       string `json:"title" form:"title" binding:"required"`
+	Description string `json:"description" form:"description" binding:"required"`
+	CategoryID  *int   `json:"category_id" form:"category_id"`
+	Location    string `json:"location" form:"location" binding:"required"`
+	// What the pay is for; required with pay_min or pay_max
+	PayType models.PayType `json:"pay_type" form:"pay_type" binding:"required_with=PayMin PayMax,omitempty,pay_type"`
+	// ISO 4217; the default currency when empty
+	Currency string `json:"currency" form:"currency" binding:"omitempty,currency"`
+	// Minor units of the currency, e.g. paise
+	PayMin       *int64 `json:"pay_min" form:"pay_min" binding:"omitempty,min=1,max=9999999999"`
+	PayMax       *int64 `json:"pay_max" form:"pay_max" binding:"omitempty,min=1,max=9999999999"`
+	Duration     string `json:"duration" form:"duration"`
+	Requirements string `json:"requirements" form:"requirements"`
+	ContactPhone string `json:"contact_phone" form:"contact_phone"`
+	ContactEmail string `json:"contact_email" form:"contact_email"`
+	// RFC 3339; give both or neither
+	StartsAt *time.Time `json:"starts_at" form:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at" form:"ends_at"`
+	// Where the work is; give both or neither
+	Latitude  *float64 `json:"latitude" form:"latitude" binding:"required_with=Longitude,omitempty,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" form:"longitude" binding:"required_with=Latitude,omitempty,min=-180,max=180"`
+}
+
+// Longest schedule a single job can have
+const maxJobLength = 90 * 24 * time.Hour
+
 type CreateJobRequest struct {
-    //EmployerID   int      `json:"employer_id" binding:"required"` // Add this line
-	  Title        string   `json:"title" binding:"required"`
-    Description  string   `json:"description" binding:"required"`
-    CategoryID   *int     `json:"category_id"`
-    Location     string   `json:"location" binding:"required"`
-    SalaryMin    *float64 `json:"salary_min"`
-    SalaryMax    *float64 `json:"salary_max"`
-    Duration     string   `json:"duration"`
-    Requirements string   `json:"requirements"`
-    ContactPhone string   `json:"contact_phone"`
-    ContactEmail string   `json:"contact_email"`
-    ExpiryDays   int      `json:"expiry_days" binding:"required,min=1,max=7"` // 1-7 days
+	JobFields
+	ExpiryDays int `json:"expiry_days" form:"expiry_days" binding:"required,min=1,max=7"` // 1-7 days
+	// Asked of everyone who applies, and fixed once the job is posted.
+	// Multipart requests send each question as JSON in a questions field.
+	Questions []QuestionRequest `json:"questions" form:"questions" binding:"omitempty,max=10,dive"`
 }
 
-// Create new job posting
-func (h *JobHandler) CreateJob(c *gin.Context) {
+// QuestionRequest is a screening question as the employer writes it; see
+// models.Question for the knockouts
+type QuestionRequest struct {
+	Prompt        string              `json:"prompt" binding:"required,max=300"`
+	Kind          models.QuestionKind `json:"kind" binding:"required,question_kind"`
+	Required      bool                `json:"required"`
+	Choices       []string            `json:"choices" binding:"omitempty,max=10,unique,dive,max=100"`
+	RejectAnswer  *bool               `json:"reject_answer"`
+	MinValue      *float64            `json:"min_value"`
+	MaxValue      *float64            `json:"max_value"`
+	RejectChoices []string            `json:"reject_choices" binding:"omitempty,max=10,dive,max=100"`
+}
+
+// questions numbers the request's questions in order, trims their text
+// and checks their knockouts suit their kind
+func (r CreateJobRequest) questions() ([]models.Question, *apierror.Error) {
+	questions := make([]models.Question, len(r.Questions))
+	for i, q := range r.Questions {
+		questions[i] = models.Question{
+			Position:      i + 1,
+			Prompt:        strings.TrimSpace(q.Prompt),
+			Kind:          q.Kind,
+			Required:      q.Required,
+			Choices:       trimAll(q.Choices),
+			RejectAnswer:  q.RejectAnswer,
+			MinValue:      q.MinValue,
+			MaxValue:      q.MaxValue,
+			RejectChoices: trimAll(q.RejectChoices),
+		}
+	}
+	if err := screening.CheckQuestions(questions); err != nil {
+		return nil, apierror.Invalid(err.Field, err.Rule, err.Message)
+	}
+	return questions, nil
+}
+
+// trimAll trims each of values, returning an empty slice for none
+func trimAll(values []string) []string {
+	trimmed := make([]string, len(values))
+	for i, v := range values {
+		trimmed[i] = strings.TrimSpace(v)
+	}
+	return trimmed
+}
+
+type UpdateJobRequest struct {
+	JobFields
+	// Restarts the clock from now when set; otherwise the expiry is kept
+	ExpiryDays int `json:"expiry_days" form:"expiry_days" binding:"omitempty,min=1,max=7"`
+}
+
+// Multipart field holding job attachments
+const attachmentsField = "attachments"
+
+// apply copies the submitted fields onto job, in currency unless they
+// name one
+func (f JobFields) apply(job *models.Job, currency string) {
+	job.Title = f.Title
+	job.Description = f.Description
+	job.CategoryID = f.CategoryID
+	job.Location = f.Location
+	job.PayType = f.PayType
+	if job.PayType == "" {
+		job.PayType = models.PayFixed
+	}
+	job.Currency = f.Currency
+	if job.Currency == "" {
+		job.Currency = currency
+	}
+	job.PayMin = f.PayMin
+	job.PayMax = f.PayMax
+	job.Duration = &f.Duration
+	job.Requirements = &f.Requirements
+	job.ContactPhone = &f.ContactPhone
+	job.ContactEmail = &f.ContactEmail
+	job.StartsAt = f.StartsAt
+	job.EndsAt = f.EndsAt
+	job.Latitude = f.Latitude
+	job.Longitude = f.Longitude
+}
+
+// check checks the fields that go together: the pay range and the
+// schedule
+func (f JobFields) check() *apierror.Error {
+	if f.PayMin != nil && f.PayMax != nil && *f.PayMin > *f.PayMax {
+		return apierror.Invalid("pay_max", "gtefield", "pay_max must be at least pay_min")
+	}
+	return f.checkSchedule()
+}
+
+// checkSchedule checks starts_at and ends_at make a sensible pair
+func (f JobFields) checkSchedule() *apierror.Error {
+	switch {
+	case f.StartsAt == nil && f.EndsAt == nil:
+		return nil
+	case f.StartsAt == nil:
+		return apierror.Invalid("starts_at", "required_with", "starts_at is required when ends_at is set")
+	case f.EndsAt == nil:
+		return apierror.Invalid("ends_at", "required_with", "ends_at is required when starts_at is set")
+	case !f.EndsAt.After(*f.StartsAt):
+		return apierror.Invalid("ends_at", "gtfield", "ends_at must be after starts_at")
+	case f.EndsAt.Sub(*f.StartsAt) > maxJobLength:
+		return apierror.Invalid("ends_at", "max", fmt.Sprintf("A job can last at most %d days", maxJobLength/(24*time.Hour)))
+	}
+	return nil
+}
+
+// pendingAttachment is an uploaded file that has been checked but not stored
+type pendingAttachment struct {
+	*models.JobAttachment
+	data []byte
+}
+
+// bindJob binds a JSON or multipart job request, with the body capped at
+// what the attachments may add up to
+func (h *JobHandler) bindJob(c *gin.Context, req any) bool {
+	limitBody(c, int64(h.MaxAttachments)*h.MaxAttachmentBytes)
+	if err := c.ShouldBind(req); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.Error(apierror.TooLarge(fmt.Sprintf("At most %d attachments of %d bytes each are allowed", h.MaxAttachments, h.MaxAttachmentBytes)))
+			return false
+		}
+		c.Error(apierror.FromBinding(err))
+		return false
+	}
+	return true
+}
+
+// readAttachments checks the files in a multipart request's attachments
+// field; JSON requests have none
+func (h *JobHandler) readAttachments(c *gin.Context, employerID int) ([]pendingAttachment, bool) {
+	if c.ContentType() != gin.MIMEMultipartPOSTForm {
+		return nil, true
+	}
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.Error(formError(err, h.MaxAttachmentBytes, "Malformed multipart form"))
+		return nil, false
+	}
+	files := form.File[attachmentsField]
+	if len(files) > h.MaxAttachments {
+		c.Error(apierror.BadRequest(fmt.Sprintf("A job can have at most %d attachments", h.MaxAttachments)))
+		return nil, false
+	}
+
+	pending := make([]pendingAttachment, 0, len(files))
+	for _, header := range files {
+		data, apiErr := readFile(header, h.MaxAttachmentBytes)
+		if apiErr != nil {
+			c.Error(apiErr)
+			return nil, false
+		}
+		data, contentType, apiErr := storableDocument(data)
+		if apiErr != nil {
+			c.Error(apiErr)
+			return nil, false
+		}
+		pending = append(pending, pendingAttachment{
+			JobAttachment: &models.JobAttachment{
+				FileName:    displayName(header.Filename),
+				ContentType: contentType,
+				SizeBytes:   int64(len(data)),
+				BlobKey:     storage.NewKey("jobs/"+strconv.Itoa(employerID), media.Extension(contentType)),
+			},
+			data: data,
+		})
+	}
+	return pending, true
+}
+
+// storeAttachments puts the files in blob storage, cleaning up after itself
+// if one fails
+func (h *JobHandler) storeAttachments(ctx context.Context, pending []pendingAttachment) ([]*models.JobAttachment, error) {
+	stored := make([]*models.JobAttachment, 0, len(pending))
+	for _, p := range pending {
+		if err := putBlob(ctx, h.Blobs, p.BlobKey, p.data, p.ContentType); err != nil {
+			h.deleteAttachmentBlobs(ctx, stored)
+			return nil, err
+		}
+		stored = append(stored, p.JobAttachment)
+	}
+	return stored, nil
+}
+
+func (h *JobHandler) deleteAttachmentBlobs(ctx context.Context, attachments []*models.JobAttachment) {
+	for _, a := range attachments {
+		deleteBlobs(ctx, h.Blobs, &a.BlobKey)
+	}
+}
 
-    employerID, exists := c.Get("user_id")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authorized"})
+// signAttachments fills in each attachment's URL
+func (h *JobHandler) signAttachments(ctx context.Context, attachments []models.JobAttachment) error {
+	for i := range attachments {
+		url, err := h.Blobs.SignedURL(ctx, attachments[i].BlobKey, h.URLTTL)
+		if err != nil {
+			return err
+		}
+		attachments[i].URL = url
+	}
+	return nil
+}
+
+// listAttachments returns the job's attachments with signed URLs
+func (h *JobHandler) listAttachments(c *gin.Context, jobID int) ([]models.JobAttachment, bool) {
+	attachments, err := h.Jobs.ListAttachments(c.Request.Context(), jobID)
+	if err == nil {
+		err = h.signAttachments(c.Request.Context(), attachments)
+	}
+	if err != nil {
+		c.Error(apierror.Internal("Failed to fetch attachments", err))
+		return nil, false
+	}
+	return attachments, true
+}
+
+// managedJob loads the job in the :id parameter; EmployerOnly has
+// already checked the caller manages its organization
+func (h *JobHandler) managedJob(c *gin.Context) (*models.JobWithDetails, bool) {
+	jobID, ok := paramInt(c, "id")
+	if !ok {
+		return nil, false
+	}
+	job, err := h.Jobs.Get(c.Request.Context(), jobID)
+	if err != nil {
+		c.Error(lookupError(err, "Job not found"))
+		return nil, false
+	}
+	return job, true
+}
+
+// Post a job for the organization EmployerOnly resolved
+func (h *JobHandler) CreateJob(c *gin.Context) {
+	// Get employer ID from JWT token
+	employerID := c.GetInt("user_id")
+	if employerID == 0 {
+		c.Error(apierror.Unauthorized("User not authorized"))
+		return
+	}
+
+	var req CreateJobRequest
+	if !h.bindJob(c, &req) {
+		return
+	}
+	if err := req.check(); err != nil {
+		c.Error(err)
+		return
+	}
+	if req.StartsAt != nil && req.StartsAt.Before(time.Now()) {
+		c.Error(apierror.Invalid("starts_at", "future", "starts_at must be in the future"))
+		return
+	}
+	questions, apiErr := req.questions()
+	if apiErr != nil {
+		c.Error(apiErr)
+		return
+	}
+	pending, ok := h.readAttachments(c, employerID)
+	if !ok {
+		return
+	}
+
+	// Verify the user is an employer
+	employer, err := h.Users.GetByID(c.Request.Context(), employerID)
+	if err != nil {
+		c.Error(lookupError(err, "Employer not found"))
+		return
+	}
+	if !employer.HasRole(models.RoleEmployer) {
+		c.Error(apierror.Forbidden("Only employers can post jobs"))
+		return
+	}
+
+	// Soft cap: two concurrent posts can both pass the check, which the
+	// per-user rate limit keeps to a handful
+	if h.MaxOpenJobs > 0 {
+		open, err := h.Jobs.CountOpenByEmployer(c.Request.Context(), employerID)
+		if err != nil {
+			c.Error(apierror.Internal("Failed to create job", err))
+			return
+		}
+		if open >= h.MaxOpenJobs {
+			metrics.QuotaExceeded.WithLabelValues("open_jobs").Inc()
+			c.Error(apierror.TooManyRequests(apierror.CodeQuotaExceeded,
+				fmt.Sprintf("You can have at most %d open jobs; close or let one expire first", h.MaxOpenJobs)))
 			return
 		}
+	}
+
+	job := models.Job{
+		EmployerID:     employerID,
+		OrganizationID: c.GetInt("org_id"),
+		ExpiresAt:      time.Now().AddDate(0, 0, req.ExpiryDays),
+	}
+	req.apply(&job, h.DefaultCurrency)
+
+	ctx := c.Request.Context()
+	attachments, err := h.storeAttachments(ctx, pending)
+	if err != nil {
+		c.Error(apierror.Internal("Failed to store attachments", err))
+		return
+	}
+	if err := h.Jobs.Create(ctx, &job, questions, attachments...); err != nil {
+		h.deleteAttachmentBlobs(ctx, attachments)
+		c.Error(apierror.Wrap(err, "Failed to create job"))
+		return
+	}
+	metrics.JobsCreated.Inc()
+
+	saved, ok := h.listAttachments(c, job.ID)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "Job created successfully",
+		"job":         job,
+		"attachments": saved,
+		"questions":   questions,
+	})
+}
+
+// Edit a job of the caller's organization. Attachments sent with the edit
+// are added to the ones the job already has.
+func (h *JobHandler) UpdateJob(c *gin.Context) {
+	existing, ok := h.managedJob(c)
+	if !ok {
+		return
+	}
+	var req UpdateJobRequest
+	if !h.bindJob(c, &req) {
+		return
+	}
+	if err := req.check(); err != nil {
+		c.Error(err)
+		return
+	}
+	pending, ok := h.readAttachments(c, existing.EmployerID)
+	if !ok {
+		return
+	}
+
+	// Pay stays in the job's currency unless the edit changes it
+	job := existing.Job
+	req.apply(&job, existing.Currency)
+	if req.ExpiryDays > 0 {
+		job.ExpiresAt = time.Now().AddDate(0, 0, req.ExpiryDays)
+	}
+
+	ctx := c.Request.Context()
+	attachments, err := h.storeAttachments(ctx, pending)
+	if err != nil {
+		c.Error(apierror.Internal("Failed to store attachments", err))
+		return
+	}
+	if err := h.Jobs.Update(ctx, &job, attachments, h.MaxAttachments); err != nil {
+		h.deleteAttachmentBlobs(ctx, attachments)
+		switch {
+		case errors.Is(err, store.ErrTooManyAttachments):
+			c.Error(apierror.BadRequest(fmt.Sprintf("A job can have at most %d attachments; delete some first", h.MaxAttachments)))
+		case errors.Is(err, store.ErrScheduleConflict):
+			c.Error(apierror.Conflict(apierror.CodeScheduleConflict, "A worker hired for this job is booked elsewhere at the new time"))
+		case errors.Is(err, store.ErrNotFound):
+			c.Error(apierror.NotFound("Job not found"))
+		default:
+			c.Error(apierror.Wrap(err, "Failed to update job"))
+		}
+		return
+	}
+
+	saved, ok := h.listAttachments(c, job.ID)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Job updated successfully",
+		"job":         job,
+		"attachments": saved,
+	})
+}
 
-    var req CreateJobRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-        return
-    }
-
-    // Get employer ID from JWT token
-    
-    /* employerID := c.GetInt("user_id") // We'll implement this with JWT later
-    if employerID == 0 {
-        // Temporary: accept from query param for testing
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Employer ID required"})
-        return
-    }*/
-		// Use employer_id from request
-empID := employerID.(int)
-
-// Verify the user is an employer
-var userType string
-checkQuery := `SELECT user_type FROM users WHERE id = $1`
-err := h.DB.QueryRow(context.Background(), checkQuery, employerID).Scan(&userType)
-
-if err != nil {
-    c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid employer ID"})
-    return
+// Delete a job of the caller's organization, with its applications and
+// attachments, once no booking for it is under way or has money held
+func (h *JobHandler) DeleteJob(c *gin.Context) {
+	job, ok := h.managedJob(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	attachments, err := h.Jobs.Delete(ctx, job.ID)
+	if err != nil {
+		if errors.Is(err, store.ErrJobBooked) {
+			c.Error(apierror.Conflict(apierror.CodeJobBooked, "This job has a booking under way or money held for it; cancel or finish it first"))
+			return
+		}
+		c.Error(lookupError(err, "Job not found"))
+		return
+	}
+	for _, a := range attachments {
+		deleteBlobs(ctx, h.Blobs, &a.BlobKey)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Job deleted"})
 }
 
-if userType != "employer" {
-    c.JSON(http.StatusForbidden, gin.H{"error": "Only employers can post jobs"})
-    return
+// Remove one attachment from a job of the caller's organization
+func (h *JobHandler) DeleteAttachment(c *gin.Context) {
+	job, ok := h.managedJob(c)
+	if !ok {
+		return
+	}
+	attachmentID, ok := paramInt(c, "attachmentId")
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	attachment, err := h.Jobs.DeleteAttachment(ctx, job.ID, attachmentID)
+	if err != nil {
+		c.Error(lookupError(err, "Attachment not found"))
+		return
+	}
+	deleteBlobs(ctx, h.Blobs, &attachment.BlobKey)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted"})
 }
 
-    // Calculate expiry date
-    expiresAt := time.Now().AddDate(0, 0, req.ExpiryDays)
-
-    query := `
-        INSERT INTO jobs (
-            employer_id, title, description, category_id, location,
-            salary_min, salary_max, duration, requirements,
-            contact_phone, contact_email, expires_at
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-        RETURNING id, employer_id, title, description, category_id, location,
-                  salary_min, salary_max, duration, requirements,
-                  contact_phone, contact_email, expires_at, status, is_active, created_at
-    `
-
-    var job struct {
-        ID          int
-        EmployerID  int
-        Title       string
-        Description string
-        CategoryID  sql.NullInt64
-        Location    string
-        SalaryMin   sql.NullFloat64
-        SalaryMax   sql.NullFloat64
-        Duration    sql.NullString
-        Requirements sql.NullString
-        ContactPhone sql.NullString
-        ContactEmail sql.NullString
-        ExpiresAt   time.Time
-        Status      string
-        IsActive    bool
-        CreatedAt   time.Time
-    }
-
-    err = h.DB.QueryRow(context.Background(), query,
-        empID, req.Title, req.Description, req.CategoryID, req.Location,
-        req.SalaryMin, req.SalaryMax, req.Duration, req.Requirements,
-        req.ContactPhone, req.ContactEmail, expiresAt,
-    ).Scan(
-        &job.ID, &job.EmployerID, &job.Title, &job.Description, &job.CategoryID,
-        &job.Location, &job.SalaryMin, &job.SalaryMax, &job.Duration,
-        &job.Requirements, &job.ContactPhone, &job.ContactEmail,
-        &job.ExpiresAt, &job.Status, &job.IsActive, &job.CreatedAt,
-    )
-
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create job", "details": err.Error()})
-        return
-    }
-
-    c.JSON(http.StatusCreated, gin.H{
-        "message": "Job created successfully",
-        "job": job,
-    })
+// JobQuery filters and sorts the job listing. Rates are pay per hour in
+// minor units (see pay.HourlyRate) and only compare within a currency, so
+// filtering or sorting by rate needs one.
+type JobQuery struct {
+	PayType  models.PayType `form:"pay_type" binding:"omitempty,pay_type"`
+	Currency string         `form:"currency" binding:"required_with=MinRate MaxRate,omitempty,currency"`
+	MinRate  int64          `form:"min_rate" binding:"omitempty,min=1"`
+	MaxRate  int64          `form:"max_rate" binding:"omitempty,min=1"`
+	Sort     string         `form:"sort,default=newest" binding:"oneof=newest rate_asc rate_desc"`
 }
 
 // Get all active jobs
 func (h *JobHandler) GetJobs(c *gin.Context) {
-    query := `
-        SELECT j.id, j.employer_id, j.title, j.description, j.category_id, j.location,
-               j.salary_min, j.salary_max, j.duration, j.requirements,
-               j.contact_phone, j.contact_email, j.expires_at, j.status, j.is_active, j.created_at,
-               u.full_name as employer_name,
-               c.name as category_name
-        FROM jobs j
-        JOIN users u ON j.employer_id = u.id
-        LEFT JOIN categories c ON j.category_id = c.id
-        WHERE j.is_active = true 
-          AND j.status = 'open'
-          AND j.expires_at > NOW()
-        ORDER BY j.created_at DESC
-    `
-
-    rows, err := h.DB.Query(context.Background(), query)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch jobs"})
-        return
-    }
-    defer rows.Close()
-
-    var jobs []map[string]interface{}
-
-    for rows.Next() {
-        var (
-            id, employerID int
-            title, description, location, status, employerName string
-            categoryID sql.NullInt64
-            salaryMin, salaryMax sql.NullFloat64
-            duration, requirements, contactPhone, contactEmail, categoryName sql.NullString
-            expiresAt, createdAt time.Time
-            isActive bool
-        )
-
-        err := rows.Scan(
-            &id, &employerID, &title, &description, &categoryID, &location,
-            &salaryMin, &salaryMax, &duration, &requirements,
-            &contactPhone, &contactEmail, &expiresAt, &status, &isActive, &createdAt,
-            &employerName, &categoryName,
-        )
-
-        if err != nil {
-            continue
-        }
-
-        job := map[string]interface{}{
-            "id":            id,
-            "employer_id":   employerID,
-            "employer_name": employerName,
-            "title":         title,
-            "description":   description,
-            "location":      location,
-            "status":        status,
-            "is_active":     isActive,
-            "expires_at":    expiresAt,
-            "created_at":    createdAt,
-        }
-
-        if categoryID.Valid {
-            job["category_id"] = categoryID.Int64
-        }
-        if categoryName.Valid {
-            job["category_name"] = categoryName.String
-        }
-        if salaryMin.Valid {
-            job["salary_min"] = salaryMin.Float64
-        }
-        if salaryMax.Valid {
-            job["salary_max"] = salaryMax.Float64
-        }
-        if duration.Valid {
-            job["duration"] = duration.String
-        }
-        if requirements.Valid {
-            job["requirements"] = requirements.String
-        }
-        if contactPhone.Valid {
-            job["contact_phone"] = contactPhone.String
-        }
-        if contactEmail.Valid {
-            job["contact_email"] = contactEmail.String
-        }
-
-        jobs = append(jobs, job)
-    }
-
-    c.JSON(http.StatusOK, gin.H{
-        "jobs": jobs,
-        "count": len(jobs),
-    })
+	var q JobQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+	if q.MinRate != 0 && q.MaxRate != 0 && q.MinRate > q.MaxRate {
+		c.Error(apierror.Invalid("max_rate", "gtefield", "max_rate must be at least min_rate"))
+		return
+	}
+	if store.JobSort(q.Sort) != store.SortNewest && q.Currency == "" {
+		c.Error(apierror.Invalid("currency", "required_with", "currency is required to sort by rate"))
+		return
+	}
+
+	jobs, err := h.Jobs.ListOpen(c.Request.Context(), store.JobFilter{
+		PayType:  q.PayType,
+		Currency: q.Currency,
+		MinRate:  q.MinRate,
+		MaxRate:  q.MaxRate,
+		Sort:     store.JobSort(q.Sort),
+	})
+	if err != nil {
+		c.Error(apierror.Wrap(err, "Failed to fetch jobs"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"jobs":  jobs,
+		"count": len(jobs),
+	})
 }
 
 // Get single job by ID
 func (h *JobHandler) GetJob(c *gin.Context) {
-    jobID := c.Param("id")
-
-    query := `
-        SELECT j.id, j.employer_id, j.title, j.description, j.category_id, j.location,
-               j.salary_min, j.salary_max, j.duration, j.requirements,
-               j.contact_phone, j.contact_email, j.expires_at, j.status, j.is_active, j.created_at,
-               u.full_name as employer_name,
-               c.name as category_name
-        FROM jobs j
-        JOIN users u ON j.employer_id = u.id
-        LEFT JOIN categories c ON j.category_id = c.id
-        WHERE j.id = $1
-    `
-
-    var (
-        id, employerID int
-        title, description, location, status, employerName string
-        categoryID sql.NullInt64
-        salaryMin, salaryMax sql.NullFloat64
-        duration, requirements, contactPhone, contactEmail, categoryName sql.NullString
-        expiresAt, createdAt time.Time
-        isActive bool
-    )
-
-    err := h.DB.QueryRow(context.Background(), query, jobID).Scan(
-        &id, &employerID, &title, &description, &categoryID, &location,
-        &salaryMin, &salaryMax, &duration, &requirements,
-        &contactPhone, &contactEmail, &expiresAt, &status, &isActive, &createdAt,
-        &employerName, &categoryName,
-    )
-
-    if err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
-        return
-    }
-
-    job := map[string]interface{}{
-        "id":            id,
-        "employer_id":   employerID,
-        "employer_name": employerName,
-        "title":         title,
-        "description":   description,
-        "location":      location,
-        "status":        status,
-        "is_active":     isActive,
-        "expires_at":    expiresAt,
-        "created_at":    createdAt,
-    }
-
-    if categoryID.Valid {
-        job["category_id"] = categoryID.Int64
-    }
-    if categoryName.Valid {
-        job["category_name"] = categoryName.String
-    }
-    if salaryMin.Valid {
-        job["salary_min"] = salaryMin.Float64
-    }
-    if salaryMax.Valid {
-        job["salary_max"] = salaryMax.Float64
-    }
-    if duration.Valid {
-        job["duration"] = duration.String
-    }
-    if requirements.Valid {
-        job["requirements"] = requirements.String
-    }
-    if contactPhone.Valid {
-        job["contact_phone"] = contactPhone.String
-    }
-    if contactEmail.Valid {
-        job["contact_email"] = contactEmail.String
-    }
-
-    c.JSON(http.StatusOK, job)
+	jobID, ok := paramInt(c, "id")
+	if !ok {
+		return
+	}
+
+	job, err := h.Jobs.Get(c.Request.Context(), jobID)
+	if err != nil {
+		c.Error(lookupError(err, "Job not found"))
+		return
+	}
+	attachments, ok := h.listAttachments(c, jobID)
+	if !ok {
+		return
+	}
+	questions, err := h.Jobs.ListQuestions(c.Request.Context(), jobID)
+	if err != nil {
+		c.Error(apierror.Internal("Failed to fetch questions", err))
+		return
+	}
+	for i := range questions {
+		questions[i] = questions[i].Public()
+	}
+
+	c.JSON(http.StatusOK, models.JobDetail{JobWithDetails: *job, Attachments: attachments, Questions: questions})
+}
+
+// Get a job's screening questions with their knockouts, for its
+// organization
+func (h *JobHandler) GetJobQuestions(c *gin.Context) {
+	job, ok := h.managedJob(c)
+	if !ok {
+		return
+	}
+	questions, err := h.Jobs.ListQuestions(c.Request.Context(), job.ID)
+	if err != nil {
+		c.Error(apierror.Internal("Failed to fetch questions", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"questions": questions,
+		"count":     len(questions),
+	})
 }
@@ -0,0 +1,410 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationHandler serves employers' organizations, their teams and
+// invitations. Routes that act on one organization sit behind
+// middleware.EmployerOnly, which sets "org_id" and "org_role".
+type OrganizationHandler struct {
+	Organizations store.OrganizationStore
+	// How long an invitation can be accepted for
+	InvitationTTL time.Duration
+}
+
+type OrganizationRequest struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	Description string `json:"description"`
+	Website     string `json:"website" binding:"omitempty,url,max=255"`
+}
+
+type MemberRoleRequest struct {
+	Role models.OrgRole `json:"role" binding:"required,org_role"`
+}
+
+type InvitationRequest struct {
+	Email string         `json:"email" binding:"required,email,max=255"`
+	Role  models.OrgRole `json:"role" binding:"required,org_role"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Start a new organization with the caller as its owner
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var req OrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	org := models.Organization{Name: req.Name, Description: optional(req.Description), Website: optional(req.Website)}
+	if err := h.Organizations.Create(c.Request.Context(), &org, c.GetInt("user_id")); err != nil {
+		c.Error(apierror.Wrap(err, "Failed to create organization"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Organization created",
+		"organization": models.Membership{Organization: org, Role: models.OrgOwner},
+	})
+}
+
+// The organizations the caller belongs to, with their role in each
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	orgs, err := h.Organizations.ListByUser(c.Request.Context(), c.GetInt("user_id"))
+	if err != nil {
+		c.Error(apierror.Wrap(err, "Failed to fetch organizations"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"organizations": orgs,
+		"count":         len(orgs),
+	})
+}
+
+// One organization with its team (members only)
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	ctx := c.Request.Context()
+	orgID := c.GetInt("org_id")
+
+	org, err := h.Organizations.Get(ctx, orgID)
+	if err != nil {
+		c.Error(lookupError(err, "Organization not found"))
+		return
+	}
+	members, err := h.Organizations.ListMembers(ctx, orgID)
+	if err != nil {
+		c.Error(apierror.Wrap(err, "Failed to fetch members"))
+		return
+	}
+
+	role, _ := c.Get("org_role")
+	c.JSON(http.StatusOK, models.OrganizationDetail{
+		Membership: models.Membership{Organization: *org, Role: role.(models.OrgRole)},
+		Members:    members,
+	})
+}
+
+// Edit the organization's profile (owners only)
+func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
+	var req OrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	org := models.Organization{
+		ID:          c.GetInt("org_id"),
+		Name:        req.Name,
+		Description: optional(req.Description),
+		Website:     optional(req.Website),
+	}
+	if err := h.Organizations.Update(c.Request.Context(), &org); err != nil {
+		c.Error(lookupError(err, "Organization not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Organization updated",
+		"organization": org,
+	})
+}
+
+// Change a member's role (owners only)
+func (h *OrganizationHandler) UpdateMember(c *gin.Context) {
+	userID, ok := paramInt(c, "userId")
+	if !ok {
+		return
+	}
+	var req MemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	member, err := h.Organizations.SetRole(c.Request.Context(), c.GetInt("org_id"), userID, req.Role)
+	if err != nil {
+		c.Error(memberError(err, "Failed to update member"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Member updated",
+		"member":  member,
+	})
+}
+
+// Take someone off the team: owners can remove anyone, and every member
+// can leave
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	userID, ok := paramInt(c, "userId")
+	if !ok {
+		return
+	}
+	if role, _ := c.Get("org_role"); userID != c.GetInt("user_id") && role != models.OrgOwner {
+		c.Error(apierror.Forbidden("Only owners can remove other members"))
+		return
+	}
+
+	if err := h.Organizations.RemoveMember(c.Request.Context(), c.GetInt("org_id"), userID); err != nil {
+		c.Error(memberError(err, "Failed to remove member"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+// Invite someone by email (owners only). The token in the response is
+// shown only this once; pass it on to the invitee, who accepts it with
+// POST /api/invitations/accept.
+func (h *OrganizationHandler) CreateInvitation(c *gin.Context) {
+	var req InvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	token, hash, err := newInvitationToken()
+	if err != nil {
+		c.Error(apierror.Internal("Failed to create invitation", err))
+		return
+	}
+	inviter := c.GetInt("user_id")
+	inv := models.Invitation{
+		OrganizationID: c.GetInt("org_id"),
+		Email:          req.Email,
+		Role:           req.Role,
+		InvitedBy:      &inviter,
+		ExpiresAt:      time.Now().Add(h.InvitationTTL),
+	}
+	if err := h.Organizations.Invite(c.Request.Context(), &inv, hash); err != nil {
+		c.Error(memberError(err, "Failed to create invitation"))
+		return
+	}
+	inv.Token = token
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Invitation created",
+		"invitation": inv,
+	})
+}
+
+// Invitations that haven't been accepted yet (owners only)
+func (h *OrganizationHandler) ListInvitations(c *gin.Context) {
+	invitations, err := h.Organizations.ListInvitations(c.Request.Context(), c.GetInt("org_id"))
+	if err != nil {
+		c.Error(apierror.Wrap(err, "Failed to fetch invitations"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"invitations": invitations,
+		"count":       len(invitations),
+	})
+}
+
+// Withdraw an invitation that hasn't been accepted (owners only)
+func (h *OrganizationHandler) RevokeInvitation(c *gin.Context) {
+	id, ok := paramInt(c, "invitationId")
+	if !ok {
+		return
+	}
+
+	if err := h.Organizations.RevokeInvitation(c.Request.Context(), c.GetInt("org_id"), id); err != nil {
+		c.Error(lookupError(err, "Invitation not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked"})
+}
+
+// Join an organization with the token from an invitation sent to the
+// caller's email address
+func (h *OrganizationHandler) AcceptInvitation(c *gin.Context) {
+	var req AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	member, err := h.Organizations.AcceptInvitation(c.Request.Context(), hashInvitationToken(req.Token), c.GetInt("user_id"))
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		c.Error(apierror.NotFound("Invitation not found, already used or expired"))
+		return
+	case errors.Is(err, store.ErrInvitationEmail):
+		c.Error(apierror.Forbidden("This invitation was sent to a different email address"))
+		return
+	case err != nil:
+		c.Error(memberError(err, "Failed to accept invitation"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invitation accepted",
+		"member":  member,
+	})
+}
+
+// memberError maps the store's team errors to responses
+func memberError(err error, message string) *apierror.Error {
+	switch {
+	case errors.Is(err, store.ErrLastOwner):
+		return apierror.Conflict(apierror.CodeLastOwner, "An organization needs at least one owner; make someone else an owner first")
+	case errors.Is(err, store.ErrAlreadyMember):
+		return apierror.Conflict(apierror.CodeAlreadyMember, "They are already a member of this organization")
+	case errors.Is(err, store.ErrNotFound):
+		return apierror.NotFound("Member not found")
+	}
+	return apierror.Wrap(err, message)
+}
+
+// newInvitationToken returns a random token and the hash that is stored
+// in its place
+func newInvitationToken() (string, []byte, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashInvitationToken(token), nil
+}
+
+func hashInvitationToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// OrgOf finds which organization a request acts on, for
+// middleware.EmployerOnly
+type OrgOf struct {
+	Organizations store.OrganizationStore
+	Jobs          store.JobStore
+	Applications  store.ApplicationStore
+	Bookings      store.BookingStore
+}
+
+// pathID reads an ID path parameter
+func pathID(c *gin.Context, name string) (int, error) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		return 0, apierror.BadRequest("Invalid " + name)
+	}
+	return id, nil
+}
+
+// Path is the organization whose ID is in the path
+func (o OrgOf) Path(param string) func(*gin.Context) (int, error) {
+	return func(c *gin.Context) (int, error) {
+		id, err := pathID(c, param)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := o.Organizations.Get(c.Request.Context(), id); err != nil {
+			return 0, lookupError(err, "Organization not found")
+		}
+		return id, nil
+	}
+}
+
+// Job is the organization that owns the job in the path
+func (o OrgOf) Job(param string) func(*gin.Context) (int, error) {
+	return func(c *gin.Context) (int, error) {
+		id, err := pathID(c, param)
+		if err != nil {
+			return 0, err
+		}
+		job, err := o.Jobs.Get(c.Request.Context(), id)
+		if err != nil {
+			return 0, lookupError(err, "Job not found")
+		}
+		return job.OrganizationID, nil
+	}
+}
+
+// Application is the organization that owns the job applied to
+func (o OrgOf) Application(param string) func(*gin.Context) (int, error) {
+	return func(c *gin.Context) (int, error) {
+		id, err := pathID(c, param)
+		if err != nil {
+			return 0, err
+		}
+		app, err := o.Applications.Get(c.Request.Context(), id)
+		if err != nil {
+			return 0, lookupError(err, "Application not found")
+		}
+		job, err := o.Jobs.Get(c.Request.Context(), app.JobID)
+		if err != nil {
+			return 0, lookupError(err, "Job not found")
+		}
+		return job.OrganizationID, nil
+	}
+}
+
+// Booking is the organization that owns the booking's job
+func (o OrgOf) Booking(param string) func(*gin.Context) (int, error) {
+	return func(c *gin.Context) (int, error) {
+		id, err := pathID(c, param)
+		if err != nil {
+			return 0, err
+		}
+		b, err := o.Bookings.Get(c.Request.Context(), id)
+		if err != nil {
+			return 0, lookupError(err, "Booking not found")
+		}
+		return b.OrganizationID, nil
+	}
+}
+
+// Managed is the one organization the caller can post jobs for. Callers
+// who manage several must name one in the path instead.
+func (o OrgOf) Managed() func(*gin.Context) (int, error) {
+	return func(c *gin.Context) (int, error) {
+		orgs, err := o.Organizations.ListByUser(c.Request.Context(), c.GetInt("user_id"))
+		if err != nil {
+			return 0, apierror.Internal("Failed to fetch organizations", err)
+		}
+		var managed []int
+		for _, org := range orgs {
+			if org.Role.AtLeast(models.OrgManager) {
+				managed = append(managed, org.ID)
+			}
+		}
+		switch len(managed) {
+		case 0:
+			return 0, apierror.Forbidden("You don't manage any organization; create one first")
+		case 1:
+			return managed[0], nil
+		}
+		return 0, apierror.BadRequest("You manage several organizations; post with POST /api/organizations/{id}/jobs")
+	}
+}
+
+// onTeam reports whether the user is at least min in the organization
+// whose job the booking is for
+func onTeam(ctx context.Context, orgs store.OrganizationStore, b *models.Booking, userID int, min models.OrgRole) (bool, error) {
+	role, err := orgs.Role(ctx, b.OrganizationID, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return role.AtLeast(min), nil
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/booking"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/ledger"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/payments"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// Webhook bodies are small JSON documents
+const maxWebhookBytes = 64 << 10
+
+// PaymentHandler serves escrow, balances and the provider's webhooks
+type PaymentHandler struct {
+	Payments store.PaymentStore
+	Bookings store.BookingStore
+	Jobs     store.JobStore
+	// Members of the job's organization see its bookings' payments
+	Organizations store.OrganizationStore
+	Escrow        *payments.Escrow
+}
+
+// Webhook applies a signed notification from the payment provider. The
+// signature is the credential.
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBytes))
+	if err != nil {
+		c.Error(apierror.BadRequest("Failed to read webhook"))
+		return
+	}
+
+	event, err := h.Escrow.HandleWebhook(c.Request.Context(), c.Request.Header, body)
+	switch {
+	case errors.Is(err, payments.ErrBadSignature), errors.Is(err, payments.ErrStaleWebhook):
+		c.Error(apierror.Unauthorized("Invalid webhook signature"))
+		return
+	case errors.Is(err, store.ErrNotFound):
+		c.Error(apierror.NotFound("Payment not found"))
+		return
+	case err != nil && event == nil:
+		c.Error(apierror.BadRequest("Invalid webhook").WithCause(err))
+		return
+	case err != nil:
+		c.Error(apierror.Wrap(err, "Failed to process webhook"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook processed"})
+}
+
+// What is held for a booking, for its worker, its employer's
+// organization or an admin
+func (h *PaymentHandler) GetEscrow(c *gin.Context) {
+	b, ok := h.participantBooking(c)
+	if !ok {
+		return
+	}
+
+	escrow, err := h.Payments.Escrow(c.Request.Context(), b.ID)
+	if err != nil {
+		c.Error(apierror.Wrap(err, "Failed to fetch payments"))
+		return
+	}
+	c.JSON(http.StatusOK, escrow)
+}
+
+// Pay into escrow for a booking, e.g. after the charge made on hiring
+// failed (managers of the job's organization only)
+func (h *PaymentHandler) CreatePayment(c *gin.Context) {
+	b, ok := h.participantBooking(c)
+	if !ok {
+		return
+	}
+	if !booking.Active(b.Status) {
+		c.Error(apierror.Conflict(apierror.CodeInvalidTransition, "This booking is "+b.Status+", so it can't be paid for"))
+		return
+	}
+
+	job, err := h.Jobs.Get(c.Request.Context(), b.JobID)
+	if err != nil {
+		c.Error(lookupError(err, "Job not found"))
+		return
+	}
+
+	payment, err := h.Escrow.Hold(c.Request.Context(), b, &job.Job)
+	switch {
+	case errors.Is(err, payments.ErrNoAmount):
+		c.Error(apierror.Conflict(apierror.CodeConflict, "This job has no pay to hold"))
+		return
+	case errors.Is(err, store.ErrPaymentExists):
+		c.Error(apierror.Conflict(apierror.CodePaymentExists, "This booking is already paid for or has a payment under way"))
+		return
+	case err != nil:
+		c.Error(apierror.New(http.StatusPaymentRequired, apierror.CodePaymentFailed, "The payment could not be made").WithCause(err))
+		return
+	case payment.Status == payments.StatusFailed:
+		c.Error(apierror.New(http.StatusPaymentRequired, apierror.CodePaymentFailed, "The payment was declined"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Payment " + payment.Status,
+		"payment": payment,
+	})
+}
+
+// Return what is held for a booking to the employer (admins only)
+func (h *PaymentHandler) RefundBooking(c *gin.Context) {
+	id, ok := paramInt(c, "id")
+	if !ok {
+		return
+	}
+
+	payment, err := h.Escrow.Refund(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, store.ErrNothingHeld) {
+			c.Error(apierror.Conflict(apierror.CodeNothingHeld, "Nothing is held for this booking"))
+			return
+		}
+		c.Error(apierror.Wrap(err, "Failed to refund booking"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payment refunded",
+		"payment": payment,
+	})
+}
+
+// What the caller has earned from completed bookings, per currency
+func (h *PaymentHandler) GetBalance(c *gin.Context) {
+	balances, err := h.Payments.Balances(c.Request.Context(), ledger.Worker(c.GetInt("user_id")))
+	if err != nil {
+		c.Error(apierror.Wrap(err, "Failed to fetch balance"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"balances": balances,
+		"count":    len(balances),
+	})
+}
+
+// participantBooking loads the booking in the path, responding with 403
+// unless the caller is part of it, a member of its job's organization or
+// an admin
+func (h *PaymentHandler) participantBooking(c *gin.Context) (*models.Booking, bool) {
+	id, ok := paramInt(c, "id")
+	if !ok {
+		return nil, false
+	}
+
+	b, err := h.Bookings.Get(c.Request.Context(), id)
+	if err != nil {
+		c.Error(lookupError(err, "Booking not found"))
+		return nil, false
+	}
+	userID := c.GetInt("user_id")
+	if userID == b.WorkerID || userID == b.EmployerID || hasRole(c, models.RoleAdmin) {
+		return b, true
+	}
+	member, err := onTeam(c.Request.Context(), h.Organizations, b, userID, models.OrgViewer)
+	if err != nil {
+		c.Error(apierror.Internal("Failed to check organization membership", err))
+		return nil, false
+	}
+	if !member {
+		c.Error(apierror.Forbidden("You are not part of this booking"))
+		return nil, false
+	}
+	return b, true
+}
+
+// What became of the escrow when an application or booking changed. The
+// change itself stands either way; a failure here only means the money
+// didn't move, and the response says so instead of hiding it.
+const (
+	escrowHeld     = "held"
+	escrowPending  = "pending"  // the provider settles the charge later
+	escrowDeclined = "declined" // the employer can pay again
+	escrowFailed   = "failed"   // the charge couldn't be made; the employer can pay again
+	escrowRefunded = "refunded"
+	// The money is still held; an admin can refund it
+	escrowRefundFailed = "refund_failed"
+)
+
+// holdEscrow charges the employer for a new booking and returns the
+// outcome, or "" when there is nothing to charge. Hiring goes ahead if the
+// charge fails; the employer can pay again later.
+func holdEscrow(ctx context.Context, escrow *payments.Escrow, b *models.Booking, job *models.Job) string {
+	p, err := escrow.Hold(ctx, b, job)
+	switch {
+	case errors.Is(err, payments.ErrNoAmount), errors.Is(err, store.ErrPaymentExists):
+		return ""
+	case err != nil:
+		logger.ErrorContext(ctx, "escrow hold failed", "booking_id", b.ID, "error", err)
+		return escrowFailed
+	case p.Status == payments.StatusPending:
+		return escrowPending
+	case p.Status == payments.StatusFailed:
+		return escrowDeclined
+	case p.Status == payments.StatusRefunded:
+		return escrowRefunded
+	}
+	return escrowHeld
+}
+
+// refundEscrow returns what is held for a booking that fell through and
+// returns the outcome, or "" when nothing was held
+func refundEscrow(ctx context.Context, escrow *payments.Escrow, bookingID int) string {
+	_, err := escrow.Refund(ctx, bookingID)
+	switch {
+	case errors.Is(err, store.ErrNothingHeld):
+		return ""
+	case err != nil:
+		logger.ErrorContext(ctx, "escrow refund failed", "booking_id", bookingID, "error", err)
+		return escrowRefundFailed
+	}
+	return escrowRefunded
+}
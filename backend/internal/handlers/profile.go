@@ -1,138 +1,93 @@
 package handlers
 
 import (
-    "context"
-    "database/sql"
-    "net/http"
-    "time"
-    "github.com/gin-gonic/gin"
-    "github.com/jackc/pgx/v5/pgxpool"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/storage"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+	"github.com/gin-gonic/gin"
 )
 
 type ProfileHandler struct {
-    DB *pgxpool.Pool
+	Users store.UserStore
+	// Uploaded avatars are returned as signed URLs valid for URLTTL
+	Blobs  storage.BlobStore
+	URLTTL time.Duration
 }
 
-type UserProfile struct {
-    ID        int       `json:"id"`
-    Email     string    `json:"email"`
-    FullName  string    `json:"full_name"`
-    UserType  string    `json:"user_type"`
-    Phone     *string   `json:"phone"`     // Use pointer for nullable fields
-    Location  *string   `json:"location"`  // Use pointer for nullable fields
-    Bio       *string   `json:"bio"`       // Use pointer for nullable fields
-    AvatarURL *string   `json:"avatar_url"` // Use pointer for nullable fields
-    CreatedAt time.Time `json:"created_at"`
+type UpdateProfileRequest struct {
+	FullName string `json:"full_name"`
+	Phone    string `json:"phone"`
+	Location string `json:"location"`
+	Bio      string `json:"bio"`
+	// Where the user is; give both or neither
+	Latitude  *float64 `json:"latitude" binding:"required_with=Longitude,omitempty,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" binding:"required_with=Latitude,omitempty,min=-180,max=180"`
 }
 
-// Get profile by user ID
+// Get profile by user ID, with what they've done as a worker and as an
+// employer
 func (h *ProfileHandler) GetProfile(c *gin.Context) {
-    userID := c.Param("id")
-    
-    query := `
-        SELECT id, email, full_name, user_type, phone, location, bio, avatar_url, created_at
-        FROM users
-        WHERE id = $1
-    `
-    
-    var profile UserProfile
-    var phone, location, bio, avatarURL sql.NullString
-    
-    err := h.DB.QueryRow(context.Background(), query, userID).Scan(
-        &profile.ID,
-        &profile.Email,
-        &profile.FullName,
-        &profile.UserType,
-        &phone,
-        &location,
-        &bio,
-        &avatarURL,
-        &profile.CreatedAt,
-    )
-    
-    if err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found", "details": err.Error()})
-        return
-    }
-    
-    // Convert sql.NullString to *string
-    if phone.Valid {
-        profile.Phone = &phone.String
-    }
-    if location.Valid {
-        profile.Location = &location.String
-    }
-    if bio.Valid {
-        profile.Bio = &bio.String
-    }
-    if avatarURL.Valid {
-        profile.AvatarURL = &avatarURL.String
-    }
-    
-    c.JSON(http.StatusOK, profile)
+	userID, ok := paramInt(c, "id")
+	if !ok {
+		return
+	}
+
+	profile, err := h.Users.GetProfile(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(lookupError(err, "User not found"))
+		return
+	}
+	user, err := withAvatarURLs(c.Request.Context(), h.Blobs, h.URLTTL, &profile.User)
+	if err != nil {
+		c.Error(apierror.Internal("Failed to sign avatar URL", err))
+		return
+	}
+	profile.User = *user
+
+	c.JSON(http.StatusOK, profile)
 }
 
 // Update profile
 func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
-    userID := c.Param("id")
-    
-    var req struct {
-        FullName string `json:"full_name"`
-        Phone    string `json:"phone"`
-        Location string `json:"location"`
-        Bio      string `json:"bio"`
-    }
-    
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-        return
-    }
-    
-    query := `
-        UPDATE users
-        SET full_name = $1, phone = $2, location = $3, bio = $4
-        WHERE id = $5
-        RETURNING id, email, full_name, user_type, phone, location, bio, avatar_url, created_at
-    `
-    
-    var profile UserProfile
-    var phone, location, bio, avatarURL sql.NullString
-    
-    err := h.DB.QueryRow(context.Background(), query,
-        req.FullName, req.Phone, req.Location, req.Bio, userID,
-    ).Scan(
-        &profile.ID,
-        &profile.Email,
-        &profile.FullName,
-        &profile.UserType,
-        &phone,
-        &location,
-        &bio,
-        &avatarURL,
-        &profile.CreatedAt,
-    )
-    
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile", "details": err.Error()})
-        return
-    }
-    
-    // Convert sql.NullString to *string
-    if phone.Valid {
-        profile.Phone = &phone.String
-    }
-    if location.Valid {
-        profile.Location = &location.String
-    }
-    if bio.Valid {
-        profile.Bio = &bio.String
-    }
-    if avatarURL.Valid {
-        profile.AvatarURL = &avatarURL.String
-    }
-    
-    c.JSON(http.StatusOK, gin.H{
-        "message": "Profile updated successfully",
-        "profile": profile,
-    })
+	userID, ok := paramInt(c, "id")
+	if !ok || !selfOrAdmin(c, userID, "You can only edit your own profile") {
+		return
+	}
+
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	profile, err := h.Users.UpdateProfile(c.Request.Context(), userID, store.ProfileUpdate{
+		FullName:  req.FullName,
+		Phone:     req.Phone,
+		Location:  req.Location,
+		Bio:       req.Bio,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.Error(apierror.NotFound("User not found"))
+			return
+		}
+		c.Error(apierror.Wrap(err, "Failed to update profile"))
+		return
+	}
+	profile, err = withAvatarURLs(c.Request.Context(), h.Blobs, h.URLTTL, profile)
+	if err != nil {
+		c.Error(apierror.Internal("Failed to sign avatar URL", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Profile updated successfully",
+		"profile": profile,
+	})
 }
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/recommend"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+type RecommendationHandler struct {
+	Jobs          store.JobStore
+	Users         store.UserStore
+	Applications  store.ApplicationStore
+	Organizations store.OrganizationStore
+	Weights       recommend.Weights
+}
+
+type RecommendationQuery struct {
+	Limit int `form:"limit,default=20" binding:"min=1,max=50"`
+}
+
+// Recommendation is an open job with its score for the caller and the
+// reasons behind it, strongest first
+type Recommendation struct {
+	Job     models.JobWithDetails `json:"job"`
+	Score   float64               `json:"score"`
+	Factors []recommend.Factor    `json:"factors"`
+	Reasons []string              `json:"reasons"`
+}
+
+// Recommend open jobs to the caller (workers only), best first. Jobs they
+// have applied or been invited to, and jobs of their own organizations,
+// are left out.
+func (h *RecommendationHandler) Recommended(c *gin.Context) {
+	var q RecommendationQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.GetInt("user_id")
+	profile, err := h.Users.GetProfile(ctx, userID)
+	if err != nil {
+		c.Error(lookupError(err, "User not found"))
+		return
+	}
+	history, err := h.Applications.ListByWorker(ctx, userID)
+	if err != nil {
+		c.Error(apierror.Internal("Failed to fetch applications", err))
+		return
+	}
+	memberships, err := h.Organizations.ListByUser(ctx, userID)
+	if err != nil {
+		c.Error(apierror.Internal("Failed to fetch organizations", err))
+		return
+	}
+	jobs, err := h.Jobs.ListOpen(ctx, store.JobFilter{})
+	if err != nil {
+		c.Error(apierror.Internal("Failed to fetch jobs", err))
+		return
+	}
+
+	skip := map[int]bool{}
+	for _, app := range history {
+		skip[app.JobID] = true
+	}
+	ownOrgs := map[int]bool{}
+	for _, m := range memberships {
+		ownOrgs[m.ID] = true
+	}
+
+	worker := toRecommendWorker(profile, history)
+	now := time.Now()
+	recommendations := []Recommendation{}
+	for _, job := range jobs {
+		if skip[job.ID] || ownOrgs[job.OrganizationID] || job.EmployerID == userID {
+			continue
+		}
+		result := recommend.Score(h.Weights, worker, toRecommendJob(job), now)
+		recommendations = append(recommendations, Recommendation{
+			Job:     job,
+			Score:   result.Score,
+			Factors: result.Factors,
+			Reasons: result.Reasons(),
+		})
+	}
+	slices.SortStableFunc(recommendations, func(a, b Recommendation) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			b.Job.CreatedAt.Compare(a.Job.CreatedAt),
+			cmp.Compare(a.Job.ID, b.Job.ID),
+		)
+	})
+	recommendations = recommendations[:min(q.Limit, len(recommendations))]
+
+	c.JSON(http.StatusOK, gin.H{
+		"recommendations": recommendations,
+		"count":           len(recommendations),
+	})
+}
+
+func toRecommendWorker(profile *models.Profile, history []models.WorkerApplication) recommend.Worker {
+	var w recommend.Worker
+	if profile.Location != nil {
+		w.Location = *profile.Location
+	}
+	w.Point = toPoint(profile.Latitude, profile.Longitude)
+	if profile.Worker != nil {
+		for _, s := range profile.Worker.Skills {
+			w.Skills = append(w.Skills, recommend.Skill{CategoryID: s.CategoryID, CategoryName: s.CategoryName, Years: s.ExperienceYears})
+		}
+	}
+	for _, app := range history {
+		w.History = append(w.History, recommend.Past{
+			CategoryID: app.CategoryID,
+			Currency:   app.Currency,
+			HourlyRate: app.HourlyRate,
+			Status:     app.Status,
+		})
+	}
+	return w
+}
+
+func toRecommendJob(job models.JobWithDetails) recommend.Job {
+	j := recommend.Job{
+		CategoryID: job.CategoryID,
+		Location:   job.Location,
+		Point:      toPoint(job.Latitude, job.Longitude),
+		Currency:   job.Currency,
+		HourlyRate: job.HourlyRate,
+		CreatedAt:  job.CreatedAt,
+	}
+	if job.CategoryName != nil {
+		j.CategoryName = *job.CategoryName
+	}
+	return j
+}
+
+func toPoint(lat, lng *float64) *recommend.Point {
+	if lat == nil || lng == nil {
+		return nil
+	}
+	return &recommend.Point{Lat: *lat, Lng: *lng}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/invoice"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// StatementHandler serves invoices and monthly statements as JSON, CSV or
+// PDF
+type StatementHandler struct {
+	Invoices store.InvoiceStore
+	Users    store.UserStore
+}
+
+type StatementQuery struct {
+	// YYYY-MM; the current month when empty
+	Month  string `form:"month"`
+	Format string `form:"format" binding:"omitempty,oneof=json csv pdf"`
+}
+
+type InvoiceQuery struct {
+	Format string `form:"format" binding:"omitempty,oneof=json csv pdf"`
+}
+
+// The caller's invoices for a month: earnings for workers, payments for
+// employers. Users with both roles get the side of their active one.
+func (h *StatementHandler) GetStatement(c *gin.Context) {
+	var query StatementQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+	from, to, err := invoice.ParseMonth(query.Month, time.Now())
+	if err != nil {
+		c.Error(apierror.Invalid("month", "month", "month must look like 2026-10"))
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	user, err := h.Users.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(lookupError(err, "User not found"))
+		return
+	}
+	invoices, err := h.Invoices.ListByUser(c.Request.Context(), userID, from, to)
+	if err != nil {
+		c.Error(apierror.Wrap(err, "Failed to fetch invoices"))
+		return
+	}
+	side := invoices[:0]
+	for _, inv := range invoices {
+		party := inv.WorkerID
+		if user.UserType == models.RoleEmployer {
+			party = inv.EmployerID
+		}
+		if party != nil && *party == userID {
+			side = append(side, inv)
+		}
+	}
+	st := invoice.Statement(user.FullName, user.UserType, from, side)
+
+	filename := "statement-" + st.Month
+	switch query.Format {
+	case "csv":
+		sendFile(c, filename+".csv", "text/csv", func(buf *bytes.Buffer) error { return invoice.WriteCSV(buf, st.Invoices) })
+	case "pdf":
+		sendFile(c, filename+".pdf", "application/pdf", func(buf *bytes.Buffer) error { return invoice.WriteStatementPDF(buf, st) })
+	default:
+		c.JSON(http.StatusOK, st)
+	}
+}
+
+// One invoice, for its worker, its employer or an admin
+func (h *StatementHandler) GetInvoice(c *gin.Context) {
+	id, ok := paramInt(c, "id")
+	if !ok {
+		return
+	}
+	var query InvoiceQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	inv, err := h.Invoices.Get(c.Request.Context(), id)
+	if err != nil {
+		c.Error(lookupError(err, "Invoice not found"))
+		return
+	}
+	userID := c.GetInt("user_id")
+	isParty := (inv.WorkerID != nil && *inv.WorkerID == userID) || (inv.EmployerID != nil && *inv.EmployerID == userID)
+	if !isParty && !hasRole(c, models.RoleAdmin) {
+		c.Error(apierror.Forbidden("This invoice is not yours"))
+		return
+	}
+
+	switch query.Format {
+	case "csv":
+		sendFile(c, inv.Number+".csv", "text/csv", func(buf *bytes.Buffer) error { return invoice.WriteCSV(buf, []models.Invoice{*inv}) })
+	case "pdf":
+		sendFile(c, inv.Number+".pdf", "application/pdf", func(buf *bytes.Buffer) error { return invoice.WriteInvoicePDF(buf, *inv) })
+	default:
+		c.JSON(http.StatusOK, inv)
+	}
+}
+
+// sendFile renders a download in full before sending it, so a rendering
+// error can still become a 500
+func sendFile(c *gin.Context, filename, contentType string, render func(*bytes.Buffer) error) {
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		c.Error(apierror.Internal("Failed to render "+filename, err))
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/media"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/storage"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// Longest side of a stored avatar, and the side of its square thumbnail
+	avatarSide    = 512
+	thumbnailSide = 128
+)
+
+type UploadHandler struct {
+	Users     store.UserStore
+	Documents store.DocumentStore
+	Blobs     storage.BlobStore
+	// How long the signed URLs in responses stay valid
+	URLTTL           time.Duration
+	MaxAvatarBytes   int64
+	MaxDocumentBytes int64
+}
+
+type UploadDocumentRequest struct {
+	Kind string `form:"kind" binding:"required,oneof=id_proof certificate other"`
+}
+
+// withAvatarURLs returns a copy of u whose avatar URLs are signed links to
+// its uploaded avatar, if it has one
+func withAvatarURLs(ctx context.Context, blobs storage.BlobStore, ttl time.Duration, u *models.User) (*models.User, error) {
+	out := *u
+	if u.AvatarKey != nil {
+		url, err := blobs.SignedURL(ctx, *u.AvatarKey, ttl)
+		if err != nil {
+			return nil, err
+		}
+		out.AvatarURL = &url
+	}
+	if u.AvatarThumbnailKey != nil {
+		url, err := blobs.SignedURL(ctx, *u.AvatarThumbnailKey, ttl)
+		if err != nil {
+			return nil, err
+		}
+		out.AvatarThumbnailURL = &url
+	}
+	return &out, nil
+}
+
+// Replace the caller's avatar. Any common image format is accepted; it is
+// stored as a JPEG of at most 512px plus a 128px square thumbnail.
+func (h *UploadHandler) UploadAvatar(c *gin.Context) {
+	data, _, ok := readUpload(c, h.MaxAvatarBytes)
+	if !ok {
+		return
+	}
+	if !media.IsImage(media.DetectType(data)) {
+		c.Error(apierror.UnsupportedType("Avatar must be a JPEG, PNG, GIF or WebP image"))
+		return
+	}
+	avatar, err := media.Reencode(data, avatarSide)
+	if err != nil {
+		c.Error(imageError(err))
+		return
+	}
+	thumbnail, err := media.Thumbnail(data, thumbnailSide)
+	if err != nil {
+		c.Error(imageError(err))
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.GetInt("user_id")
+	key := storage.NewKey("avatars/"+strconv.Itoa(userID), "")
+	avatarKey, thumbnailKey := key+".jpg", key+"_thumb.jpg"
+
+	if err := putBlob(ctx, h.Blobs, avatarKey, avatar, media.JPEG); err != nil {
+		c.Error(apierror.Internal("Failed to store avatar", err))
+		return
+	}
+	if err := putBlob(ctx, h.Blobs, thumbnailKey, thumbnail, media.JPEG); err != nil {
+		deleteBlobs(ctx, h.Blobs, &avatarKey)
+		c.Error(apierror.Internal("Failed to store avatar", err))
+		return
+	}
+
+	updated, before, err := h.Users.SetAvatar(ctx, userID, &avatarKey, &thumbnailKey)
+	if err != nil {
+		deleteBlobs(ctx, h.Blobs, &avatarKey, &thumbnailKey)
+		c.Error(lookupError(err, "User not found"))
+		return
+	}
+	deleteBlobs(ctx, h.Blobs, before.AvatarKey, before.AvatarThumbnailKey)
+
+	h.respondProfile(c, "Avatar updated", updated)
+}
+
+// Remove the caller's uploaded avatar
+func (h *UploadHandler) DeleteAvatar(c *gin.Context) {
+	ctx := c.Request.Context()
+	updated, before, err := h.Users.SetAvatar(ctx, c.GetInt("user_id"), nil, nil)
+	if err != nil {
+		c.Error(lookupError(err, "User not found"))
+		return
+	}
+	deleteBlobs(ctx, h.Blobs, before.AvatarKey, before.AvatarThumbnailKey)
+
+	h.respondProfile(c, "Avatar removed", updated)
+}
+
+func (h *UploadHandler) respondProfile(c *gin.Context, message string, u *models.User) {
+	profile, err := withAvatarURLs(c.Request.Context(), h.Blobs, h.URLTTL, u)
+	if err != nil {
+		c.Error(apierror.Internal("Failed to sign avatar URL", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"profile": profile,
+	})
+}
+
+// Upload an ID proof, certificate or other document: a PDF or an image.
+// Images are re-encoded as JPEG; PDFs are stored as sent.
+func (h *UploadHandler) UploadDocument(c *gin.Context) {
+	data, name, ok := readUpload(c, h.MaxDocumentBytes)
+	if !ok {
+		return
+	}
+	var req UploadDocumentRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	data, contentType, apiErr := storableDocument(data)
+	if apiErr != nil {
+		c.Error(apiErr)
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.GetInt("user_id")
+	doc := &models.Document{
+		UserID:      userID,
+		Kind:        req.Kind,
+		FileName:    displayName(name),
+		ContentType: contentType,
+		SizeBytes:   int64(len(data)),
+		BlobKey:     storage.NewKey("documents/"+strconv.Itoa(userID), media.Extension(contentType)),
+	}
+	if err := putBlob(ctx, h.Blobs, doc.BlobKey, data, contentType); err != nil {
+		c.Error(apierror.Internal("Failed to store document", err))
+		return
+	}
+	if err := h.Documents.Create(ctx, doc); err != nil {
+		deleteBlobs(ctx, h.Blobs, &doc.BlobKey)
+		c.Error(apierror.Wrap(err, "Failed to save document"))
+		return
+	}
+
+	if err := h.signDocument(ctx, doc); err != nil {
+		c.Error(apierror.Internal("Failed to sign document URL", err))
+		return
+	}
+	c.JSON(http.StatusCreated, doc)
+}
+
+// List the caller's documents, newest first
+func (h *UploadHandler) ListDocuments(c *gin.Context) {
+	ctx := c.Request.Context()
+	docs, err := h.Documents.ListByUser(ctx, c.GetInt("user_id"))
+	if err != nil {
+		c.Error(apierror.Internal("Failed to fetch documents", err))
+		return
+	}
+	for i := range docs {
+		if err := h.signDocument(ctx, &docs[i]); err != nil {
+			c.Error(apierror.Internal("Failed to sign document URL", err))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"documents": docs,
+		"count":     len(docs),
+	})
+}
+
+// Delete one of the caller's documents
+func (h *UploadHandler) DeleteDocument(c *gin.Context) {
+	docID, ok := paramInt(c, "id")
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	doc, err := h.Documents.Delete(ctx, docID, c.GetInt("user_id"))
+	if err != nil {
+		c.Error(lookupError(err, "Document not found"))
+		return
+	}
+	deleteBlobs(ctx, h.Blobs, &doc.BlobKey)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Document deleted"})
+}
+
+func (h *UploadHandler) signDocument(ctx context.Context, doc *models.Document) error {
+	url, err := h.Blobs.SignedURL(ctx, doc.BlobKey, h.URLTTL)
+	if err != nil {
+		return err
+	}
+	doc.URL = url
+	return nil
+}
+
+// Serve a file by signed URL. Only used by storage backends whose URLs
+// point back at the API; S3 URLs go straight to the bucket.
+func (h *UploadHandler) ServeFile(c *gin.Context) {
+	verifier, ok := h.Blobs.(storage.URLVerifier)
+	if !ok {
+		c.Error(apierror.NotFound("File not found"))
+		return
+	}
+
+	key := c.Query("key")
+	if err := verifier.VerifyURL(key, c.Query("expires"), c.Query("signature")); err != nil {
+		if errors.Is(err, storage.ErrURLExpired) {
+			c.Error(apierror.Forbidden("This link has expired"))
+			return
+		}
+		c.Error(apierror.Forbidden("Invalid file link"))
+		return
+	}
+
+	body, info, err := h.Blobs.Open(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.Error(apierror.NotFound("File not found"))
+			return
+		}
+		c.Error(apierror.Internal("Failed to open file", err))
+		return
+	}
+	defer body.Close()
+
+	c.DataFromReader(http.StatusOK, info.Size, info.ContentType, body, map[string]string{
+		"Cache-Control":          "private, max-age=300",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
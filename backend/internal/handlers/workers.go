@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/schedule"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/storage"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+type WorkerHandler struct {
+	Workers      store.WorkerStore
+	Availability store.AvailabilityStore
+	// Avatar thumbnails are returned as signed URLs valid for URLTTL
+	Blobs  storage.BlobStore
+	URLTTL time.Duration
+}
+
+type WorkerQuery struct {
+	CategoryID    int     `form:"category_id" binding:"omitempty,min=1"`
+	MinExperience int     `form:"min_experience" binding:"omitempty,min=0"`
+	Location      string  `form:"location" binding:"max=100"`
+	MinRating     float64 `form:"min_rating" binding:"omitempty,min=1,max=5"`
+	// Only workers free for the whole of this window
+	AvailableFrom time.Time `form:"available_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	AvailableTo   time.Time `form:"available_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page          int       `form:"page,default=1" binding:"min=1"`
+	PerPage       int       `form:"per_page,default=20" binding:"min=1,max=100"`
+}
+
+type UpdateSkillsRequest struct {
+	Skills []models.SkillInput `json:"skills" binding:"max=20,dive"`
+}
+
+// Bounds on a search for workers free in a window, which checks each
+// candidate's calendar in Go: how long the window may be, and how many of
+// the best rated candidates are checked
+const (
+	maxWorkerWindow     = 31 * 24 * time.Hour
+	maxWindowCandidates = 500
+)
+
+// List workers for the public directory, best rated first. Contact details
+// stay private; employers reach workers by inviting them to a job.
+func (h *WorkerHandler) ListWorkers(c *gin.Context) {
+	var q WorkerQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+	window := !q.AvailableFrom.IsZero() || !q.AvailableTo.IsZero()
+	if window && !q.AvailableTo.After(q.AvailableFrom) {
+		c.Error(apierror.BadRequest("available_from and available_to must both be given, with available_to later"))
+		return
+	}
+	if window && q.AvailableTo.Sub(q.AvailableFrom) > maxWorkerWindow {
+		c.Error(apierror.BadRequest("available_to must be at most 31 days after available_from"))
+		return
+	}
+	if q.MinExperience > 0 && q.CategoryID == 0 {
+		c.Error(apierror.BadRequest("min_experience needs a category_id"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	filter := store.WorkerFilter{
+		CategoryID:    q.CategoryID,
+		MinExperience: q.MinExperience,
+		Location:      q.Location,
+		MinRating:     q.MinRating,
+		Limit:         q.PerPage,
+		Offset:        (q.Page - 1) * q.PerPage,
+	}
+	// Calendars are checked in Go, so a window search pages after
+	// checking the best rated candidates the database couldn't rule out
+	if window {
+		filter.AvailableFrom, filter.AvailableTo = q.AvailableFrom, q.AvailableTo
+		filter.Limit, filter.Offset = maxWindowCandidates, 0
+	}
+	workers, total, err := h.Workers.List(ctx, filter)
+	if err != nil {
+		c.Error(apierror.Internal("Failed to fetch workers", err))
+		return
+	}
+	resp := gin.H{"page": q.Page, "per_page": q.PerPage}
+	if window {
+		resp["truncated"] = total > maxWindowCandidates
+		want := schedule.Interval{Start: q.AvailableFrom, End: q.AvailableTo}
+		if workers, err = h.onlyAvailable(ctx, workers, want); err != nil {
+			c.Error(apierror.Internal("Failed to check availability", err))
+			return
+		}
+		total = len(workers)
+		workers = page(workers, (q.Page-1)*q.PerPage, q.PerPage)
+	}
+	for i := range workers {
+		h.signThumbnail(ctx, &workers[i])
+	}
+
+	resp["workers"], resp["total"] = workers, total
+	c.JSON(http.StatusOK, resp)
+}
+
+// Replace the caller's skills (workers only)
+func (h *WorkerHandler) UpdateSkills(c *gin.Context) {
+	var req UpdateSkillsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apierror.FromBinding(err))
+		return
+	}
+
+	skills, err := h.Workers.ReplaceSkills(c.Request.Context(), c.GetInt("user_id"), req.Skills)
+	if err != nil {
+		c.Error(apierror.Wrap(err, "Failed to update skills"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Skills updated",
+		"skills":  skills,
+	})
+}
+
+// onlyAvailable keeps the workers whose calendar covers want and who
+// aren't booked during it, marking them Available
+func (h *WorkerHandler) onlyAvailable(ctx context.Context, workers []models.WorkerCard, want schedule.Interval) ([]models.WorkerCard, error) {
+	ids := make([]int, len(workers))
+	for i, w := range workers {
+		ids[i] = w.ID
+	}
+	calendars, err := h.Availability.ListByWorkers(ctx, ids, want.Start, want.End)
+	if err != nil {
+		return nil, err
+	}
+
+	free := []models.WorkerCard{}
+	for _, w := range workers {
+		if calendar := calendars[w.ID]; calendar != nil && toSchedule(calendar, 0).Covers(want) {
+			available := true
+			w.Available = &available
+			free = append(free, w)
+		}
+	}
+	return free, nil
+}
+
+func (h *WorkerHandler) signThumbnail(ctx context.Context, w *models.WorkerCard) {
+	if w.AvatarThumbnailKey == nil {
+		return
+	}
+	url, err := h.Blobs.SignedURL(ctx, *w.AvatarThumbnailKey, h.URLTTL)
+	if err != nil {
+		logger.WarnContext(ctx, "signing avatar thumbnail failed", "user_id", w.ID, "error", err)
+		return
+	}
+	w.AvatarThumbnailURL = &url
+}
+
+// page returns up to limit items starting at offset
+func page[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return items[:0]
+	}
+	return items[offset:min(offset+limit, len(items))]
+}
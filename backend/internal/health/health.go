@@ -0,0 +1,122 @@
+// Package health serves the liveness and readiness probes.
+//
+// /livez only says the process is serving HTTP; a failing liveness probe
+// gets the pod restarted, so it must not depend on the database.
+// /readyz says whether the pod should receive traffic: the database
+// answers and its schema is at the version this build expects. Anyone can
+// call /readyz, so failures are logged in full but reported only as a
+// short message.
+package health
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/db"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/logging"
+)
+
+var logger = logging.For("health")
+
+// PoolStats is the subset of pgxpool.Stat worth reporting
+type PoolStats struct {
+	TotalConns        int32 `json:"total_conns"`
+	IdleConns         int32 `json:"idle_conns"`
+	AcquiredConns     int32 `json:"acquired_conns"`
+	ConstructingConns int32 `json:"constructing_conns"`
+	MaxConns          int32 `json:"max_conns"`
+	AcquireCount      int64 `json:"acquire_count"`
+	EmptyAcquireCount int64 `json:"empty_acquire_count"`
+	CanceledAcquires  int64 `json:"canceled_acquire_count"`
+}
+
+// Check is the result of one readiness check
+type Check struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Current  string `json:"current,omitempty"`
+	Expected string `json:"expected,omitempty"`
+}
+
+// Checker runs the probes. The fields are functions so tests can fake
+// them; NewChecker wires them to a real pool.
+type Checker struct {
+	Ping            func(ctx context.Context) error
+	MigrationStatus func(ctx context.Context) (current, expected string, err error)
+	PoolStats       func() PoolStats
+	// Upper bound for the whole readiness check
+	Timeout time.Duration
+}
+
+func NewChecker(pool *pgxpool.Pool, migrations fs.FS) *Checker {
+	return &Checker{
+		Ping: pool.Ping,
+		MigrationStatus: func(ctx context.Context) (string, string, error) {
+			return db.MigrationStatus(ctx, pool, migrations)
+		},
+		PoolStats: func() PoolStats {
+			s := pool.Stat()
+			return PoolStats{
+				TotalConns:        s.TotalConns(),
+				IdleConns:         s.IdleConns(),
+				AcquiredConns:     s.AcquiredConns(),
+				ConstructingConns: s.ConstructingConns(),
+				MaxConns:          s.MaxConns(),
+				AcquireCount:      s.AcquireCount(),
+				EmptyAcquireCount: s.EmptyAcquireCount(),
+				CanceledAcquires:  s.CanceledAcquireCount(),
+			}
+		},
+		Timeout: 2 * time.Second,
+	}
+}
+
+// Livez serves GET /livez
+func (h *Checker) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz serves GET /readyz: 200 when every check passes, 503 otherwise
+func (h *Checker) Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.Timeout)
+	defer cancel()
+
+	ready := true
+	checks := map[string]Check{}
+
+	if err := h.Ping(ctx); err != nil {
+		ready = false
+		logger.ErrorContext(ctx, "readiness: database ping failed", "error", err)
+		checks["database"] = Check{Status: "fail", Error: "database is unreachable"}
+	} else {
+		checks["database"] = Check{Status: "ok"}
+	}
+
+	current, expected, err := h.MigrationStatus(ctx)
+	switch {
+	case err != nil:
+		ready = false
+		logger.ErrorContext(ctx, "readiness: migration status failed", "error", err)
+		checks["migrations"] = Check{Status: "fail", Error: "schema version is unknown"}
+	case current != expected:
+		ready = false
+		checks["migrations"] = Check{Status: "fail", Error: "schema is not at the expected version", Current: current, Expected: expected}
+	default:
+		checks["migrations"] = Check{Status: "ok", Current: current, Expected: expected}
+	}
+
+	status, code := "ok", http.StatusOK
+	if !ready {
+		status, code = "unavailable", http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{
+		"status": status,
+		"checks": checks,
+		"pool":   h.PoolStats(),
+	})
+}
@@ -0,0 +1,94 @@
+// Package invoice numbers invoices and renders them, and the monthly
+// statements that list them, as CSV and PDF.
+//
+// Invoices are issued by the store when a completed booking's escrow is
+// paid to the worker; the worker keeps them as proof of income and the
+// employer as receipts.
+package invoice
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+)
+
+// MonthLayout is how statement months are written, e.g. 2026-10
+const MonthLayout = "2006-01"
+
+// ErrInvalidMonth is returned for a month not written as YYYY-MM
+var ErrInvalidMonth = errors.New("month must look like 2026-10")
+
+// Number formats the seq'th invoice issued in year, e.g. INV-2026-000042
+func Number(year, seq int) string {
+	return fmt.Sprintf("INV-%d-%06d", year, seq)
+}
+
+// ParseMonth returns the UTC bounds [from, to) of a YYYY-MM month; an
+// empty month means the one now falls in
+func ParseMonth(month string, now time.Time) (from, to time.Time, err error) {
+	if month == "" {
+		now = now.UTC()
+		from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	} else if from, err = time.Parse(MonthLayout, month); err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidMonth
+	}
+	return from, from.AddDate(0, 1, 0), nil
+}
+
+// FormatAmount writes minor units as a decimal with thousands separators,
+// e.g. 125000 INR as "1,250.00 INR"
+func FormatAmount(amount int64, currency string) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	whole := strconv.FormatInt(amount/100, 10)
+	var b strings.Builder
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return fmt.Sprintf("%s%s.%02d %s", sign, b.String(), amount%100, currency)
+}
+
+// decimal writes minor units as a plain decimal for CSV, e.g. 1250.00
+func decimal(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+// Statement gathers a month's invoices for name, oldest first, with a
+// total per currency
+func Statement(name string, role models.Role, from time.Time, invoices []models.Invoice) models.Statement {
+	st := models.Statement{
+		Month:    from.Format(MonthLayout),
+		Role:     role,
+		Name:     name,
+		Invoices: invoices,
+		Totals:   []models.Balance{},
+	}
+	if st.Invoices == nil {
+		st.Invoices = []models.Invoice{}
+	}
+	sort.SliceStable(st.Invoices, func(i, j int) bool { return st.Invoices[i].IssuedAt.Before(st.Invoices[j].IssuedAt) })
+
+	totals := map[string]int64{}
+	for _, inv := range st.Invoices {
+		totals[inv.Currency] += inv.Amount
+	}
+	for currency, amount := range totals {
+		st.Totals = append(st.Totals, models.Balance{Currency: currency, Amount: amount})
+	}
+	sort.Slice(st.Totals, func(i, j int) bool { return st.Totals[i].Currency < st.Totals[j].Currency })
+	return st
+}
@@ -0,0 +1,157 @@
+package invoice
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+)
+
+func TestNumber(t *testing.T) {
+	if got := Number(2026, 42); got != "INV-2026-000042" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestParseMonth(t *testing.T) {
+	now := time.Date(2026, 10, 19, 23, 30, 0, 0, time.FixedZone("IST", 5*3600+1800))
+
+	from, to, err := ParseMonth("", now)
+	if err != nil || !from.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) || !to.Equal(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("current month: %v %v %v", from, to, err)
+	}
+	from, to, err = ParseMonth("2026-12", now)
+	if err != nil || from.Month() != time.December || to.Year() != 2027 || to.Month() != time.January {
+		t.Errorf("december: %v %v %v", from, to, err)
+	}
+	for _, bad := range []string{"2026-13", "10-2026", "2026/10", "2026-1"} {
+		if _, _, err := ParseMonth(bad, now); !errors.Is(err, ErrInvalidMonth) {
+			t.Errorf("%q: %v", bad, err)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := map[int64]string{
+		0:          "0.00 INR",
+		5:          "0.05 INR",
+		125000:     "1,250.00 INR",
+		123456789:  "1,234,567.89 INR",
+		-100000050: "-1,000,000.50 INR",
+	}
+	for amount, want := range tests {
+		if got := FormatAmount(amount, "INR"); got != want {
+			t.Errorf("%d: got %s, want %s", amount, got, want)
+		}
+	}
+}
+
+func invoices() []models.Invoice {
+	booking := 7
+	issued := time.Date(2026, 10, 3, 12, 0, 0, 0, time.UTC)
+	return []models.Invoice{
+		{ID: 2, Number: "INV-2026-000002", BookingID: &booking, WorkerName: "Asha", EmployerName: "Ravi, Sons & Co",
+			JobTitle: "Paint fence", Amount: 90050, Currency: "INR", IssuedAt: issued.Add(48 * time.Hour)},
+		{ID: 1, Number: "INV-2026-000001", WorkerName: "Asha", EmployerName: "Meera",
+			JobTitle: "Fix sink", Amount: 125000, Currency: "INR", IssuedAt: issued},
+		{ID: 3, Number: "INV-2026-000003", WorkerName: "Asha", EmployerName: "Acme",
+			JobTitle: "Tiling", Amount: 2000, Currency: "USD", IssuedAt: issued.Add(72 * time.Hour)},
+	}
+}
+
+func TestStatement(t *testing.T) {
+	st := Statement("Asha", models.RoleWorker, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), invoices())
+	if st.Month != "2026-10" || st.Invoices[0].Number != "INV-2026-000001" {
+		t.Errorf("statement: %+v", st)
+	}
+	want := []models.Balance{{Currency: "INR", Amount: 215050}, {Currency: "USD", Amount: 2000}}
+	if fmt.Sprint(st.Totals) != fmt.Sprint(want) {
+		t.Errorf("totals = %v, want %v", st.Totals, want)
+	}
+
+	empty := Statement("Asha", models.RoleWorker, time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), nil)
+	if empty.Invoices == nil || empty.Totals == nil {
+		t.Error("empty statement must have empty lists, not null")
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, invoices()); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 || rows[0][0] != "invoice_number" {
+		t.Fatalf("rows: %v", rows)
+	}
+	if got := rows[1]; got[2] != "7" || got[5] != "Ravi, Sons & Co" || got[6] != "900.50" || got[1] != "2026-10-05T12:00:00Z" {
+		t.Errorf("row: %v", got)
+	}
+	if rows[2][2] != "" {
+		t.Errorf("invoice without a booking: %v", rows[2])
+	}
+}
+
+func TestWriteCSVDefusesFormulas(t *testing.T) {
+	inv := invoices()[:1]
+	inv[0].JobTitle = `=HYPERLINK("http://evil.example","Paid")`
+	inv[0].WorkerName, inv[0].EmployerName = "+cmd|' /C calc'!A0", "-Ravi"
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, inv); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range map[int]string{3: "'" + inv[0].JobTitle, 4: "'" + inv[0].WorkerName, 5: "'-Ravi", 6: "900.50"} {
+		if rows[1][i] != want {
+			t.Errorf("column %s = %q, want %q", rows[0][i], rows[1][i], want)
+		}
+	}
+}
+
+func TestPDFs(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteInvoicePDF(&buf, invoices()[0]); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) || !bytes.Contains(buf.Bytes(), []byte("INV-2026-000002")) {
+		t.Error("invoice PDF")
+	}
+
+	// Names in Indian scripts are spelled out rather than lost
+	inv := invoices()[0]
+	inv.WorkerName, inv.JobTitle = "राजेश कुमार", "பெயிண்ட்"
+	buf.Reset()
+	if err := WriteInvoicePDF(&buf, inv); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("(Rajesh Kumar)")) || !bytes.Contains(buf.Bytes(), []byte("(Peyint)")) {
+		t.Error("non-Latin names not transliterated")
+	}
+
+	// Enough invoices to need a second page
+	var many []models.Invoice
+	for i := 0; i < 60; i++ {
+		many = append(many, invoices()...)
+	}
+	buf.Reset()
+	st := Statement("Asha", models.RoleWorker, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), many)
+	if err := WriteStatementPDF(&buf, st); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("/Count 5")) {
+		t.Errorf("expected 5 pages for %d invoices", len(many))
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("129,030.00 INR")) {
+		t.Error("missing total")
+	}
+}
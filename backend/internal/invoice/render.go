@@ -0,0 +1,171 @@
+package invoice
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/pdf"
+)
+
+var csvHeader = []string{"invoice_number", "issued_at", "booking_id", "job_title", "worker", "employer", "amount", "currency"}
+
+// WriteCSV writes one row per invoice. Amounts are plain decimals so
+// spreadsheets can sum them.
+func WriteCSV(w io.Writer, invoices []models.Invoice) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, inv := range invoices {
+		bookingID := ""
+		if inv.BookingID != nil {
+			bookingID = fmt.Sprint(*inv.BookingID)
+		}
+		row := []string{
+			inv.Number, inv.IssuedAt.UTC().Format(time.RFC3339), bookingID, textCell(inv.JobTitle),
+			textCell(inv.WorkerName), textCell(inv.EmployerName), decimal(inv.Amount), inv.Currency,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// textCell defuses text users typed, such as a job titled
+// "=HYPERLINK(...)", which a spreadsheet would otherwise run as a formula:
+// a leading quote makes it read as text.
+func textCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// Layout, in points
+const (
+	margin     = 50.0
+	lineHeight = 16.0
+	right      = pdf.PageWidth - margin
+	// Columns of the statement table
+	colDate   = margin + 110
+	colJob    = margin + 180
+	colAmount = right
+)
+
+// WriteInvoicePDF renders one invoice
+func WriteInvoicePDF(w io.Writer, inv models.Invoice) error {
+	doc := pdf.New("Invoice " + inv.Number)
+	p := doc.AddPage()
+	y := pdf.PageHeight - margin - 10
+
+	p.Text(margin, y, 22, true, "Invoice")
+	p.TextRight(right, y, 12, true, inv.Number)
+	y -= 2 * lineHeight
+	p.Text(margin, y, 10, false, "Issued "+inv.IssuedAt.UTC().Format("2 January 2006"))
+	y -= 2 * lineHeight
+
+	for _, row := range [][2]string{
+		{"Worker", inv.WorkerName},
+		{"Employer", inv.EmployerName},
+		{"Job", inv.JobTitle},
+		{"Booking", bookingRef(inv)},
+	} {
+		p.Text(margin, y, 10, true, row[0])
+		p.Text(margin+80, y, 10, false, row[1])
+		y -= lineHeight
+	}
+
+	y -= lineHeight
+	p.Line(margin, y+lineHeight-4, right, y+lineHeight-4)
+	p.Text(margin, y, 12, true, "Amount paid")
+	p.TextRight(right, y, 12, true, FormatAmount(inv.Amount, inv.Currency))
+	y -= 2 * lineHeight
+	p.Text(margin, y, 8, false, "Paid from escrow when both sides confirmed the work was done.")
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+func bookingRef(inv models.Invoice) string {
+	if inv.BookingID == nil {
+		return "-"
+	}
+	return fmt.Sprintf("#%d", *inv.BookingID)
+}
+
+// WriteStatementPDF renders a month's statement, continuing the table on
+// new pages as needed
+func WriteStatementPDF(w io.Writer, st models.Statement) error {
+	title := "Earnings statement"
+	if st.Role == models.RoleEmployer {
+		title = "Payments statement"
+	}
+	month := st.Month
+	if t, err := time.Parse(MonthLayout, st.Month); err == nil {
+		month = t.Format("January 2006")
+	}
+
+	doc := pdf.New(title + " " + st.Month)
+	p := doc.AddPage()
+	y := pdf.PageHeight - margin - 10
+	p.Text(margin, y, 20, true, title)
+	y -= 1.5 * lineHeight
+	p.Text(margin, y, 11, false, st.Name+", "+month)
+	y -= 2 * lineHeight
+
+	header := func() {
+		p.Text(margin, y, 9, true, "Invoice")
+		p.Text(colDate, y, 9, true, "Date")
+		p.Text(colJob, y, 9, true, "Job")
+		p.TextRight(colAmount, y, 9, true, "Amount")
+		p.Line(margin, y-5, right, y-5)
+		y -= lineHeight + 2
+	}
+	header()
+
+	for _, inv := range st.Invoices {
+		if y < margin+3*lineHeight {
+			p = doc.AddPage()
+			y = pdf.PageHeight - margin - 10
+			header()
+		}
+		counterparty := inv.EmployerName
+		if st.Role == models.RoleEmployer {
+			counterparty = inv.WorkerName
+		}
+		p.Text(margin, y, 9, false, inv.Number)
+		p.Text(colDate, y, 9, false, inv.IssuedAt.UTC().Format("02 Jan"))
+		p.Text(colJob, y, 9, false, truncate(inv.JobTitle+" ("+counterparty+")", 55))
+		p.TextRight(colAmount, y, 9, false, FormatAmount(inv.Amount, inv.Currency))
+		y -= lineHeight
+	}
+	if len(st.Invoices) == 0 {
+		p.Text(margin, y, 9, false, "No invoices this month.")
+		y -= lineHeight
+	}
+
+	p.Line(margin, y+lineHeight-5, right, y+lineHeight-5)
+	y -= 4
+	for _, total := range st.Totals {
+		p.Text(colJob, y, 10, true, "Total")
+		p.TextRight(colAmount, y, 10, true, FormatAmount(total.Amount, total.Currency))
+		y -= lineHeight
+	}
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
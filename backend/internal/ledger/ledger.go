@@ -0,0 +1,145 @@
+// Package ledger describes money movements as double-entry transactions.
+//
+// Every transaction is a set of entries whose amounts sum to zero, so money
+// is only ever moved between accounts, never created or lost. An account's
+// balance is the sum of its entries; nothing stores a running total that
+// could drift from the history. Amounts are in minor units (paise, cents).
+//
+// Accounts are named by code:
+//
+//	external:<provider>   money that came in from, or went back to, a payment provider
+//	escrow:booking:<id>   held for a booking until it completes or is refunded
+//	worker:<id>           earned by a worker
+//
+// A positive entry adds to an account; the external account therefore goes
+// negative as money is paid in.
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// Kinds of transaction
+const (
+	KindHold    = "hold"
+	KindRelease = "release"
+	KindRefund  = "refund"
+)
+
+var (
+	// ErrUnbalanced is returned for a transaction whose entries don't sum
+	// to zero
+	ErrUnbalanced = errors.New("ledger transaction does not balance")
+	// ErrInvalid is returned for a transaction that is malformed in any
+	// other way
+	ErrInvalid = errors.New("invalid ledger transaction")
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Entry moves Amount into (or, when negative, out of) Account
+type Entry struct {
+	Account string
+	Amount  int64
+}
+
+// Transaction is one balanced movement of money in a single currency
+type Transaction struct {
+	Kind string
+	// Posting a transaction with a key already used does nothing, so
+	// retries are safe
+	Key         string
+	Currency    string
+	BookingID   *int
+	PaymentID   *int
+	Description string
+	Entries     []Entry
+}
+
+// Validate checks t can be posted
+func (t Transaction) Validate() error {
+	if t.Key == "" {
+		return fmt.Errorf("%w: missing key", ErrInvalid)
+	}
+	if !currencyCode.MatchString(t.Currency) {
+		return fmt.Errorf("%w: currency %q is not an ISO 4217 code", ErrInvalid, t.Currency)
+	}
+	if len(t.Entries) < 2 {
+		return fmt.Errorf("%w: needs at least two entries", ErrInvalid)
+	}
+	var sum int64
+	for _, e := range t.Entries {
+		if e.Account == "" || e.Amount == 0 {
+			return fmt.Errorf("%w: entries need an account and a non-zero amount", ErrInvalid)
+		}
+		sum += e.Amount
+	}
+	if sum != 0 {
+		return fmt.Errorf("%w: entries sum to %d", ErrUnbalanced, sum)
+	}
+	return nil
+}
+
+// External is the account for money moving through provider
+func External(provider string) string {
+	return "external:" + provider
+}
+
+// Escrow is the account holding a booking's payment
+func Escrow(bookingID int) string {
+	return fmt.Sprintf("escrow:booking:%d", bookingID)
+}
+
+// Worker is the account of what a worker has earned
+func Worker(workerID int) string {
+	return fmt.Sprintf("worker:%d", workerID)
+}
+
+// Hold moves a successful payment into the booking's escrow
+func Hold(bookingID, paymentID int, provider, currency string, amount int64) Transaction {
+	return Transaction{
+		Kind:        KindHold,
+		Key:         fmt.Sprintf("hold:payment:%d", paymentID),
+		Currency:    currency,
+		BookingID:   &bookingID,
+		PaymentID:   &paymentID,
+		Description: fmt.Sprintf("Escrow for booking %d", bookingID),
+		Entries: []Entry{
+			{External(provider), -amount},
+			{Escrow(bookingID), amount},
+		},
+	}
+}
+
+// Release pays what is held for a completed booking to its worker
+func Release(bookingID, workerID int, currency string, amount int64) Transaction {
+	return Transaction{
+		Kind:        KindRelease,
+		Key:         fmt.Sprintf("release:booking:%d:%s", bookingID, currency),
+		Currency:    currency,
+		BookingID:   &bookingID,
+		Description: fmt.Sprintf("Payment for booking %d", bookingID),
+		Entries: []Entry{
+			{Escrow(bookingID), -amount},
+			{Worker(workerID), amount},
+		},
+	}
+}
+
+// Refund returns what is held for a booking to the payer
+func Refund(bookingID, paymentID int, provider, currency string, amount int64) Transaction {
+	return Transaction{
+		Kind:        KindRefund,
+		Key:         fmt.Sprintf("refund:payment:%d", paymentID),
+		Currency:    currency,
+		BookingID:   &bookingID,
+		PaymentID:   &paymentID,
+		Description: fmt.Sprintf("Refund for booking %d", bookingID),
+		Entries: []Entry{
+			{Escrow(bookingID), -amount},
+			{External(provider), amount},
+		},
+	}
+}
@@ -0,0 +1,79 @@
+package ledger
+
+import (
+	"errors"
+	"testing"
+)
+
+func sum(t Transaction, account string) int64 {
+	var total int64
+	for _, e := range t.Entries {
+		if e.Account == account {
+			total += e.Amount
+		}
+	}
+	return total
+}
+
+func TestBuildersBalance(t *testing.T) {
+	for _, tx := range []Transaction{
+		Hold(3, 7, "fake", "INR", 150000),
+		Release(3, 2, "INR", 150000),
+		Refund(3, 7, "fake", "INR", 150000),
+	} {
+		if err := tx.Validate(); err != nil {
+			t.Errorf("%s: %v", tx.Kind, err)
+		}
+	}
+}
+
+func TestHoldReleaseMovesMoneyToWorker(t *testing.T) {
+	hold := Hold(3, 7, "fake", "INR", 150000)
+	release := Release(3, 2, "INR", 150000)
+
+	if got := sum(hold, Escrow(3)) + sum(release, Escrow(3)); got != 0 {
+		t.Errorf("escrow after release = %d, want 0", got)
+	}
+	if got := sum(release, Worker(2)); got != 150000 {
+		t.Errorf("worker = %d", got)
+	}
+	if got := sum(hold, External("fake")); got != -150000 {
+		t.Errorf("external = %d", got)
+	}
+}
+
+func TestKeysMakePostingIdempotent(t *testing.T) {
+	if Hold(3, 7, "fake", "INR", 1).Key == Refund(3, 7, "fake", "INR", 1).Key {
+		t.Error("hold and refund of one payment share a key")
+	}
+	if Release(3, 2, "INR", 5).Key != Release(3, 2, "INR", 9).Key {
+		t.Error("a booking's release key depends on the amount, so it could be paid twice")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() Transaction { return Hold(1, 1, "fake", "INR", 100) }
+
+	tests := []struct {
+		name   string
+		modify func(*Transaction)
+		want   error
+	}{
+		{"unbalanced", func(tx *Transaction) { tx.Entries[1].Amount = 99 }, ErrUnbalanced},
+		{"no key", func(tx *Transaction) { tx.Key = "" }, ErrInvalid},
+		{"bad currency", func(tx *Transaction) { tx.Currency = "rupees" }, ErrInvalid},
+		{"one entry", func(tx *Transaction) { tx.Entries = tx.Entries[:1] }, ErrInvalid},
+		{"zero entry", func(tx *Transaction) {
+			tx.Entries = append(tx.Entries, Entry{Account: Worker(1), Amount: 0})
+		}, ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := valid()
+			tt.modify(&tx)
+			if err := tx.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+// Package logging configures log/slog for the whole service.
+//
+// Every package gets its own logger from For("name"). Its minimum level
+// comes from LOG_LEVELS (e.g. "db=debug,http=warn") and falls back to
+// LOG_LEVEL. Loggers look the configuration up on each call, so loggers
+// created in package-level vars pick up Setup even though they exist
+// before it runs.
+//
+// Records logged with a context (InfoContext etc.) carry the request ID
+// and trace ID of that request. Secrets and email addresses are masked
+// before anything is written; see redact.go.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/config"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// state is swapped atomically by Setup
+type state struct {
+	base   slog.Handler
+	level  slog.Level
+	levels map[string]slog.Level
+}
+
+var current atomic.Pointer[state]
+
+func init() {
+	current.Store(&state{base: newBase(os.Stderr, FormatJSON), level: slog.LevelInfo})
+	slog.SetDefault(For("app"))
+}
+
+// Setup applies the configured format and levels and makes For("app") the
+// default logger, which also routes the standard log package through slog
+func Setup(cfg config.LogConfig) error {
+	return setup(os.Stderr, cfg)
+}
+
+func setup(w io.Writer, cfg config.LogConfig) error {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+	levels := make(map[string]slog.Level, len(cfg.Levels))
+	for _, entry := range cfg.Levels {
+		name, raw, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("log level %q must look like package=level", entry)
+		}
+		l, err := ParseLevel(raw)
+		if err != nil {
+			return err
+		}
+		levels[strings.TrimSpace(name)] = l
+	}
+
+	current.Store(&state{base: newBase(w, cfg.Format), level: level, levels: levels})
+	slog.SetDefault(For("app"))
+	return nil
+}
+
+// ParseLevel accepts debug, info, warn or error
+func ParseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, fmt.Errorf("unknown log level %q", s)
+	}
+	return l, nil
+}
+
+func newBase(w io.Writer, format string) slog.Handler {
+	// The base handler lets everything through; packageHandler filters
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug, ReplaceAttr: redact}
+	if format == FormatText {
+		return slog.NewTextHandler(w, opts)
+	}
+	return slog.NewJSONHandler(w, opts)
+}
+
+// For returns the logger for a package. Records are tagged with
+// "logger": name.
+func For(name string) *slog.Logger {
+	return slog.New(&packageHandler{name: name})
+}
+
+// packageHandler resolves the base handler and level at log time
+type packageHandler struct {
+	name string
+	// WithAttrs and WithGroup calls, replayed on the current base handler
+	with []func(slog.Handler) slog.Handler
+}
+
+func (h *packageHandler) Enabled(_ context.Context, level slog.Level) bool {
+	s := current.Load()
+	threshold, ok := s.levels[h.name]
+	if !ok {
+		threshold = s.level
+	}
+	return level >= threshold
+}
+
+func (h *packageHandler) Handle(ctx context.Context, r slog.Record) error {
+	base := current.Load().base.WithAttrs([]slog.Attr{slog.String("logger", h.name)})
+	if id := RequestID(ctx); id != "" {
+		base = base.WithAttrs([]slog.Attr{slog.String("request_id", id)})
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		base = base.WithAttrs([]slog.Attr{slog.String("trace_id", span.TraceID().String())})
+	}
+	for _, apply := range h.with {
+		base = apply(base)
+	}
+	return base.Handle(ctx, r)
+}
+
+func (h *packageHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.extend(func(base slog.Handler) slog.Handler { return base.WithAttrs(attrs) })
+}
+
+func (h *packageHandler) WithGroup(name string) slog.Handler {
+	return h.extend(func(base slog.Handler) slog.Handler { return base.WithGroup(name) })
+}
+
+func (h *packageHandler) extend(apply func(slog.Handler) slog.Handler) slog.Handler {
+	with := append(append([]func(slog.Handler) slog.Handler(nil), h.with...), apply)
+	return &packageHandler{name: h.name, with: with}
+}
+
+type requestIDKey struct{}
+
+// WithRequestID stores the request ID in ctx for loggers and error responses
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the ID stored by WithRequestID, or ""
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
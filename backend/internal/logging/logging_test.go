@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/config"
+)
+
+func capture(t *testing.T, cfg config.LogConfig) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := setup(&buf, cfg); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = setup(&bytes.Buffer{}, config.Default().Log) })
+	return &buf
+}
+
+func lines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("not JSON: %q", line)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func TestPerPackageLevels(t *testing.T) {
+	// Loggers made before Setup must follow it
+	db, http := For("db"), For("http")
+	buf := capture(t, config.LogConfig{Level: "info", Format: FormatJSON, Levels: []string{"db=debug", "http=warn"}})
+
+	db.Debug("db debug")
+	http.Info("http info")
+	http.Warn("http warn")
+	For("other").Debug("other debug")
+
+	got := lines(t, buf)
+	if len(got) != 2 || got[0]["msg"] != "db debug" || got[1]["msg"] != "http warn" {
+		t.Fatalf("unexpected records: %v", got)
+	}
+	if got[0]["logger"] != "db" {
+		t.Errorf("logger = %v, want db", got[0]["logger"])
+	}
+}
+
+func TestRequestIDFromContext(t *testing.T) {
+	buf := capture(t, config.LogConfig{Level: "info", Format: FormatJSON})
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	For("http").With("k", "v").InfoContext(ctx, "hello")
+
+	got := lines(t, buf)
+	if len(got) != 1 || got[0]["request_id"] != "req-1" || got[0]["k"] != "v" {
+		t.Fatalf("unexpected records: %v", got)
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	buf := capture(t, config.LogConfig{Level: "info", Format: FormatJSON})
+
+	For("auth").Info("login failed for jane.doe@example.com",
+		"Authorization", "Bearer abc.def.ghi",
+		"password", "hunter22",
+		"email", "jane.doe@example.com",
+		"error", errors.New(`duplicate key: jane.doe@example.com`),
+	)
+
+	out := buf.String()
+	for _, leak := range []string{"abc.def.ghi", "hunter22", "jane.doe@"} {
+		if strings.Contains(out, leak) {
+			t.Errorf("log leaks %q: %s", leak, out)
+		}
+	}
+	got := lines(t, buf)[0]
+	if got["msg"] != "login failed for j***@example.com" || got["email"] != "j***@example.com" {
+		t.Errorf("emails not masked: %v", got)
+	}
+	if got["Authorization"] != redacted || got["password"] != redacted {
+		t.Errorf("secrets not redacted: %v", got)
+	}
+}
@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// Attribute keys whose values are never written, compared case-insensitively
+var secretKeys = map[string]bool{
+	"authorization": true,
+	"cookie":        true,
+	"set-cookie":    true,
+	"password":      true,
+	"password_hash": true,
+	"token":         true,
+	"secret":        true,
+	"jwt_secret":    true,
+}
+
+var emailPattern = regexp.MustCompile(`([A-Za-z0-9._%+\-])[A-Za-z0-9._%+\-]*@([A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+
+// MaskEmails keeps the first character and the domain of every address,
+// so "jane@example.com" becomes "j***@example.com"
+func MaskEmails(s string) string {
+	return emailPattern.ReplaceAllString(s, "$1***@$2")
+}
+
+// redact is the ReplaceAttr hook of the base handler. It also sees the
+// message, so emails in log messages are masked too.
+func redact(_ []string, a slog.Attr) slog.Attr {
+	if secretKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, MaskEmails(a.Value.String()))
+	case slog.KindAny:
+		// Errors often quote the offending row, emails included
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, MaskEmails(err.Error()))
+		}
+	}
+	return a
+}
@@ -0,0 +1,143 @@
+// Package media checks and rewrites uploaded files before they are stored.
+//
+// Types are sniffed from the bytes, never taken from the client's
+// Content-Type or file name. Images are decoded and re-encoded as JPEG,
+// which drops EXIF data (camera GPS positions included) and anything
+// smuggled in after the image data.
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"net/http"
+	"strings"
+
+	// Decoders for image.Decode
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	JPEG = "image/jpeg"
+	PNG  = "image/png"
+	GIF  = "image/gif"
+	WebP = "image/webp"
+	PDF  = "application/pdf"
+)
+
+// MaxPixels bounds decoded image size. A few kilobytes of PNG can claim
+// to be 50000x50000 pixels, which would take gigabytes to decode.
+const MaxPixels = 40_000_000
+
+var (
+	ErrUnsupported   = errors.New("unsupported file type")
+	ErrTooManyPixels = errors.New("image dimensions are too large")
+)
+
+// jpegQuality balances size against artefacts for photos of people and
+// documents
+const jpegQuality = 85
+
+// DetectType sniffs the content type from the first bytes of data
+func DetectType(data []byte) string {
+	ct := http.DetectContentType(data)
+	ct, _, _ = strings.Cut(ct, ";")
+	return ct
+}
+
+// IsImage reports whether contentType is an image Reencode can read
+func IsImage(contentType string) bool {
+	switch contentType {
+	case JPEG, PNG, GIF, WebP:
+		return true
+	}
+	return false
+}
+
+// Extension returns the file extension stored files of contentType get
+func Extension(contentType string) string {
+	switch contentType {
+	case JPEG:
+		return ".jpg"
+	case PDF:
+		return ".pdf"
+	}
+	return ""
+}
+
+func decode(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	return img, nil
+}
+
+// Reencode decodes an image and writes it back as JPEG, scaled down so
+// neither side exceeds maxSide. Animated GIFs keep their first frame.
+func Reencode(data []byte, maxSide int) ([]byte, error) {
+	img, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	b := img.Bounds()
+	w, h := fit(b.Dx(), b.Dy(), maxSide)
+	return encode(scale(img, b, w, h))
+}
+
+// Thumbnail crops the centre square of an image and scales it to side
+// pixels, for avatars in lists
+func Thumbnail(data []byte, side int) ([]byte, error) {
+	img, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	b := img.Bounds()
+	n := min(b.Dx(), b.Dy())
+	x, y := b.Min.X+(b.Dx()-n)/2, b.Min.Y+(b.Dy()-n)/2
+	crop := image.Rect(x, y, x+n, y+n)
+	side = min(side, n)
+	return encode(scale(img, crop, side, side))
+}
+
+// fit scales w x h down, keeping its aspect ratio, until both sides are at
+// most maxSide
+func fit(w, h, maxSide int) (int, int) {
+	if w <= maxSide && h <= maxSide {
+		return w, h
+	}
+	if w >= h {
+		return maxSide, max(1, h*maxSide/w)
+	}
+	return max(1, w*maxSide/h), maxSide
+}
+
+// scale draws src's rect onto a w x h white canvas; white because JPEG
+// has no alpha and transparent PNGs would otherwise turn black
+func scale(src image.Image, rect image.Rectangle, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, rect, draw.Over, nil)
+	return dst
+}
+
+func encode(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
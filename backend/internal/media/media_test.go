@@ -0,0 +1,117 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func pngBytes(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDetectType(t *testing.T) {
+	tests := map[string]string{
+		"%PDF-1.7\n":       PDF,
+		"hello":            "text/plain",
+		"\xff\xd8\xff\xe0": JPEG,
+	}
+	for data, want := range tests {
+		if got := DetectType([]byte(data)); got != want {
+			t.Errorf("DetectType(%q) = %q, want %q", data, got, want)
+		}
+	}
+	if got := DetectType(pngBytes(t, image.NewGray(image.Rect(0, 0, 1, 1)))); got != PNG {
+		t.Errorf("png detected as %q", got)
+	}
+}
+
+func TestReencode(t *testing.T) {
+	// Fully transparent, so it must come out white rather than black
+	src := pngBytes(t, image.NewNRGBA(image.Rect(0, 0, 300, 1200)))
+
+	out, err := Reencode(src, 400)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("output is not JPEG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 100 || b.Dy() != 400 {
+		t.Errorf("size = %v, want 100x400", b)
+	}
+	if r, g, b, _ := img.At(50, 200).RGBA(); r < 0xf000 || g < 0xf000 || b < 0xf000 {
+		t.Errorf("transparent pixel became %v", img.At(50, 200))
+	}
+
+	// Small images keep their size
+	out, err = Reencode(pngBytes(t, image.NewGray(image.Rect(0, 0, 20, 10))), 400)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg, _ := jpeg.DecodeConfig(bytes.NewReader(out)); cfg.Width != 20 || cfg.Height != 10 {
+		t.Errorf("small image resized to %dx%d", cfg.Width, cfg.Height)
+	}
+}
+
+func TestThumbnailCropsCentre(t *testing.T) {
+	// Red left and right thirds, blue centre square
+	img := image.NewRGBA(image.Rect(0, 0, 300, 100))
+	for x := 0; x < 300; x++ {
+		for y := 0; y < 100; y++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 100 && x < 200 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	out, err := Thumbnail(pngBytes(t, img), 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thumb, err := jpeg.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := thumb.Bounds(); b.Dx() != 50 || b.Dy() != 50 {
+		t.Errorf("size = %v, want 50x50", b)
+	}
+	if r, _, b, _ := thumb.At(25, 25).RGBA(); r > 0x2000 || b < 0xd000 {
+		t.Errorf("centre = %v, want blue", thumb.At(25, 25))
+	}
+}
+
+// A PNG header claiming an enormous image must be refused before decoding
+func TestPixelLimit(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], 50000)
+	binary.BigEndian.PutUint32(ihdr[4:], 50000)
+	ihdr[8], ihdr[9] = 8, 2 // 8-bit RGB
+	chunk := append([]byte("IHDR"), ihdr...)
+	binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)))
+	buf.Write(chunk)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+
+	if _, err := Reencode(buf.Bytes(), 512); !errors.Is(err, ErrTooManyPixels) {
+		t.Errorf("got %v, want ErrTooManyPixels", err)
+	}
+	if _, err := Reencode([]byte("not an image"), 512); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("got %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector reads pgxpool.Stat() on every scrape
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	totalConns        *prometheus.Desc
+	idleConns         *prometheus.Desc
+	acquiredConns     *prometheus.Desc
+	constructingConns *prometheus.Desc
+	maxConns          *prometheus.Desc
+	acquireCount      *prometheus.Desc
+	emptyAcquireCount *prometheus.Desc
+	canceledAcquires  *prometheus.Desc
+	acquireDuration   *prometheus.Desc
+}
+
+// RegisterPool exports the pool's statistics as db_pool_* metrics
+func RegisterPool(pool *pgxpool.Pool) error {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("db_pool_"+name, help, nil, nil)
+	}
+	return Registry.Register(&poolCollector{
+		pool:              pool,
+		totalConns:        desc("total_conns", "Connections currently in the pool."),
+		idleConns:         desc("idle_conns", "Idle connections."),
+		acquiredConns:     desc("acquired_conns", "Connections checked out by queries."),
+		constructingConns: desc("constructing_conns", "Connections being opened."),
+		maxConns:          desc("max_conns", "Configured maximum pool size."),
+		acquireCount:      desc("acquires_total", "Successful connection acquires."),
+		emptyAcquireCount: desc("empty_acquires_total", "Acquires that had to wait because the pool was empty."),
+		canceledAcquires:  desc("canceled_acquires_total", "Acquires canceled by their context."),
+		acquireDuration:   desc("acquire_duration_seconds_total", "Total time spent waiting for connections."),
+	})
+}
+
+func (p *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(p, ch)
+}
+
+func (p *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := p.pool.Stat()
+	gauge := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v)
+	}
+	counter := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v)
+	}
+
+	gauge(p.totalConns, float64(s.TotalConns()))
+	gauge(p.idleConns, float64(s.IdleConns()))
+	gauge(p.acquiredConns, float64(s.AcquiredConns()))
+	gauge(p.constructingConns, float64(s.ConstructingConns()))
+	gauge(p.maxConns, float64(s.MaxConns()))
+	counter(p.acquireCount, float64(s.AcquireCount()))
+	counter(p.emptyAcquireCount, float64(s.EmptyAcquireCount()))
+	counter(p.canceledAcquires, float64(s.CanceledAcquireCount()))
+	counter(p.acquireDuration, s.AcquireDuration().Seconds())
+}
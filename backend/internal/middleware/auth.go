@@ -1,20 +1,22 @@
 package middleware
 
 import (
-    "net/http"
     "strings"
 
     "github.com/gin-gonic/gin"
+    "github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+    "github.com/Sabari-Vijayan/DBMS-project/internal/audit"
     "github.com/Sabari-Vijayan/DBMS-project/internal/auth"
+    "github.com/Sabari-Vijayan/DBMS-project/internal/models"
 )
 
 // JWT Authentication Middleware
-func AuthRequired() gin.HandlerFunc {
+func AuthRequired(tokens *auth.Manager) gin.HandlerFunc {
     return func(c *gin.Context) {
         // Get token from Authorization header
         authHeader := c.GetHeader("Authorization")
         if authHeader == "" {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+            c.Error(apierror.Unauthorized("Authorization header required"))
             c.Abort()
             return
         }
@@ -22,7 +24,7 @@ func AuthRequired() gin.HandlerFunc {
         // Extract token (format: "Bearer <token>")
         parts := strings.Split(authHeader, " ")
         if len(parts) != 2 || parts[0] != "Bearer" {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+            c.Error(apierror.Unauthorized("Invalid authorization header format"))
             c.Abort()
             return
         }
@@ -30,9 +32,9 @@ func AuthRequired() gin.HandlerFunc {
         tokenString := parts[1]
 
         // Verify token
-        claims, err := auth.VerifyToken(tokenString)
+        claims, err := tokens.VerifyToken(tokenString)
         if err != nil {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+            c.Error(apierror.Unauthorized("Invalid or expired token"))
             c.Abort()
             return
         }
@@ -41,17 +43,27 @@ func AuthRequired() gin.HandlerFunc {
         c.Set("user_id", claims.UserID)
         c.Set("email", claims.Email)
         c.Set("user_type", claims.UserType)
+        c.Set("claims", claims)
+        c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), claims.UserID))
 
         c.Next()
     }
 }
 
-// Middleware to check if user is an employer
-func EmployerOnly() gin.HandlerFunc {
+// HasRole reports whether the authenticated user has role, active or not
+func HasRole(c *gin.Context, role models.Role) bool {
+    value, _ := c.Get("claims")
+    claims, ok := value.(*auth.Claims)
+    return ok && claims.HasRole(role)
+}
+
+// Middleware to check that the user has the given role. Users with several
+// roles pass with any of them, whichever is active; the active role only
+// picks what the app shows first.
+func RequireRole(role models.Role, message string) gin.HandlerFunc {
     return func(c *gin.Context) {
-        userType, exists := c.Get("user_type")
-        if !exists || userType != "employer" {
-            c.JSON(http.StatusForbidden, gin.H{"error": "Only employers can access this resource"})
+        if !HasRole(c, role) {
+            c.Error(apierror.Forbidden(message))
             c.Abort()
             return
         }
@@ -61,13 +73,10 @@ func EmployerOnly() gin.HandlerFunc {
 
 // Middleware to check if user is a worker
 func WorkerOnly() gin.HandlerFunc {
-    return func(c *gin.Context) {
-        userType, exists := c.Get("user_type")
-        if !exists || userType != "worker" {
-            c.JSON(http.StatusForbidden, gin.H{"error": "Only workers can access this resource"})
-            c.Abort()
-            return
-        }
-        c.Next()
-    }
+    return RequireRole(models.RoleWorker, "Only workers can access this resource")
+}
+
+// Middleware to check if user is an admin
+func AdminOnly() gin.HandlerFunc {
+    return RequireRole(models.RoleAdmin, "Only admins can access this resource")
 }
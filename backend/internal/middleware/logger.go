@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/logging"
+	"github.com/gin-gonic/gin"
+)
+
+var httpLog = logging.For("http")
+
+// Probes and scrapes run every few seconds; keep them out of info logs
+var quietPaths = map[string]bool{
+	"/health":  true,
+	"/livez":   true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// Logger writes one access log line per request. Headers, bodies and the
+// query string are left out because they can carry tokens and emails.
+func Logger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		case quietPaths[c.FullPath()]:
+			level = slog.LevelDebug
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("duration", time.Since(start)),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if userID, ok := c.Get("user_id"); ok {
+			attrs = append(attrs, slog.Any("user_id", userID))
+		}
+		httpLog.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Recovery turns a panic into a logged 500 rendered by apierror.Middleware,
+// so the client gets the usual error body with its request ID
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				httpLog.ErrorContext(c.Request.Context(), "panic",
+					"panic", fmt.Sprint(r),
+					"stack", string(debug.Stack()),
+				)
+				c.Error(apierror.Internal("Internal server error", fmt.Errorf("panic: %v", r)))
+				c.Abort()
+			}
+		}()
+		c.Next()
+	}
+}
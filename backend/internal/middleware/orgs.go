@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// Memberships looks up what a user may do in an organization
+type Memberships interface {
+	// Role fails with store.ErrNotFound when the user isn't a member
+	Role(ctx context.Context, orgID, userID int) (models.OrgRole, error)
+}
+
+// OrgResolver finds the organization a request acts on, e.g. the one that
+// owns the job in the path. Errors should be *apierror.Error, such as a
+// 404 for a missing job.
+type OrgResolver func(c *gin.Context) (int, error)
+
+// EmployerOnly lets employers through whose role in the organization that
+// resolve finds is at least min. The organization and the caller's role
+// in it are stored as "org_id" and "org_role".
+func EmployerOnly(members Memberships, resolve OrgResolver, min models.OrgRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasRole(c, models.RoleEmployer) {
+			c.Error(apierror.Forbidden("Only employers can access this resource"))
+			c.Abort()
+			return
+		}
+
+		orgID, err := resolve(c)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		role, err := members.Role(c.Request.Context(), orgID, c.GetInt("user_id"))
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			c.Error(apierror.Forbidden("You are not a member of this organization"))
+			c.Abort()
+			return
+		case err != nil:
+			c.Error(apierror.Internal("Failed to check organization membership", err))
+			c.Abort()
+			return
+		case !role.AtLeast(min):
+			c.Error(apierror.Forbidden(fmt.Sprintf("This needs the %s role in the organization; you are a %s", min, role)))
+			c.Abort()
+			return
+		}
+
+		c.Set("org_id", orgID)
+		c.Set("org_role", role)
+		c.Next()
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/logging"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/metrics"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+var rateLog = logging.For("ratelimit")
+
+// RateLimit gives each client its own bucket in the named group. Clients
+// are the authenticated user when AuthRequired ran first, otherwise the
+// IP. Every response gets RateLimit-Limit/Remaining/Reset headers and a
+// refusal also gets Retry-After.
+//
+// If the store fails the request is let through: an outage of the limiter
+// should not become an outage of the API.
+func RateLimit(store ratelimit.Store, group string, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := group + ":ip:" + c.ClientIP()
+		if userID, ok := c.Get("user_id"); ok {
+			key = fmt.Sprintf("%s:user:%v", group, userID)
+		}
+
+		res, err := store.Take(c.Request.Context(), key, limit, time.Now())
+		if err != nil {
+			rateLog.WarnContext(c.Request.Context(), "rate limit store failed, allowing request", "group", group, "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", ceilSeconds(res.Reset))
+		if !res.Allowed {
+			metrics.RateLimited.WithLabelValues(group).Inc()
+			c.Header("Retry-After", ceilSeconds(res.RetryAfter))
+			c.Error(apierror.TooManyRequests(apierror.CodeRateLimited, "Too many requests, please slow down"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ceilSeconds rounds up so clients never retry a moment too early
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/audit"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/logging"
+	"github.com/gin-gonic/gin"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// Incoming IDs are reused only if they are short and harmless to log
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._\-]{1,128}$`)
+
+// RequestID reuses the caller's X-Request-ID (e.g. from a proxy) or makes
+// one up, echoes it in the response and stores it in the request context
+// for logs, error bodies and audit events
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+
+		c.Header(RequestIDHeader, id)
+		c.Set("request_id", id)
+
+		// Stores stamp audit events with these; AuthRequired adds the actor
+		ctx := logging.WithRequestID(c.Request.Context(), id)
+		ctx = audit.WithRequest(ctx, audit.Request{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			RequestID: id,
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+type Application struct {
+	ID          int       `json:"id" db:"id"`
+	JobID       int       `json:"job_id" db:"job_id"`
+	WorkerID    int       `json:"worker_id" db:"worker_id"`
+	CoverLetter *string   `json:"cover_letter" db:"cover_letter"`
+	Status      string    `json:"status" db:"status"`
+	AppliedAt   time.Time `json:"applied_at" db:"applied_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	// The employer who invited the worker; nil when the worker applied
+	InvitedBy *int `json:"invited_by" db:"invited_by"`
+}
+
+// Application statuses. Invited applications come from the employer and
+// wait for the worker, who accepts (making it pending) or declines. An
+// accepted application whose booking is cancelled or missed is withdrawn
+// if the worker cancelled and rejected otherwise.
+const (
+	ApplicationInvited   = "invited"
+	ApplicationDeclined  = "declined"
+	ApplicationPending   = "pending"
+	ApplicationAccepted  = "accepted"
+	ApplicationRejected  = "rejected"
+	ApplicationWithdrawn = "withdrawn"
+)
+
+// Application as seen by the worker who sent it
+type WorkerApplication struct {
+	Application
+	JobTitle     string  `json:"job_title"`
+	Location     string  `json:"location"`
+	PayType      PayType `json:"pay_type"`
+	Currency     string  `json:"currency"`
+	PayMin       *int64  `json:"pay_min"`
+	PayMax       *int64  `json:"pay_max"`
+	EmployerName string  `json:"employer_name"`
+	// The job's category and hourly rate, which recommendations learn from
+	CategoryID *int   `json:"category_id"`
+	HourlyRate *int64 `json:"hourly_rate"`
+}
+
+// Application as seen by the employer who posted the job
+type JobApplicant struct {
+	Application
+	WorkerName     string  `json:"worker_name"`
+	WorkerEmail    string  `json:"worker_email"`
+	WorkerPhone    *string `json:"worker_phone"`
+	WorkerLocation *string `json:"worker_location"`
+	// Whether the worker is free for the whole job; nil when the job has
+	// no schedule
+	Available *bool `json:"available"`
+	// The worker's track record, for ranking applicants
+	WorkerSkills  []WorkerSkill `json:"worker_skills"`
+	WorkerRating  *float64      `json:"worker_rating"`
+	RatingCount   int           `json:"rating_count"`
+	CompletedJobs int           `json:"completed_jobs"`
+	// Bookings the worker cancelled or didn't turn up for
+	DroppedJobs int `json:"dropped_jobs"`
+	// Kept private: distance is shown, not where the worker lives
+	WorkerLatitude  *float64 `json:"-"`
+	WorkerLongitude *float64 `json:"-"`
+	// The employer's own shortlist, tags and notes
+	Review ApplicationReview `json:"review"`
+	// Answers to the job's screening questions, in question order
+	Answers []Answer `json:"answers"`
+}
+
+// ApplicationReview is what the job's organization noted about an
+// applicant; the worker never sees it
+type ApplicationReview struct {
+	Shortlisted bool     `json:"shortlisted"`
+	Tags        []string `json:"tags"`
+	Notes       *string  `json:"notes"`
+	// Who last changed it and when; nil before anyone has
+	UpdatedBy *int       `json:"updated_by"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// AvailabilitySlot is a weekly window a worker is free in, in their time
+// zone. Times are "HH:MM"; EndTime may be "24:00".
+type AvailabilitySlot struct {
+	ID        int    `json:"id"`
+	WorkerID  int    `json:"-"`
+	Weekday   int    `json:"weekday"` // 0 is Sunday
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// AvailabilityException adds time to a worker's weekly slots or, when
+// Available is false, blocks it out
+type AvailabilityException struct {
+	ID        int       `json:"id"`
+	WorkerID  int       `json:"-"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Available bool      `json:"available"`
+	Note      *string   `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Availability is a worker's calendar
+type Availability struct {
+	WorkerID   int                     `json:"worker_id"`
+	Timezone   string                  `json:"timezone"`
+	Weekly     []AvailabilitySlot      `json:"weekly"`
+	Exceptions []AvailabilityException `json:"exceptions"`
+	// Active bookings overlapping the period asked about; not part of the
+	// calendar the worker edits
+	Booked []BookedTime `json:"-"`
+}
+
+// BookedTime is when a worker is booked to work on a job
+type BookedTime struct {
+	JobID    int
+	StartsAt time.Time
+	EndsAt   time.Time
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Booking is the engagement between a worker and an employer that starts
+// when the employer accepts the worker's application
+type Booking struct {
+	ID                   int        `json:"id"`
+	ApplicationID        int        `json:"application_id"`
+	JobID                int        `json:"job_id"`
+	WorkerID             int        `json:"worker_id"`
+	EmployerID           int        `json:"employer_id"`
+	Status               string     `json:"status"`
+	WorkerCheckedInAt    *time.Time `json:"worker_checked_in_at"`
+	WorkerCheckedOutAt   *time.Time `json:"worker_checked_out_at"`
+	EmployerCheckedInAt  *time.Time `json:"employer_checked_in_at"`
+	EmployerCheckedOutAt *time.Time `json:"employer_checked_out_at"`
+	WorkerConfirmedAt    *time.Time `json:"worker_confirmed_at"`
+	EmployerConfirmedAt  *time.Time `json:"employer_confirmed_at"`
+	CompletedAt          *time.Time `json:"completed_at"`
+	CancelledBy          *int       `json:"cancelled_by"`
+	CancelReason         *string    `json:"cancel_reason"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+
+	// The employer's rating of the worker, 1 to 5, once it completes
+	WorkerRating  *int       `json:"worker_rating"`
+	WorkerReview  *string    `json:"worker_review"`
+	WorkerRatedAt *time.Time `json:"worker_rated_at"`
+
+	// From the job, read-only
+	OrganizationID int        `json:"organization_id"`
+	JobTitle       string     `json:"job_title"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+}
+
+// WorkExperience is a gig on a worker's record, either added by hand or
+// written when a booking completes
+type WorkExperience struct {
+	ID           int        `json:"id"`
+	WorkerID     int        `json:"worker_id"`
+	BookingID    *int       `json:"booking_id"`
+	JobTitle     string     `json:"job_title"`
+	EmployerName *string    `json:"employer_name"`
+	Description  *string    `json:"description"`
+	Duration     *string    `json:"duration"`
+	StartDate    *time.Time `json:"start_date"`
+	EndDate      *time.Time `json:"end_date"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Kinds of document a user can upload
+const (
+	DocumentIDProof     = "id_proof"
+	DocumentCertificate = "certificate"
+	DocumentOther       = "other"
+)
+
+// Document is a file a user uploaded to back up their profile, such as an
+// ID proof or a trade certificate
+type Document struct {
+	ID          int    `json:"id"`
+	UserID      int    `json:"user_id"`
+	Kind        string `json:"kind"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+	BlobKey     string `json:"-"`
+	// Signed and short-lived; filled in by the handler
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Invoice records what a worker was paid for one completed booking.
+// Amount is in minor units, e.g. paise.
+type Invoice struct {
+	ID           int       `json:"id"`
+	Number       string    `json:"number"`
+	BookingID    *int      `json:"booking_id"`
+	WorkerID     *int      `json:"worker_id"`
+	EmployerID   *int      `json:"employer_id"`
+	WorkerName   string    `json:"worker_name"`
+	EmployerName string    `json:"employer_name"`
+	JobTitle     string    `json:"job_title"`
+	Amount       int64     `json:"amount"`
+	Currency     string    `json:"currency"`
+	IssuedAt     time.Time `json:"issued_at"`
+}
+
+// Statement lists a user's invoices for one month: earnings for workers,
+// payments for employers
+type Statement struct {
+	// YYYY-MM, in UTC
+	Month    string    `json:"month"`
+	Role     Role      `json:"role"`
+	Name     string    `json:"name"`
+	Invoices []Invoice `json:"invoices"`
+	Totals   []Balance `json:"totals"`
+}
@@ -3,27 +3,62 @@ package models
 import "time"
 
 type Job struct {
-    ID          int       `json:"id" db:"id"`
-    EmployerID  int       `json:"employer_id" db:"employer_id"`
-    Title       string    `json:"title" db:"title"`
-    Description string    `json:"description" db:"description"`
-    CategoryID  *int      `json:"category_id" db:"category_id"`
-    Location    string    `json:"location" db:"location"`
-    SalaryMin   *float64  `json:"salary_min" db:"salary_min"`
-    SalaryMax   *float64  `json:"salary_max" db:"salary_max"`
-    Duration    *string   `json:"duration" db:"duration"`
-    Requirements *string  `json:"requirements" db:"requirements"`
-    ContactPhone *string  `json:"contact_phone" db:"contact_phone"`
-    ContactEmail *string  `json:"contact_email" db:"contact_email"`
-    ExpiresAt   time.Time `json:"expires_at" db:"expires_at"`
-    Status      string    `json:"status" db:"status"`
-    IsActive    bool      `json:"is_active" db:"is_active"`
-    CreatedAt   time.Time `json:"created_at" db:"created_at"`
-    UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID           int        `json:"id" db:"id"`
+	EmployerID   int        `json:"employer_id" db:"employer_id"` // who posted it
+	Title        string     `json:"title" db:"title"`
+	Description  string     `json:"description" db:"description"`
+	CategoryID   *int       `json:"category_id" db:"category_id"`
+	Location     string     `json:"location" db:"location"`
+	PayType      PayType    `json:"pay_type" db:"pay_type"`
+	Currency     string     `json:"currency" db:"currency"` // ISO 4217
+	PayMin       *int64     `json:"pay_min" db:"pay_min"`   // minor units, e.g. paise
+	PayMax       *int64     `json:"pay_max" db:"pay_max"`
+	HourlyRate   *int64     `json:"hourly_rate" db:"hourly_rate"` // see pay.HourlyRate
+	Duration     *string    `json:"duration" db:"duration"`
+	Requirements *string    `json:"requirements" db:"requirements"`
+	ContactPhone *string    `json:"contact_phone" db:"contact_phone"`
+	ContactEmail *string    `json:"contact_email" db:"contact_email"`
+	ExpiresAt    time.Time  `json:"expires_at" db:"expires_at"`
+	StartsAt     *time.Time `json:"starts_at" db:"starts_at"` // nil for jobs without a schedule
+	EndsAt       *time.Time `json:"ends_at" db:"ends_at"`
+	Status       string     `json:"status" db:"status"`
+	IsActive     bool       `json:"is_active" db:"is_active"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+
+	// The organization the job belongs to; its members manage it
+	OrganizationID int `json:"organization_id" db:"organization_id"`
+
+	// Where the work is, for distances to workers; both or neither are set
+	Latitude  *float64 `json:"latitude" db:"latitude"`
+	Longitude *float64 `json:"longitude" db:"longitude"`
 }
 
 type JobWithDetails struct {
-    Job
-    EmployerName string  `json:"employer_name"`
-    CategoryName *string `json:"category_name"`
+	Job
+	EmployerName     string  `json:"employer_name"`
+	OrganizationName string  `json:"organization_name"`
+	CategoryName     *string `json:"category_name"`
+}
+
+// JobAttachment is a photo or PDF an employer attached to a job
+type JobAttachment struct {
+	ID          int    `json:"id"`
+	JobID       int    `json:"job_id"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+	BlobKey     string `json:"-"`
+	// Signed and short-lived; filled in by the handler
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// JobDetail is a job with its attachments and screening questions, as
+// GetJob returns it
+type JobDetail struct {
+	JobWithDetails
+	Attachments []JobAttachment `json:"attachments"`
+	// Without their knockouts
+	Questions []Question `json:"questions"`
 }
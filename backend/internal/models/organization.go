@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// OrgRole is what a member may do in an organization
+type OrgRole string
+
+const (
+	// Sees the organization's jobs and their applicants
+	OrgViewer OrgRole = "viewer"
+	// Also posts and edits jobs, hires and handles bookings and payments
+	OrgManager OrgRole = "manager"
+	// Also edits the organization and manages its members and invitations
+	OrgOwner OrgRole = "owner"
+)
+
+// OrgRoles lists every valid role, from least to most able
+var OrgRoles = []OrgRole{OrgViewer, OrgManager, OrgOwner}
+
+// Valid reports whether r is one of OrgRoles
+func (r OrgRole) Valid() bool {
+	return r.rank() >= 0
+}
+
+// AtLeast reports whether r may do everything min may
+func (r OrgRole) AtLeast(min OrgRole) bool {
+	return r.Valid() && r.rank() >= min.rank()
+}
+
+func (r OrgRole) rank() int {
+	for i, role := range OrgRoles {
+		if r == role {
+			return i
+		}
+	}
+	return -1
+}
+
+// Organization is an employer's company profile. Its jobs belong to it
+// rather than to whoever posted them.
+type Organization struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	Website     *string   `json:"website"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Membership is an organization as one of its members sees it
+type Membership struct {
+	Organization
+	Role OrgRole `json:"role"`
+}
+
+// Member is a user on an organization's team
+type Member struct {
+	OrganizationID int       `json:"organization_id"`
+	UserID         int       `json:"user_id"`
+	FullName       string    `json:"full_name"`
+	Email          string    `json:"email"`
+	Role           OrgRole   `json:"role"`
+	JoinedAt       time.Time `json:"joined_at"`
+}
+
+// OrganizationDetail is an organization with its team, as its members see
+// it
+type OrganizationDetail struct {
+	Membership
+	Members []Member `json:"members"`
+}
+
+// Invitation asks whoever has the email address to join an organization
+type Invitation struct {
+	ID             int        `json:"id"`
+	OrganizationID int        `json:"organization_id"`
+	Email          string     `json:"email"`
+	Role           OrgRole    `json:"role"`
+	InvitedBy      *int       `json:"invited_by"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	// Only returned when the invitation is created
+	Token string `json:"token,omitempty"`
+}
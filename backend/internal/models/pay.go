@@ -0,0 +1,28 @@
+package models
+
+import "regexp"
+
+// PayType says what a job's pay is for: an hour, a day or the whole job
+type PayType string
+
+const (
+	PayHourly PayType = "hourly"
+	PayDaily  PayType = "daily"
+	PayFixed  PayType = "fixed"
+)
+
+// PayTypes lists every valid pay type
+var PayTypes = []PayType{PayHourly, PayDaily, PayFixed}
+
+// Valid reports whether p is one of PayTypes
+func (p PayType) Valid() bool {
+	for _, t := range PayTypes {
+		if p == t {
+			return true
+		}
+	}
+	return false
+}
+
+// currencyCode matches ISO 4217 codes such as INR
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Payment is a charge made through the payment provider to fund a
+// booking's escrow. Amount is in minor units, e.g. paise.
+type Payment struct {
+	ID            int       `json:"id"`
+	BookingID     *int      `json:"booking_id"`
+	Provider      string    `json:"provider"`
+	ProviderRef   *string   `json:"provider_ref"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	Status        string    `json:"status"` // pending, succeeded, failed or refunded
+	FailureReason *string   `json:"failure_reason"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Balance is an amount of one currency, in minor units, summed from ledger
+// entries
+type Balance struct {
+	Currency string `json:"currency"`
+	Amount   int64  `json:"amount"`
+}
+
+// Escrow is what is held for a booking and the payments behind it
+type Escrow struct {
+	BookingID int       `json:"booking_id"`
+	Held      []Balance `json:"held"`
+	Payments  []Payment `json:"payments"`
+}
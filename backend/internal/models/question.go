@@ -0,0 +1,66 @@
+package models
+
+// QuestionKind says what answer a screening question takes
+type QuestionKind string
+
+const (
+	QuestionYesNo  QuestionKind = "yes_no"
+	QuestionNumber QuestionKind = "number"
+	QuestionText   QuestionKind = "text"
+	// One of the question's choices
+	QuestionChoice QuestionKind = "choice"
+)
+
+// QuestionKinds lists every valid question kind
+var QuestionKinds = []QuestionKind{QuestionYesNo, QuestionNumber, QuestionText, QuestionChoice}
+
+// Valid reports whether k is one of QuestionKinds
+func (k QuestionKind) Valid() bool {
+	for _, kind := range QuestionKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Question is a screening question on a job posting
+type Question struct {
+	ID       int          `json:"id"`
+	JobID    int          `json:"job_id"`
+	Position int          `json:"position"`
+	Prompt   string       `json:"prompt"`
+	Kind     QuestionKind `json:"kind"`
+	Required bool         `json:"required"`
+	// The options of a choice question; empty for other kinds
+	Choices []string `json:"choices"`
+
+	// Knockouts, which reject the application automatically: the yes/no
+	// answer that rejects, the range a number must fall in and the
+	// choices that reject. Only the job's organization sees them.
+	RejectAnswer  *bool    `json:"reject_answer,omitempty"`
+	MinValue      *float64 `json:"min_value,omitempty"`
+	MaxValue      *float64 `json:"max_value,omitempty"`
+	RejectChoices []string `json:"reject_choices,omitempty"`
+}
+
+// HasKnockout reports whether some answer to q rejects the application
+func (q Question) HasKnockout() bool {
+	return q.RejectAnswer != nil || q.MinValue != nil || q.MaxValue != nil || len(q.RejectChoices) > 0
+}
+
+// Public is q as applicants see it, without its knockouts
+func (q Question) Public() Question {
+	q.RejectAnswer, q.MinValue, q.MaxValue, q.RejectChoices = nil, nil, nil, nil
+	return q
+}
+
+// Answer is an applicant's answer to a screening question
+type Answer struct {
+	QuestionID int    `json:"question_id"`
+	Prompt     string `json:"prompt"`
+	// "yes" or "no", a number, free text or one of the choices
+	Answer string `json:"answer"`
+	// Whether the answer hit one of the question's knockouts
+	KnockedOut bool `json:"knocked_out"`
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/schedule"
+	"github.com/go-playground/validator/v10"
+)
+
+// Role is the kind of account a user has. It is the single source of truth
+// for user types: the auth middleware, request validation and the rows of
+// the roles table (which users.user_type references) all come from Roles.
+type Role string
+
+const (
+	RoleWorker   Role = "worker"
+	RoleEmployer Role = "employer"
+	// Admins are promoted by hand in the database, never signed up
+	RoleAdmin Role = "admin"
+)
+
+// Roles lists every valid role
+var Roles = []Role{RoleWorker, RoleEmployer, RoleAdmin}
+
+// SignupRoles are the roles anyone may register with
+var SignupRoles = []Role{RoleWorker, RoleEmployer}
+
+// Valid reports whether r is one of Roles
+func (r Role) Valid() bool {
+	for _, role := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// CanSignUp reports whether r is one of SignupRoles
+func (r Role) CanSignUp() bool {
+	for _, role := range SignupRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterValidators adds the "role", "signup_role", "org_role" and
+// "pay_type" tags so request structs can use `binding:"required,role"`
+// instead of repeating the list with oneof, and "currency" for ISO 4217
+// codes.
+// It also makes validation errors report JSON field names.
+func RegisterValidators(v *validator.Validate) error {
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+
+	if err := v.RegisterValidation("role", func(fl validator.FieldLevel) bool {
+		return Role(fl.Field().String()).Valid()
+	}); err != nil {
+		return err
+	}
+	if err := v.RegisterValidation("signup_role", func(fl validator.FieldLevel) bool {
+		return Role(fl.Field().String()).CanSignUp()
+	}); err != nil {
+		return err
+	}
+	if err := v.RegisterValidation("org_role", func(fl validator.FieldLevel) bool {
+		return OrgRole(fl.Field().String()).Valid()
+	}); err != nil {
+		return err
+	}
+	if err := v.RegisterValidation("pay_type", func(fl validator.FieldLevel) bool {
+		return PayType(fl.Field().String()).Valid()
+	}); err != nil {
+		return err
+	}
+	if err := v.RegisterValidation("currency", func(fl validator.FieldLevel) bool {
+		return currencyCode.MatchString(fl.Field().String())
+	}); err != nil {
+		return err
+	}
+	if err := v.RegisterValidation("question_kind", func(fl validator.FieldLevel) bool {
+		return QuestionKind(fl.Field().String()).Valid()
+	}); err != nil {
+		return err
+	}
+	// "HH:MM" time of day, up to 24:00
+	return v.RegisterValidation("clock", func(fl validator.FieldLevel) bool {
+		_, err := schedule.ParseClock(fl.Field().String())
+		return err == nil
+	})
+}
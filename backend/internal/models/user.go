@@ -1,16 +1,95 @@
 package models
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 type User struct {
-    ID           int       `json:"id" db:"id"`
-    Email        string    `json:"email" db:"email"`
-    PasswordHash string    `json:"-" db:"password_hash"` // "-" means don't show in JSON
-    FullName     string    `json:"full_name" db:"full_name"`
-    UserType     string    `json:"user_type" db:"user_type"`
-    Phone        string    `json:"phone" db:"phone"`
-    Location     string    `json:"location" db:"location"`
-    Bio          string    `json:"bio" db:"bio"`
-    AvatarURL    string    `json:"avatar_url" db:"avatar_url"`
-    CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	ID           int       `json:"id" db:"id"`
+	Email        string    `json:"email" db:"email"`
+	PasswordHash string    `json:"-" db:"password_hash"` // "-" means don't show in JSON
+	FullName     string    `json:"full_name" db:"full_name"`
+	UserType     Role      `json:"user_type" db:"user_type"` // the active role
+	Phone        *string   `json:"phone" db:"phone"`         // Use pointer for nullable fields
+	Location     *string   `json:"location" db:"location"`
+	Bio          *string   `json:"bio" db:"bio"`
+	AvatarURL    *string   `json:"avatar_url" db:"avatar_url"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+
+	// Blob keys of an uploaded avatar; handlers turn them into signed URLs
+	AvatarKey          *string `json:"-" db:"avatar_key"`
+	AvatarThumbnailKey *string `json:"-" db:"avatar_thumbnail_key"`
+	AvatarThumbnailURL *string `json:"avatar_thumbnail_url"`
+
+	// Every role the user has, the active one included
+	Roles []Role `json:"roles"`
+
+	// Where the user is, for distances to jobs; both or neither are set
+	Latitude  *float64 `json:"latitude" db:"latitude"`
+	Longitude *float64 `json:"longitude" db:"longitude"`
+}
+
+// HasRole reports whether r is one of the user's roles
+func (u *User) HasRole(r Role) bool {
+	return u.UserType == r || slices.Contains(u.Roles, r)
+}
+
+// Profile is a user with a summary of each side of the marketplace they
+// are on. Worker is nil for users who aren't workers, Employer for users
+// who aren't employers.
+type Profile struct {
+	User
+	Worker   *WorkerFacet   `json:"worker"`
+	Employer *EmployerFacet `json:"employer"`
+}
+
+// WorkerFacet is what a user has done as a worker
+type WorkerFacet struct {
+	Skills        []WorkerSkill `json:"skills"`
+	CompletedJobs int           `json:"completed_jobs"`
+}
+
+// WorkerSkill is a category a worker has experience in
+type WorkerSkill struct {
+	CategoryID      int    `json:"category_id"`
+	CategoryName    string `json:"category_name"`
+	ExperienceYears int    `json:"experience_years"`
+}
+
+// WorkerCard is a worker as the public directory shows them, without
+// contact details
+type WorkerCard struct {
+	ID                 int           `json:"id"`
+	FullName           string        `json:"full_name"`
+	Location           *string       `json:"location"`
+	Bio                *string       `json:"bio"`
+	AvatarThumbnailURL *string       `json:"avatar_thumbnail_url"`
+	Skills             []WorkerSkill `json:"skills"`
+	CompletedJobs      int           `json:"completed_jobs"`
+
+	// Average of the ratings employers gave on completed bookings; nil
+	// until the first one
+	Rating      *float64 `json:"rating"`
+	RatingCount int      `json:"rating_count"`
+	// Whether the worker is free for the whole window searched; nil when
+	// the search gave none
+	Available *bool `json:"available"`
+
+	// Blob key of an uploaded avatar's thumbnail, signed into
+	// AvatarThumbnailURL
+	AvatarThumbnailKey *string `json:"-"`
+}
+
+// SkillInput is one skill in a worker's list
+type SkillInput struct {
+	CategoryID      int `json:"category_id" binding:"required"`
+	ExperienceYears int `json:"experience_years" binding:"min=0,max=80"`
+}
+
+// EmployerFacet is what a user has done as an employer
+type EmployerFacet struct {
+	Organizations []Organization `json:"organizations"`
+	JobsPosted    int            `json:"jobs_posted"`
+	Hires         int            `json:"hires"`
 }
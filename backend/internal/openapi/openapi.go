@@ -0,0 +1,76 @@
+// Package openapi serves the OpenAPI 3 description of the REST API.
+//
+// openapi.yaml is the contract: the routes in internal/server and the
+// request/response structs in handlers and models must match it. The
+// contract tests in internal/server fail when they drift apart.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+// Load parses and validates the embedded spec
+func Load() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(specYAML)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Docs serves the spec as JSON and a browsable UI for it
+type Docs struct {
+	specJSON []byte
+}
+
+// Handler loads the spec once so requests only copy bytes
+func Handler() (*Docs, error) {
+	doc, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	specJSON, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &Docs{specJSON: specJSON}, nil
+}
+
+// Spec serves GET /api/openapi.json
+func (d *Docs) Spec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", d.specJSON)
+}
+
+// UI serves GET /api/docs, a Swagger UI page pointed at the spec
+func (d *Docs) UI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(uiPage))
+}
+
+const uiPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Job-seeker API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/api/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
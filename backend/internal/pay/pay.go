@@ -0,0 +1,100 @@
+// Package pay turns a job's pay into numbers that can be compared and
+// charged: an hourly rate for sorting and filtering the job listing, and
+// the total held in escrow when a worker is hired.
+//
+// Amounts are integer minor units of the job's currency, e.g. paise.
+package pay
+
+import (
+	"math"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+)
+
+// HoursPerDay is how many hours a day's pay, or a day of a multi-day job,
+// is taken to cover
+const HoursPerDay = 8
+
+// Amount is the top of the job's pay range, or the bottom if it has no
+// top; 0 when it has no pay
+func Amount(job *models.Job) int64 {
+	pay := job.PayMax
+	if pay == nil {
+		pay = job.PayMin
+	}
+	if pay == nil || *pay <= 0 {
+		return 0
+	}
+	return *pay
+}
+
+// Days is how many calendar days, or part days, a schedule spans
+func Days(starts, ends *time.Time) int {
+	if starts == nil || ends == nil || !ends.After(*starts) {
+		return 0
+	}
+	return int(math.Ceil(ends.Sub(*starts).Hours() / 24))
+}
+
+// BillableHours is how many hours a schedule pays for: its length when it
+// fits in a day, otherwise HoursPerDay for each day it spans. 0 when there
+// is no schedule.
+func BillableHours(starts, ends *time.Time) float64 {
+	days := Days(starts, ends)
+	if days == 0 {
+		return 0
+	}
+	if days == 1 {
+		return ends.Sub(*starts).Hours()
+	}
+	return float64(days * HoursPerDay)
+}
+
+// HourlyRate normalises the top of the job's pay to minor units per hour.
+// Daily pay covers HoursPerDay hours and fixed pay the schedule's
+// billable hours; it is nil for jobs with no pay, and for fixed pay
+// without a schedule.
+func HourlyRate(job *models.Job) *int64 {
+	amount := Amount(job)
+	if amount == 0 {
+		return nil
+	}
+	var rate float64
+	switch job.PayType {
+	case models.PayHourly:
+		rate = float64(amount)
+	case models.PayDaily:
+		rate = float64(amount) / HoursPerDay
+	case models.PayFixed:
+		hours := BillableHours(job.StartsAt, job.EndsAt)
+		if hours == 0 {
+			return nil
+		}
+		rate = float64(amount) / hours
+	default:
+		return nil
+	}
+	r := int64(math.Round(rate))
+	return &r
+}
+
+// Total is what the whole job pays at the top of its range: fixed pay as
+// it is, hourly pay for the schedule's billable hours and daily pay for
+// each day it spans. Hourly and daily jobs without a schedule pay for one
+// day.
+func Total(job *models.Job) int64 {
+	amount := Amount(job)
+	switch job.PayType {
+	case models.PayHourly:
+		hours := BillableHours(job.StartsAt, job.EndsAt)
+		if hours == 0 {
+			hours = HoursPerDay
+		}
+		return int64(math.Round(float64(amount) * hours))
+	case models.PayDaily:
+		return amount * int64(max(Days(job.StartsAt, job.EndsAt), 1))
+	default:
+		return amount
+	}
+}
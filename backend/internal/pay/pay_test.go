@@ -0,0 +1,91 @@
+package pay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+)
+
+func amount(v int64) *int64 { return &v }
+
+func at(day, hour int) *time.Time {
+	t := time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func TestAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max *int64
+		want     int64
+	}{
+		{"range", amount(80000), amount(120056), 120056},
+		{"minimum only", amount(80000), nil, 80000},
+		{"unpaid", nil, nil, 0},
+	}
+	for _, tt := range tests {
+		if got := Amount(&models.Job{PayMin: tt.min, PayMax: tt.max}); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBillableHours(t *testing.T) {
+	tests := []struct {
+		name         string
+		starts, ends *time.Time
+		want         float64
+	}{
+		{"unscheduled", nil, nil, 0},
+		{"shift", at(2, 9), at(2, 13), 4},
+		{"overnight", at(2, 20), at(3, 8), 12},
+		{"three days", at(2, 9), at(4, 17), 3 * HoursPerDay},
+		{"backwards", at(2, 13), at(2, 9), 0},
+	}
+	for _, tt := range tests {
+		if got := BillableHours(tt.starts, tt.ends); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHourlyRateAndTotal(t *testing.T) {
+	tests := []struct {
+		name  string
+		job   models.Job
+		rate  *int64
+		total int64
+	}{
+		{"hourly", models.Job{PayType: models.PayHourly, PayMin: amount(20000), PayMax: amount(30000), StartsAt: at(2, 9), EndsAt: at(2, 13)},
+			amount(30000), 120000},
+		{"hourly unscheduled", models.Job{PayType: models.PayHourly, PayMin: amount(25000)},
+			amount(25000), 25000 * HoursPerDay},
+		{"daily", models.Job{PayType: models.PayDaily, PayMax: amount(100000), StartsAt: at(2, 9), EndsAt: at(4, 17)},
+			amount(12500), 300000},
+		{"daily unscheduled", models.Job{PayType: models.PayDaily, PayMax: amount(99999)},
+			amount(12500), 99999},
+		{"fixed", models.Job{PayType: models.PayFixed, PayMax: amount(150000), StartsAt: at(2, 9), EndsAt: at(2, 15)},
+			amount(25000), 150000},
+		{"fixed unscheduled", models.Job{PayType: models.PayFixed, PayMax: amount(150000)},
+			nil, 150000},
+		{"unpaid", models.Job{PayType: models.PayHourly},
+			nil, 0},
+	}
+	for _, tt := range tests {
+		rate := HourlyRate(&tt.job)
+		if (rate == nil) != (tt.rate == nil) || (rate != nil && *rate != *tt.rate) {
+			t.Errorf("%s: rate = %v, want %v", tt.name, deref(rate), deref(tt.rate))
+		}
+		if got := Total(&tt.job); got != tt.total {
+			t.Errorf("%s: total = %d, want %d", tt.name, got, tt.total)
+		}
+	}
+}
+
+func deref(p *int64) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
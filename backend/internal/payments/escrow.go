@@ -0,0 +1,139 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/pay"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+)
+
+// ErrNoAmount is returned when holding escrow for a job with no pay set
+var ErrNoAmount = errors.New("job has no pay to hold")
+
+// Escrow charges employers when they hire and refunds them when a booking
+// falls through. The provider is always called outside a database
+// transaction, and every call carries an idempotency key, so a request
+// that fails halfway can simply be retried.
+type Escrow struct {
+	Payments store.PaymentStore
+	Provider Provider
+}
+
+// Hold charges the employer what the whole job pays, in the job's
+// currency, and once the charge succeeds holds it in escrow. It returns
+// store.ErrPaymentExists if the booking is already paid for or a charge
+// is under way.
+func (e *Escrow) Hold(ctx context.Context, b *models.Booking, job *models.Job) (*models.Payment, error) {
+	amount := pay.Total(job)
+	if amount == 0 {
+		return nil, ErrNoAmount
+	}
+
+	p := &models.Payment{BookingID: &b.ID, Provider: e.Provider.Name(), Amount: amount, Currency: job.Currency}
+	if err := e.Payments.Create(ctx, p); err != nil {
+		return nil, err
+	}
+
+	charge, err := e.Provider.Charge(ctx, ChargeRequest{
+		Amount:         p.Amount,
+		Currency:       p.Currency,
+		Reference:      fmt.Sprintf("booking:%d", b.ID),
+		IdempotencyKey: fmt.Sprintf("payment:%d", p.ID),
+	})
+	if err != nil {
+		if _, _, settleErr := e.Payments.Settle(ctx, p.ID, "", StatusFailed, err.Error()); settleErr != nil {
+			return nil, errors.Join(err, settleErr)
+		}
+		return nil, err
+	}
+
+	// A pending charge is only given its reference here; the webhook
+	// settles it
+	return e.settle(ctx, p.ID, charge.ID, charge.Status, charge.FailureReason)
+}
+
+// settle records the provider's answer for a payment, and refunds it
+// straight away if its booking fell through while it was being charged
+func (e *Escrow) settle(ctx context.Context, id int, chargeID, status, reason string) (*models.Payment, error) {
+	p, refundDue, err := e.Payments.Settle(ctx, id, chargeID, status, reason)
+	if err != nil || !refundDue {
+		return p, err
+	}
+	refunded, err := e.Refund(ctx, *p.BookingID)
+	if errors.Is(err, store.ErrNothingHeld) {
+		return p, nil
+	}
+	return refunded, err
+}
+
+// Refund returns everything held for the booking to the employer. It
+// returns store.ErrNothingHeld if there is nothing to return.
+func (e *Escrow) Refund(ctx context.Context, bookingID int) (*models.Payment, error) {
+	p, err := e.Payments.HeldPayment(ctx, bookingID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, store.ErrNothingHeld
+	}
+	if err != nil {
+		return nil, err
+	}
+	escrow, err := e.Payments.Escrow(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	var held int64
+	for _, balance := range escrow.Held {
+		if balance.Currency == p.Currency {
+			held = balance.Amount
+		}
+	}
+	if held <= 0 || p.ProviderRef == nil {
+		return nil, store.ErrNothingHeld
+	}
+
+	err = e.Provider.Refund(ctx, RefundRequest{
+		ChargeID:       *p.ProviderRef,
+		Amount:         held,
+		IdempotencyKey: fmt.Sprintf("refund:payment:%d", p.ID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e.Payments.Refund(ctx, p.ID, held)
+}
+
+// HandleWebhook verifies and applies a provider webhook. Events already
+// handled are ignored, so the provider may redeliver freely. The event is
+// nil only when the webhook itself is bad; with any other error it is
+// returned too, since the provider should redeliver it.
+func (e *Escrow) HandleWebhook(ctx context.Context, header http.Header, body []byte) (*WebhookEvent, error) {
+	event, err := e.Provider.ParseWebhook(header, body)
+	if err != nil {
+		return nil, err
+	}
+	seen, err := e.Payments.WebhookSeen(ctx, e.Provider.Name(), event.ID)
+	if err != nil || seen {
+		return event, err
+	}
+
+	var status string
+	switch event.Type {
+	case EventChargeSucceeded:
+		status = StatusSucceeded
+	case EventChargeFailed:
+		status = StatusFailed
+	}
+	if status != "" {
+		p, err := e.Payments.GetByProviderRef(ctx, e.Provider.Name(), event.ChargeID)
+		if err != nil {
+			return event, err
+		}
+		if _, err := e.settle(ctx, p.ID, event.ChargeID, status, event.FailureReason); err != nil {
+			return event, err
+		}
+	}
+	return event, e.Payments.RecordWebhook(ctx, e.Provider.Name(), event.ID, event.Type, body)
+}
@@ -0,0 +1,158 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FakeProvider is an in-memory Provider for development and tests. Charges
+// succeed at once unless Async is set, when they stay pending until
+// Settle produces the webhook a real provider would send. Amounts listed
+// in Decline are refused.
+type FakeProvider struct {
+	Secret    string
+	Tolerance time.Duration
+	Async     bool
+	Decline   map[int64]bool
+
+	mu      sync.Mutex
+	charges map[string]*fakeCharge
+	byKey   map[string]string
+	refunds map[string]int64
+	events  int
+	now     func() time.Time
+}
+
+type fakeCharge struct {
+	Charge
+	Amount   int64
+	Refunded int64
+}
+
+func NewFakeProvider(secret string, tolerance time.Duration) *FakeProvider {
+	return &FakeProvider{
+		Secret:    secret,
+		Tolerance: tolerance,
+		Decline:   map[int64]bool{},
+		charges:   map[string]*fakeCharge{},
+		byKey:     map[string]string{},
+		refunds:   map[string]int64{},
+		now:       time.Now,
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Charge(ctx context.Context, req ChargeRequest) (*Charge, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if id, ok := p.byKey[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		c := p.charges[id].Charge
+		return &c, nil
+	}
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("charge amount must be positive, got %d", req.Amount)
+	}
+
+	c := &fakeCharge{Charge: Charge{ID: fmt.Sprintf("ch_fake_%d", len(p.charges)+1)}, Amount: req.Amount}
+	switch {
+	case p.Async:
+		c.Status = StatusPending
+	case p.Decline[req.Amount]:
+		c.Status, c.FailureReason = StatusFailed, ErrDeclined.Error()
+	default:
+		c.Status = StatusSucceeded
+	}
+	p.charges[c.ID] = c
+	if req.IdempotencyKey != "" {
+		p.byKey[req.IdempotencyKey] = c.ID
+	}
+	out := c.Charge
+	return &out, nil
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, req RefundRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.refunds[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		return nil
+	}
+	c, ok := p.charges[req.ChargeID]
+	if !ok {
+		return fmt.Errorf("no charge %q", req.ChargeID)
+	}
+	if c.Status != StatusSucceeded && c.Status != StatusRefunded {
+		return fmt.Errorf("charge %q is %s", req.ChargeID, c.Status)
+	}
+	if req.Amount <= 0 || c.Refunded+req.Amount > c.Amount {
+		return fmt.Errorf("can't refund %d of charge %q", req.Amount, req.ChargeID)
+	}
+	c.Refunded += req.Amount
+	if c.Refunded == c.Amount {
+		c.Status = StatusRefunded
+	}
+	p.refunds[req.IdempotencyKey] = req.Amount
+	return nil
+}
+
+// Refunded reports how much of the charge has been refunded
+func (p *FakeProvider) Refunded(chargeID string) int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if c, ok := p.charges[chargeID]; ok {
+		return c.Refunded
+	}
+	return 0
+}
+
+// Settle completes a pending charge and returns the signed webhook that
+// reports it, ready to POST to the API
+func (p *FakeProvider) Settle(chargeID string, succeeded bool) (http.Header, []byte, error) {
+	p.mu.Lock()
+	c, ok := p.charges[chargeID]
+	if !ok {
+		p.mu.Unlock()
+		return nil, nil, fmt.Errorf("no charge %q", chargeID)
+	}
+	event := WebhookEvent{ChargeID: chargeID, Type: EventChargeSucceeded}
+	c.Status = StatusSucceeded
+	if !succeeded {
+		event.Type, event.FailureReason = EventChargeFailed, ErrDeclined.Error()
+		c.Status, c.FailureReason = StatusFailed, ErrDeclined.Error()
+	}
+	p.events++
+	event.ID = fmt.Sprintf("evt_fake_%d", p.events)
+	p.mu.Unlock()
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, err
+	}
+	header := http.Header{}
+	header.Set(SignatureHeader, Sign(p.Secret, p.now(), body))
+	header.Set("Content-Type", "application/json")
+	return header, body, nil
+}
+
+func (p *FakeProvider) ParseWebhook(header http.Header, body []byte) (*WebhookEvent, error) {
+	if err := VerifySignature(p.Secret, header.Get(SignatureHeader), body, p.Tolerance, p.now()); err != nil {
+		return nil, err
+	}
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("decode webhook: %w", err)
+	}
+	if event.ID == "" || event.ChargeID == "" {
+		return nil, fmt.Errorf("decode webhook: missing id or charge_id")
+	}
+	return &event, nil
+}
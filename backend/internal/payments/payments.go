@@ -0,0 +1,161 @@
+// Package payments moves money through a payment provider and into the
+// ledger.
+//
+// Provider hides the provider's API. Charges fund a booking's escrow when
+// a worker is hired, and refunds return it if the booking falls through;
+// the escrow is paid to the worker when the booking completes (see
+// store.PgBookingStore.Apply). Providers report charges that settle later
+// with signed webhooks, checked by VerifySignature before anything in them
+// is trusted.
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/config"
+)
+
+// Charge statuses, matching the payments table
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusRefunded  = "refunded"
+)
+
+// Webhook event types
+const (
+	EventChargeSucceeded = "charge.succeeded"
+	EventChargeFailed    = "charge.failed"
+)
+
+// SignatureHeader carries a webhook's signature, e.g.
+// "t=1700000000,v1=5f0c..."
+const SignatureHeader = "X-Payment-Signature"
+
+var (
+	ErrBadSignature = errors.New("webhook signature does not match")
+	// ErrStaleWebhook is returned for a correctly signed webhook sent
+	// longer ago than the tolerance, which may be a replay
+	ErrStaleWebhook = errors.New("webhook timestamp outside tolerance")
+	// ErrDeclined is returned when the provider refuses a charge
+	ErrDeclined = errors.New("payment declined")
+)
+
+// Provider charges and refunds through an external payment service
+type Provider interface {
+	// Name identifies the provider in payment records and ledger accounts
+	Name() string
+	// Charge takes money for a booking. A charge that settles later comes
+	// back pending and is completed by a webhook.
+	Charge(ctx context.Context, req ChargeRequest) (*Charge, error)
+	Refund(ctx context.Context, req RefundRequest) error
+	// ParseWebhook verifies a webhook's signature and decodes it
+	ParseWebhook(header http.Header, body []byte) (*WebhookEvent, error)
+}
+
+// ChargeRequest asks for Amount minor units of Currency. Repeating a
+// request with the same IdempotencyKey returns the original charge.
+type ChargeRequest struct {
+	Amount         int64
+	Currency       string
+	Reference      string
+	IdempotencyKey string
+}
+
+// Charge is the provider's record of a charge
+type Charge struct {
+	ID            string
+	Status        string
+	FailureReason string
+}
+
+type RefundRequest struct {
+	ChargeID       string
+	Amount         int64
+	IdempotencyKey string
+}
+
+// WebhookEvent is a verified notification from the provider
+type WebhookEvent struct {
+	ID            string `json:"id"`
+	Type          string `json:"type"`
+	ChargeID      string `json:"charge_id"`
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
+// New builds the Provider cfg selects. Without a configured webhook
+// secret one is derived from fallbackSecret (the JWT secret).
+func New(cfg config.PaymentsConfig, fallbackSecret string) (Provider, error) {
+	secret := cfg.WebhookSecret
+	if secret == "" {
+		mac := hmac.New(sha256.New, []byte(fallbackSecret))
+		mac.Write([]byte("payment webhooks"))
+		secret = hex.EncodeToString(mac.Sum(nil))
+	}
+	switch cfg.Provider {
+	case "fake":
+		return NewFakeProvider(secret, cfg.WebhookTolerance), nil
+	}
+	return nil, fmt.Errorf("unknown payment provider %q", cfg.Provider)
+}
+
+func signature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sign returns the SignatureHeader value for body sent at t
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := t.Unix()
+	return fmt.Sprintf("t=%d,v1=%s", ts, signature(secret, ts, body))
+}
+
+// VerifySignature checks a SignatureHeader value made by Sign, rejecting
+// ones more than tolerance away from now
+func VerifySignature(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var ts int64
+	var sigs []string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			parsed, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return ErrBadSignature
+			}
+			ts = parsed
+		case "v1":
+			sigs = append(sigs, v)
+		}
+	}
+	if ts == 0 || len(sigs) == 0 {
+		return ErrBadSignature
+	}
+
+	want := signature(secret, ts, body)
+	matched := false
+	for _, sig := range sigs {
+		if hmac.Equal([]byte(sig), []byte(want)) {
+			matched = true
+		}
+	}
+	if !matched {
+		return ErrBadSignature
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > tolerance || age < -tolerance {
+		return ErrStaleWebhook
+	}
+	return nil
+}
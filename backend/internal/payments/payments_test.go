@@ -0,0 +1,117 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/config"
+)
+
+func TestVerifySignature(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"id":"evt_1","type":"charge.succeeded","charge_id":"ch_1"}`)
+	header := Sign("whsec", now, body)
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		at     time.Time
+		want   error
+	}{
+		{"valid", "whsec", header, body, now.Add(time.Minute), nil},
+		{"wrong secret", "guess", header, body, now, ErrBadSignature},
+		{"tampered body", "whsec", header, []byte(`{"id":"evt_1","type":"charge.succeeded","charge_id":"ch_2"}`), now, ErrBadSignature},
+		{"replayed later", "whsec", header, body, now.Add(10 * time.Minute), ErrStaleWebhook},
+		{"missing", "whsec", "", body, now, ErrBadSignature},
+		{"garbage timestamp", "whsec", "t=soon,v1=abc", body, now, ErrBadSignature},
+		{"second signature matches", "whsec", header[:len("t=1700000000")] + ",v1=old," + header[len("t=1700000000,"):], body, now, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifySignature(tt.secret, tt.header, tt.body, 5*time.Minute, tt.at); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFakeProviderChargesIdempotently(t *testing.T) {
+	p := NewFakeProvider("whsec", time.Minute)
+	ctx := context.Background()
+
+	first, err := p.Charge(ctx, ChargeRequest{Amount: 500, Currency: "INR", IdempotencyKey: "payment:1"})
+	if err != nil || first.Status != StatusSucceeded {
+		t.Fatalf("charge: %+v %v", first, err)
+	}
+	again, _ := p.Charge(ctx, ChargeRequest{Amount: 500, Currency: "INR", IdempotencyKey: "payment:1"})
+	if again.ID != first.ID {
+		t.Errorf("retry made a second charge: %s and %s", first.ID, again.ID)
+	}
+
+	p.Decline[700] = true
+	declined, _ := p.Charge(ctx, ChargeRequest{Amount: 700, Currency: "INR", IdempotencyKey: "payment:2"})
+	if declined.Status != StatusFailed {
+		t.Errorf("declined charge: %+v", declined)
+	}
+
+	if err := p.Refund(ctx, RefundRequest{ChargeID: first.ID, Amount: 500, IdempotencyKey: "refund:1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Refund(ctx, RefundRequest{ChargeID: first.ID, Amount: 500, IdempotencyKey: "refund:1"}); err != nil {
+		t.Errorf("retried refund: %v", err)
+	}
+	if got := p.Refunded(first.ID); got != 500 {
+		t.Errorf("refunded %d", got)
+	}
+	if err := p.Refund(ctx, RefundRequest{ChargeID: first.ID, Amount: 1, IdempotencyKey: "refund:2"}); err == nil {
+		t.Error("refunded more than was charged")
+	}
+}
+
+func TestFakeProviderWebhooks(t *testing.T) {
+	p := NewFakeProvider("whsec", time.Minute)
+	p.Async = true
+	charge, _ := p.Charge(context.Background(), ChargeRequest{Amount: 500, Currency: "INR"})
+	if charge.Status != StatusPending {
+		t.Fatalf("async charge: %+v", charge)
+	}
+
+	header, body, err := p.Settle(charge.ID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, err := p.ParseWebhook(header, body)
+	if err != nil || event.Type != EventChargeSucceeded || event.ChargeID != charge.ID {
+		t.Fatalf("event: %+v %v", event, err)
+	}
+
+	other := NewFakeProvider("other", time.Minute)
+	if _, err := other.ParseWebhook(header, body); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("webhook verified with the wrong secret: %v", err)
+	}
+	if _, err := p.ParseWebhook(http.Header{}, body); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("unsigned webhook: %v", err)
+	}
+}
+
+func TestNewDerivesWebhookSecret(t *testing.T) {
+	cfg := config.Default().Payments
+	p, err := New(cfg, "jwt-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := p.(*FakeProvider)
+	if fake.Secret == "" || fake.Secret == "jwt-secret" {
+		t.Errorf("webhook secret = %q", fake.Secret)
+	}
+
+	cfg.Provider = "stripe"
+	if _, err := New(cfg, "jwt-secret"); err == nil {
+		t.Error("unknown provider accepted")
+	}
+}
@@ -0,0 +1,162 @@
+// Package pdf writes simple text documents as PDF without any
+// dependencies: A4 pages of Helvetica text and ruled lines, enough for
+// invoices and statements.
+//
+// Text is encoded as WinAnsi, which covers ASCII and Latin-1. Devanagari
+// and Tamil are transliterated to Latin letters first; any other
+// character is written as "?".
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 in points
+const (
+	PageWidth  = 595.0
+	PageHeight = 842.0
+)
+
+// Document is a PDF being built page by page
+type Document struct {
+	title string
+	pages []*Page
+}
+
+// Page holds the drawing operators for one page. Coordinates are in
+// points from the bottom-left corner.
+type Page struct {
+	content bytes.Buffer
+}
+
+func New(title string) *Document {
+	return &Document{title: title}
+}
+
+func (d *Document) AddPage() *Page {
+	p := &Page{}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Text writes s with its baseline starting at (x, y)
+func (p *Page) Text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, escape(s))
+}
+
+// TextRight writes s so that it ends at x
+func (p *Page) TextRight(x, y, size float64, bold bool, s string) {
+	p.Text(x-Width(s, size, bold), y, size, bold, s)
+}
+
+// Line draws a thin line from (x1, y1) to (x2, y2)
+func (p *Page) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.content, "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+// Width estimates how wide s is set in Helvetica at size. Digits and
+// punctuation, which right-aligned amounts are made of, are exact; bold
+// is treated as regular.
+func Width(s string, size float64, bold bool) float64 {
+	var units float64
+	for _, r := range latin(s) {
+		switch {
+		case r >= '0' && r <= '9':
+			units += 556
+		case r == '.' || r == ',' || r == ' ':
+			units += 278
+		case r >= 'A' && r <= 'Z':
+			units += 667
+		default:
+			units += 540
+		}
+	}
+	return units * size / 1000
+}
+
+// escape encodes s as the body of a PDF literal string
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range latin(s) {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// WriteTo writes the finished document to w
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	var offsets []int64
+	object := func(body string) {
+		offsets = append(offsets, cw.n)
+		fmt.Fprintf(cw, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Objects 1-5 are fixed; each page then adds a page and a content
+	// stream, so page i is object 6+2i
+	fmt.Fprint(cw, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Producer (jobseeker) >>", escape(d.title)))
+	for i, p := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, 7+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()))
+	}
+
+	xref := cw.n
+	fmt.Fprintf(cw, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(cw, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(cw, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+	return cw.n, cw.w.Flush()
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
@@ -0,0 +1,99 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	doc := New("Statement")
+	p := doc.AddPage()
+	p.Text(50, 800, 18, true, "Earnings (October)")
+	p.Line(50, 790, 545, 790)
+	doc.AddPage().TextRight(545, 700, 10, false, "1,250.00 INR")
+
+	var buf bytes.Buffer
+	n, err := doc.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.Bytes()
+	if int(n) != len(out) {
+		t.Errorf("reported %d bytes, wrote %d", n, len(out))
+	}
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatalf("not a PDF: %q...", out[:20])
+	}
+	if !bytes.Contains(out, []byte("/Count 2")) {
+		t.Error("page count missing")
+	}
+	if !bytes.Contains(out, []byte(`(Earnings \(October\))`)) {
+		t.Error("parentheses not escaped")
+	}
+
+	// startxref points at the xref table, and each entry at its object
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	if m == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(out[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d points at %q", xref, out[xref:xref+10])
+	}
+	entries := strings.Split(string(out[xref:]), "\n")[3:]
+	for i := 1; i <= 9; i++ {
+		off, _ := strconv.Atoi(entries[i-1][:10])
+		if want := strconv.Itoa(i) + " 0 obj"; !bytes.HasPrefix(out[off:], []byte(want)) {
+			t.Errorf("object %d: offset %d points at %q", i, off, out[off:off+10])
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := map[string]string{
+		`a\b`:    `a\\b`,
+		"Café":   `Caf\351`,
+		"₹ 500":  "? 500",
+		"(note)": `\(note\)`,
+	}
+	for in, want := range tests {
+		if got := escape(in); got != want {
+			t.Errorf("escape(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLatin(t *testing.T) {
+	tests := map[string]string{
+		"राजेश कुमार": "Rajesh Kumar",
+		"प्रिया":      "Priya",
+		"संजय":        "Sanjay",
+		"हिंदी":       "Hindi",
+		"क":           "Ka",
+		"ज़फ़र":       "Zafar",
+		"முருகன்":     "Murukan",
+		"லட்சுமி":     "Latchumi",
+		"Ravi (राम)":  "Ravi (Ram)",
+		"Café ₹ 500":  "Café ₹ 500",
+		"पाइप ठीक":    "Paip Thik",
+	}
+	for in, want := range tests {
+		if got := latin(in); got != want {
+			t.Errorf("latin(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	// The PDF carries the spelled out name, not question marks
+	doc := New("Invoice")
+	doc.AddPage().Text(50, 800, 10, false, "Worker: அருண் / अरुण")
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("(Worker: Arun / Arun)")) {
+		t.Error("names not transliterated")
+	}
+}
@@ -0,0 +1,179 @@
+package pdf
+
+import (
+	"strings"
+	"unicode"
+)
+
+type letterKind int
+
+const (
+	other letterKind = iota
+	consonant
+	vowel     // an independent vowel
+	vowelSign // a vowel written on the consonant before it
+	virama    // cancels the consonant's vowel
+	modifier  // anusvara, visarga and the like
+	silent    // e.g. a nukta, which changes the consonant only slightly
+)
+
+type letter struct {
+	kind  letterKind
+	latin string
+}
+
+// script maps a script's letters to Latin. dropFinalA is for Hindi and
+// Marathi, which don't say the vowel of a word's last consonant.
+type script struct {
+	first, last rune
+	letters     map[rune]letter
+	dropFinalA  bool
+}
+
+var scripts = []*script{devanagari, tamil}
+
+var devanagari = &script{first: 0x0900, last: 0x097F, dropFinalA: true, letters: letters(
+	consonant, "क k ख kh ग g घ gh ङ n च ch छ chh ज j झ jh ञ n ट t ठ th ड d ढ dh ण n त t थ th द d ध dh न n "+
+		"प p फ ph ब b भ bh म m य y र r ल l ळ l व v श sh ष sh स s ह h क़ q ख़ kh ग़ g ज़ z ड़ r ढ़ rh फ़ f य़ y",
+	vowel, "अ a आ a इ i ई i उ u ऊ u ऋ ri ए e ऐ ai ओ o औ au ऑ o",
+	vowelSign, "ा a ि i ी i ु u ू u ृ ri े e ै ai ो o ौ au ॉ o",
+	virama, "्",
+	modifier, "ं n ँ n ः h",
+	silent, "़ ऽ",
+	other, "० 0 १ 1 २ 2 ३ 3 ४ 4 ५ 5 ६ 6 ७ 7 ८ 8 ९ 9 । . ॥ .",
+)}
+
+var tamil = &script{first: 0x0B80, last: 0x0BFF, letters: letters(
+	consonant, "க k ங ng ச ch ஞ nj ட t ண n த th ந n ப p ம m ய y ர r ல l வ v ழ zh ள l ற r ன n "+
+		"ஜ j ஷ sh ஸ s ஹ h",
+	vowel, "அ a ஆ a இ i ஈ i உ u ஊ u எ e ஏ e ஐ ai ஒ o ஓ o ஔ au",
+	vowelSign, "ா a ி i ீ i ு u ூ u ெ e ே e ை ai ொ o ோ o ௌ au",
+	virama, "்",
+	modifier, "ஂ m ஃ h",
+	silent, "ௗ",
+	other, "௦ 0 ௧ 1 ௨ 2 ௩ 3 ௪ 4 ௫ 5 ௬ 6 ௭ 7 ௮ 8 ௯ 9",
+)}
+
+// letters builds a letter table from kinds each followed by a list of
+// "letter latin" pairs, or of bare letters for kinds with no sound
+func letters(defs ...any) map[rune]letter {
+	table := map[rune]letter{}
+	for i := 0; i < len(defs); i += 2 {
+		kind, fields := defs[i].(letterKind), strings.Fields(defs[i+1].(string))
+		step := 2
+		if kind == virama || kind == silent {
+			step = 1
+		}
+		for j := 0; j < len(fields); j += step {
+			l := letter{kind: kind}
+			if step == 2 {
+				l.latin = fields[j+1]
+			}
+			// Letters with a nukta are the base letter and the nukta
+			r := []rune(fields[j])
+			if len(r) == 2 {
+				table[-r[0]] = l
+				continue
+			}
+			table[r[0]] = l
+		}
+	}
+	return table
+}
+
+func scriptOf(r rune) *script {
+	for _, s := range scripts {
+		if r >= s.first && r <= s.last {
+			return s
+		}
+	}
+	return nil
+}
+
+// latin returns s with its Devanagari and Tamil spelled in plain Latin
+// letters, each word capitalized, e.g. "राजेश" as "Rajesh" and "முருகன்" as
+// "Murukan". Helvetica has neither script, and a name printed as "????"
+// is no use on proof of income. The spelling follows the letters, so a
+// vowel Hindi leaves unsaid mid-word is still written ("करना" comes out
+// as "Karana"). Everything else is left alone.
+func latin(s string) string {
+	if !strings.ContainsFunc(s, func(r rune) bool { return scriptOf(r) != nil }) {
+		return s
+	}
+
+	var b strings.Builder
+	runes := []rune(s)
+	var word strings.Builder
+	var sc *script
+	owed := false // the last consonant still needs its vowel
+	syllables := 0
+	flush := func(final bool) {
+		if owed && !(final && sc.dropFinalA && syllables > 0) {
+			word.WriteByte('a')
+			syllables++
+		}
+		owed = false
+	}
+	endWord := func() {
+		if sc != nil {
+			flush(true)
+			w := []rune(word.String())
+			if len(w) > 0 {
+				w[0] = unicode.ToUpper(w[0])
+			}
+			b.WriteString(string(w))
+		}
+		word.Reset()
+		sc, syllables = nil, 0
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		next := scriptOf(r)
+		if next == nil {
+			endWord()
+			b.WriteRune(r)
+			continue
+		}
+		if next != sc {
+			endWord()
+			sc = next
+		}
+		l, ok := sc.letters[r]
+		if i+1 < len(runes) && runes[i+1] == 0x093C {
+			if nukta, found := sc.letters[-r]; found {
+				l, ok = nukta, true
+				i++
+			}
+		}
+		if !ok {
+			l = letter{kind: other, latin: "?"}
+		}
+
+		switch l.kind {
+		case consonant:
+			flush(false)
+			word.WriteString(l.latin)
+			owed = true
+		case vowelSign:
+			word.WriteString(l.latin)
+			owed = false
+			syllables++
+		case virama:
+			owed = false
+		case silent:
+		case vowel:
+			flush(false)
+			word.WriteString(l.latin)
+			syllables++
+		case modifier:
+			flush(false)
+			word.WriteString(l.latin)
+		default:
+			endWord()
+			b.WriteString(l.latin)
+		}
+	}
+	endWord()
+	return b.String()
+}
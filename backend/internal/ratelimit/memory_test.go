@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreBucket(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{PerMinute: 60, Burst: 3}
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	take := func(at time.Time) Result {
+		t.Helper()
+		res, err := store.Take(context.Background(), "k", limit, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	// The burst is available at once
+	for i := 2; i >= 0; i-- {
+		res := take(now)
+		if !res.Allowed || res.Remaining != i {
+			t.Fatalf("take %d: %+v", 3-i, res)
+		}
+	}
+
+	res := take(now)
+	if res.Allowed || res.RetryAfter != time.Second || res.Reset != 3*time.Second {
+		t.Fatalf("over the burst: %+v", res)
+	}
+
+	// One token a second comes back
+	if res := take(now.Add(time.Second)); !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("after refill: %+v", res)
+	}
+
+	// Refill never exceeds the burst
+	if res := take(now.Add(time.Hour)); !res.Allowed || res.Remaining != 2 {
+		t.Fatalf("after a long pause: %+v", res)
+	}
+
+	// Keys are independent
+	other, _ := store.Take(context.Background(), "other", limit, now)
+	if !other.Allowed || other.Remaining != 2 {
+		t.Fatalf("other key: %+v", other)
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	store.Take(context.Background(), "old", Limit{PerMinute: 1, Burst: 1}, now.Add(-2*time.Hour))
+	store.Take(context.Background(), "new", Limit{PerMinute: 1, Burst: 1}, now)
+
+	if err := store.Sweep(context.Background(), now.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.buckets["old"]; ok {
+		t.Error("idle bucket was not swept")
+	}
+	if _, ok := store.buckets["new"]; !ok {
+		t.Error("active bucket was swept")
+	}
+}
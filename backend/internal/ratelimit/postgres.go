@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgStore keeps buckets in the rate_limit_buckets table so every instance
+// shares them. Each Take is a single upsert; the row lock makes
+// concurrent takes on one key queue up instead of double-spending.
+type PgStore struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewPgStore(db *pgxpool.Pool, queryTimeout time.Duration) *PgStore {
+	return &PgStore{DB: db, QueryTimeout: queryTimeout}
+}
+
+func (s *PgStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	// $2 burst, $3 tokens per second, $4 now. The refilled amount is
+	// spelled out twice because SET expressions cannot share an alias.
+	query := `
+		INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+		VALUES ($1, $2::float8 - 1, true, $4::timestamptz)
+		ON CONFLICT (key) DO UPDATE SET
+			allowed = LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM ($4::timestamptz - b.updated_at))::float8, 0) * $3::float8) >= 1,
+			tokens = LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM ($4::timestamptz - b.updated_at))::float8, 0) * $3::float8)
+				- CASE WHEN LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM ($4::timestamptz - b.updated_at))::float8, 0) * $3::float8) >= 1 THEN 1 ELSE 0 END,
+			updated_at = $4::timestamptz
+		RETURNING b.allowed, b.tokens`
+
+	var allowed bool
+	var tokens float64
+	err := s.DB.QueryRow(ctx, query, key, limit.Burst, limit.perSecond(), now).Scan(&allowed, &tokens)
+	if err != nil {
+		return Result{}, err
+	}
+	return result(allowed, tokens, limit), nil
+}
+
+func (s *PgStore) Sweep(ctx context.Context, before time.Time) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	_, err := s.DB.Exec(ctx, `DELETE FROM rate_limit_buckets WHERE updated_at < $1`, before)
+	return err
+}
+
+func (s *PgStore) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.QueryTimeout)
+}
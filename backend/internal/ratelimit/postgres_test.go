@@ -0,0 +1,68 @@
+package ratelimit_test
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/ratelimit"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/testutil"
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	testutil.Stop()
+	os.Exit(code)
+}
+
+func TestPgStore(t *testing.T) {
+	db := testutil.DB(t)
+	db.Reset(t)
+	store := ratelimit.NewPgStore(db.Pool, 5*time.Second)
+	ctx := context.Background()
+	limit := ratelimit.Limit{PerMinute: 60, Burst: 5}
+	now := time.Now()
+
+	// Concurrent takes on one key must not double-spend tokens
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := store.Take(ctx, "k", limit, now)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if res.Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed != 5 {
+		t.Fatalf("allowed %d of 8 concurrent takes, want 5", allowed)
+	}
+
+	res, err := store.Take(ctx, "k", limit, now.Add(2*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Allowed || res.Remaining != 1 {
+		t.Fatalf("after 2s refill: %+v", res)
+	}
+
+	if err := store.Sweep(ctx, now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	res, _ = store.Take(ctx, "k", limit, now.Add(3*time.Second))
+	if res.Remaining != 4 {
+		t.Fatalf("swept bucket should start full: %+v", res)
+	}
+}
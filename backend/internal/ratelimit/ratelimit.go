@@ -0,0 +1,90 @@
+// Package ratelimit throttles clients with token buckets.
+//
+// Each bucket holds up to Burst tokens and refills at PerMinute tokens a
+// minute. A request takes one token or is refused with 429. Buckets live
+// in a Store: MemoryStore for a single instance, PgStore when several
+// instances must share limits.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit is a token-bucket policy
+type Limit struct {
+	PerMinute int
+	Burst     int
+}
+
+func (l Limit) perSecond() float64 {
+	return float64(l.PerMinute) / 60
+}
+
+// Result describes a bucket after a Take
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is how long until the next token, zero when allowed
+	RetryAfter time.Duration
+}
+
+// Store keeps buckets by key
+type Store interface {
+	// Take removes a token from the bucket for key if one is available
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+	// Sweep forgets buckets untouched since before, which are full anyway
+	Sweep(ctx context.Context, before time.Time) error
+}
+
+// refill returns the tokens in a bucket last seen at last with tokens left
+func refill(tokens float64, last, now time.Time, limit Limit) float64 {
+	elapsed := now.Sub(last).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(float64(limit.Burst), tokens+elapsed*limit.perSecond())
+}
+
+// result builds the Result for a bucket holding tokens after the take
+func result(allowed bool, tokens float64, limit Limit) Result {
+	rate := limit.perSecond()
+	r := Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     seconds((float64(limit.Burst) - tokens) / rate),
+	}
+	if !allowed {
+		r.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return r
+}
+
+func seconds(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(s * float64(time.Second))
+}
+
+// SweepEvery calls store.Sweep until ctx is done, dropping buckets idle
+// for longer than idle
+func SweepEvery(ctx context.Context, store Store, interval, idle time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := store.Sweep(ctx, now.Add(-idle)); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
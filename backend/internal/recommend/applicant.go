@@ -0,0 +1,122 @@
+package recommend
+
+import "fmt"
+
+// MatchWeights says how much each factor of an applicant's match counts.
+// Only the ratios between the five weights matter.
+type MatchWeights struct {
+	Skill      float64 `json:"skill"`
+	Experience float64 `json:"experience"`
+	Rating     float64 `json:"rating"`
+	Distance   float64 `json:"distance"`
+	Completion float64 `json:"completion"`
+	// Distance at which the distance score falls to a half
+	DistanceHalfKm float64 `json:"distance_half_km"`
+}
+
+// DefaultMatchWeights favours applicants with the job's skill
+func DefaultMatchWeights() MatchWeights {
+	return MatchWeights{
+		Skill:          0.3,
+		Experience:     0.2,
+		Rating:         0.2,
+		Distance:       0.15,
+		Completion:     0.15,
+		DistanceHalfKm: 10,
+	}
+}
+
+// Applicant is what an employer knows of a worker who applied
+type Applicant struct {
+	Skills   []Skill `json:"skills"`
+	Location string  `json:"location"`
+	Point    *Point  `json:"point"`
+	// Average rating from 1 to 5; nil until an employer has rated them
+	Rating      *float64 `json:"rating"`
+	RatingCount int      `json:"rating_count"`
+	// Bookings they completed, and bookings they cancelled or didn't turn
+	// up for
+	Completed int `json:"completed"`
+	Dropped   int `json:"dropped"`
+}
+
+// Factor names for applicant matches, besides FactorDistance
+const (
+	FactorSkill      = "skill"
+	FactorExperience = "experience"
+	FactorRating     = "rating"
+	FactorCompletion = "completion"
+)
+
+// Years of experience that earn a full experience score
+const fullExperience = 5
+
+// Match rates how well applicant fits job
+func Match(w MatchWeights, applicant Applicant, job Job) Result {
+	skill, experience := skillMatch(applicant, job)
+	factors := []Factor{
+		skill,
+		experience,
+		rating(applicant),
+		nearness(w.DistanceHalfKm, job.Location, job.Point, applicant.Location, applicant.Point, "Based in "+applicant.Location),
+		completion(applicant),
+	}
+	return weigh(factors, map[string]float64{
+		FactorSkill:      w.Skill,
+		FactorExperience: w.Experience,
+		FactorRating:     w.Rating,
+		FactorDistance:   w.Distance,
+		FactorCompletion: w.Completion,
+	})
+}
+
+// skillMatch scores whether the applicant has the job's skill, and their
+// years in it. Both are neutral for jobs without a category.
+func skillMatch(applicant Applicant, job Job) (skill, experience Factor) {
+	skill = Factor{Name: FactorSkill, Score: neutral}
+	experience = Factor{Name: FactorExperience, Score: neutral}
+	if job.CategoryID == nil {
+		return skill, experience
+	}
+	skill.Score, experience.Score = 0, 0
+	name := job.CategoryName
+	if name == "" {
+		name = "this category"
+	}
+	for _, s := range applicant.Skills {
+		if s.CategoryID != *job.CategoryID {
+			continue
+		}
+		skill.Score = 1
+		skill.Reason = "Has the " + name + " skill"
+		experience.Score = float64(min(s.Years, fullExperience)) / fullExperience
+		if s.Years > 0 {
+			experience.Reason = fmt.Sprintf("%s of %s experience", plural(s.Years, "year"), name)
+		}
+		break
+	}
+	return skill, experience
+}
+
+// rating maps the average rating from 1 to 5 onto 0 to 1
+func rating(applicant Applicant) Factor {
+	f := Factor{Name: FactorRating, Score: neutral}
+	if applicant.Rating == nil {
+		return f
+	}
+	f.Score = min(max((*applicant.Rating-1)/4, 0), 1)
+	f.Reason = fmt.Sprintf("Rated %.1f from %s", *applicant.Rating, plural(applicant.RatingCount, "job"))
+	return f
+}
+
+// completion is the share of finished bookings the applicant completed
+func completion(applicant Applicant) Factor {
+	f := Factor{Name: FactorCompletion, Score: neutral}
+	finished := applicant.Completed + applicant.Dropped
+	if finished == 0 {
+		return f
+	}
+	f.Score = float64(applicant.Completed) / float64(finished)
+	f.Reason = fmt.Sprintf("Completed %d of %s", applicant.Completed, plural(finished, "booking"))
+	return f
+}
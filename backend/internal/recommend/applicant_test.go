@@ -0,0 +1,73 @@
+package recommend
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestMatchSkill(t *testing.T) {
+	tiling := Job{CategoryID: id(3), CategoryName: "Tiling"}
+	tests := []struct {
+		name       string
+		applicant  Applicant
+		job        Job
+		skill      float64
+		experience float64
+		reason     string
+	}{
+		{"veteran", Applicant{Skills: []Skill{{CategoryID: 3, Years: 8}}}, tiling, 1, 1, "8 years of Tiling experience"},
+		{"two years", Applicant{Skills: []Skill{{CategoryID: 3, Years: 2}}}, tiling, 1, 0.4, "2 years of Tiling experience"},
+		{"new to it", Applicant{Skills: []Skill{{CategoryID: 3}}}, tiling, 1, 0, ""},
+		{"other skill", Applicant{Skills: []Skill{{CategoryID: 4, Years: 8}}}, tiling, 0, 0, ""},
+		{"job without category", Applicant{Skills: []Skill{{CategoryID: 3, Years: 8}}}, Job{}, neutral, neutral, ""},
+	}
+	for _, tt := range tests {
+		r := Match(DefaultMatchWeights(), tt.applicant, tt.job)
+		skill, experience := factor(r, FactorSkill), factor(r, FactorExperience)
+		if math.Abs(skill.Score-tt.skill) > 1e-9 || math.Abs(experience.Score-tt.experience) > 1e-9 || experience.Reason != tt.reason {
+			t.Errorf("%s: got skill %v, experience %v %q; want %v, %v %q",
+				tt.name, skill.Score, experience.Score, experience.Reason, tt.skill, tt.experience, tt.reason)
+		}
+	}
+}
+
+func TestMatchRatingAndCompletion(t *testing.T) {
+	four := 4.0
+	tests := []struct {
+		name       string
+		applicant  Applicant
+		rating     float64
+		completion float64
+		reasons    []string
+	}{
+		{"unknown", Applicant{}, neutral, neutral, []string{}},
+		{"reliable", Applicant{Rating: &four, RatingCount: 3, Completed: 3, Dropped: 1}, 0.75, 0.75,
+			[]string{"Rated 4.0 from 3 jobs", "Completed 3 of 4 bookings"}},
+		{"walked off", Applicant{Dropped: 2}, neutral, 0, []string{"Completed 0 of 2 bookings"}},
+	}
+	for _, tt := range tests {
+		r := Match(DefaultMatchWeights(), tt.applicant, Job{})
+		if got := factor(r, FactorRating).Score; math.Abs(got-tt.rating) > 1e-9 {
+			t.Errorf("%s: rating %v, want %v", tt.name, got, tt.rating)
+		}
+		if got := factor(r, FactorCompletion).Score; math.Abs(got-tt.completion) > 1e-9 {
+			t.Errorf("%s: completion %v, want %v", tt.name, got, tt.completion)
+		}
+		if got := r.Reasons(); !slices.Equal(got, tt.reasons) {
+			t.Errorf("%s: reasons %q, want %q", tt.name, got, tt.reasons)
+		}
+	}
+}
+
+func TestMatchDistance(t *testing.T) {
+	job := Job{Location: "Kochi"}
+	near := Match(DefaultMatchWeights(), Applicant{Location: "Kochi, Kerala"}, job)
+	if f := factor(near, FactorDistance); f.Score != 1 || f.Reason != "Based in Kochi, Kerala" {
+		t.Errorf("same town: got %v %q", f.Score, f.Reason)
+	}
+	far := Match(DefaultMatchWeights(), Applicant{Location: "Delhi"}, job)
+	if near.Score <= far.Score {
+		t.Errorf("nearby applicant scores %v, not above %v", near.Score, far.Score)
+	}
+}
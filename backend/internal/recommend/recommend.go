@@ -0,0 +1,344 @@
+// Package recommend scores open jobs for a worker, and a job's applicants
+// for the employer.
+//
+// A job's score is a weighted mean of four factors, each between 0 and 1:
+// how well its category matches the worker's skills and past hires, how
+// far it is from them, how its pay compares with jobs they have applied
+// to, and how recently it was posted. An applicant's match (see Match)
+// weighs their skills, experience, rating, distance and completion rate
+// the same way. Both are pure: they see only the values passed in, so
+// weights can be tuned offline by replaying exported data through them.
+package recommend
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Weights says how much each factor counts and how quickly distance and
+// age wear a job's score down. Only the ratios between the four weights
+// matter.
+type Weights struct {
+	Category  float64 `json:"category"`
+	Distance  float64 `json:"distance"`
+	Pay       float64 `json:"pay"`
+	Freshness float64 `json:"freshness"`
+	// Distance at which the distance score falls to a half
+	DistanceHalfKm float64 `json:"distance_half_km"`
+	// Age at which the freshness score falls to a half
+	FreshnessHalfLife time.Duration `json:"freshness_half_life"`
+}
+
+// DefaultWeights favours category matches, then distance
+func DefaultWeights() Weights {
+	return Weights{
+		Category:          0.4,
+		Distance:          0.25,
+		Pay:               0.2,
+		Freshness:         0.15,
+		DistanceHalfKm:    10,
+		FreshnessHalfLife: 48 * time.Hour,
+	}
+}
+
+// Point is a position in degrees
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// Skill is a category the worker has experience in
+type Skill struct {
+	CategoryID   int    `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	Years        int    `json:"years"`
+}
+
+// Past is one of the worker's applications and how it went
+type Past struct {
+	CategoryID *int   `json:"category_id"`
+	Currency   string `json:"currency"`
+	HourlyRate *int64 `json:"hourly_rate"`
+	// Application status: pending, accepted, rejected and so on
+	Status string `json:"status"`
+}
+
+type Worker struct {
+	Skills   []Skill `json:"skills"`
+	Location string  `json:"location"`
+	// nil when the worker hasn't given coordinates
+	Point   *Point `json:"point"`
+	History []Past `json:"history"`
+}
+
+type Job struct {
+	CategoryID   *int   `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	Location     string `json:"location"`
+	Point        *Point `json:"point"`
+	Currency     string `json:"currency"`
+	// Minor units per hour; nil for jobs whose pay has no hourly rate
+	HourlyRate *int64    `json:"hourly_rate"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Factor names
+const (
+	FactorCategory  = "category"
+	FactorDistance  = "distance"
+	FactorPay       = "pay"
+	FactorFreshness = "freshness"
+)
+
+// Factor is one part of a job's score
+type Factor struct {
+	Name string `json:"name"`
+	// Between 0 and 1, before weighting
+	Score  float64 `json:"score"`
+	Weight float64 `json:"weight"`
+	// Why, for the worker to read; empty when there was nothing to go on
+	Reason string `json:"reason"`
+}
+
+type Result struct {
+	// Weighted mean of the factors, between 0 and 1
+	Score   float64  `json:"score"`
+	Factors []Factor `json:"factors"`
+}
+
+// Reasons returns the factors' reasons, strongest contribution first
+func (r Result) Reasons() []string {
+	factors := slices.Clone(r.Factors)
+	slices.SortStableFunc(factors, func(a, b Factor) int {
+		return cmp.Compare(b.Score*b.Weight, a.Score*a.Weight)
+	})
+	reasons := []string{}
+	for _, f := range factors {
+		if f.Reason != "" {
+			reasons = append(reasons, f.Reason)
+		}
+	}
+	return reasons
+}
+
+// Neutral score for a factor there is no information about, so missing
+// data neither helps nor hurts a job
+const neutral = 0.5
+
+// Score rates job for worker as of now
+func Score(w Weights, worker Worker, job Job, now time.Time) Result {
+	factors := []Factor{
+		category(worker, job),
+		distance(w, worker, job),
+		payFactor(worker, job),
+		freshness(w, job, now),
+	}
+	return weigh(factors, map[string]float64{
+		FactorCategory:  w.Category,
+		FactorDistance:  w.Distance,
+		FactorPay:       w.Pay,
+		FactorFreshness: w.Freshness,
+	})
+}
+
+// weigh sets each factor's weight and takes the weighted mean of their
+// scores
+func weigh(factors []Factor, weights map[string]float64) Result {
+	var total, sum float64
+	for i := range factors {
+		factors[i].Weight = weights[factors[i].Name]
+		total += factors[i].Weight
+		sum += factors[i].Weight * factors[i].Score
+	}
+	result := Result{Factors: factors}
+	if total > 0 {
+		result.Score = sum / total
+	}
+	return result
+}
+
+// category scores a skill in the job's category by years of experience,
+// and falls back on how the worker's applications in it went
+func category(worker Worker, job Job) Factor {
+	f := Factor{Name: FactorCategory}
+	if job.CategoryID == nil {
+		return f
+	}
+	name := job.CategoryName
+	if name == "" {
+		name = "this category"
+	}
+
+	for _, s := range worker.Skills {
+		if s.CategoryID == *job.CategoryID {
+			f.Score = 0.6 + 0.4*math.Min(float64(s.Years), 5)/5
+			f.Reason = fmt.Sprintf("Matches your %s skill", name)
+			if s.Years > 0 {
+				f.Reason += fmt.Sprintf(" (%s)", plural(s.Years, "year"))
+			}
+			break
+		}
+	}
+
+	var hired, applied, rejected int
+	for _, p := range worker.History {
+		if p.CategoryID == nil || *p.CategoryID != *job.CategoryID {
+			continue
+		}
+		switch p.Status {
+		case "accepted":
+			hired++
+		case "rejected":
+			rejected++
+		default:
+			applied++
+		}
+	}
+	var score float64
+	var reason string
+	switch {
+	case hired > 0:
+		score, reason = 0.8, fmt.Sprintf("You've been hired for %s in %s before", plural(hired, "job"), name)
+	case applied > 0:
+		score, reason = 0.5, fmt.Sprintf("You've applied to %s jobs before", name)
+	case rejected > 0:
+		score = 0.2
+	}
+	if score > f.Score {
+		f.Score, f.Reason = score, reason
+	}
+	return f
+}
+
+// distance halves the score every DistanceHalfKm, or compares the location
+// text when either side has no coordinates
+func distance(w Weights, worker Worker, job Job) Factor {
+	return nearness(w.DistanceHalfKm, worker.Location, worker.Point, job.Location, job.Point, "In "+job.Location+", near you")
+}
+
+// nearness scores how close two places are, by coordinates when both have
+// them and otherwise by whether one location name contains the other, in
+// which case sameTown is the reason
+func nearness(halfKm float64, fromName string, from *Point, toName string, to *Point, sameTown string) Factor {
+	f := Factor{Name: FactorDistance, Score: neutral}
+	if from != nil && to != nil {
+		km := DistanceKm(*from, *to)
+		f.Score = 1
+		if halfKm > 0 {
+			f.Score = 1 / (1 + km/halfKm)
+		}
+		if km < 1 {
+			f.Reason = "Less than 1 km away"
+		} else {
+			f.Reason = fmt.Sprintf("%.0f km away", km)
+		}
+		return f
+	}
+
+	a := strings.ToLower(strings.TrimSpace(fromName))
+	b := strings.ToLower(strings.TrimSpace(toName))
+	if a == "" || b == "" {
+		return f
+	}
+	if strings.Contains(a, b) || strings.Contains(b, a) {
+		f.Score = 1
+		f.Reason = sameTown
+		return f
+	}
+	f.Score = 0
+	return f
+}
+
+// payFactor compares the job's hourly rate with the median of jobs the
+// worker was hired for, or else applied to, in the same currency. Twice
+// the median or more scores 1.
+func payFactor(worker Worker, job Job) Factor {
+	f := Factor{Name: FactorPay, Score: neutral}
+	if job.HourlyRate == nil {
+		return f
+	}
+
+	var hired, applied []int64
+	for _, p := range worker.History {
+		if p.HourlyRate == nil || p.Currency != job.Currency {
+			continue
+		}
+		applied = append(applied, *p.HourlyRate)
+		if p.Status == "accepted" {
+			hired = append(hired, *p.HourlyRate)
+		}
+	}
+	rates, than := hired, "jobs you've been hired for"
+	if len(rates) == 0 {
+		rates, than = applied, "jobs you've applied to"
+	}
+	if len(rates) == 0 {
+		return f
+	}
+
+	usual := median(rates)
+	if usual <= 0 {
+		return f
+	}
+	ratio := float64(*job.HourlyRate) / usual
+	f.Score = math.Min(ratio/2, 1)
+	switch change := math.Round((ratio - 1) * 100); {
+	case change >= 5:
+		f.Reason = fmt.Sprintf("Pays %.0f%% more than %s", change, than)
+	case change <= -5:
+		f.Reason = fmt.Sprintf("Pays %.0f%% less than %s", -change, than)
+	default:
+		f.Reason = "Pays about the same as " + than
+	}
+	return f
+}
+
+// freshness halves the score every FreshnessHalfLife since the job was
+// posted
+func freshness(w Weights, job Job, now time.Time) Factor {
+	age := max(now.Sub(job.CreatedAt), 0)
+	f := Factor{Name: FactorFreshness, Score: 1}
+	if w.FreshnessHalfLife > 0 {
+		f.Score = math.Pow(0.5, float64(age)/float64(w.FreshnessHalfLife))
+	}
+	switch {
+	case age < time.Hour:
+		f.Reason = "Posted in the last hour"
+	case age < 24*time.Hour:
+		f.Reason = fmt.Sprintf("Posted %s ago", plural(int(age/time.Hour), "hour"))
+	default:
+		f.Reason = fmt.Sprintf("Posted %s ago", plural(int(age/(24*time.Hour)), "day"))
+	}
+	return f
+}
+
+// DistanceKm is the great-circle distance between a and b
+func DistanceKm(a, b Point) float64 {
+	const earthRadiusKm = 6371
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := rad(b.Lat - a.Lat)
+	dLng := rad(b.Lng - a.Lng)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(a.Lat))*math.Cos(rad(b.Lat))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func median(values []int64) float64 {
+	sorted := slices.Sorted(slices.Values(values))
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return float64(sorted[mid])
+	}
+	return float64(sorted[mid-1]+sorted[mid]) / 2
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,164 @@
+package recommend
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func id(v int) *int       { return &v }
+func rate(v int64) *int64 { return &v }
+
+var now = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func factor(r Result, name string) Factor {
+	for _, f := range r.Factors {
+		if f.Name == name {
+			return f
+		}
+	}
+	return Factor{}
+}
+
+func TestCategory(t *testing.T) {
+	plumbing := Job{CategoryID: id(3), CategoryName: "Plumbing", CreatedAt: now}
+	tests := []struct {
+		name   string
+		worker Worker
+		score  float64
+		reason string
+	}{
+		{"no skill or history", Worker{}, 0, ""},
+		{"skill", Worker{Skills: []Skill{{CategoryID: 3, Years: 5}}}, 1, "Matches your Plumbing skill (5 years)"},
+		{"new skill", Worker{Skills: []Skill{{CategoryID: 3}}}, 0.6, "Matches your Plumbing skill"},
+		{"hired before", Worker{History: []Past{{CategoryID: id(3), Status: "accepted"}}}, 0.8, "You've been hired for 1 job in Plumbing before"},
+		{"applied before", Worker{History: []Past{{CategoryID: id(3), Status: "pending"}}}, 0.5, "You've applied to Plumbing jobs before"},
+		{"only rejected", Worker{History: []Past{{CategoryID: id(3), Status: "rejected"}}}, 0.2, ""},
+		{"other category", Worker{Skills: []Skill{{CategoryID: 4, Years: 5}}}, 0, ""},
+	}
+	for _, tt := range tests {
+		got := factor(Score(DefaultWeights(), tt.worker, plumbing, now), FactorCategory)
+		if math.Abs(got.Score-tt.score) > 1e-9 || got.Reason != tt.reason {
+			t.Errorf("%s: got %v %q, want %v %q", tt.name, got.Score, got.Reason, tt.score, tt.reason)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	kochi := &Point{Lat: 9.9312, Lng: 76.2673}
+	aluva := &Point{Lat: 10.1004, Lng: 76.3570}
+	tests := []struct {
+		name        string
+		worker      Worker
+		job         Job
+		score       float64
+		reason      string
+		approximate bool
+	}{
+		{"same place", Worker{Point: kochi}, Job{Point: kochi}, 1, "Less than 1 km away", false},
+		{"twice the half distance", Worker{Point: kochi}, Job{Point: aluva}, 0.32, "21 km away", true},
+		{"same town by name", Worker{Location: "kochi"}, Job{Location: "Kochi, Kerala"}, 1, "In Kochi, Kerala, near you", false},
+		{"another town", Worker{Location: "Delhi"}, Job{Location: "Kochi"}, 0, "", false},
+		{"unknown", Worker{}, Job{Location: "Kochi"}, neutral, "", false},
+	}
+	for _, tt := range tests {
+		tt.job.CreatedAt = now
+		got := factor(Score(DefaultWeights(), tt.worker, tt.job, now), FactorDistance)
+		tolerance := 1e-9
+		if tt.approximate {
+			tolerance = 0.01
+		}
+		if math.Abs(got.Score-tt.score) > tolerance || got.Reason != tt.reason {
+			t.Errorf("%s: got %v %q, want %v %q", tt.name, got.Score, got.Reason, tt.score, tt.reason)
+		}
+	}
+}
+
+func TestPay(t *testing.T) {
+	history := []Past{
+		{Currency: "INR", HourlyRate: rate(10000), Status: "accepted"},
+		{Currency: "INR", HourlyRate: rate(30000), Status: "accepted"},
+		{Currency: "INR", HourlyRate: rate(90000), Status: "rejected"},
+		{Currency: "USD", HourlyRate: rate(2000), Status: "accepted"},
+	}
+	tests := []struct {
+		name    string
+		history []Past
+		job     Job
+		score   float64
+		reason  string
+	}{
+		{"above hires", history, Job{Currency: "INR", HourlyRate: rate(25000)}, 0.625, "Pays 25% more than jobs you've been hired for"},
+		{"same as hires", history, Job{Currency: "INR", HourlyRate: rate(20000)}, 0.5, "Pays about the same as jobs you've been hired for"},
+		{"double or more", history, Job{Currency: "INR", HourlyRate: rate(60000)}, 1, "Pays 200% more than jobs you've been hired for"},
+		{"only applications", history[2:3], Job{Currency: "INR", HourlyRate: rate(45000)}, 0.25, "Pays 50% less than jobs you've applied to"},
+		{"other currency", history[:3], Job{Currency: "USD", HourlyRate: rate(2000)}, neutral, ""},
+		{"no rate", history, Job{Currency: "INR"}, neutral, ""},
+	}
+	for _, tt := range tests {
+		tt.job.CreatedAt = now
+		got := factor(Score(DefaultWeights(), Worker{History: tt.history}, tt.job, now), FactorPay)
+		if math.Abs(got.Score-tt.score) > 1e-9 || got.Reason != tt.reason {
+			t.Errorf("%s: got %v %q, want %v %q", tt.name, got.Score, got.Reason, tt.score, tt.reason)
+		}
+	}
+}
+
+func TestFreshness(t *testing.T) {
+	tests := []struct {
+		age    time.Duration
+		score  float64
+		reason string
+	}{
+		{0, 1, "Posted in the last hour"},
+		{5 * time.Hour, math.Pow(0.5, 5.0/48), "Posted 5 hours ago"},
+		{48 * time.Hour, 0.5, "Posted 2 days ago"},
+		{-time.Hour, 1, "Posted in the last hour"},
+	}
+	for _, tt := range tests {
+		got := factor(Score(DefaultWeights(), Worker{}, Job{CreatedAt: now.Add(-tt.age)}, now), FactorFreshness)
+		if math.Abs(got.Score-tt.score) > 1e-9 || got.Reason != tt.reason {
+			t.Errorf("age %v: got %v %q, want %v %q", tt.age, got.Score, got.Reason, tt.score, tt.reason)
+		}
+	}
+}
+
+func TestWeights(t *testing.T) {
+	worker := Worker{Skills: []Skill{{CategoryID: 3, Years: 5}}, Location: "Delhi"}
+	job := Job{CategoryID: id(3), CategoryName: "Plumbing", Location: "Kochi", CreatedAt: now}
+
+	// Category 1, distance 0, pay 0.5, freshness 1
+	if got := Score(DefaultWeights(), worker, job, now).Score; math.Abs(got-0.65) > 1e-9 {
+		t.Errorf("default weights: got %v, want 0.65", got)
+	}
+	onlyDistance := Weights{Distance: 1}
+	if got := Score(onlyDistance, worker, job, now).Score; got != 0 {
+		t.Errorf("distance only: got %v, want 0", got)
+	}
+	if got := Score(Weights{}, worker, job, now).Score; got != 0 {
+		t.Errorf("no weights: got %v, want 0", got)
+	}
+}
+
+func TestReasonsByContribution(t *testing.T) {
+	worker := Worker{Skills: []Skill{{CategoryID: 3, Years: 5}}, Location: "Kochi"}
+	job := Job{CategoryID: id(3), CategoryName: "Plumbing", Location: "Kochi", CreatedAt: now.Add(-3 * time.Hour)}
+	got := Score(DefaultWeights(), worker, job, now).Reasons()
+	want := []string{"Matches your Plumbing skill (5 years)", "In Kochi, near you", "Posted 3 hours ago"}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("reason %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDistanceKm(t *testing.T) {
+	london := Point{Lat: 51.5074, Lng: -0.1278}
+	paris := Point{Lat: 48.8566, Lng: 2.3522}
+	if got := DistanceKm(london, paris); math.Abs(got-343.5) > 1 {
+		t.Errorf("London to Paris: got %.1f km, want about 343.5", got)
+	}
+}
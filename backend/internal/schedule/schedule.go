@@ -0,0 +1,179 @@
+// Package schedule works out whether a worker is free for a job.
+//
+// A worker's free time is a set of weekly slots in their own time zone,
+// plus one-off exceptions that add or block out time, minus the gigs they
+// have already been booked for. Everything here is pure, so the rules can
+// be tested without a database.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ErrBadClock is returned by ParseClock for anything that isn't HH:MM
+var ErrBadClock = errors.New("time of day must be HH:MM between 00:00 and 24:00")
+
+// Clock is a time of day in minutes after midnight; 24*60 is the midnight
+// that ends a day
+type Clock int
+
+const EndOfDay Clock = 24 * 60
+
+// ParseClock reads "HH:MM". "24:00" is allowed so a slot can run to midnight.
+func ParseClock(s string) (Clock, error) {
+	if len(s) != 5 || s[2] != ':' {
+		return 0, ErrBadClock
+	}
+	digits := [4]byte{s[0], s[1], s[3], s[4]}
+	for _, d := range digits {
+		if d < '0' || d > '9' {
+			return 0, ErrBadClock
+		}
+	}
+	h := int(digits[0]-'0')*10 + int(digits[1]-'0')
+	m := int(digits[2]-'0')*10 + int(digits[3]-'0')
+	c := Clock(h*60 + m)
+	if m > 59 || c > EndOfDay {
+		return 0, ErrBadClock
+	}
+	return c, nil
+}
+
+func (c Clock) String() string {
+	return fmt.Sprintf("%02d:%02d", c/60, c%60)
+}
+
+// Slot is a recurring weekly window, Start inclusive and End exclusive
+type Slot struct {
+	Weekday    time.Weekday
+	Start, End Clock
+}
+
+// Interval is a span of absolute time, Start inclusive and End exclusive
+type Interval struct {
+	Start, End time.Time
+}
+
+func (i Interval) overlaps(o Interval) bool {
+	return i.Start.Before(o.End) && o.Start.Before(i.End)
+}
+
+// Exception adds time to the weekly slots or, if !Available, blocks it out
+type Exception struct {
+	Interval
+	Available bool
+}
+
+// Availability is everything known about when one worker is free
+type Availability struct {
+	// Zone the weekly slots are in; nil means UTC
+	Location   *time.Location
+	Weekly     []Slot
+	Exceptions []Exception
+	// Gigs the worker is already booked for
+	Busy []Interval
+}
+
+// Covers reports whether the worker is free for the whole of want
+func (a Availability) Covers(want Interval) bool {
+	if !want.Start.Before(want.End) {
+		return false
+	}
+	free := a.free(want)
+	for _, i := range free {
+		if !i.Start.After(want.Start) && !i.End.Before(want.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// free returns the worker's free time around window as sorted, merged
+// intervals
+func (a Availability) free(window Interval) []Interval {
+	free := a.weekly(window)
+	for _, e := range a.Exceptions {
+		if e.Available && e.overlaps(window) {
+			free = append(free, e.Interval)
+		}
+	}
+	free = merge(free)
+
+	for _, e := range a.Exceptions {
+		if !e.Available {
+			free = subtract(free, e.Interval)
+		}
+	}
+	for _, b := range a.Busy {
+		free = subtract(free, b)
+	}
+	return free
+}
+
+// weekly expands the slots into the intervals they cover around window,
+// starting a day early so a slot that window begins part way through is
+// included. Days are walked by calendar date so DST changes are honoured.
+func (a Availability) weekly(window Interval) []Interval {
+	loc := a.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	start := window.Start.In(loc)
+	day := time.Date(start.Year(), start.Month(), start.Day()-1, 0, 0, 0, 0, loc)
+
+	var out []Interval
+	for ; day.Before(window.End); day = day.AddDate(0, 0, 1) {
+		for _, s := range a.Weekly {
+			if s.Weekday != day.Weekday() {
+				continue
+			}
+			i := Interval{at(day, s.Start), at(day, s.End)}
+			if i.overlaps(window) {
+				out = append(out, i)
+			}
+		}
+	}
+	return out
+}
+
+// at returns the instant c falls at on day, in day's location
+func at(day time.Time, c Clock) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), int(c/60), int(c%60), 0, 0, day.Location())
+}
+
+// merge sorts intervals and joins those that overlap or touch
+func merge(intervals []Interval) []Interval {
+	slices.SortFunc(intervals, func(a, b Interval) int { return a.Start.Compare(b.Start) })
+	var out []Interval
+	for _, i := range intervals {
+		if n := len(out); n > 0 && !i.Start.After(out[n-1].End) {
+			if i.End.After(out[n-1].End) {
+				out[n-1].End = i.End
+			}
+			continue
+		}
+		out = append(out, i)
+	}
+	return out
+}
+
+// subtract removes cut from each of intervals
+func subtract(intervals []Interval, cut Interval) []Interval {
+	var out []Interval
+	for _, i := range intervals {
+		if !i.overlaps(cut) {
+			out = append(out, i)
+			continue
+		}
+		if i.Start.Before(cut.Start) {
+			out = append(out, Interval{i.Start, cut.Start})
+		}
+		if cut.End.Before(i.End) {
+			out = append(out, Interval{cut.End, i.End})
+		}
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseClock(t *testing.T) {
+	valid := map[string]Clock{"00:00": 0, "09:30": 570, "23:59": 1439, "24:00": EndOfDay}
+	for s, want := range valid {
+		if got, err := ParseClock(s); err != nil || got != want {
+			t.Errorf("ParseClock(%q) = %v, %v; want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "9:30", "09:60", "24:01", "25:00", "-1:00", "09-30", "+9:30"} {
+		if _, err := ParseClock(s); !errors.Is(err, ErrBadClock) {
+			t.Errorf("ParseClock(%q) = %v, want ErrBadClock", s, err)
+		}
+	}
+	if s := Clock(570).String(); s != "09:30" {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestCovers(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	// Mondays 09:00-17:00 and Tuesdays 09:00-midnight in Kolkata;
+	// 2026-03-02 is a Monday
+	weekly := []Slot{
+		{time.Monday, 9 * 60, 17 * 60},
+		{time.Tuesday, 9 * 60, EndOfDay},
+		{time.Wednesday, 0, 6 * 60},
+	}
+	day := func(d, h, m int) time.Time { return time.Date(2026, 3, d, h, m, 0, 0, kolkata) }
+	span := func(from, to time.Time) Interval { return Interval{from, to} }
+
+	base := Availability{Location: kolkata, Weekly: weekly}
+	tests := []struct {
+		name  string
+		avail Availability
+		want  Interval
+		ok    bool
+	}{
+		{"inside a slot", base, span(day(2, 10, 0), day(2, 12, 0)), true},
+		{"exactly a slot", base, span(day(2, 9, 0), day(2, 17, 0)), true},
+		{"past the slot's end", base, span(day(2, 16, 0), day(2, 18, 0)), false},
+		{"a week later", base, span(day(9, 10, 0), day(9, 12, 0)), true},
+		{"day off", base, span(day(4, 10, 0), day(4, 12, 0)), false},
+		{"across midnight into the next slot", base, span(day(3, 22, 0), day(4, 5, 0)), true},
+		{"other time zone", base, span(time.Date(2026, 3, 2, 5, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC)), true},
+		{"empty interval", base, span(day(2, 10, 0), day(2, 10, 0)), false},
+		{
+			"time off",
+			Availability{Location: kolkata, Weekly: weekly, Exceptions: []Exception{{span(day(2, 11, 0), day(2, 13, 0)), false}}},
+			span(day(2, 10, 0), day(2, 12, 0)), false,
+		},
+		{
+			"time off elsewhere",
+			Availability{Location: kolkata, Weekly: weekly, Exceptions: []Exception{{span(day(2, 14, 0), day(2, 15, 0)), false}}},
+			span(day(2, 10, 0), day(2, 12, 0)), true,
+		},
+		{
+			"extra hours extend a slot",
+			Availability{Location: kolkata, Weekly: weekly, Exceptions: []Exception{{span(day(2, 17, 0), day(2, 20, 0)), true}}},
+			span(day(2, 16, 0), day(2, 19, 0)), true,
+		},
+		{
+			"already booked",
+			Availability{Location: kolkata, Weekly: weekly, Busy: []Interval{span(day(2, 11, 30), day(2, 14, 0))}},
+			span(day(2, 10, 0), day(2, 12, 0)), false,
+		},
+		{"no slots", Availability{}, span(day(2, 10, 0), day(2, 12, 0)), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.avail.Covers(tt.want); got != tt.ok {
+				t.Errorf("Covers = %v, want %v", got, tt.ok)
+			}
+		})
+	}
+}
+
+// A slot's wall-clock times hold across a DST change
+func TestCoversAcrossDST(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	a := Availability{Location: london, Weekly: []Slot{{time.Sunday, 8 * 60, 10 * 60}}}
+	// Clocks went forward on 2026-03-29, a Sunday
+	for _, d := range []int{22, 29} {
+		want := Interval{time.Date(2026, 3, d, 8, 0, 0, 0, london), time.Date(2026, 3, d, 10, 0, 0, 0, london)}
+		if !a.Covers(want) {
+			t.Errorf("March %d 08:00-10:00 not covered", d)
+		}
+	}
+}
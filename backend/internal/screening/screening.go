@@ -0,0 +1,181 @@
+// Package screening checks the questions an employer asks everyone who
+// applies to a job, and applicants' answers to them.
+//
+// A question takes a yes/no answer, a number, free text or one of a list
+// of choices, and may be required. Its knockouts say which answers
+// disqualify an applicant: one of yes or no, a number outside a range, or
+// some of the choices. Check normalises the answers and marks the ones
+// that were knocked out; the caller rejects those applications.
+package screening
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+)
+
+// Error is a problem with one field of the questions or answers
+type Error struct {
+	// Path of the field, e.g. "questions[1].reject_choices"
+	Field   string
+	Rule    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// CheckQuestions checks that each question's choices and knockouts suit
+// its kind. A question with a knockout must be required, or applicants
+// could get past it by leaving it blank.
+func CheckQuestions(questions []models.Question) *Error {
+	for i, q := range questions {
+		field := func(name string) string { return fmt.Sprintf("questions[%d].%s", i, name) }
+		if err := checkQuestion(q, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkQuestion(q models.Question, field func(string) string) *Error {
+	if strings.TrimSpace(q.Prompt) == "" {
+		return &Error{field("prompt"), "required", "prompt is required"}
+	}
+	if !q.Kind.Valid() {
+		return &Error{field("kind"), "question_kind", "kind must be yes_no, number, text or choice"}
+	}
+
+	// Knockouts that belong to another kind
+	switch {
+	case q.RejectAnswer != nil && q.Kind != models.QuestionYesNo:
+		return &Error{field("reject_answer"), "excluded_unless", "Only yes/no questions have a reject_answer"}
+	case q.MinValue != nil && q.Kind != models.QuestionNumber:
+		return &Error{field("min_value"), "excluded_unless", "Only number questions have a min_value"}
+	case q.MaxValue != nil && q.Kind != models.QuestionNumber:
+		return &Error{field("max_value"), "excluded_unless", "Only number questions have a max_value"}
+	case len(q.Choices) > 0 && q.Kind != models.QuestionChoice:
+		return &Error{field("choices"), "excluded_unless", "Only choice questions have choices"}
+	case len(q.RejectChoices) > 0 && q.Kind != models.QuestionChoice:
+		return &Error{field("reject_choices"), "excluded_unless", "Only choice questions have reject_choices"}
+	}
+
+	switch q.Kind {
+	case models.QuestionNumber:
+		for _, v := range []*float64{q.MinValue, q.MaxValue} {
+			if v != nil && (math.IsNaN(*v) || math.IsInf(*v, 0)) {
+				return &Error{field("min_value"), "number", "Limits must be finite numbers"}
+			}
+		}
+		if q.MinValue != nil && q.MaxValue != nil && *q.MinValue > *q.MaxValue {
+			return &Error{field("max_value"), "gtefield", "max_value must be at least min_value"}
+		}
+	case models.QuestionChoice:
+		if len(q.Choices) < 2 {
+			return &Error{field("choices"), "min", "A choice question needs at least 2 choices"}
+		}
+		for i, c := range q.Choices {
+			if strings.TrimSpace(c) == "" {
+				return &Error{field("choices"), "required", "Choices can't be blank"}
+			}
+			if slices.Contains(q.Choices[:i], c) {
+				return &Error{field("choices"), "unique", fmt.Sprintf("%q is listed twice", c)}
+			}
+		}
+		rejected := 0
+		for i, c := range q.RejectChoices {
+			if !slices.Contains(q.Choices, c) {
+				return &Error{field("reject_choices"), "oneof", fmt.Sprintf("%q is not one of the choices", c)}
+			}
+			if !slices.Contains(q.RejectChoices[:i], c) {
+				rejected++
+			}
+		}
+		if rejected == len(q.Choices) {
+			return &Error{field("reject_choices"), "max", "At least one choice must pass"}
+		}
+	}
+
+	if q.HasKnockout() && !q.Required {
+		return &Error{field("required"), "required_with", "A question with a knockout must be required"}
+	}
+	return nil
+}
+
+// Given is an applicant's answer as they sent it
+type Given struct {
+	QuestionID int
+	Answer     string
+}
+
+// Check matches the given answers to questions and normalises them: yes/no
+// answers to "yes" or "no", numbers to their shortest form, text trimmed
+// and choices to the choice as the employer wrote it. Blank answers to
+// optional questions are dropped. The answers come back in question
+// order, each marked with whether it hit a knockout.
+func Check(questions []models.Question, given []Given) ([]models.Answer, *Error) {
+	byQuestion := map[int]string{}
+	for i, g := range given {
+		field := fmt.Sprintf("answers[%d].question_id", i)
+		if !slices.ContainsFunc(questions, func(q models.Question) bool { return q.ID == g.QuestionID }) {
+			return nil, &Error{field, "oneof", fmt.Sprintf("Question %d isn't on this job", g.QuestionID)}
+		}
+		if _, ok := byQuestion[g.QuestionID]; ok {
+			return nil, &Error{field, "unique", fmt.Sprintf("Question %d is answered twice", g.QuestionID)}
+		}
+		byQuestion[g.QuestionID] = g.Answer
+	}
+
+	answers := []models.Answer{}
+	for _, q := range questions {
+		raw := strings.TrimSpace(byQuestion[q.ID])
+		if raw == "" {
+			if q.Required {
+				return nil, &Error{"answers", "required", fmt.Sprintf("%q must be answered", q.Prompt)}
+			}
+			continue
+		}
+		answer, err := check(q, raw)
+		if err != nil {
+			i := slices.IndexFunc(given, func(g Given) bool { return g.QuestionID == q.ID })
+			err.Field = fmt.Sprintf("answers[%d].answer", i)
+			return nil, err
+		}
+		answers = append(answers, answer)
+	}
+	return answers, nil
+}
+
+// check normalises one non-blank answer to q; the error's field is left
+// for the caller
+func check(q models.Question, raw string) (models.Answer, *Error) {
+	a := models.Answer{QuestionID: q.ID, Prompt: q.Prompt, Answer: raw}
+	switch q.Kind {
+	case models.QuestionYesNo:
+		a.Answer = strings.ToLower(raw)
+		if a.Answer != "yes" && a.Answer != "no" {
+			return a, &Error{Rule: "oneof", Message: fmt.Sprintf("Answer %q with yes or no", q.Prompt)}
+		}
+		a.KnockedOut = q.RejectAnswer != nil && *q.RejectAnswer == (a.Answer == "yes")
+	case models.QuestionNumber:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return a, &Error{Rule: "number", Message: fmt.Sprintf("Answer %q with a number", q.Prompt)}
+		}
+		a.Answer = strconv.FormatFloat(v, 'f', -1, 64)
+		a.KnockedOut = q.MinValue != nil && v < *q.MinValue || q.MaxValue != nil && v > *q.MaxValue
+	case models.QuestionChoice:
+		i := slices.IndexFunc(q.Choices, func(c string) bool { return strings.EqualFold(c, raw) })
+		if i < 0 {
+			return a, &Error{Rule: "oneof", Message: fmt.Sprintf("Answer %q with one of: %s", q.Prompt, strings.Join(q.Choices, ", "))}
+		}
+		a.Answer = q.Choices[i]
+		a.KnockedOut = slices.Contains(q.RejectChoices, a.Answer)
+	}
+	return a, nil
+}
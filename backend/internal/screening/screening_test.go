@@ -0,0 +1,116 @@
+package screening
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+)
+
+func yes() *bool                  { v := true; return &v }
+func no() *bool                   { v := false; return &v }
+func num(v float64) *float64      { return &v }
+func choice(c ...string) []string { return c }
+
+func TestCheckQuestions(t *testing.T) {
+	tests := []struct {
+		name     string
+		question models.Question
+		field    string
+	}{
+		{"plain yes/no", models.Question{Prompt: "Own a ladder?", Kind: models.QuestionYesNo}, ""},
+		{"yes/no knockout", models.Question{Prompt: "Own a ladder?", Kind: models.QuestionYesNo, Required: true, RejectAnswer: no()}, ""},
+		{"knocked out by yes", models.Question{Prompt: "Afraid of heights?", Kind: models.QuestionYesNo, Required: true, RejectAnswer: yes()}, ""},
+		{"optional knockout", models.Question{Prompt: "Own a ladder?", Kind: models.QuestionYesNo, RejectAnswer: no()}, "questions[0].required"},
+		{"blank prompt", models.Question{Prompt: " ", Kind: models.QuestionText}, "questions[0].prompt"},
+		{"unknown kind", models.Question{Prompt: "Why?", Kind: "essay"}, "questions[0].kind"},
+		{"number range", models.Question{Prompt: "Years?", Kind: models.QuestionNumber, Required: true, MinValue: num(2), MaxValue: num(40)}, ""},
+		{"backwards range", models.Question{Prompt: "Years?", Kind: models.QuestionNumber, Required: true, MinValue: num(5), MaxValue: num(2)}, "questions[0].max_value"},
+		{"range on text", models.Question{Prompt: "Why?", Kind: models.QuestionText, Required: true, MinValue: num(1)}, "questions[0].min_value"},
+		{"choices on yes/no", models.Question{Prompt: "Own a ladder?", Kind: models.QuestionYesNo, Choices: choice("Yes", "No")}, "questions[0].choices"},
+		{"choice", models.Question{Prompt: "Shift?", Kind: models.QuestionChoice, Required: true, Choices: choice("Day", "Night"), RejectChoices: choice("Night")}, ""},
+		{"one choice", models.Question{Prompt: "Shift?", Kind: models.QuestionChoice, Choices: choice("Day")}, "questions[0].choices"},
+		{"repeated choice", models.Question{Prompt: "Shift?", Kind: models.QuestionChoice, Choices: choice("Day", "Day")}, "questions[0].choices"},
+		{"rejects unknown choice", models.Question{Prompt: "Shift?", Kind: models.QuestionChoice, Required: true, Choices: choice("Day", "Night"), RejectChoices: choice("Evening")}, "questions[0].reject_choices"},
+		{"rejects every choice", models.Question{Prompt: "Shift?", Kind: models.QuestionChoice, Required: true, Choices: choice("Day", "Night"), RejectChoices: choice("Night", "Day")}, "questions[0].reject_choices"},
+	}
+	for _, tt := range tests {
+		err := CheckQuestions([]models.Question{tt.question})
+		switch {
+		case err == nil && tt.field != "":
+			t.Errorf("%s: no error, want one on %s", tt.name, tt.field)
+		case err != nil && err.Field != tt.field:
+			t.Errorf("%s: error on %s (%s), want %q", tt.name, err.Field, err.Message, tt.field)
+		}
+	}
+}
+
+var questions = []models.Question{
+	{ID: 1, Prompt: "Do you own a ladder?", Kind: models.QuestionYesNo, Required: true, RejectAnswer: no()},
+	{ID: 2, Prompt: "Years of tiling experience?", Kind: models.QuestionNumber, Required: true, MinValue: num(2)},
+	{ID: 3, Prompt: "Anything else?", Kind: models.QuestionText},
+	{ID: 4, Prompt: "Which shift?", Kind: models.QuestionChoice, Choices: choice("Day", "Night")},
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		given []Given
+		// Normalised answers, and which of them were knocked out
+		answers    []string
+		knockedOut []int
+		field      string
+	}{
+		{"passes", []Given{{1, "Yes"}, {2, " 3.50 "}, {4, "night"}}, []string{"yes", "3.5", "Night"}, nil, ""},
+		{"optional answered", []Given{{1, "yes"}, {2, "2"}, {3, "  Own van  "}}, []string{"yes", "2", "Own van"}, nil, ""},
+		{"no ladder", []Given{{1, "no"}, {2, "10"}}, []string{"no", "10"}, []int{1}, ""},
+		{"too new", []Given{{1, "yes"}, {2, "1"}}, []string{"yes", "1"}, []int{2}, ""},
+		{"blank optional dropped", []Given{{1, "yes"}, {2, "4"}, {3, "   "}}, []string{"yes", "4"}, nil, ""},
+		{"required missing", []Given{{2, "4"}}, nil, nil, "answers"},
+		{"required blank", []Given{{1, " "}, {2, "4"}}, nil, nil, "answers"},
+		{"not yes or no", []Given{{2, "4"}, {1, "maybe"}}, nil, nil, "answers[1].answer"},
+		{"not a number", []Given{{1, "yes"}, {2, "lots"}}, nil, nil, "answers[1].answer"},
+		{"infinite", []Given{{1, "yes"}, {2, "Inf"}}, nil, nil, "answers[1].answer"},
+		{"not a choice", []Given{{1, "yes"}, {2, "4"}, {4, "Evening"}}, nil, nil, "answers[2].answer"},
+		{"other job's question", []Given{{1, "yes"}, {9, "4"}}, nil, nil, "answers[1].question_id"},
+		{"answered twice", []Given{{1, "yes"}, {1, "no"}}, nil, nil, "answers[1].question_id"},
+	}
+	for _, tt := range tests {
+		answers, err := Check(questions, tt.given)
+		if err != nil || tt.field != "" {
+			if err == nil || err.Field != tt.field {
+				t.Errorf("%s: got error %v, want one on %q", tt.name, err, tt.field)
+			}
+			continue
+		}
+		var got []string
+		var knockedOut []int
+		for _, a := range answers {
+			got = append(got, a.Answer)
+			if a.KnockedOut {
+				knockedOut = append(knockedOut, a.QuestionID)
+			}
+		}
+		if !slices.Equal(got, tt.answers) || !slices.Equal(knockedOut, tt.knockedOut) {
+			t.Errorf("%s: got %q knocked out by %v, want %q by %v", tt.name, got, knockedOut, tt.answers, tt.knockedOut)
+		}
+	}
+}
+
+func TestCheckWithoutQuestions(t *testing.T) {
+	answers, err := Check(nil, nil)
+	if err != nil || answers == nil || len(answers) != 0 {
+		t.Errorf("got %v, %v; want no answers", answers, err)
+	}
+}
+
+func TestKnockoutChoices(t *testing.T) {
+	q := []models.Question{{ID: 1, Prompt: "Shift?", Kind: models.QuestionChoice, Required: true,
+		Choices: choice("Day", "Night", "Either"), RejectChoices: choice("Night")}}
+	for answer, want := range map[string]bool{"day": false, "Night": true, "EITHER": false} {
+		got, err := Check(q, []Given{{1, answer}})
+		if err != nil || got[0].KnockedOut != want {
+			t.Errorf("%q: got %v, %v; want knocked out %v", answer, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+)
+
+// newApplicantsRouter makes job 1 a plumbing job in Town with two
+// applicants: worker 2, a local plumber who applied first, and worker 3,
+// who applied later with neither
+func newApplicantsRouter(t *testing.T) (employer, worker apiClient) {
+	t.Helper()
+	deps := newFakeDeps(t)
+	plumbing, name, town := 2, "Plumbing", "Town"
+	job := deps.Jobs.(*fakeJobs).byID[1]
+	job.CategoryID, job.CategoryName = &plumbing, &name
+
+	users := deps.Users.(*fakeUsers)
+	users.byID[2].Location = &town
+	users.skills[2] = []models.WorkerSkill{{CategoryID: plumbing, CategoryName: name, ExperienceYears: 6}}
+	users.byID[3] = &models.User{ID: 3, Email: "new@example.com", FullName: "Newcomer", UserType: models.RoleWorker,
+		Roles: []models.Role{models.RoleWorker}, CreatedAt: time.Now()}
+	deps.Applications.(*fakeApplications).byID[3] = &models.Application{ID: 3, JobID: 1, WorkerID: 3, Status: models.ApplicationPending,
+		AppliedAt: time.Now(), UpdatedAt: time.Now()}
+
+	clients := newClients(t, deps, token(t, 1, models.RoleEmployer), token(t, 2, models.RoleWorker))
+	return clients[0], clients[1]
+}
+
+type applicantList struct {
+	Applications []struct {
+		ID    int `json:"id"`
+		Match struct {
+			Reasons []string `json:"reasons"`
+		} `json:"match"`
+	} `json:"applications"`
+}
+
+func listApplicants(t *testing.T, client apiClient, query string) applicantList {
+	t.Helper()
+	code, body := sendJSON(client, "GET", "/api/applications/job/1"+query, nil)
+	if code != http.StatusOK {
+		t.Fatalf("GET %s: status = %d; body: %s", query, code, body)
+	}
+	var list applicantList
+	json.Unmarshal(body, &list)
+	return list
+}
+
+func applicantIDs(t *testing.T, client apiClient, query string) []int {
+	t.Helper()
+	var ids []int
+	for _, a := range listApplicants(t, client, query).Applications {
+		ids = append(ids, a.ID)
+	}
+	return ids
+}
+
+func TestApplicantRanking(t *testing.T) {
+	employer, _ := newApplicantsRouter(t)
+
+	if got := applicantIDs(t, employer, ""); !slices.Equal(got, []int{3, 1}) {
+		t.Errorf("newest first = %v, want [3 1]", got)
+	}
+	if got := applicantIDs(t, employer, "?sort=match"); !slices.Equal(got, []int{1, 3}) {
+		t.Errorf("best match first = %v, want [1 3]", got)
+	}
+
+	list := listApplicants(t, employer, "?sort=match")
+	want := []string{"Has the Plumbing skill", "6 years of Plumbing experience", "Based in Town"}
+	if got := list.Applications[0].Match.Reasons; !slices.Equal(got, want) {
+		t.Errorf("reasons = %q, want %q", got, want)
+	}
+}
+
+func TestApplicantReviewsArePrivate(t *testing.T) {
+	employer, worker := newApplicantsRouter(t)
+
+	code, body := sendJSON(employer, "PUT", "/api/applications/1/review",
+		map[string]any{"shortlisted": true, "tags": []string{" Tiler", "tiler", "Call back"}, "notes": "Brings own tools"})
+	if code != http.StatusOK {
+		t.Fatalf("review: status = %d; body: %s", code, body)
+	}
+	var saved struct {
+		Review models.ApplicationReview `json:"review"`
+	}
+	json.Unmarshal(body, &saved)
+	if !slices.Equal(saved.Review.Tags, []string{"tiler", "call back"}) {
+		t.Errorf("tags = %q, want tiler and call back", saved.Review.Tags)
+	}
+
+	// Leaving fields out keeps them
+	if code, body := sendJSON(employer, "PUT", "/api/applications/1/review", map[string]any{"notes": ""}); code != http.StatusOK {
+		t.Fatalf("clear notes: status = %d; body: %s", code, body)
+	}
+	if got := applicantIDs(t, employer, "?shortlisted=true"); !slices.Equal(got, []int{1}) {
+		t.Errorf("shortlisted = %v, want [1]", got)
+	}
+	if got := applicantIDs(t, employer, "?tag=TILER"); !slices.Equal(got, []int{1}) {
+		t.Errorf("tagged tiler = %v, want [1]", got)
+	}
+
+	code, body = sendJSON(worker, "GET", "/api/applications/worker/2", nil)
+	if code != http.StatusOK || strings.Contains(string(body), "tiler") || strings.Contains(string(body), "shortlisted") {
+		t.Errorf("worker sees the review: status = %d; body: %s", code, body)
+	}
+}
+
+func TestBulkReject(t *testing.T) {
+	employer, _ := newApplicantsRouter(t)
+
+	code, body := sendJSON(employer, "POST", "/api/jobs/1/applications/bulk",
+		map[string]any{"application_ids": []int{1, 2, 3}, "status": "rejected"})
+	if code != http.StatusOK {
+		t.Fatalf("status = %d; body: %s", code, body)
+	}
+	var out struct {
+		Results []struct {
+			ApplicationID int                 `json:"application_id"`
+			Application   *models.Application `json:"application"`
+			Error         *struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		} `json:"results"`
+		Updated int `json:"updated"`
+		Failed  int `json:"failed"`
+	}
+	json.Unmarshal(body, &out)
+	if out.Updated != 2 || out.Failed != 1 || len(out.Results) != 3 {
+		t.Fatalf("updated %d, failed %d; body: %s", out.Updated, out.Failed, body)
+	}
+	// Application 2 is for job 2
+	if r := out.Results[1]; r.ApplicationID != 2 || r.Error == nil || r.Error.Code != "not_found" {
+		t.Errorf("application 2: %+v, want not_found", r)
+	}
+	for _, r := range []int{0, 2} {
+		if a := out.Results[r].Application; a == nil || a.Status != models.ApplicationRejected {
+			t.Errorf("result %d: %+v, want rejected", r, out.Results[r])
+		}
+	}
+}
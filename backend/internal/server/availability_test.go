@@ -0,0 +1,111 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+)
+
+// newScheduleRouter seeds two overlapping jobs next Monday, 10:00-13:00
+// and 12:00-15:00 UTC. Worker 2 has been hired for the first; workers 2
+// and 3 have applied to the second.
+func newScheduleRouter(t *testing.T) (apiClient, *fakeAvailability, time.Time) {
+	t.Helper()
+	deps := newFakeDeps(t)
+	jobs := deps.Jobs.(*fakeJobs)
+	apps := deps.Applications.(*fakeApplications)
+
+	now := time.Now().UTC()
+	monday := time.Date(now.Year(), now.Month(), now.Day()+7-int(now.Weekday())+1, 0, 0, 0, 0, time.UTC)
+	at := func(h int) *time.Time { t := monday.Add(time.Duration(h) * time.Hour); return &t }
+	for id, hours := range map[int][2]int{3: {10, 13}, 4: {12, 15}} {
+		jobs.byID[id] = &models.JobWithDetails{Job: models.Job{
+			ID: id, EmployerID: 1, OrganizationID: 1, Title: "Gig", Description: "Work", Location: "Town",
+			ExpiresAt: now.Add(48 * time.Hour), StartsAt: at(hours[0]), EndsAt: at(hours[1]),
+			Status: "open", IsActive: true,
+		}, EmployerName: "Boss"}
+	}
+	apps.byID[2] = &models.Application{ID: 2, JobID: 3, WorkerID: 2, Status: "accepted", AppliedAt: now, UpdatedAt: now}
+	apps.byID[3] = &models.Application{ID: 3, JobID: 4, WorkerID: 2, Status: "pending", AppliedAt: now, UpdatedAt: now}
+	apps.byID[4] = &models.Application{ID: 4, JobID: 4, WorkerID: 3, Status: "pending", AppliedAt: now, UpdatedAt: now}
+	apps.bookings.book(apps.byID[2])
+
+	employer := newClients(t, deps, token(t, 1, models.RoleEmployer))[0]
+	return employer, deps.Availability.(*fakeAvailability), monday
+}
+
+func applicantsFor(t *testing.T, client apiClient, path string) map[int]*bool {
+	t.Helper()
+	rec := client.do("GET", path, nil, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s: status = %d; body: %s", path, rec.Code, rec.Body)
+	}
+	var resp struct {
+		Applications []models.JobApplicant `json:"applications"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	available := map[int]*bool{}
+	for _, a := range resp.Applications {
+		available[a.WorkerID] = a.Available
+	}
+	return available
+}
+
+func TestApplicantAvailability(t *testing.T) {
+	client, calendars, monday := newScheduleRouter(t)
+
+	// Both work Mondays, but worker 2 is booked on job 3 until 13:00
+	for _, worker := range []int{2, 3} {
+		calendars.ReplaceWeekly(t.Context(), worker, "UTC", []models.AvailabilitySlot{{Weekday: 1, StartTime: "08:00", EndTime: "18:00"}})
+	}
+	got := applicantsFor(t, client, "/api/applications/job/4")
+	if got[2] == nil || *got[2] || got[3] == nil || !*got[3] {
+		t.Errorf("available = {2: %v, 3: %v}, want 2 busy and 3 free", deref(got[2]), deref(got[3]))
+	}
+
+	// The filter keeps only worker 3
+	got = applicantsFor(t, client, "/api/applications/job/4?available=true")
+	if _, ok := got[3]; len(got) != 1 || !ok {
+		t.Errorf("filtered applicants = %v", got)
+	}
+
+	// Worker 3 takes the afternoon off
+	calendars.AddException(t.Context(), &models.AvailabilityException{
+		WorkerID: 3, StartsAt: monday.Add(14 * time.Hour), EndsAt: monday.Add(18 * time.Hour),
+	})
+	if got := applicantsFor(t, client, "/api/applications/job/4?available=true"); len(got) != 0 {
+		t.Errorf("after time off: %v", got)
+	}
+
+	// A worker hired for a job is still available for that job
+	if got := applicantsFor(t, client, "/api/applications/job/3"); got[2] == nil || !*got[2] {
+		t.Errorf("hired worker on own job: %v", deref(got[2]))
+	}
+}
+
+func deref(b *bool) any {
+	if b == nil {
+		return nil
+	}
+	return *b
+}
+
+func TestAcceptScheduleConflict(t *testing.T) {
+	client, _, _ := newScheduleRouter(t)
+
+	// Worker 2 is already hired for the overlapping job 3
+	rec := client.do("PUT", "/api/applications/3", []byte(`{"status":"accepted"}`), "application/json")
+	if rec.Code != http.StatusConflict || errorCode(t, rec) != "schedule_conflict" {
+		t.Errorf("double booking: status %d, body %s", rec.Code, rec.Body)
+	}
+	// Rejecting is always fine, and so is hiring someone free
+	if rec := client.do("PUT", "/api/applications/3", []byte(`{"status":"rejected"}`), "application/json"); rec.Code != http.StatusOK {
+		t.Errorf("reject: status %d", rec.Code)
+	}
+	if rec := client.do("PUT", "/api/applications/4", []byte(`{"status":"accepted"}`), "application/json"); rec.Code != http.StatusOK {
+		t.Errorf("accept free worker: status %d", rec.Code)
+	}
+}
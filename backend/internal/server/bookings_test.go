@@ -0,0 +1,145 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/booking"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+)
+
+// newBookingClients returns clients for employer 1 and worker 2, who has
+// a scheduled booking (1) for job 2
+func newBookingClients(t *testing.T) (employer, worker apiClient, deps Deps) {
+	t.Helper()
+	deps = newFakeDeps(t)
+	clients := newClients(t, deps, token(t, 1, models.RoleEmployer), token(t, 2, models.RoleWorker))
+	return clients[0], clients[1], deps
+}
+
+func bookingStatus(t *testing.T, client apiClient, path string, want int) string {
+	t.Helper()
+	rec := client.do("POST", path, nil, "")
+	if rec.Code != want {
+		t.Fatalf("%s: status = %d, want %d; body: %s", path, rec.Code, want, rec.Body)
+	}
+	var resp struct {
+		Booking models.Booking `json:"booking"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	return resp.Booking.Status
+}
+
+func TestBookingLifecycle(t *testing.T) {
+	employer, worker, deps := newBookingClients(t)
+	bookings := deps.Bookings.(*fakeBookings)
+
+	if got := bookingStatus(t, worker, "/api/bookings/1/check-in", http.StatusOK); got != booking.InProgress {
+		t.Fatalf("after check-in: %s", got)
+	}
+	bookingStatus(t, worker, "/api/bookings/1/check-out", http.StatusOK)
+	if got := bookingStatus(t, worker, "/api/bookings/1/confirm", http.StatusOK); got != booking.InProgress {
+		t.Fatalf("after one confirmation: %s", got)
+	}
+	if len(bookings.experience) != 0 {
+		t.Fatal("work experience added before both sides confirmed")
+	}
+
+	// The worker's booking has started, so the hire can't be undone
+	rec := employer.do("PUT", "/api/applications/2", []byte(`{"status":"rejected"}`), "application/json")
+	if rec.Code != http.StatusConflict || errorCode(t, rec) != "invalid_transition" {
+		t.Errorf("reject started booking: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	if got := bookingStatus(t, employer, "/api/bookings/1/confirm", http.StatusOK); got != booking.Completed {
+		t.Fatalf("after both confirmed: %s", got)
+	}
+	if len(bookings.experience) != 1 || bookings.experience[0].WorkerID != 2 || bookings.experience[0].JobTitle != "Paint" {
+		t.Errorf("work experience = %+v", bookings.experience)
+	}
+
+	// Completed is final
+	rec = worker.do("POST", "/api/bookings/1/cancel", nil, "")
+	if rec.Code != http.StatusConflict || errorCode(t, rec) != "invalid_transition" {
+		t.Errorf("cancel completed booking: status %d, body %s", rec.Code, rec.Body)
+	}
+}
+
+func TestRejectingCancelsBooking(t *testing.T) {
+	employer, worker, deps := newBookingClients(t)
+	bookings := deps.Bookings.(*fakeBookings)
+
+	rec := employer.do("PUT", "/api/applications/2", []byte(`{"status":"rejected"}`), "application/json")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("reject: status %d, body %s", rec.Code, rec.Body)
+	}
+	if got := bookings.byID[1].Status; got != booking.Cancelled {
+		t.Errorf("booking after rejection: %s", got)
+	}
+	bookingStatus(t, worker, "/api/bookings/1/check-in", http.StatusConflict)
+
+	// Accepting again reschedules the same booking
+	rec = employer.do("PUT", "/api/applications/2", []byte(`{"status":"accepted"}`), "application/json")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("re-accept: status %d, body %s", rec.Code, rec.Body)
+	}
+	if len(bookings.byID) != 1 || bookings.byID[1].Status != booking.Scheduled {
+		t.Errorf("bookings after re-accepting: %+v", bookings.byID)
+	}
+
+	// Accepting a new applicant books them too
+	rec = employer.do("PUT", "/api/applications/1", []byte(`{"status":"accepted"}`), "application/json")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("accept: status %d, body %s", rec.Code, rec.Body)
+	}
+	rec = worker.do("GET", "/api/bookings?status=scheduled", nil, "")
+	var list struct {
+		Count int `json:"count"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if list.Count != 2 {
+		t.Errorf("scheduled bookings = %d, want 2", list.Count)
+	}
+}
+
+func TestCancellingEndsApplication(t *testing.T) {
+	tests := []struct {
+		name, path string
+		byWorker   bool
+		want       string
+	}{
+		{"worker cancels", "/api/bookings/1/cancel", true, models.ApplicationWithdrawn},
+		{"employer cancels", "/api/bookings/1/cancel", false, models.ApplicationRejected},
+		{"employer reports a no-show", "/api/bookings/1/no-show", false, models.ApplicationRejected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			employer, worker, _ := newBookingClients(t)
+			client := employer
+			if tt.byWorker {
+				client = worker
+			}
+			bookingStatus(t, client, tt.path, http.StatusOK)
+
+			// The worker no longer counts as hired for the job
+			rec := employer.do("GET", "/api/applications/job/2", nil, "")
+			var resp struct {
+				Applications []models.JobApplicant `json:"applications"`
+			}
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if len(resp.Applications) != 1 || resp.Applications[0].Status != tt.want {
+				t.Errorf("applications after cancelling: %s", rec.Body)
+			}
+
+			// Only an employer's change of mind can be undone
+			rec = employer.do("PUT", "/api/applications/2", []byte(`{"status":"accepted"}`), "application/json")
+			if tt.byWorker && (rec.Code != http.StatusConflict || errorCode(t, rec) != "invalid_transition") {
+				t.Errorf("re-hire a withdrawn worker: status %d, body %s", rec.Code, rec.Body)
+			}
+			if !tt.byWorker && rec.Code != http.StatusOK {
+				t.Errorf("re-hire: status %d, body %s", rec.Code, rec.Body)
+			}
+		})
+	}
+}
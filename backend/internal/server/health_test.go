@@ -0,0 +1,29 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestReadyzHidesErrorDetail(t *testing.T) {
+	deps := newFakeDeps(t)
+	detail := `failed to connect to host=db.internal user=app: FATAL: password authentication failed`
+	checker := deps.Health
+	checker.Ping = func(context.Context) error { return errors.New(detail) }
+	checker.MigrationStatus = func(context.Context) (string, string, error) {
+		return "", "", errors.New(`ERROR: relation "schema_migrations" does not exist (SQLSTATE 42P01)`)
+	}
+	rec := newClients(t, deps, "")[0].do("GET", "/readyz", nil, "")
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	for _, leak := range []string{"db.internal", "user=app", "schema_migrations", "SQLSTATE"} {
+		if strings.Contains(rec.Body.String(), leak) {
+			t.Errorf("body reveals %q: %s", leak, rec.Body)
+		}
+	}
+}
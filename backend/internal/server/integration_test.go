@@ -0,0 +1,1531 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/config"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/db"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/health"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/invoice"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/logging"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/payments"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/ratelimit"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/storage"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/testutil"
+	"github.com/Sabari-Vijayan/DBMS-project/migrations"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	// Keep access logs out of test output; failures still log
+	if err := logging.Setup(config.LogConfig{Level: "error", Format: logging.FormatText}); err != nil {
+		panic(err)
+	}
+	code := m.Run()
+	testutil.Stop()
+	os.Exit(code)
+}
+
+// integration is one test's view of the real database: a clean schema, a
+// router over the Postgres stores and a factory for fixtures
+type integration struct {
+	t       *testing.T
+	db      *testutil.Database
+	router  *gin.Engine
+	factory *testutil.Factory
+}
+
+func newIntegration(t *testing.T, configure ...func(*config.Config)) *integration {
+	t.Helper()
+	database := testutil.DB(t)
+	database.Reset(t)
+
+	// Functional tests send many requests from one address; keep them
+	// clear of the limits unless a test tightens them again
+	cfg := testutil.Config()
+	cfg.RateLimit.AuthBurst, cfg.RateLimit.WriteBurst = 1000, 1000
+	for _, apply := range configure {
+		apply(&cfg)
+	}
+
+	blobs, err := storage.NewLocalStore(t.TempDir(), storage.NewSigner(cfg.Auth.JWTSecret, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router, err := New(cfg, Deps{
+		Users:        store.NewPgUserStore(database.Pool, 5*time.Second),
+		Jobs:         store.NewPgJobStore(database.Pool, 5*time.Second),
+		Applications: store.NewPgApplicationStore(database.Pool, 5*time.Second),
+		Audit:        store.NewPgAuditStore(database.Pool, 5*time.Second),
+		Documents:    store.NewPgDocumentStore(database.Pool, 5*time.Second),
+		Availability: store.NewPgAvailabilityStore(database.Pool, 5*time.Second),
+		Bookings:     store.NewPgBookingStore(database.Pool, 5*time.Second),
+		Payments:     store.NewPgPaymentStore(database.Pool, 5*time.Second),
+		Invoices:     store.NewPgInvoiceStore(database.Pool, 5*time.Second),
+		Blobs:        blobs,
+
+		Organizations: store.NewPgOrganizationStore(database.Pool, 5*time.Second),
+		Workers:       store.NewPgWorkerStore(database.Pool, 5*time.Second),
+
+		PaymentProvider: payments.NewFakeProvider(testWebhookSecret, time.Minute),
+		Tokens:          testutil.Tokens,
+		Health:          health.NewChecker(database.Pool, migrations.FS),
+		RateLimits:      ratelimit.NewPgStore(database.Pool, 5*time.Second),
+	})
+	if err != nil {
+		t.Fatalf("build router: %v", err)
+	}
+	return &integration{t: t, db: database, router: router, factory: testutil.NewFactory(database.Pool)}
+}
+
+type response struct {
+	Status int
+	Body   map[string]any
+}
+
+func (r response) code() string {
+	code, _ := r.Body["code"].(string)
+	return code
+}
+
+func (it *integration) do(method, path, token string, body any) response {
+	it.t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			it.t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	it.router.ServeHTTP(rec, req)
+
+	resp := response{Status: rec.Code}
+	if rec.Body.Len() > 0 && rec.Header().Get("Content-Type") == "application/json; charset=utf-8" {
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp.Body); err != nil {
+			it.t.Fatalf("decode %s %s response: %v", method, path, err)
+		}
+	}
+	return resp
+}
+
+// upload sends a multipart form built by multipartBody
+func (it *integration) upload(method, path, token string, body []byte, contentType string) response {
+	it.t.Helper()
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	it.router.ServeHTTP(rec, req)
+
+	resp := response{Status: rec.Code}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp.Body); err != nil {
+		it.t.Fatalf("decode %s %s response: %v", method, path, err)
+	}
+	return resp
+}
+
+func (it *integration) exec(sql string, args ...any) {
+	it.t.Helper()
+	if _, err := it.db.Pool.Exec(context.Background(), sql, args...); err != nil {
+		it.t.Fatalf("exec %q: %v", sql, err)
+	}
+}
+
+func TestIntegrationRegisterAndLogin(t *testing.T) {
+	it := newIntegration(t)
+	existing := it.factory.Worker(t)
+
+	tests := []struct {
+		name   string
+		path   string
+		body   map[string]any
+		status int
+		code   string
+	}{
+		{"register worker", "/api/register", map[string]any{"email": "w@example.com", "password": "secret1", "full_name": "W", "user_type": "worker"}, 201, ""},
+		{"register employer", "/api/register", map[string]any{"email": "e@example.com", "password": "secret1", "full_name": "E", "user_type": "employer"}, 201, ""},
+		{"register duplicate email", "/api/register", map[string]any{"email": existing.Email, "password": "secret1", "full_name": "X", "user_type": "worker"}, 409, "email_taken"},
+		{"register unknown role", "/api/register", map[string]any{"email": "h@example.com", "password": "secret1", "full_name": "H", "user_type": "handyman"}, 400, "validation_failed"},
+		{"register short password", "/api/register", map[string]any{"email": "s@example.com", "password": "123", "full_name": "S", "user_type": "worker"}, 400, "validation_failed"},
+		{"login", "/api/login", map[string]any{"email": existing.Email, "password": testutil.Password}, 200, ""},
+		{"login wrong password", "/api/login", map[string]any{"email": existing.Email, "password": "wrong-password"}, 401, "invalid_credentials"},
+		{"login unknown email", "/api/login", map[string]any{"email": "nobody@example.com", "password": testutil.Password}, 401, "invalid_credentials"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it.t = t
+			resp := it.do("POST", tt.path, "", tt.body)
+			if resp.Status != tt.status || resp.code() != tt.code {
+				t.Fatalf("got %d %q, want %d %q: %v", resp.Status, resp.code(), tt.status, tt.code, resp.Body)
+			}
+		})
+	}
+
+	// A freshly registered user can log in and use the token
+	it.t = t
+	resp := it.do("POST", "/api/login", "", map[string]any{"email": "w@example.com", "password": "secret1"})
+	token, _ := resp.Body["token"].(string)
+	if token == "" {
+		t.Fatalf("login returned no token: %v", resp.Body)
+	}
+	user := resp.Body["user"].(map[string]any)
+	if resp := it.do("GET", fmt.Sprintf("/api/profile/%v", user["id"]), token, nil); resp.Status != 200 {
+		t.Fatalf("profile with new token: %d %v", resp.Status, resp.Body)
+	}
+}
+
+func TestIntegrationCreateJobByRole(t *testing.T) {
+	it := newIntegration(t)
+	employer := testutil.Token(t, it.factory.Employer(t))
+	worker := testutil.Token(t, it.factory.Worker(t))
+
+	valid := map[string]any{"title": "Fix tap", "description": "Kitchen tap drips", "location": "Kochi", "expiry_days": 3}
+	withCategory := func(id int) map[string]any {
+		body := map[string]any{"category_id": id}
+		for k, v := range valid {
+			body[k] = v
+		}
+		return body
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		body   map[string]any
+		status int
+		code   string
+	}{
+		{"employer", employer, valid, 201, ""},
+		{"employer with category", employer, withCategory(1), 201, ""},
+		{"unknown category", employer, withCategory(9999), 422, "invalid_reference"},
+		{"expiry too long", employer, map[string]any{"title": "T", "description": "D", "location": "L", "expiry_days": 30}, 400, "validation_failed"},
+		{"worker", worker, valid, 403, "forbidden"},
+		{"anonymous", "", valid, 401, "unauthorized"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it.t = t
+			resp := it.do("POST", "/api/jobs", tt.token, tt.body)
+			if resp.Status != tt.status || resp.code() != tt.code {
+				t.Fatalf("got %d %q, want %d %q: %v", resp.Status, resp.code(), tt.status, tt.code, resp.Body)
+			}
+		})
+	}
+}
+
+func TestIntegrationJobListing(t *testing.T) {
+	it := newIntegration(t)
+	employer := it.factory.Employer(t)
+	open := it.factory.Job(t, employer.ID)
+	filled := it.factory.Job(t, employer.ID)
+	it.exec(`UPDATE jobs SET status = 'filled' WHERE id = $1`, filled.ID)
+	it.factory.Job(t, employer.ID, func(j *models.Job) { j.ExpiresAt = time.Now().Add(-time.Hour) })
+
+	resp := it.do("GET", "/api/jobs", "", nil)
+	if resp.Status != 200 || resp.Body["count"] != float64(1) {
+		t.Fatalf("want only the open job, got %d %v", resp.Status, resp.Body)
+	}
+	listed := resp.Body["jobs"].([]any)[0].(map[string]any)
+	if listed["id"] != float64(open.ID) || listed["employer_name"] != employer.FullName {
+		t.Fatalf("unexpected job %v", listed)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{"open job", fmt.Sprintf("/api/jobs/%d", open.ID), 200},
+		{"filled job is still viewable", fmt.Sprintf("/api/jobs/%d", filled.ID), 200},
+		{"missing job", "/api/jobs/9999", 404},
+		{"bad id", "/api/jobs/abc", 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it.t = t
+			if resp := it.do("GET", tt.path, "", nil); resp.Status != tt.status {
+				t.Fatalf("got %d, want %d: %v", resp.Status, tt.status, resp.Body)
+			}
+		})
+	}
+}
+
+func TestIntegrationJobListingByRate(t *testing.T) {
+	it := newIntegration(t)
+	employer := it.factory.Employer(t)
+	paid := func(payType models.PayType, currency string, amount int64) *models.Job {
+		return it.factory.Job(t, employer.ID, func(j *models.Job) {
+			j.PayType, j.Currency, j.PayMax = payType, currency, &amount
+		})
+	}
+	hourly := paid(models.PayHourly, "INR", 30000) // 300/hour
+	daily := paid(models.PayDaily, "INR", 160000)  // 200/hour
+	fixed := paid(models.PayFixed, "INR", 500000)  // no schedule, so no rate
+	dollars := paid(models.PayHourly, "USD", 2000) // 20/hour
+
+	ids := func(path string) []int {
+		resp := it.do("GET", path, "", nil)
+		if resp.Status != 200 {
+			t.Fatalf("%s: %d %v", path, resp.Status, resp.Body)
+		}
+		var ids []int
+		for _, j := range resp.Body["jobs"].([]any) {
+			ids = append(ids, int(j.(map[string]any)["id"].(float64)))
+		}
+		return ids
+	}
+	tests := []struct {
+		path string
+		want []int
+	}{
+		{"/api/jobs?currency=INR&sort=rate_desc", []int{hourly.ID, daily.ID, fixed.ID}},
+		{"/api/jobs?currency=INR&sort=rate_asc", []int{daily.ID, hourly.ID, fixed.ID}},
+		{"/api/jobs?currency=INR&min_rate=25000", []int{hourly.ID}},
+		{"/api/jobs?currency=INR&max_rate=25000", []int{daily.ID}},
+		{"/api/jobs?currency=USD&pay_type=hourly&sort=rate_asc", []int{dollars.ID}},
+	}
+	for _, tt := range tests {
+		if got := ids(tt.path); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	// 2000 cents and 30000 paise can't be put in order
+	if resp := it.do("GET", "/api/jobs?pay_type=hourly&sort=rate_asc", "", nil); resp.Status != 400 {
+		t.Errorf("rate sort across currencies: %d %v", resp.Status, resp.Body)
+	}
+
+	// The database enforces the range too
+	if _, err := it.db.Pool.Exec(context.Background(), `UPDATE jobs SET pay_min = pay_max + 1 WHERE id = $1`, hourly.ID); err == nil {
+		t.Error("pay_min above pay_max was saved")
+	}
+}
+
+func TestIntegrationApply(t *testing.T) {
+	it := newIntegration(t)
+	employerUser := it.factory.Employer(t)
+	workerUser := it.factory.Worker(t)
+	employer := testutil.Token(t, employerUser)
+	worker := testutil.Token(t, workerUser)
+
+	open := it.factory.Job(t, employerUser.ID)
+	closed := it.factory.Job(t, employerUser.ID)
+	it.exec(`UPDATE jobs SET status = 'closed' WHERE id = $1`, closed.ID)
+	expired := it.factory.Job(t, employerUser.ID, func(j *models.Job) { j.ExpiresAt = time.Now().Add(-time.Hour) })
+
+	// Subtests run in order; "duplicate" relies on "apply" succeeding
+	tests := []struct {
+		name   string
+		token  string
+		jobID  int
+		status int
+		code   string
+	}{
+		{"apply", worker, open.ID, 201, ""},
+		{"duplicate", worker, open.ID, 409, "already_applied"},
+		{"closed job", worker, closed.ID, 400, "job_closed"},
+		{"expired job", worker, expired.ID, 400, "job_expired"},
+		{"missing job", worker, 9999, 404, "not_found"},
+		{"employer cannot apply", employer, open.ID, 403, "forbidden"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it.t = t
+			resp := it.do("POST", "/api/applications", tt.token, map[string]any{"job_id": tt.jobID, "cover_letter": "I have tools"})
+			if resp.Status != tt.status || resp.code() != tt.code {
+				t.Fatalf("got %d %q, want %d %q: %v", resp.Status, resp.code(), tt.status, tt.code, resp.Body)
+			}
+		})
+	}
+
+	it.t = t
+	resp := it.do("GET", fmt.Sprintf("/api/applications/worker/%d", workerUser.ID), worker, nil)
+	if resp.Status != 200 || resp.Body["count"] != float64(1) {
+		t.Fatalf("worker applications: %d %v", resp.Status, resp.Body)
+	}
+}
+
+func TestIntegrationQuotas(t *testing.T) {
+	it := newIntegration(t, func(cfg *config.Config) {
+		cfg.Quota.MaxOpenJobsPerEmployer = 2
+		cfg.Quota.MaxApplicationsPerDay = 2
+		cfg.RateLimit.AuthBurst = 2
+	})
+	employerUser := it.factory.Employer(t)
+	workerUser := it.factory.Worker(t)
+	employer := testutil.Token(t, employerUser)
+	worker := testutil.Token(t, workerUser)
+
+	// Closed and expired jobs do not count towards the open jobs quota
+	first := it.factory.Job(t, employerUser.ID)
+	closed := it.factory.Job(t, employerUser.ID)
+	it.exec(`UPDATE jobs SET status = 'closed' WHERE id = $1`, closed.ID)
+	it.factory.Job(t, employerUser.ID, func(j *models.Job) { j.ExpiresAt = time.Now().Add(-time.Hour) })
+
+	job := map[string]any{"title": "Tile", "description": "Bathroom", "location": "Town", "expiry_days": 3}
+	if resp := it.do("POST", "/api/jobs", employer, job); resp.Status != 201 {
+		t.Fatalf("second open job: %d %v", resp.Status, resp.Body)
+	}
+	if resp := it.do("POST", "/api/jobs", employer, job); resp.Status != 429 || resp.code() != "quota_exceeded" {
+		t.Fatalf("third open job: %d %v", resp.Status, resp.Body)
+	}
+
+	// Applications older than a day have dropped out of the window
+	old := it.factory.Job(t, employerUser.ID)
+	it.factory.Application(t, old.ID, workerUser.ID)
+	it.exec(`UPDATE applications SET applied_at = applied_at - INTERVAL '25 hours'`)
+	second := it.factory.Job(t, it.factory.Employer(t).ID)
+	third := it.factory.Job(t, it.factory.Employer(t).ID)
+	// Nor do invitations, which the worker didn't ask for
+	for range 3 {
+		invite := fmt.Sprintf("/api/jobs/%d/invite", it.factory.Job(t, employerUser.ID).ID)
+		if resp := it.do("POST", invite, employer, map[string]any{"worker_id": workerUser.ID}); resp.Status != 201 {
+			t.Fatalf("invite: %d %v", resp.Status, resp.Body)
+		}
+	}
+	for i, jobID := range []int{first.ID, second.ID} {
+		if resp := it.do("POST", "/api/applications", worker, map[string]any{"job_id": jobID}); resp.Status != 201 {
+			t.Fatalf("application %d: %d %v", i+1, resp.Status, resp.Body)
+		}
+	}
+	if resp := it.do("POST", "/api/applications", worker, map[string]any{"job_id": third.ID}); resp.Status != 429 || resp.code() != "quota_exceeded" {
+		t.Fatalf("application over quota: %d %v", resp.Status, resp.Body)
+	}
+
+	// Login attempts share a bucket in rate_limit_buckets
+	login := map[string]any{"email": "nobody@example.com", "password": "wrong"}
+	for range 2 {
+		it.do("POST", "/api/login", "", login)
+	}
+	if resp := it.do("POST", "/api/login", "", login); resp.Status != 429 || resp.code() != "rate_limited" {
+		t.Fatalf("login over limit: %d %v", resp.Status, resp.Body)
+	}
+}
+
+func TestIntegrationAcceptReject(t *testing.T) {
+	it := newIntegration(t)
+	employerUser := it.factory.Employer(t)
+	employer := testutil.Token(t, employerUser)
+	job := it.factory.Job(t, employerUser.ID)
+
+	first := it.factory.Worker(t)
+	second := it.factory.Worker(t)
+	firstApp := it.factory.Application(t, job.ID, first.ID)
+	secondApp := it.factory.Application(t, job.ID, second.ID)
+
+	resp := it.do("GET", fmt.Sprintf("/api/applications/job/%d", job.ID), employer, nil)
+	if resp.Status != 200 || resp.Body["count"] != float64(2) {
+		t.Fatalf("job applications: %d %v", resp.Status, resp.Body)
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		appID  int
+		status string
+		want   int
+		code   string
+	}{
+		{"accept", employer, firstApp.ID, "accepted", 200, ""},
+		{"reject", employer, secondApp.ID, "rejected", 200, ""},
+		{"invalid status", employer, firstApp.ID, "maybe", 400, "validation_failed"},
+		{"missing application", employer, 9999, "accepted", 404, "not_found"},
+		{"worker cannot decide", testutil.Token(t, first), firstApp.ID, "accepted", 403, "forbidden"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it.t = t
+			resp := it.do("PUT", fmt.Sprintf("/api/applications/%d", tt.appID), tt.token, map[string]any{"status": tt.status})
+			if resp.Status != tt.want || resp.code() != tt.code {
+				t.Fatalf("got %d %q, want %d %q: %v", resp.Status, resp.code(), tt.want, tt.code, resp.Body)
+			}
+			if tt.want == 200 {
+				app := resp.Body["application"].(map[string]any)
+				if app["status"] != tt.status {
+					t.Fatalf("status = %v, want %s", app["status"], tt.status)
+				}
+			}
+		})
+	}
+
+	// The workers see the outcome
+	it.t = t
+	resp = it.do("GET", fmt.Sprintf("/api/applications/worker/%d", first.ID), testutil.Token(t, first), nil)
+	apps := resp.Body["applications"].([]any)
+	if len(apps) != 1 || apps[0].(map[string]any)["status"] != "accepted" {
+		t.Fatalf("first worker applications: %v", resp.Body)
+	}
+}
+
+func TestIntegrationScheduling(t *testing.T) {
+	it := newIntegration(t)
+	employerUser := it.factory.Employer(t)
+	employer := testutil.Token(t, employerUser)
+	busy, free := it.factory.Worker(t), it.factory.Worker(t)
+
+	// Two overlapping jobs next week, 10:00-13:00 and 12:00-15:00 in Kolkata
+	kolkata := time.FixedZone("IST", 5*3600+1800)
+	now := time.Now().In(kolkata)
+	monday := time.Date(now.Year(), now.Month(), now.Day()+7-int(now.Weekday())+1, 0, 0, 0, 0, kolkata)
+	schedule := func(from, to int) func(*models.Job) {
+		return func(j *models.Job) {
+			start, end := monday.Add(time.Duration(from)*time.Hour), monday.Add(time.Duration(to)*time.Hour)
+			j.StartsAt, j.EndsAt = &start, &end
+		}
+	}
+	morning := it.factory.Job(t, employerUser.ID, schedule(10, 13))
+	midday := it.factory.Job(t, employerUser.ID, schedule(12, 15))
+
+	for _, w := range []*models.User{busy, free} {
+		resp := it.do("PUT", "/api/me/availability", testutil.Token(t, w), map[string]any{
+			"timezone": "Asia/Kolkata",
+			"weekly":   []map[string]any{{"weekday": 1, "start_time": "09:00", "end_time": "24:00"}},
+		})
+		if resp.Status != 200 {
+			t.Fatalf("set availability: %d %v", resp.Status, resp.Body)
+		}
+	}
+	resp := it.do("GET", "/api/me/availability", testutil.Token(t, free), nil)
+	weekly, _ := resp.Body["weekly"].([]any)
+	if len(weekly) != 1 || weekly[0].(map[string]any)["end_time"] != "24:00" {
+		t.Errorf("availability: %v", resp.Body)
+	}
+
+	hired := it.factory.Application(t, morning.ID, busy.ID)
+	if resp := it.do("PUT", fmt.Sprintf("/api/applications/%d", hired.ID), employer, map[string]any{"status": "accepted"}); resp.Status != 200 {
+		t.Fatalf("hire: %d %v", resp.Status, resp.Body)
+	}
+	clash := it.factory.Application(t, midday.ID, busy.ID)
+	it.factory.Application(t, midday.ID, free.ID)
+
+	resp = it.do("GET", fmt.Sprintf("/api/applications/job/%d?available=true", midday.ID), employer, nil)
+	apps, _ := resp.Body["applications"].([]any)
+	if len(apps) != 1 || apps[0].(map[string]any)["worker_id"] != float64(free.ID) {
+		t.Errorf("available applicants: %v", resp.Body)
+	}
+
+	// The directory's window search rules out the booked worker in SQL
+	// and checks the other's calendar
+	window := url.Values{"available_from": {monday.Add(12 * time.Hour).Format(time.RFC3339)}, "available_to": {monday.Add(15 * time.Hour).Format(time.RFC3339)}}
+	resp = it.do("GET", "/api/workers?"+window.Encode(), "", nil)
+	var listed []float64
+	for _, w := range resp.Body["workers"].([]any) {
+		listed = append(listed, w.(map[string]any)["id"].(float64))
+	}
+	if resp.Status != 200 || resp.Body["truncated"] != false || !slices.Contains(listed, float64(free.ID)) || slices.Contains(listed, float64(busy.ID)) {
+		t.Errorf("workers free at midday: %d %v", resp.Status, resp.Body)
+	}
+
+	// The free worker blocks out Monday afternoon
+	resp = it.do("POST", "/api/me/availability/exceptions", testutil.Token(t, free), map[string]any{
+		"starts_at": monday.Add(14 * time.Hour), "ends_at": monday.Add(20 * time.Hour), "available": false,
+	})
+	if resp.Status != 201 {
+		t.Fatalf("exception: %d %v", resp.Status, resp.Body)
+	}
+	resp = it.do("GET", fmt.Sprintf("/api/applications/job/%d?available=true", midday.ID), employer, nil)
+	if apps, _ := resp.Body["applications"].([]any); len(apps) != 0 {
+		t.Errorf("after time off: %v", resp.Body)
+	}
+
+	if resp := it.do("PUT", fmt.Sprintf("/api/applications/%d", clash.ID), employer, map[string]any{"status": "accepted"}); resp.Status != 409 || resp.code() != "schedule_conflict" {
+		t.Errorf("double booking: %d %v", resp.Status, resp.Body)
+	}
+
+	// Moving the morning job clear of midday frees the worker, and moving
+	// it back is refused once they are hired for both
+	moved := map[string]any{"title": morning.Title, "description": morning.Description, "location": morning.Location,
+		"starts_at": monday.Add(8 * time.Hour), "ends_at": monday.Add(11 * time.Hour)}
+	if resp := it.do("PUT", fmt.Sprintf("/api/jobs/%d", morning.ID), employer, moved); resp.Status != 200 {
+		t.Fatalf("move job: %d %v", resp.Status, resp.Body)
+	}
+	if resp := it.do("PUT", fmt.Sprintf("/api/applications/%d", clash.ID), employer, map[string]any{"status": "accepted"}); resp.Status != 200 {
+		t.Errorf("accept after move: %d %v", resp.Status, resp.Body)
+	}
+	moved["starts_at"], moved["ends_at"] = monday.Add(10*time.Hour), monday.Add(13*time.Hour)
+	if resp := it.do("PUT", fmt.Sprintf("/api/jobs/%d", morning.ID), employer, moved); resp.Status != 409 {
+		t.Errorf("move back: %d %v", resp.Status, resp.Body)
+	}
+}
+
+func TestIntegrationBookings(t *testing.T) {
+	it := newIntegration(t)
+	employerUser := it.factory.Employer(t)
+	employer := testutil.Token(t, employerUser)
+	workerUser := it.factory.Worker(t)
+	worker := testutil.Token(t, workerUser)
+	job := it.factory.Job(t, employerUser.ID)
+	app := it.factory.Application(t, job.ID, workerUser.ID)
+
+	if resp := it.do("PUT", fmt.Sprintf("/api/applications/%d", app.ID), employer, map[string]any{"status": "accepted"}); resp.Status != 200 {
+		t.Fatalf("hire: %d %v", resp.Status, resp.Body)
+	}
+	resp := it.do("GET", "/api/bookings", worker, nil)
+	bookings, _ := resp.Body["bookings"].([]any)
+	if len(bookings) != 1 {
+		t.Fatalf("worker bookings: %d %v", resp.Status, resp.Body)
+	}
+	b := bookings[0].(map[string]any)
+	if b["status"] != "scheduled" || b["job_title"] != job.Title || b["employer_id"] != float64(employerUser.ID) {
+		t.Errorf("booking: %v", b)
+	}
+	path := fmt.Sprintf("/api/bookings/%v", b["id"])
+	jobPath := fmt.Sprintf("/api/jobs/%d", job.ID)
+	if resp := it.do("DELETE", jobPath, employer, nil); resp.Status != 409 || resp.code() != "job_booked" {
+		t.Fatalf("delete booked job: %d %v", resp.Status, resp.Body)
+	}
+
+	steps := []struct {
+		name   string
+		token  string
+		action string
+		want   int
+		status string
+	}{
+		{"check out first", worker, "check-out", 409, ""},
+		{"check in", worker, "check-in", 200, "in_progress"},
+		{"cancel once started", employer, "cancel", 409, ""},
+		{"check out", worker, "check-out", 200, "in_progress"},
+		{"worker confirms", worker, "confirm", 200, "in_progress"},
+		{"employer confirms", employer, "confirm", 200, "completed"},
+		{"confirm again", employer, "confirm", 409, ""},
+	}
+	for _, step := range steps {
+		resp := it.do("POST", path+"/"+step.action, step.token, nil)
+		if resp.Status != step.want {
+			t.Fatalf("%s: %d %v", step.name, resp.Status, resp.Body)
+		}
+		if step.status != "" && resp.Body["booking"].(map[string]any)["status"] != step.status {
+			t.Fatalf("%s: %v", step.name, resp.Body)
+		}
+	}
+
+	var title string
+	var bookingID int
+	err := it.db.Pool.QueryRow(context.Background(),
+		`SELECT job_title, booking_id FROM work_experience WHERE worker_id = $1`, workerUser.ID).Scan(&title, &bookingID)
+	if err != nil || title != job.Title || float64(bookingID) != b["id"] {
+		t.Errorf("work experience: %q %d %v", title, bookingID, err)
+	}
+
+	// The hire can't be undone once the work is done
+	if resp := it.do("PUT", fmt.Sprintf("/api/applications/%d", app.ID), employer, map[string]any{"status": "rejected"}); resp.Status != 409 || resp.code() != "invalid_transition" {
+		t.Errorf("reject after completion: %d %v", resp.Status, resp.Body)
+	}
+	if resp := it.do("GET", path, testutil.Token(t, it.factory.Employer(t)), nil); resp.Status != 403 {
+		t.Errorf("stranger reads booking: %d", resp.Status)
+	}
+	if resp := it.do("DELETE", jobPath, employer, nil); resp.Status != 200 {
+		t.Errorf("delete job once completed: %d %v", resp.Status, resp.Body)
+	}
+}
+
+func TestIntegrationPayments(t *testing.T) {
+	it := newIntegration(t)
+	employerUser := it.factory.Employer(t)
+	employer := testutil.Token(t, employerUser)
+	workerUser := it.factory.Worker(t)
+	worker := testutil.Token(t, workerUser)
+	pay := int64(125000)
+	paid := func(j *models.Job) { j.PayMax = &pay }
+
+	hire := func() string {
+		job := it.factory.Job(t, employerUser.ID, paid)
+		app := it.factory.Application(t, job.ID, workerUser.ID)
+		if resp := it.do("PUT", fmt.Sprintf("/api/applications/%d", app.ID), employer, map[string]any{"status": "accepted"}); resp.Status != 200 {
+			t.Fatalf("hire: %d %v", resp.Status, resp.Body)
+		}
+		var bookingID int
+		if err := it.db.Pool.QueryRow(context.Background(), `SELECT id FROM bookings WHERE application_id = $1`, app.ID).Scan(&bookingID); err != nil {
+			t.Fatal(err)
+		}
+		return fmt.Sprintf("/api/bookings/%d", bookingID)
+	}
+	held := func(path string) float64 {
+		resp := it.do("GET", path+"/payments", employer, nil)
+		var total float64
+		for _, b := range resp.Body["held"].([]any) {
+			total += b.(map[string]any)["amount"].(float64)
+		}
+		return total
+	}
+
+	// Completed: escrow goes to the worker
+	completed := hire()
+	if got := held(completed); got != 125000 {
+		t.Fatalf("held after hiring = %v", got)
+	}
+	if resp := it.do("POST", completed+"/payments", employer, nil); resp.Status != 409 || resp.code() != "payment_exists" {
+		t.Errorf("pay twice: %d %v", resp.Status, resp.Body)
+	}
+	for _, step := range []struct{ token, action string }{
+		{worker, "check-in"}, {worker, "check-out"}, {worker, "confirm"}, {employer, "confirm"},
+	} {
+		if resp := it.do("POST", completed+"/"+step.action, step.token, nil); resp.Status != 200 {
+			t.Fatalf("%s: %d %v", step.action, resp.Status, resp.Body)
+		}
+	}
+	if got := held(completed); got != 0 {
+		t.Errorf("held after completion = %v", got)
+	}
+
+	// Cancelled: escrow goes back to the employer
+	cancelled := hire()
+	if resp := it.do("POST", cancelled+"/cancel", employer, nil); resp.Status != 200 {
+		t.Fatalf("cancel: %d %v", resp.Status, resp.Body)
+	}
+	if got := held(cancelled); got != 0 {
+		t.Errorf("held after cancelling = %v", got)
+	}
+	var appStatus string
+	if err := it.db.Pool.QueryRow(context.Background(), `
+		SELECT a.status FROM applications a JOIN bookings b ON b.application_id = a.id
+		WHERE b.id = $1`, strings.TrimPrefix(cancelled, "/api/bookings/")).Scan(&appStatus); err != nil || appStatus != models.ApplicationRejected {
+		t.Errorf("application after cancelling: %q, %v", appStatus, err)
+	}
+
+	resp := it.do("GET", "/api/me/balance", worker, nil)
+	balances, _ := resp.Body["balances"].([]any)
+	if len(balances) != 1 || balances[0].(map[string]any)["amount"] != float64(125000) {
+		t.Errorf("worker balance: %v", resp.Body)
+	}
+
+	// A charge that settles after its booking was cancelled is held, then
+	// due a refund, even when the webhook is redelivered
+	unpaid := it.factory.Job(t, employerUser.ID)
+	app := it.factory.Application(t, unpaid.ID, workerUser.ID)
+	if resp := it.do("PUT", fmt.Sprintf("/api/applications/%d", app.ID), employer, map[string]any{"status": "accepted"}); resp.Status != 200 {
+		t.Fatalf("hire: %d %v", resp.Status, resp.Body)
+	}
+	if resp := it.do("PUT", fmt.Sprintf("/api/applications/%d", app.ID), employer, map[string]any{"status": "rejected"}); resp.Status != 200 {
+		t.Fatalf("reject: %d %v", resp.Status, resp.Body)
+	}
+	var lateBooking int
+	if err := it.db.Pool.QueryRow(context.Background(), `SELECT id FROM bookings WHERE application_id = $1`, app.ID).Scan(&lateBooking); err != nil {
+		t.Fatal(err)
+	}
+	paymentStore := store.NewPgPaymentStore(it.db.Pool, 5*time.Second)
+	late := &models.Payment{BookingID: &lateBooking, Provider: "fake", Amount: 5000, Currency: "INR"}
+	if err := paymentStore.Create(context.Background(), late); err != nil {
+		t.Fatal(err)
+	}
+	for i := range 2 {
+		p, refundDue, err := paymentStore.Settle(context.Background(), late.ID, "ch_late", "succeeded", "")
+		if err != nil || !refundDue || p.Status != "succeeded" {
+			t.Fatalf("settle %d: %+v, refund due %v, %v", i+1, p, refundDue, err)
+		}
+	}
+	if got := held(fmt.Sprintf("/api/bookings/%d", lateBooking)); got != 5000 {
+		t.Errorf("held for the late charge = %v", got)
+	}
+
+	// Every transaction balances, so the whole ledger sums to zero
+	var total int64
+	if err := it.db.Pool.QueryRow(context.Background(), `SELECT COALESCE(SUM(amount), 0)::bigint FROM ledger_entries`).Scan(&total); err != nil || total != 0 {
+		t.Errorf("ledger sums to %d (%v)", total, err)
+	}
+	if _, err := it.db.Pool.Exec(context.Background(), `UPDATE ledger_entries SET amount = amount + 1`); err == nil {
+		t.Error("ledger entries were edited in place")
+	}
+	_, err := it.db.Pool.Exec(context.Background(), `
+		WITH t AS (
+			INSERT INTO ledger_transactions (kind, idempotency_key, currency) VALUES ('hold', 'test:unbalanced', 'INR') RETURNING id
+		)
+		INSERT INTO ledger_entries (transaction_id, account_id, amount)
+		SELECT t.id, a.id, 1 FROM t, ledger_accounts a LIMIT 1`)
+	if err == nil {
+		t.Error("unbalanced transaction committed")
+	}
+}
+
+func TestIntegrationInvoices(t *testing.T) {
+	it := newIntegration(t)
+	employerUser := it.factory.Employer(t)
+	employer := testutil.Token(t, employerUser)
+	workerUser := it.factory.Worker(t)
+	worker := testutil.Token(t, workerUser)
+	pay := int64(80000)
+
+	// Bring several paid bookings to the last confirmation, then confirm
+	// them all at once so their invoices are numbered concurrently
+	const n = 8
+	var paths []string
+	for range n {
+		job := it.factory.Job(t, employerUser.ID, func(j *models.Job) { j.PayMax = &pay })
+		app := it.factory.Application(t, job.ID, workerUser.ID)
+		if resp := it.do("PUT", fmt.Sprintf("/api/applications/%d", app.ID), employer, map[string]any{"status": "accepted"}); resp.Status != 200 {
+			t.Fatalf("hire: %d %v", resp.Status, resp.Body)
+		}
+		var bookingID int
+		if err := it.db.Pool.QueryRow(context.Background(), `SELECT id FROM bookings WHERE application_id = $1`, app.ID).Scan(&bookingID); err != nil {
+			t.Fatal(err)
+		}
+		path := fmt.Sprintf("/api/bookings/%d", bookingID)
+		for _, action := range []string{"check-in", "check-out", "confirm"} {
+			if resp := it.do("POST", path+"/"+action, worker, nil); resp.Status != 200 {
+				t.Fatalf("%s: %d %v", action, resp.Status, resp.Body)
+			}
+		}
+		paths = append(paths, path)
+	}
+
+	codes := make(chan int, n)
+	for _, path := range paths {
+		go func() {
+			req := httptest.NewRequest("POST", path+"/confirm", nil)
+			req.Header.Set("Authorization", "Bearer "+employer)
+			rec := httptest.NewRecorder()
+			it.router.ServeHTTP(rec, req)
+			codes <- rec.Code
+		}()
+	}
+	for range n {
+		if code := <-codes; code != 200 {
+			t.Errorf("confirm: %d", code)
+		}
+	}
+
+	// Numbers run from 1 with no gaps or repeats
+	rows, err := it.db.Pool.Query(context.Background(), `SELECT number FROM invoices ORDER BY number`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var numbers []string
+	for rows.Next() {
+		var number string
+		if err := rows.Scan(&number); err != nil {
+			t.Fatal(err)
+		}
+		numbers = append(numbers, number)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(numbers) != n {
+		t.Fatalf("%d invoices for %d bookings", len(numbers), n)
+	}
+	for i, number := range numbers {
+		if want := invoice.Number(time.Now().UTC().Year(), i+1); number != want {
+			t.Errorf("invoice %d is %s, want %s", i, number, want)
+		}
+	}
+
+	resp := it.do("GET", "/api/me/statements", worker, nil)
+	totals, _ := resp.Body["totals"].([]any)
+	if len(totals) != 1 || totals[0].(map[string]any)["amount"] != float64(n*80000) {
+		t.Errorf("worker statement: %v", resp.Body)
+	}
+	resp = it.do("GET", "/api/me/statements", employer, nil)
+	if invoices, _ := resp.Body["invoices"].([]any); len(invoices) != n {
+		t.Errorf("employer statement: %v", resp.Body)
+	}
+	if resp := it.do("GET", "/api/me/statements?month=2001-01", worker, nil); len(resp.Body["invoices"].([]any)) != 0 {
+		t.Errorf("statement for another month: %v", resp.Body)
+	}
+
+	for format, contentType := range map[string]string{"csv": "text/csv", "pdf": "application/pdf"} {
+		req := httptest.NewRequest("GET", "/api/me/statements?format="+format, nil)
+		req.Header.Set("Authorization", "Bearer "+worker)
+		rec := httptest.NewRecorder()
+		it.router.ServeHTTP(rec, req)
+		if rec.Code != 200 || rec.Header().Get("Content-Type") != contentType ||
+			!strings.HasPrefix(rec.Header().Get("Content-Disposition"), "attachment") {
+			t.Errorf("%s statement: %d %v", format, rec.Code, rec.Header())
+		}
+	}
+}
+
+func TestIntegrationProfile(t *testing.T) {
+	it := newIntegration(t)
+	user := it.factory.Worker(t)
+	token := testutil.Token(t, user)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		status int
+	}{
+		{"get", "GET", fmt.Sprintf("/api/profile/%d", user.ID), nil, 200},
+		{"update", "PUT", fmt.Sprintf("/api/profile/%d", user.ID), map[string]any{"full_name": "Renamed", "bio": "Electrician"}, 200},
+		{"missing", "GET", "/api/profile/9999", nil, 404},
+		{"update missing", "PUT", "/api/profile/9999", map[string]any{"full_name": "Ghost"}, 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it.t = t
+			if resp := it.do(tt.method, tt.path, token, tt.body); resp.Status != tt.status {
+				t.Fatalf("got %d, want %d: %v", resp.Status, tt.status, resp.Body)
+			}
+		})
+	}
+
+	it.t = t
+	resp := it.do("GET", fmt.Sprintf("/api/profile/%d", user.ID), token, nil)
+	if resp.Body["full_name"] != "Renamed" || resp.Body["bio"] != "Electrician" {
+		t.Fatalf("profile not updated: %v", resp.Body)
+	}
+}
+
+func TestIntegrationDualRole(t *testing.T) {
+	it := newIntegration(t)
+	user := it.factory.Worker(t)
+	token := testutil.Token(t, user)
+	other := it.factory.Employer(t)
+	theirJob := it.factory.Job(t, other.ID)
+
+	resp := it.do("POST", "/api/me/roles", token, map[string]any{"role": "employer"})
+	if resp.Status != 201 {
+		t.Fatalf("add role: %d %v", resp.Status, resp.Body)
+	}
+	token = resp.Body["token"].(string)
+	if roles := resp.Body["user"].(map[string]any)["roles"].([]any); len(roles) != 2 {
+		t.Fatalf("roles = %v, want worker and employer", roles)
+	}
+	if resp := it.do("POST", "/api/me/roles", token, map[string]any{"role": "employer"}); resp.Status != 409 || resp.code() != "role_held" {
+		t.Fatalf("add role twice: %d %v", resp.Status, resp.Body)
+	}
+
+	// Becoming an employer made them an organization to post from, and
+	// they keep working as before
+	resp = it.do("POST", "/api/jobs", token, map[string]any{"title": "Helper", "description": "Carry planks", "location": "Kochi", "expiry_days": 3})
+	if resp.Status != 201 {
+		t.Fatalf("post job: %d %v", resp.Status, resp.Body)
+	}
+	ownJob := int(resp.Body["job"].(map[string]any)["id"].(float64))
+	if resp := it.do("POST", "/api/applications", token, map[string]any{"job_id": theirJob.ID}); resp.Status != 201 {
+		t.Fatalf("apply: %d %v", resp.Status, resp.Body)
+	}
+	if resp := it.do("POST", "/api/applications", token, map[string]any{"job_id": ownJob}); resp.Status != 400 {
+		t.Fatalf("apply to own job: %d %v", resp.Status, resp.Body)
+	}
+
+	resp = it.do("GET", fmt.Sprintf("/api/profile/%d", user.ID), token, nil)
+	employer, _ := resp.Body["employer"].(map[string]any)
+	if resp.Body["worker"] == nil || employer == nil || employer["jobs_posted"] != float64(1) {
+		t.Fatalf("profile: %v", resp.Body)
+	}
+
+	resp = it.do("PUT", "/api/me/active-role", token, map[string]any{"role": "employer"})
+	if resp.Status != 200 || resp.Body["user"].(map[string]any)["user_type"] != "employer" {
+		t.Fatalf("switch: %d %v", resp.Status, resp.Body)
+	}
+	if resp := it.do("PUT", "/api/me/active-role", token, map[string]any{"role": "admin"}); resp.Status != 400 {
+		t.Fatalf("switch to admin: %d %v", resp.Status, resp.Body)
+	}
+
+	// Logging in again starts in the role they left active
+	resp = it.do("POST", "/api/login", "", map[string]any{"email": user.Email, "password": testutil.Password})
+	if resp.Body["user"].(map[string]any)["user_type"] != "employer" {
+		t.Fatalf("login: %v", resp.Body)
+	}
+}
+
+func TestIntegrationWorkerDirectory(t *testing.T) {
+	it := newIntegration(t)
+	employer := it.factory.Employer(t)
+	employerToken := testutil.Token(t, employer)
+	job := it.factory.Job(t, employer.ID)
+	kochi, delhi := "Kochi", "Delhi"
+	veteran := it.factory.Worker(t, func(u *models.User) { u.Location = &kochi })
+	novice := it.factory.Worker(t, func(u *models.User) { u.Location = &delhi })
+
+	for worker, years := range map[*models.User]int{veteran: 6, novice: 1} {
+		resp := it.do("PUT", "/api/me/skills", testutil.Token(t, worker), map[string]any{"skills": []map[string]any{{"category_id": 1, "experience_years": years}}})
+		if resp.Status != 200 {
+			t.Fatalf("skills: %d %v", resp.Status, resp.Body)
+		}
+	}
+
+	// Invited, the veteran accepts and is hired like any applicant
+	resp := it.do("POST", fmt.Sprintf("/api/jobs/%d/invite", job.ID), employerToken, map[string]any{"worker_id": veteran.ID})
+	if resp.Status != 201 {
+		t.Fatalf("invite: %d %v", resp.Status, resp.Body)
+	}
+	appID := int(resp.Body["application"].(map[string]any)["id"].(float64))
+	if resp := it.do("PUT", fmt.Sprintf("/api/applications/%d", appID), employerToken, map[string]any{"status": "accepted"}); resp.Status != 409 {
+		t.Fatalf("hire before accepting: %d %v", resp.Status, resp.Body)
+	}
+	if resp := it.do("PUT", fmt.Sprintf("/api/applications/%d/invitation", appID), testutil.Token(t, veteran), map[string]any{"response": "accept"}); resp.Status != 200 {
+		t.Fatalf("accept invitation: %d %v", resp.Status, resp.Body)
+	}
+	if resp := it.do("PUT", fmt.Sprintf("/api/applications/%d", appID), employerToken, map[string]any{"status": "accepted"}); resp.Status != 200 {
+		t.Fatalf("hire: %d %v", resp.Status, resp.Body)
+	}
+
+	var bookingID int
+	if err := it.db.Pool.QueryRow(context.Background(),
+		`UPDATE bookings SET status = 'completed', completed_at = NOW() WHERE application_id = $1 RETURNING id`, appID,
+	).Scan(&bookingID); err != nil {
+		t.Fatal(err)
+	}
+	if resp := it.do("POST", fmt.Sprintf("/api/bookings/%d/rating", bookingID), employerToken, map[string]any{"rating": 4}); resp.Status != 200 {
+		t.Fatalf("rate: %d %v", resp.Status, resp.Body)
+	}
+
+	resp = it.do("GET", "/api/workers?category_id=1&min_experience=5&location=koc&min_rating=4", "", nil)
+	workers := resp.Body["workers"].([]any)
+	if resp.Status != 200 || len(workers) != 1 || resp.Body["total"] != float64(1) {
+		t.Fatalf("search: %d %v", resp.Status, resp.Body)
+	}
+	card := workers[0].(map[string]any)
+	if card["id"] != float64(veteran.ID) || card["rating"] != float64(4) || card["rating_count"] != float64(1) {
+		t.Errorf("card = %v", card)
+	}
+	if _, ok := card["email"]; ok {
+		t.Errorf("directory shows email: %v", card)
+	}
+
+	// Unrated workers come after rated ones
+	resp = it.do("GET", "/api/workers?category_id=1", "", nil)
+	workers = resp.Body["workers"].([]any)
+	if len(workers) != 2 || workers[0].(map[string]any)["id"] != float64(veteran.ID) {
+		t.Errorf("ranking: %v", resp.Body)
+	}
+}
+
+func TestIntegrationRecommendations(t *testing.T) {
+	it := newIntegration(t)
+	employer := it.factory.Employer(t)
+	worker := it.factory.Worker(t)
+	token := testutil.Token(t, worker)
+	plumbing, painting := 1, 2
+	kochiLat, kochiLng, delhiLat, delhiLng := 9.9312, 76.2673, 28.6139, 77.2090
+	near := it.factory.Job(t, employer.ID, func(j *models.Job) {
+		j.CategoryID, j.Latitude, j.Longitude = &plumbing, &kochiLat, &kochiLng
+	})
+	far := it.factory.Job(t, employer.ID, func(j *models.Job) {
+		j.CategoryID, j.Latitude, j.Longitude = &painting, &delhiLat, &delhiLng
+	})
+
+	if resp := it.do("PUT", "/api/me/skills", token, map[string]any{"skills": []map[string]any{{"category_id": plumbing, "experience_years": 3}}}); resp.Status != 200 {
+		t.Fatalf("skills: %d %v", resp.Status, resp.Body)
+	}
+	resp := it.do("PUT", fmt.Sprintf("/api/profile/%d", worker.ID), token, map[string]any{"full_name": worker.FullName, "latitude": kochiLat, "longitude": kochiLng})
+	if resp.Status != 200 {
+		t.Fatalf("profile: %d %v", resp.Status, resp.Body)
+	}
+
+	recommended := func() []int {
+		t.Helper()
+		resp := it.do("GET", "/api/jobs/recommended?limit=50", token, nil)
+		if resp.Status != 200 {
+			t.Fatalf("recommended: %d %v", resp.Status, resp.Body)
+		}
+		var ids []int
+		for _, r := range resp.Body["recommendations"].([]any) {
+			rec := r.(map[string]any)
+			id := int(rec["job"].(map[string]any)["id"].(float64))
+			ids = append(ids, id)
+			if id == near.ID && !slices.Contains(rec["reasons"].([]any), any("Less than 1 km away")) {
+				t.Errorf("reasons for the nearby job = %v", rec["reasons"])
+			}
+		}
+		return ids
+	}
+	ids := recommended()
+	if i, j := slices.Index(ids, near.ID), slices.Index(ids, far.ID); i == -1 || j == -1 || i > j {
+		t.Errorf("recommended %v, want job %d before job %d", ids, near.ID, far.ID)
+	}
+
+	// Applying takes a job off the list
+	it.factory.Application(t, near.ID, worker.ID)
+	if ids := recommended(); slices.Contains(ids, near.ID) {
+		t.Errorf("recommended %v after applying to job %d", ids, near.ID)
+	}
+}
+
+func TestIntegrationApplicantReviews(t *testing.T) {
+	it := newIntegration(t)
+	employer := it.factory.Employer(t)
+	employerToken := testutil.Token(t, employer)
+	plumbing := 1
+	job := it.factory.Job(t, employer.ID, func(j *models.Job) { j.CategoryID = &plumbing })
+	plumber := it.factory.Worker(t)
+	novice := it.factory.Worker(t)
+	if resp := it.do("PUT", "/api/me/skills", testutil.Token(t, plumber), map[string]any{"skills": []map[string]any{{"category_id": plumbing, "experience_years": 4}}}); resp.Status != 200 {
+		t.Fatalf("skills: %d %v", resp.Status, resp.Body)
+	}
+	first := it.factory.Application(t, job.ID, plumber.ID)
+	second := it.factory.Application(t, job.ID, novice.ID)
+
+	path := fmt.Sprintf("/api/applications/%d/review", first.ID)
+	if resp := it.do("PUT", path, employerToken, map[string]any{"shortlisted": true, "tags": []string{"Tiler"}}); resp.Status != 200 {
+		t.Fatalf("review: %d %v", resp.Status, resp.Body)
+	}
+	resp := it.do("PUT", path, employerToken, map[string]any{"notes": "Call back"})
+	review := resp.Body["review"].(map[string]any)
+	if resp.Status != 200 || review["shortlisted"] != true || review["notes"] != "Call back" || review["updated_by"] != float64(employer.ID) {
+		t.Fatalf("second review: %d %v", resp.Status, resp.Body)
+	}
+
+	resp = it.do("GET", fmt.Sprintf("/api/applications/job/%d?sort=match", job.ID), employerToken, nil)
+	apps := resp.Body["applications"].([]any)
+	if resp.Status != 200 || len(apps) != 2 || apps[0].(map[string]any)["id"] != float64(first.ID) {
+		t.Fatalf("by match: %d %v", resp.Status, resp.Body)
+	}
+	if tags := apps[0].(map[string]any)["review"].(map[string]any)["tags"].([]any); len(tags) != 1 || tags[0] != "tiler" {
+		t.Errorf("tags = %v", tags)
+	}
+
+	resp = it.do("POST", fmt.Sprintf("/api/jobs/%d/applications/bulk", job.ID), employerToken,
+		map[string]any{"application_ids": []int{first.ID, second.ID}, "status": "rejected"})
+	if resp.Status != 200 || resp.Body["updated"] != float64(2) {
+		t.Fatalf("bulk reject: %d %v", resp.Status, resp.Body)
+	}
+}
+
+func TestIntegrationScreeningQuestions(t *testing.T) {
+	it := newIntegration(t)
+	employer := it.factory.Employer(t)
+	employerToken := testutil.Token(t, employer)
+	resp := it.do("POST", "/api/jobs", employerToken, map[string]any{
+		"title": "Tile", "description": "Bathroom", "location": "Town", "expiry_days": 3,
+		"questions": []map[string]any{
+			{"prompt": "Do you own a ladder?", "kind": "yes_no", "required": true, "reject_answer": false},
+			{"prompt": "Which shift?", "kind": "choice", "required": true, "choices": []string{"Day", "Night"}, "reject_choices": []string{"Night"}},
+		},
+	})
+	if resp.Status != 201 {
+		t.Fatalf("post job: %d %v", resp.Status, resp.Body)
+	}
+	jobID := int(resp.Body["job"].(map[string]any)["id"].(float64))
+	questions := resp.Body["questions"].([]any)
+	ladder := questions[0].(map[string]any)["id"]
+	shift := questions[1].(map[string]any)["id"]
+
+	resp = it.do("GET", fmt.Sprintf("/api/jobs/%d", jobID), "", nil)
+	public := resp.Body["questions"].([]any)
+	if _, ok := public[1].(map[string]any)["reject_choices"]; resp.Status != 200 || len(public) != 2 || ok {
+		t.Fatalf("public questions: %d %v", resp.Status, resp.Body)
+	}
+
+	apply := func(worker *models.User, ladderAnswer, shiftAnswer string) string {
+		t.Helper()
+		resp := it.do("POST", "/api/applications", testutil.Token(t, worker), map[string]any{"job_id": jobID, "answers": []map[string]any{
+			{"question_id": ladder, "answer": ladderAnswer}, {"question_id": shift, "answer": shiftAnswer},
+		}})
+		if resp.Status != 201 {
+			t.Fatalf("apply: %d %v", resp.Status, resp.Body)
+		}
+		return resp.Body["application"].(map[string]any)["status"].(string)
+	}
+	if status := apply(it.factory.Worker(t), "yes", "day"); status != models.ApplicationPending {
+		t.Errorf("passing answers: status %s", status)
+	}
+	if status := apply(it.factory.Worker(t), "yes", "night"); status != models.ApplicationRejected {
+		t.Errorf("night shift: status %s", status)
+	}
+
+	resp = it.do("GET", fmt.Sprintf("/api/applications/job/%d", jobID), employerToken, nil)
+	apps := resp.Body["applications"].([]any)
+	if resp.Status != 200 || len(apps) != 2 {
+		t.Fatalf("applicants: %d %v", resp.Status, resp.Body)
+	}
+	// Newest first: the night shift applicant, knocked out on the choice
+	answers := apps[0].(map[string]any)["answers"].([]any)
+	if len(answers) != 2 || answers[1].(map[string]any)["answer"] != "Night" || answers[1].(map[string]any)["knocked_out"] != true {
+		t.Errorf("answers = %v", answers)
+	}
+	var screenedOut int
+	if err := it.db.Pool.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM audit_events WHERE action = 'application.screened_out'`).Scan(&screenedOut); err != nil {
+		t.Fatal(err)
+	}
+	if screenedOut != 1 {
+		t.Errorf("screened out events = %d, want 1", screenedOut)
+	}
+
+	// An invited worker is screened when they accept
+	invitee := it.factory.Worker(t)
+	resp = it.do("POST", fmt.Sprintf("/api/jobs/%d/invite", jobID), employerToken, map[string]any{"worker_id": invitee.ID})
+	if resp.Status != 201 {
+		t.Fatalf("invite: %d %v", resp.Status, resp.Body)
+	}
+	invitation := fmt.Sprintf("/api/applications/%d/invitation", int(resp.Body["application"].(map[string]any)["id"].(float64)))
+	if resp := it.do("PUT", invitation, testutil.Token(t, invitee), map[string]any{"response": "accept"}); resp.Status != 400 {
+		t.Fatalf("accept without answers: %d %v", resp.Status, resp.Body)
+	}
+	resp = it.do("PUT", invitation, testutil.Token(t, invitee), map[string]any{"response": "accept", "answers": []map[string]any{
+		{"question_id": ladder, "answer": "yes"}, {"question_id": shift, "answer": "night"},
+	}})
+	if resp.Status != 200 || resp.Body["application"].(map[string]any)["status"] != models.ApplicationRejected {
+		t.Fatalf("accept on the night shift: %d %v", resp.Status, resp.Body)
+	}
+	var knockedOut int
+	if err := it.db.Pool.QueryRow(context.Background(), `
+		SELECT COUNT(*) FROM application_answers aa JOIN applications a ON a.id = aa.application_id
+		WHERE a.worker_id = $1 AND aa.knocked_out`, invitee.ID).Scan(&knockedOut); err != nil {
+		t.Fatal(err)
+	}
+	if knockedOut != 1 {
+		t.Errorf("invitee's knocked out answers = %d, want 1", knockedOut)
+	}
+}
+
+func TestIntegrationUploads(t *testing.T) {
+	it := newIntegration(t)
+	user := it.factory.Worker(t)
+	other := it.factory.Worker(t)
+	token := testutil.Token(t, user)
+
+	// Replacing an avatar points the row at new keys
+	var keys []string
+	for range 2 {
+		body, contentType := multipartBody(t, "me.png", testPNG(t, 64, 64), nil)
+		if resp := it.upload("PUT", "/api/me/avatar", token, body, contentType); resp.Status != 200 {
+			t.Fatalf("avatar: %d %v", resp.Status, resp.Body)
+		}
+		var key string
+		if err := it.db.Pool.QueryRow(context.Background(),
+			`SELECT avatar_key FROM users WHERE id = $1`, user.ID).Scan(&key); err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+	}
+	if keys[0] == keys[1] {
+		t.Errorf("avatar key reused: %v", keys)
+	}
+	resp := it.do("GET", fmt.Sprintf("/api/profile/%d", user.ID), token, nil)
+	if url, _ := resp.Body["avatar_thumbnail_url"].(string); url == "" {
+		t.Errorf("profile has no thumbnail: %v", resp.Body)
+	}
+
+	body, contentType := multipartBody(t, "cert.pdf", []byte("%PDF-1.4\n"), map[string]string{"kind": "certificate"})
+	resp = it.upload("POST", "/api/me/documents", token, body, contentType)
+	if resp.Status != 201 {
+		t.Fatalf("document: %d %v", resp.Status, resp.Body)
+	}
+	docPath := fmt.Sprintf("/api/me/documents/%v", resp.Body["id"])
+
+	// Documents are private to their owner
+	if resp := it.do("GET", "/api/me/documents", testutil.Token(t, other), nil); len(resp.Body["documents"].([]any)) != 0 {
+		t.Errorf("other user sees documents: %v", resp.Body)
+	}
+	if resp := it.do("DELETE", docPath, testutil.Token(t, other), nil); resp.Status != 404 {
+		t.Errorf("other user delete: %d", resp.Status)
+	}
+	if resp := it.do("DELETE", docPath, token, nil); resp.Status != 200 {
+		t.Errorf("owner delete: %d %v", resp.Status, resp.Body)
+	}
+
+	var uploaded, deleted, avatars int
+	err := it.db.Pool.QueryRow(context.Background(), `
+		SELECT COUNT(*) FILTER (WHERE action = 'document.uploaded'),
+		       COUNT(*) FILTER (WHERE action = 'document.deleted'),
+		       COUNT(*) FILTER (WHERE action = 'profile.avatar_updated')
+		FROM audit_events WHERE actor_id = $1`, user.ID).Scan(&uploaded, &deleted, &avatars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uploaded != 1 || deleted != 1 || avatars != 2 {
+		t.Errorf("audit events: uploaded %d, deleted %d, avatar %d", uploaded, deleted, avatars)
+	}
+}
+
+func TestIntegrationJobAttachments(t *testing.T) {
+	it := newIntegration(t, func(c *config.Config) { c.Storage.MaxJobAttachments = 2 })
+	employerUser := it.factory.Employer(t)
+	employer := testutil.Token(t, employerUser)
+	worker := it.factory.Worker(t)
+	pdf := []byte("%PDF-1.4\n")
+	fields := map[string]string{"title": "Tile", "description": "Bathroom", "location": "Town", "expiry_days": "3"}
+
+	body, contentType := jobForm(t, fields, testPNG(t, 32, 32), pdf)
+	resp := it.upload("POST", "/api/jobs", employer, body, contentType)
+	if resp.Status != 201 {
+		t.Fatalf("create: %d %v", resp.Status, resp.Body)
+	}
+	jobID := int(resp.Body["job"].(map[string]any)["id"].(float64))
+	jobPath := fmt.Sprintf("/api/jobs/%d", jobID)
+
+	// The attachment limit counts what the job already has
+	body, contentType = jobForm(t, fields, pdf)
+	if resp := it.upload("PUT", jobPath, employer, body, contentType); resp.Status != 400 {
+		t.Errorf("third attachment: %d %v", resp.Status, resp.Body)
+	}
+	if resp := it.do("PUT", jobPath, testutil.Token(t, it.factory.Employer(t)), map[string]any{"title": "Mine", "description": "Now", "location": "Town"}); resp.Status != 403 {
+		t.Errorf("other employer edit: %d", resp.Status)
+	}
+	resp = it.do("PUT", jobPath, employer, map[string]any{"title": "Tile floor", "description": "Bathroom", "location": "Town"})
+	if resp.Status != 200 || resp.Body["job"].(map[string]any)["title"] != "Tile floor" {
+		t.Errorf("edit: %d %v", resp.Status, resp.Body)
+	}
+
+	resp = it.do("GET", jobPath, "", nil)
+	attachments, _ := resp.Body["attachments"].([]any)
+	if len(attachments) != 2 {
+		t.Fatalf("attachments: %v", resp.Body)
+	}
+	first := attachments[0].(map[string]any)
+	if resp := it.do("DELETE", fmt.Sprintf("%s/attachments/%v", jobPath, first["id"]), employer, nil); resp.Status != 200 {
+		t.Errorf("delete attachment: %d %v", resp.Status, resp.Body)
+	}
+
+	// Deleting the job takes its applications and attachments with it
+	it.factory.Application(t, jobID, worker.ID)
+	if resp := it.do("DELETE", jobPath, employer, nil); resp.Status != 200 {
+		t.Fatalf("delete job: %d %v", resp.Status, resp.Body)
+	}
+	var jobs, apps, files int
+	err := it.db.Pool.QueryRow(context.Background(), `
+		SELECT (SELECT COUNT(*) FROM jobs WHERE id = $1),
+		       (SELECT COUNT(*) FROM applications WHERE job_id = $1),
+		       (SELECT COUNT(*) FROM job_attachments WHERE job_id = $1)`, jobID).Scan(&jobs, &apps, &files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jobs+apps+files != 0 {
+		t.Errorf("left behind: %d jobs, %d applications, %d attachments", jobs, apps, files)
+	}
+
+	var actions []string
+	rows, err := it.db.Pool.Query(context.Background(),
+		`SELECT action FROM audit_events WHERE target_type = 'job' AND target_id = $1 ORDER BY id`, jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var action string
+		rows.Scan(&action)
+		actions = append(actions, action)
+	}
+	want := "job.created job.attachment_added job.attachment_added job.updated job.attachment_deleted job.deleted"
+	if got := strings.Join(actions, " "); got != want {
+		t.Errorf("audit trail = %s, want %s", got, want)
+	}
+}
+
+func TestIntegrationAuditLog(t *testing.T) {
+	it := newIntegration(t)
+	admin := testutil.Token(t, it.factory.User(t, models.RoleAdmin))
+	employerUser := it.factory.Employer(t)
+	employer := testutil.Token(t, employerUser)
+	// Forget the fixtures' own user.registered events
+	it.exec(`DELETE FROM audit_events`)
+
+	// One of each audited action, through the API
+	resp := it.do("POST", "/api/register", "", map[string]any{"email": "audit@example.com", "password": testutil.Password, "full_name": "Audited", "user_type": "worker"})
+	if resp.Status != 201 {
+		t.Fatalf("register: %d %v", resp.Status, resp.Body)
+	}
+	workerID := int(resp.Body["user"].(map[string]any)["id"].(float64))
+	it.do("POST", "/api/login", "", map[string]any{"email": "audit@example.com", "password": "wrong"})
+	resp = it.do("POST", "/api/login", "", map[string]any{"email": "audit@example.com", "password": testutil.Password})
+	worker := resp.Body["token"].(string)
+	it.do("PUT", fmt.Sprintf("/api/profile/%d", workerID), worker, map[string]any{"full_name": "Audited", "bio": "Roofer"})
+	resp = it.do("POST", "/api/jobs", employer, map[string]any{"title": "Roof", "description": "Leak", "location": "Town", "expiry_days": 3})
+	jobID := int(resp.Body["job"].(map[string]any)["id"].(float64))
+	resp = it.do("POST", "/api/applications", worker, map[string]any{"job_id": jobID})
+	appID := int(resp.Body["application"].(map[string]any)["id"].(float64))
+	it.do("PUT", fmt.Sprintf("/api/applications/%d", appID), employer, map[string]any{"status": "accepted"})
+
+	resp = it.do("GET", "/api/admin/audit-events?per_page=100", admin, nil)
+	if resp.Status != 200 {
+		t.Fatalf("list: %d %v", resp.Status, resp.Body)
+	}
+	var actions []string
+	for _, e := range resp.Body["events"].([]any) {
+		actions = append(actions, e.(map[string]any)["action"].(string))
+	}
+	want := []string{"application.accepted", "application.submitted", "job.created", "profile.updated", "auth.login", "auth.login_failed", "user.registered"}
+	if fmt.Sprint(actions) != fmt.Sprint(want) {
+		t.Fatalf("actions = %v, want %v", actions, want)
+	}
+
+	// The profile event records who, from where, and only what changed
+	resp = it.do("GET", fmt.Sprintf("/api/admin/audit-events?action=profile.updated&actor_id=%d", workerID), admin, nil)
+	events := resp.Body["events"].([]any)
+	if resp.Body["total"] != float64(1) || len(events) != 1 {
+		t.Fatalf("filtered: %v", resp.Body)
+	}
+	event := events[0].(map[string]any)
+	before, after := event["before"].(map[string]any), event["after"].(map[string]any)
+	if _, ok := before["bio"]; !ok || before["bio"] != nil || after["bio"] != "Roofer" {
+		t.Errorf("diff = %v -> %v", before, after)
+	}
+	if _, ok := after["full_name"]; ok {
+		t.Errorf("unchanged full_name in diff: %v", after)
+	}
+	if event["ip"] == nil || event["request_id"] == nil {
+		t.Errorf("missing client details: %v", event)
+	}
+
+	// Pages are newest first and count every match
+	resp = it.do("GET", "/api/admin/audit-events?page=2&per_page=3", admin, nil)
+	if resp.Body["total"] != float64(len(want)) || len(resp.Body["events"].([]any)) != 3 {
+		t.Fatalf("page 2: %v", resp.Body)
+	}
+
+	if resp := it.do("GET", "/api/admin/audit-events", employer, nil); resp.Status != 403 {
+		t.Fatalf("employer: %d", resp.Status)
+	}
+}
+
+func TestIntegrationOrganizations(t *testing.T) {
+	it := newIntegration(t)
+	ownerUser := it.factory.Employer(t)
+	owner := testutil.Token(t, ownerUser)
+	helperUser := it.factory.Employer(t)
+	helper := testutil.Token(t, helperUser)
+	job := it.factory.Job(t, ownerUser.ID)
+	edited := map[string]any{"title": "Fix sink", "description": "Leaky tap", "location": "Kochi"}
+
+	// Registering as an employer made each of them an organization
+	resp := it.do("GET", "/api/organizations", owner, nil)
+	if resp.Status != 200 || resp.Body["count"] != float64(1) {
+		t.Fatalf("owner's organizations: %d %v", resp.Status, resp.Body)
+	}
+	org := resp.Body["organizations"].([]any)[0].(map[string]any)
+	if org["role"] != "owner" || org["id"] != float64(job.OrganizationID) {
+		t.Fatalf("owner's organization = %v, want owner of %d", org, job.OrganizationID)
+	}
+	orgPath := fmt.Sprintf("/api/organizations/%d", job.OrganizationID)
+
+	if resp := it.do("PUT", fmt.Sprintf("/api/jobs/%d", job.ID), helper, edited); resp.Status != 403 {
+		t.Fatalf("edit before joining: %d %v", resp.Status, resp.Body)
+	}
+
+	resp = it.do("POST", orgPath+"/invitations", owner, map[string]any{"email": strings.ToUpper(helperUser.Email), "role": "manager"})
+	if resp.Status != 201 {
+		t.Fatalf("invite: %d %v", resp.Status, resp.Body)
+	}
+	token := resp.Body["invitation"].(map[string]any)["token"].(string)
+
+	// Inviting again replaces the open invitation
+	if resp := it.do("POST", orgPath+"/invitations", owner, map[string]any{"email": helperUser.Email, "role": "manager"}); resp.Status != 201 {
+		t.Fatalf("invite again: %d %v", resp.Status, resp.Body)
+	}
+	if resp := it.do("POST", "/api/invitations/accept", helper, map[string]any{"token": token}); resp.Status != 404 {
+		t.Fatalf("accept replaced invitation: %d %v", resp.Status, resp.Body)
+	}
+	resp = it.do("GET", orgPath+"/invitations", owner, nil)
+	if resp.Status != 200 || resp.Body["count"] != float64(1) {
+		t.Fatalf("invitations: %d %v", resp.Status, resp.Body)
+	}
+	it.exec(`DELETE FROM organization_invitations`)
+
+	resp = it.do("POST", orgPath+"/invitations", owner, map[string]any{"email": helperUser.Email, "role": "manager"})
+	token = resp.Body["invitation"].(map[string]any)["token"].(string)
+	if resp := it.do("POST", "/api/invitations/accept", owner, map[string]any{"token": token}); resp.Status != 403 {
+		t.Fatalf("accept someone else's invitation: %d %v", resp.Status, resp.Body)
+	}
+	if resp := it.do("POST", "/api/invitations/accept", helper, map[string]any{"token": token}); resp.Status != 200 {
+		t.Fatalf("accept: %d %v", resp.Status, resp.Body)
+	}
+
+	// The manager edits the owner's job; it stays the organization's
+	resp = it.do("PUT", fmt.Sprintf("/api/jobs/%d", job.ID), helper, edited)
+	if resp.Status != 200 {
+		t.Fatalf("edit as manager: %d %v", resp.Status, resp.Body)
+	}
+	if got := resp.Body["job"].(map[string]any); got["organization_id"] != float64(job.OrganizationID) || got["employer_id"] != float64(ownerUser.ID) {
+		t.Fatalf("edited job = %v", got)
+	}
+
+	// With two organizations to manage, posting needs the organization
+	if resp := it.do("POST", "/api/jobs", helper, map[string]any{"title": "Tile", "description": "Bathroom", "location": "Kochi", "expiry_days": 3}); resp.Status != 400 {
+		t.Fatalf("post without organization: %d %v", resp.Status, resp.Body)
+	}
+	resp = it.do("POST", orgPath+"/jobs", helper, map[string]any{"title": "Tile", "description": "Bathroom", "location": "Kochi", "expiry_days": 3})
+	if resp.Status != 201 || resp.Body["job"].(map[string]any)["organization_id"] != float64(job.OrganizationID) {
+		t.Fatalf("post for organization: %d %v", resp.Status, resp.Body)
+	}
+
+	// The only owner can't step down, but can once there's another
+	memberPath := fmt.Sprintf("%s/members/%d", orgPath, ownerUser.ID)
+	if resp := it.do("PUT", memberPath, owner, map[string]any{"role": "manager"}); resp.Status != 409 || resp.code() != "last_owner" {
+		t.Fatalf("demote only owner: %d %v", resp.Status, resp.Body)
+	}
+	if resp := it.do("PUT", fmt.Sprintf("%s/members/%d", orgPath, helperUser.ID), owner, map[string]any{"role": "owner"}); resp.Status != 200 {
+		t.Fatalf("promote helper: %d %v", resp.Status, resp.Body)
+	}
+	if resp := it.do("DELETE", memberPath, owner, nil); resp.Status != 200 {
+		t.Fatalf("leave: %d %v", resp.Status, resp.Body)
+	}
+	if resp := it.do("GET", orgPath, owner, nil); resp.Status != 403 {
+		t.Fatalf("organization after leaving: %d %v", resp.Status, resp.Body)
+	}
+}
+
+func TestIntegrationHealth(t *testing.T) {
+	it := newIntegration(t)
+	for _, path := range []string{"/health", "/livez", "/readyz"} {
+		if resp := it.do(http.MethodGet, path, "", nil); resp.Status != 200 {
+			t.Fatalf("%s: %d %v", path, resp.Status, resp.Body)
+		}
+	}
+
+	// A schema behind the code is not ready
+	it.exec(`DELETE FROM schema_migrations WHERE version = (SELECT MAX(version) FROM schema_migrations)`)
+	defer it.exec(`INSERT INTO schema_migrations (version) VALUES ($1)`, latestMigration(t))
+
+	resp := it.do(http.MethodGet, "/readyz", "", nil)
+	if resp.Status != http.StatusServiceUnavailable {
+		t.Fatalf("readyz with missing migration: %d %v", resp.Status, resp.Body)
+	}
+}
+
+func TestIntegrationBaseline(t *testing.T) {
+	it := newIntegration(t)
+	ctx := context.Background()
+	files, err := fs.Glob(migrations.FS, "*.sql")
+	if err != nil || len(files) < 2 {
+		t.Fatalf("migrations: %v %v", files, err)
+	}
+	defer db.Baseline(ctx, it.db.Pool, migrations.FS, latestMigration(t))
+
+	// A database set up with psql up to the second-to-last file keeps
+	// the last one pending
+	it.exec(`DELETE FROM schema_migrations`)
+	through := files[len(files)-2]
+	if err := db.Baseline(ctx, it.db.Pool, migrations.FS, through[:3]); err != nil {
+		t.Fatal(err)
+	}
+	current, expected, err := db.MigrationStatus(ctx, it.db.Pool, migrations.FS)
+	if err != nil || current != through || expected != latestMigration(t) {
+		t.Errorf("status = %q, %q, %v; want %q behind %q", current, expected, err, through, latestMigration(t))
+	}
+
+	if err := db.Baseline(ctx, it.db.Pool, migrations.FS, "999"); err == nil {
+		t.Error("baseline through a missing migration succeeded")
+	}
+}
+
+func latestMigration(t *testing.T) string {
+	t.Helper()
+	entries, err := fs.Glob(migrations.FS, "*.sql")
+	if err != nil || len(entries) == 0 {
+		t.Fatalf("no migrations: %v", err)
+	}
+	return entries[len(entries)-1]
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/metrics"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+)
+
+// Requests are labelled by route template and accepting an application
+// counts as a hire
+func TestMetrics(t *testing.T) {
+	router, _, _ := newContractRouter(t)
+	hires := testutil.ToFloat64(metrics.Hires)
+
+	for _, path := range []string{"/api/jobs/1", "/api/jobs/2"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	req := httptest.NewRequest("PUT", "/api/applications/1", bytes.NewBufferString(`{"status":"accepted"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token(t, 1, models.RoleEmployer))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got := testutil.ToFloat64(metrics.Hires) - hires; got != 1 {
+		t.Errorf("hires_total grew by %v, want 1", got)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	if !strings.Contains(body, `http_requests_total{method="GET",route="/api/jobs/:id",status="200"}`) {
+		t.Errorf("no per-route request counter in /metrics:\n%s", body)
+	}
+	if strings.Contains(body, `route="/api/jobs/1"`) {
+		t.Error("raw paths must not be used as labels")
+	}
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+)
+
+// newTeamRouter has employer 1 own organization 1, with jobs 1 and 2, and
+// employer 5 registered but not on its team
+func newTeamRouter(t *testing.T) (owner, helper apiClient) {
+	t.Helper()
+	deps := newFakeDeps(t)
+	deps.Users.(*fakeUsers).byID[5] = &models.User{ID: 5, Email: "Helper@example.com", FullName: "Helper", UserType: models.RoleEmployer, Roles: []models.Role{models.RoleEmployer}, CreatedAt: time.Now()}
+	clients := newClients(t, deps, token(t, 1, models.RoleEmployer), token(t, 5, models.RoleEmployer))
+	return clients[0], clients[1]
+}
+
+func TestInvitedManagerEditsJobs(t *testing.T) {
+	owner, helper := newTeamRouter(t)
+	editedJob := map[string]any{"title": "Fix sink", "description": "Leaky tap", "location": "Town"}
+
+	if code, _ := sendJSON(helper, "PUT", "/api/jobs/1", editedJob); code != http.StatusForbidden {
+		t.Fatalf("edit before joining: status = %d, want 403", code)
+	}
+
+	code, body := sendJSON(owner, "POST", "/api/organizations/1/invitations", map[string]any{"email": "helper@example.com", "role": "viewer"})
+	if code != http.StatusCreated {
+		t.Fatalf("invite: status = %d; body: %s", code, body)
+	}
+	var invited struct {
+		Invitation models.Invitation `json:"invitation"`
+	}
+	json.Unmarshal(body, &invited)
+
+	// Only the invitee can use the token, and only once
+	if code, _ := sendJSON(owner, "POST", "/api/invitations/accept", map[string]any{"token": invited.Invitation.Token}); code != http.StatusForbidden {
+		t.Errorf("accept as someone else: status = %d, want 403", code)
+	}
+	if code, body := sendJSON(helper, "POST", "/api/invitations/accept", map[string]any{"token": invited.Invitation.Token}); code != http.StatusOK {
+		t.Fatalf("accept: status = %d; body: %s", code, body)
+	}
+	if code, _ := sendJSON(helper, "POST", "/api/invitations/accept", map[string]any{"token": invited.Invitation.Token}); code != http.StatusNotFound {
+		t.Errorf("accept twice: status = %d, want 404", code)
+	}
+
+	// Viewers see applicants but can't edit
+	if code, _ := sendJSON(helper, "GET", "/api/applications/job/1", nil); code != http.StatusOK {
+		t.Errorf("applicants as viewer: status = %d, want 200", code)
+	}
+	if code, _ := sendJSON(helper, "PUT", "/api/jobs/1", editedJob); code != http.StatusForbidden {
+		t.Errorf("edit as viewer: status = %d, want 403", code)
+	}
+	if code, _ := sendJSON(helper, "GET", "/api/organizations/1/invitations", nil); code != http.StatusForbidden {
+		t.Errorf("invitations as viewer: status = %d, want 403", code)
+	}
+
+	if code, body := sendJSON(owner, "PUT", "/api/organizations/1/members/5", map[string]any{"role": "manager"}); code != http.StatusOK {
+		t.Fatalf("promote: status = %d; body: %s", code, body)
+	}
+	if code, body := sendJSON(helper, "PUT", "/api/jobs/1", editedJob); code != http.StatusOK {
+		t.Errorf("edit as manager: status = %d; body: %s", code, body)
+	}
+
+	// Jobs the manager posts belong to the organization
+	code, body = sendJSON(helper, "POST", "/api/jobs", map[string]any{"title": "Tile", "description": "Bathroom", "location": "Town", "expiry_days": 3})
+	if code != http.StatusCreated {
+		t.Fatalf("post as manager: status = %d; body: %s", code, body)
+	}
+	var posted struct {
+		Job models.Job `json:"job"`
+	}
+	json.Unmarshal(body, &posted)
+	if posted.Job.OrganizationID != 1 || posted.Job.EmployerID != 5 {
+		t.Errorf("posted job organization = %d, employer = %d; want 1 and 5", posted.Job.OrganizationID, posted.Job.EmployerID)
+	}
+
+	// Leaving takes the access away again
+	if code, body := sendJSON(helper, "DELETE", "/api/organizations/1/members/5", nil); code != http.StatusOK {
+		t.Fatalf("leave: status = %d; body: %s", code, body)
+	}
+	if code, _ := sendJSON(helper, "PUT", "/api/jobs/1", editedJob); code != http.StatusForbidden {
+		t.Errorf("edit after leaving: status = %d, want 403", code)
+	}
+}
+
+func TestLastOwnerCannotLeave(t *testing.T) {
+	owner, _ := newTeamRouter(t)
+	if code, _ := sendJSON(owner, "DELETE", "/api/organizations/1/members/1", nil); code != http.StatusConflict {
+		t.Errorf("leave as the only owner: status = %d, want 409", code)
+	}
+	if code, _ := sendJSON(owner, "PUT", "/api/organizations/1/members/1", map[string]any{"role": "manager"}); code != http.StatusConflict {
+		t.Errorf("demote the only owner: status = %d, want 409", code)
+	}
+}
@@ -0,0 +1,293 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/payments"
+)
+
+func escrowOf(t *testing.T, client apiClient, bookingID string) models.Escrow {
+	t.Helper()
+	rec := client.do("GET", "/api/bookings/"+bookingID+"/payments", nil, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("escrow: status %d, body %s", rec.Code, rec.Body)
+	}
+	var escrow models.Escrow
+	if err := json.Unmarshal(rec.Body.Bytes(), &escrow); err != nil {
+		t.Fatal(err)
+	}
+	return escrow
+}
+
+func held(escrow models.Escrow) int64 {
+	var total int64
+	for _, b := range escrow.Held {
+		total += b.Amount
+	}
+	return total
+}
+
+// payJob1 gives job 1 a fixed pay of 800 so hiring for it charges 80000
+// paise
+func payJob1(deps Deps) {
+	amount := int64(80000)
+	deps.Jobs.(*fakeJobs).byID[1].PayMin = &amount
+}
+
+// escrowOutcome is the escrow field of an application or booking update
+func escrowOutcome(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var resp struct {
+		Escrow string `json:"escrow"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp.Escrow
+}
+
+func sendWebhook(t *testing.T, client apiClient, header http.Header, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("POST", "http://localhost:8080/api/payments/webhook", bytes.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	client.router.ServeHTTP(rec, req)
+	checkAgainstSpec(t, client.specRouter, req, body, rec)
+	return rec
+}
+
+func TestEscrowReleasedOnCompletion(t *testing.T) {
+	employer, worker, _ := newBookingClients(t)
+
+	if got := held(escrowOf(t, worker, "1")); got != 150000 {
+		t.Fatalf("held before the work = %d", got)
+	}
+	for _, action := range []string{"check-in", "check-out", "confirm"} {
+		bookingStatus(t, worker, "/api/bookings/1/"+action, http.StatusOK)
+	}
+	bookingStatus(t, employer, "/api/bookings/1/confirm", http.StatusOK)
+
+	if got := held(escrowOf(t, employer, "1")); got != 0 {
+		t.Errorf("held after completion = %d", got)
+	}
+	rec := worker.do("GET", "/api/me/balance", nil, "")
+	var resp struct {
+		Balances []models.Balance `json:"balances"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if len(resp.Balances) != 1 || resp.Balances[0] != (models.Balance{Currency: "INR", Amount: 150000}) {
+		t.Errorf("worker balance = %+v", resp.Balances)
+	}
+
+	// Nothing is left to refund
+	rec = employer.do("POST", "/api/bookings/1/cancel", nil, "")
+	if rec.Code != http.StatusConflict {
+		t.Errorf("cancel completed booking: %d", rec.Code)
+	}
+}
+
+func TestHiringHoldsAndRejectingRefunds(t *testing.T) {
+	employer, worker, deps := newBookingClients(t)
+	payJob1(deps)
+	provider := deps.PaymentProvider.(*payments.FakeProvider)
+
+	if rec := employer.do("PUT", "/api/applications/1", []byte(`{"status":"accepted"}`), "application/json"); rec.Code != http.StatusOK || escrowOutcome(t, rec) != "held" {
+		t.Fatalf("hire: %d %s", rec.Code, rec.Body)
+	}
+	escrow := escrowOf(t, worker, "2")
+	if held(escrow) != 80000 || len(escrow.Payments) != 1 || escrow.Payments[0].Status != payments.StatusSucceeded {
+		t.Fatalf("escrow after hiring = %+v", escrow)
+	}
+
+	if rec := employer.do("PUT", "/api/applications/1", []byte(`{"status":"rejected"}`), "application/json"); rec.Code != http.StatusOK || escrowOutcome(t, rec) != "refunded" {
+		t.Fatalf("reject: %d %s", rec.Code, rec.Body)
+	}
+	escrow = escrowOf(t, employer, "2")
+	if held(escrow) != 0 || escrow.Payments[0].Status != payments.StatusRefunded {
+		t.Errorf("escrow after rejecting = %+v", escrow)
+	}
+	if got := provider.Refunded(*escrow.Payments[0].ProviderRef); got != 80000 {
+		t.Errorf("provider refunded %d", got)
+	}
+}
+
+func TestCancellingRefunds(t *testing.T) {
+	_, worker, deps := newBookingClients(t)
+	provider := deps.PaymentProvider.(*payments.FakeProvider)
+
+	bookingStatus(t, worker, "/api/bookings/1/cancel", http.StatusOK)
+	escrow := escrowOf(t, worker, "1")
+	if held(escrow) != 0 || provider.Refunded(*escrow.Payments[0].ProviderRef) != 150000 {
+		t.Errorf("escrow after cancelling = %+v", escrow)
+	}
+}
+
+func TestFailedRefundIsReported(t *testing.T) {
+	_, worker, deps := newBookingClients(t)
+	deps.Payments.(*fakePayments).failErr = errors.New("connection reset")
+
+	// The cancellation stands, but the response owns up to the money
+	rec := worker.do("POST", "/api/bookings/1/cancel", nil, "")
+	if rec.Code != http.StatusOK || escrowOutcome(t, rec) != "refund_failed" {
+		t.Fatalf("cancel: %d %s", rec.Code, rec.Body)
+	}
+	if got := held(escrowOf(t, worker, "1")); got != 150000 {
+		t.Errorf("held after a failed refund = %d", got)
+	}
+}
+
+func TestWebhookSettlesPendingCharge(t *testing.T) {
+	employer, _, deps := newBookingClients(t)
+	payJob1(deps)
+	provider := deps.PaymentProvider.(*payments.FakeProvider)
+	provider.Async = true
+
+	employer.do("PUT", "/api/applications/1", []byte(`{"status":"accepted"}`), "application/json")
+	escrow := escrowOf(t, employer, "2")
+	if held(escrow) != 0 || escrow.Payments[0].Status != payments.StatusPending {
+		t.Fatalf("escrow before the webhook = %+v", escrow)
+	}
+
+	// A charge is under way, so paying again is refused
+	rec := employer.do("POST", "/api/bookings/2/payments", nil, "")
+	if rec.Code != http.StatusConflict || errorCode(t, rec) != "payment_exists" {
+		t.Errorf("second payment: %d %s", rec.Code, rec.Body)
+	}
+
+	header, body, err := provider.Settle(*escrow.Payments[0].ProviderRef, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := bytes.Replace(body, []byte("charge.succeeded"), []byte("charge.failed"), 1)
+	if rec := sendWebhook(t, employer, header, tampered); rec.Code != http.StatusUnauthorized {
+		t.Errorf("tampered webhook: %d", rec.Code)
+	}
+	for i := 0; i < 2; i++ {
+		if rec := sendWebhook(t, employer, header, body); rec.Code != http.StatusOK {
+			t.Fatalf("webhook delivery %d: %d %s", i+1, rec.Code, rec.Body)
+		}
+	}
+
+	escrow = escrowOf(t, employer, "2")
+	if held(escrow) != 80000 || escrow.Payments[0].Status != payments.StatusSucceeded {
+		t.Errorf("escrow after the webhook = %+v", escrow)
+	}
+}
+
+func TestDeclinedChargeCanBeRetried(t *testing.T) {
+	employer, _, deps := newBookingClients(t)
+	payJob1(deps)
+	provider := deps.PaymentProvider.(*payments.FakeProvider)
+	provider.Decline[80000] = true
+
+	if rec := employer.do("PUT", "/api/applications/1", []byte(`{"status":"accepted"}`), "application/json"); rec.Code != http.StatusOK || escrowOutcome(t, rec) != "declined" {
+		t.Fatalf("a declined charge must not block hiring: %d %s", rec.Code, rec.Body)
+	}
+	rec := employer.do("POST", "/api/bookings/2/payments", nil, "")
+	if rec.Code != http.StatusPaymentRequired || errorCode(t, rec) != "payment_failed" {
+		t.Errorf("declined retry: %d %s", rec.Code, rec.Body)
+	}
+
+	delete(provider.Decline, 80000)
+	if rec := employer.do("POST", "/api/bookings/2/payments", nil, ""); rec.Code != http.StatusCreated {
+		t.Fatalf("retry: %d %s", rec.Code, rec.Body)
+	}
+	escrow := escrowOf(t, employer, "2")
+	if held(escrow) != 80000 || len(escrow.Payments) != 3 {
+		t.Errorf("escrow after retrying = %+v", escrow)
+	}
+}
+
+func TestLateChargeForEndedBooking(t *testing.T) {
+	employer, worker, deps := newBookingClients(t)
+	payJob1(deps)
+	provider := deps.PaymentProvider.(*payments.FakeProvider)
+	provider.Async = true
+
+	employer.do("PUT", "/api/applications/1", []byte(`{"status":"accepted"}`), "application/json")
+	// Rejecting while the charge is pending leaves nothing to refund yet
+	if rec := employer.do("PUT", "/api/applications/1", []byte(`{"status":"rejected"}`), "application/json"); rec.Code != http.StatusOK {
+		t.Fatalf("reject: %d %s", rec.Code, rec.Body)
+	}
+	charge := *escrowOf(t, employer, "2").Payments[0].ProviderRef
+
+	header, body, err := provider.Settle(charge, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := sendWebhook(t, employer, header, body); rec.Code != http.StatusOK {
+		t.Fatalf("webhook: %d %s", rec.Code, rec.Body)
+	}
+	escrow := escrowOf(t, worker, "2")
+	if held(escrow) != 0 || escrow.Payments[0].Status != payments.StatusRefunded || provider.Refunded(charge) != 80000 {
+		t.Errorf("escrow after a late charge = %+v", escrow)
+	}
+}
+
+func TestLateChargeForCompletedBooking(t *testing.T) {
+	employer, worker, deps := newBookingClients(t)
+	payJob1(deps)
+	provider := deps.PaymentProvider.(*payments.FakeProvider)
+	provider.Async = true
+
+	employer.do("PUT", "/api/applications/1", []byte(`{"status":"accepted"}`), "application/json")
+	for _, action := range []string{"check-in", "check-out", "confirm"} {
+		bookingStatus(t, worker, "/api/bookings/2/"+action, http.StatusOK)
+	}
+	bookingStatus(t, employer, "/api/bookings/2/confirm", http.StatusOK)
+
+	header, body, err := provider.Settle(*escrowOf(t, employer, "2").Payments[0].ProviderRef, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := sendWebhook(t, employer, header, body); rec.Code != http.StatusOK {
+		t.Fatalf("webhook: %d %s", rec.Code, rec.Body)
+	}
+	// The work is done, so the money goes straight to the worker
+	if got := held(escrowOf(t, worker, "2")); got != 0 {
+		t.Errorf("held after a late charge = %d", got)
+	}
+	rec := worker.do("GET", "/api/me/balance", nil, "")
+	var resp struct {
+		Balances []models.Balance `json:"balances"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if len(resp.Balances) != 1 || resp.Balances[0].Amount != 80000 {
+		t.Errorf("worker balance = %+v", resp.Balances)
+	}
+}
+
+func TestWebhookRedeliveredAfterFailure(t *testing.T) {
+	employer, _, deps := newBookingClients(t)
+	payJob1(deps)
+	provider := deps.PaymentProvider.(*payments.FakeProvider)
+	provider.Async = true
+
+	employer.do("PUT", "/api/applications/1", []byte(`{"status":"accepted"}`), "application/json")
+	header, body, err := provider.Settle(*escrowOf(t, employer, "2").Payments[0].ProviderRef, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A 4xx would tell the provider to give up on the event
+	fake := deps.Payments.(*fakePayments)
+	fake.failErr = errors.New("connection reset")
+	if rec := sendWebhook(t, employer, header, body); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("webhook while settling fails: %d %s", rec.Code, rec.Body)
+	}
+	fake.failErr = nil
+	if rec := sendWebhook(t, employer, header, body); rec.Code != http.StatusOK {
+		t.Fatalf("redelivered webhook: %d %s", rec.Code, rec.Body)
+	}
+	if got := held(escrowOf(t, employer, "2")); got != 80000 {
+		t.Errorf("held after redelivery = %d", got)
+	}
+}
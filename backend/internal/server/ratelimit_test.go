@@ -0,0 +1,146 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/config"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+)
+
+func TestRateLimit(t *testing.T) {
+	router, _, specRouter := newContractRouter(t, func(cfg *config.Config) {
+		cfg.RateLimit.AuthRequestsPerMinute = 1
+		cfg.RateLimit.AuthBurst = 2
+	})
+	body := []byte(`{"email": "worker@example.com", "password": "wrong"}`)
+	login := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "http://localhost:8080/api/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		checkAgainstSpec(t, specRouter, req, body, rec)
+		return rec
+	}
+
+	for i := range 2 {
+		rec := login()
+		if rec.Code != 401 {
+			t.Fatalf("attempt %d: status = %d, want 401", i+1, rec.Code)
+		}
+		if got, want := rec.Header().Get("RateLimit-Remaining"), []string{"1", "0"}[i]; got != want {
+			t.Errorf("attempt %d: RateLimit-Remaining = %q, want %q", i+1, got, want)
+		}
+	}
+
+	rec := login()
+	if rec.Code != 429 {
+		t.Fatalf("status = %d, want 429; body: %s", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("Retry-After") != "60" || rec.Header().Get("RateLimit-Limit") != "2" {
+		t.Errorf("headers = %v", rec.Header())
+	}
+	if code := errorCode(t, rec); code != "rate_limited" {
+		t.Errorf("code = %q, want rate_limited", code)
+	}
+
+	// Other groups have their own buckets
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/jobs", nil))
+	if rec.Code != 200 {
+		t.Errorf("GET /api/jobs status = %d, want 200", rec.Code)
+	}
+}
+
+func TestRateLimitIgnoresUntrustedForwardedFor(t *testing.T) {
+	login := func(router http.Handler, forwardedFor string) int {
+		req := httptest.NewRequest("POST", "http://localhost:8080/api/login",
+			bytes.NewReader([]byte(`{"email": "worker@example.com", "password": "wrong"}`)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	limited := func(cfg *config.Config) {
+		cfg.RateLimit.AuthRequestsPerMinute = 1
+		cfg.RateLimit.AuthBurst = 1
+	}
+
+	// A new forwarded address on every attempt still lands in the bucket
+	// of the address the request really came from
+	router, _, _ := newContractRouter(t, limited)
+	if code := login(router, "203.0.113.1"); code != 401 {
+		t.Fatalf("first attempt: status = %d, want 401", code)
+	}
+	if code := login(router, "203.0.113.2"); code != 429 {
+		t.Errorf("spoofed address: status = %d, want 429", code)
+	}
+
+	// Behind a trusted proxy each forwarded client has its own bucket;
+	// httptest requests come from 192.0.2.1
+	router, _, _ = newContractRouter(t, limited, func(cfg *config.Config) {
+		cfg.HTTP.TrustedProxies = []string{"192.0.2.0/24"}
+	})
+	for _, client := range []string{"203.0.113.1", "203.0.113.2"} {
+		if code := login(router, client); code != 401 {
+			t.Errorf("client %s via the proxy: status = %d, want 401", client, code)
+		}
+	}
+	if code := login(router, "203.0.113.1"); code != 429 {
+		t.Errorf("client 203.0.113.1 again: status = %d, want 429", code)
+	}
+}
+
+func TestQuotas(t *testing.T) {
+	router, _, specRouter := newContractRouter(t, func(cfg *config.Config) {
+		cfg.Quota.MaxOpenJobsPerEmployer = 1
+		cfg.Quota.MaxApplicationsPerDay = 1
+	})
+	send := func(path, token string, payload any) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest("POST", "http://localhost:8080"+path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		checkAgainstSpec(t, specRouter, req, body, rec)
+		return rec
+	}
+
+	// The seeded employer already has one open job
+	rec := send("/api/jobs", token(t, 1, models.RoleEmployer),
+		map[string]any{"title": "Tile", "description": "Bathroom", "location": "Town", "expiry_days": 3})
+	if rec.Code != 429 || errorCode(t, rec) != "quota_exceeded" {
+		t.Fatalf("open jobs quota: status = %d; body: %s", rec.Code, rec.Body)
+	}
+
+	// The seeded worker applied today already
+	rec = send("/api/applications", token(t, 2, models.RoleWorker), map[string]any{"job_id": 1})
+	if rec.Code != 429 || errorCode(t, rec) != "quota_exceeded" {
+		t.Fatalf("applications quota: status = %d; body: %s", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("applications quota should say when to retry")
+	}
+
+	// A worker with no applications is under the quota
+	rec = send("/api/applications", token(t, 3, models.RoleWorker), map[string]any{"job_id": 1})
+	if rec.Code != 201 {
+		t.Fatalf("first application: status = %d; body: %s", rec.Code, rec.Body)
+	}
+}
+
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("error body: %v", err)
+	}
+	return body.Code
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+)
+
+type recommendationList struct {
+	Recommendations []struct {
+		Job     models.JobWithDetails `json:"job"`
+		Score   float64               `json:"score"`
+		Reasons []string              `json:"reasons"`
+	} `json:"recommendations"`
+}
+
+func TestRecommendedJobs(t *testing.T) {
+	deps := newFakeDeps(t)
+	location := "North Town"
+	deps.Users.(*fakeUsers).byID[3] = &models.User{ID: 3, Email: "new@example.com", FullName: "Newcomer", UserType: models.RoleWorker,
+		Roles: []models.Role{models.RoleWorker}, Location: &location, CreatedAt: time.Now()}
+	deps.Users.(*fakeUsers).skills[3] = []models.WorkerSkill{{CategoryID: 2, CategoryName: "Plumbing", ExperienceYears: 1}}
+
+	plumbing, painting := 2, 5
+	plumbingName, paintingName := "Plumbing", "Painting"
+	expires := time.Now().Add(48 * time.Hour)
+	jobs := deps.Jobs.(*fakeJobs).byID
+	jobs[3] = &models.JobWithDetails{Job: models.Job{ID: 3, EmployerID: 1, OrganizationID: 1, Title: "Fix tap", Description: "Drips", Location: "North Town",
+		CategoryID: &plumbing, PayType: models.PayFixed, Currency: "INR", ExpiresAt: expires, Status: "open", IsActive: true, CreatedAt: time.Now()},
+		EmployerName: "Boss", OrganizationName: "Boss", CategoryName: &plumbingName}
+	jobs[4] = &models.JobWithDetails{Job: models.Job{ID: 4, EmployerID: 1, OrganizationID: 1, Title: "Paint wall", Description: "Blue", Location: "Far City",
+		CategoryID: &painting, PayType: models.PayFixed, Currency: "INR", ExpiresAt: expires, Status: "open", IsActive: true, CreatedAt: time.Now().Add(-5 * 24 * time.Hour)},
+		EmployerName: "Boss", OrganizationName: "Boss", CategoryName: &paintingName}
+
+	clients := newClients(t, deps, token(t, 2, models.RoleWorker), token(t, 3, models.RoleWorker))
+	worker, newcomer := clients[0], clients[1]
+
+	code, body := sendJSON(newcomer, "GET", "/api/jobs/recommended", nil)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d; body: %s", code, body)
+	}
+	var list recommendationList
+	json.Unmarshal(body, &list)
+	var ids []int
+	for _, r := range list.Recommendations {
+		ids = append(ids, r.Job.ID)
+	}
+	// The skill match nearby comes first, then the nearby job without a
+	// category, then the old one far away
+	if want := []int{3, 1, 4}; !slices.Equal(ids, want) {
+		t.Fatalf("jobs = %v, want %v", ids, want)
+	}
+	for i := 1; i < len(list.Recommendations); i++ {
+		if list.Recommendations[i].Score > list.Recommendations[i-1].Score {
+			t.Errorf("job %d scores above job %d", ids[i], ids[i-1])
+		}
+	}
+	wantReasons := []string{"Matches your Plumbing skill (1 year)", "In North Town, near you", "Posted in the last hour"}
+	if got := list.Recommendations[0].Reasons; !slices.Equal(got, wantReasons) {
+		t.Errorf("reasons = %q, want %q", got, wantReasons)
+	}
+
+	code, body = sendJSON(newcomer, "GET", "/api/jobs/recommended?limit=1", nil)
+	json.Unmarshal(body, &list)
+	if code != http.StatusOK || len(list.Recommendations) != 1 || list.Recommendations[0].Job.ID != 3 {
+		t.Errorf("limit 1: status = %d; body: %s", code, body)
+	}
+
+	// Worker 2 has applied to job 1 already
+	code, body = sendJSON(worker, "GET", "/api/jobs/recommended", nil)
+	list = recommendationList{}
+	json.Unmarshal(body, &list)
+	for _, r := range list.Recommendations {
+		if r.Job.ID == 1 {
+			t.Errorf("job 1 recommended after applying to it; body: %s", body)
+		}
+	}
+	if code != http.StatusOK || len(list.Recommendations) != 2 {
+		t.Errorf("status = %d, %d recommendations, want 2", code, len(list.Recommendations))
+	}
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	router, _, _ := newContractRouter(t)
+
+	t.Run("reused from caller", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/jobs/99", nil)
+		req.Header.Set("X-Request-ID", "proxy-123")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("X-Request-ID"); got != "proxy-123" {
+			t.Errorf("X-Request-ID = %q, want proxy-123", got)
+		}
+		var body struct {
+			RequestID string `json:"request_id"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if body.RequestID != "proxy-123" {
+			t.Errorf("error body request_id = %q, want proxy-123", body.RequestID)
+		}
+	})
+
+	t.Run("generated when missing or unsafe", func(t *testing.T) {
+		for _, incoming := range []string{"", "has spaces\nand newlines"} {
+			req := httptest.NewRequest("GET", "/livez", nil)
+			req.Header.Set("X-Request-ID", incoming)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			got := rec.Header().Get("X-Request-ID")
+			if len(got) != 32 || got == incoming {
+				t.Errorf("incoming %q: X-Request-ID = %q, want a generated ID", incoming, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/testutil"
+)
+
+// session decodes a response that reissues the caller's token
+type session struct {
+	Token string `json:"token"`
+	User  struct {
+		UserType models.Role   `json:"user_type"`
+		Roles    []models.Role `json:"roles"`
+	} `json:"user"`
+}
+
+func TestWorkerAddsEmployerRole(t *testing.T) {
+	worker := newClients(t, newFakeDeps(t), token(t, 2, models.RoleWorker))[0]
+	organization := map[string]any{"name": "Worker & Co"}
+
+	if code, _ := sendJSON(worker, "POST", "/api/organizations", organization); code != http.StatusForbidden {
+		t.Fatalf("employer route as worker: status = %d, want 403", code)
+	}
+
+	code, body := sendJSON(worker, "POST", "/api/me/roles", map[string]any{"role": "employer"})
+	if code != http.StatusCreated {
+		t.Fatalf("add role: status = %d; body: %s", code, body)
+	}
+	var added session
+	json.Unmarshal(body, &added)
+	if added.User.UserType != models.RoleWorker || len(added.User.Roles) != 2 {
+		t.Fatalf("after adding = %+v, want worker active with two roles", added.User)
+	}
+
+	// The old token still only carries the worker role
+	if code, _ := sendJSON(worker, "POST", "/api/organizations", organization); code != http.StatusForbidden {
+		t.Errorf("employer route with old token: status = %d, want 403", code)
+	}
+
+	// The new one passes both sides' checks, whichever is active
+	worker.token = added.Token
+	if code, body := sendJSON(worker, "POST", "/api/organizations", organization); code != http.StatusCreated {
+		t.Errorf("employer route with new token: status = %d; body: %s", code, body)
+	}
+	if code, _ := sendJSON(worker, "GET", "/api/me/availability", nil); code != http.StatusOK {
+		t.Errorf("worker route with new token: status = %d, want 200", code)
+	}
+
+	code, body = sendJSON(worker, "PUT", "/api/me/active-role", map[string]any{"role": "employer"})
+	if code != http.StatusOK {
+		t.Fatalf("switch: status = %d; body: %s", code, body)
+	}
+	var switched session
+	json.Unmarshal(body, &switched)
+	if switched.User.UserType != models.RoleEmployer || switched.Token == added.Token {
+		t.Errorf("after switching = %+v, want employer active and a new token", switched.User)
+	}
+	worker.token = switched.Token
+	if code, _ := sendJSON(worker, "GET", "/api/me/availability", nil); code != http.StatusOK {
+		t.Errorf("worker route while employer is active: status = %d, want 200", code)
+	}
+
+	// The profile shows both sides
+	code, body = sendJSON(worker, "GET", "/api/profile/2", nil)
+	if code != http.StatusOK {
+		t.Fatalf("profile: status = %d; body: %s", code, body)
+	}
+	var profile models.Profile
+	json.Unmarshal(body, &profile)
+	if profile.Worker == nil || profile.Employer == nil {
+		t.Errorf("profile facets = worker %v, employer %v; want both", profile.Worker, profile.Employer)
+	}
+}
+
+func TestEmployerCannotApplyToOwnJob(t *testing.T) {
+	deps := newFakeDeps(t)
+	deps.Users.(*fakeUsers).byID[1].Roles = []models.Role{models.RoleEmployer, models.RoleWorker}
+	tok, err := testutil.Tokens.GenerateToken(1, "boss@example.com", models.RoleWorker, []models.Role{models.RoleEmployer, models.RoleWorker})
+	if err != nil {
+		t.Fatal(err)
+	}
+	boss := newClients(t, deps, tok)[0]
+
+	if code, _ := sendJSON(boss, "POST", "/api/applications", map[string]any{"job_id": 1}); code != http.StatusBadRequest {
+		t.Errorf("apply to own job: status = %d, want 400", code)
+	}
+}
+
+func TestAdminSwitchesBack(t *testing.T) {
+	deps := newFakeDeps(t)
+	roles := []models.Role{models.RoleAdmin, models.RoleWorker}
+	deps.Users.(*fakeUsers).byID[9] = &models.User{ID: 9, Email: "admin@example.com", FullName: "Admin",
+		UserType: models.RoleAdmin, Roles: roles}
+	tok, err := testutil.Tokens.GenerateToken(9, "admin@example.com", models.RoleAdmin, roles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin := newClients(t, deps, tok)[0]
+
+	for _, role := range []models.Role{models.RoleWorker, models.RoleAdmin} {
+		code, body := sendJSON(admin, "PUT", "/api/me/active-role", map[string]any{"role": role})
+		if code != http.StatusOK {
+			t.Fatalf("switch to %s: status = %d; body: %s", role, code, body)
+		}
+		var switched session
+		json.Unmarshal(body, &switched)
+		if switched.User.UserType != role {
+			t.Fatalf("after switching to %s = %+v", role, switched.User)
+		}
+		admin.token = switched.Token
+	}
+
+	if code, _ := sendJSON(admin, "PUT", "/api/me/active-role", map[string]any{"role": "employer"}); code != http.StatusForbidden {
+		t.Errorf("switch to a role not held: status = %d, want 403", code)
+	}
+	if code, _ := sendJSON(admin, "PUT", "/api/me/active-role", map[string]any{"role": "owner"}); code != http.StatusBadRequest {
+		t.Errorf("switch to no role at all: status = %d, want 400", code)
+	}
+	if code, _ := sendJSON(admin, "POST", "/api/me/roles", map[string]any{"role": "admin"}); code != http.StatusBadRequest {
+		t.Errorf("add the admin role: status = %d, want 400", code)
+	}
+}
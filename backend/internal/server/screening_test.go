@@ -0,0 +1,192 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"testing"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+)
+
+// postScreenedJob posts a job asking for a ladder and two years'
+// experience, and returns its ID and questions
+func postScreenedJob(t *testing.T, employer apiClient) (int, []models.Question) {
+	t.Helper()
+	code, body := sendJSON(employer, "POST", "/api/jobs", map[string]any{
+		"title": "Tile", "description": "Bathroom", "location": "Town", "expiry_days": 3,
+		"questions": []map[string]any{
+			{"prompt": "Do you own a ladder?", "kind": "yes_no", "required": true, "reject_answer": false},
+			{"prompt": "Years of tiling experience?", "kind": "number", "required": true, "min_value": 2},
+			{"prompt": "Anything else?", "kind": "text"},
+		},
+	})
+	if code != http.StatusCreated {
+		t.Fatalf("post job: status = %d; body: %s", code, body)
+	}
+	var created struct {
+		Job       models.Job        `json:"job"`
+		Questions []models.Question `json:"questions"`
+	}
+	json.Unmarshal(body, &created)
+	if len(created.Questions) != 3 || created.Questions[0].RejectAnswer == nil || created.Questions[1].MinValue == nil {
+		t.Fatalf("questions = %+v, want 3 with their knockouts", created.Questions)
+	}
+	return created.Job.ID, created.Questions
+}
+
+func TestScreeningQuestions(t *testing.T) {
+	employer, worker, newcomer := newDirectoryRouter(t)
+	jobID, questions := postScreenedJob(t, employer)
+	ladder, years := questions[0].ID, questions[1].ID
+	path := "/api/jobs/" + strconv.Itoa(jobID)
+
+	// Applicants see the questions but not what rejects them
+	code, body := sendJSON(worker, "GET", path, nil)
+	if code != http.StatusOK {
+		t.Fatalf("get job: status = %d; body: %s", code, body)
+	}
+	var detail models.JobDetail
+	json.Unmarshal(body, &detail)
+	if len(detail.Questions) != 3 || detail.Questions[0].HasKnockout() || detail.Questions[1].HasKnockout() {
+		t.Errorf("public questions = %+v, want 3 without knockouts", detail.Questions)
+	}
+	if code, body := sendJSON(employer, "GET", path+"/questions", nil); code != http.StatusOK {
+		t.Errorf("questions for the organization: status = %d; body: %s", code, body)
+	}
+
+	apply := func(client apiClient, answers ...map[string]any) (int, models.Application) {
+		t.Helper()
+		code, body := sendJSON(client, "POST", "/api/applications", map[string]any{"job_id": jobID, "answers": answers})
+		var applied struct {
+			Application models.Application `json:"application"`
+		}
+		json.Unmarshal(body, &applied)
+		return code, applied.Application
+	}
+	if code, _ := apply(worker, map[string]any{"question_id": years, "answer": "5"}); code != http.StatusBadRequest {
+		t.Errorf("required question unanswered: status = %d, want 400", code)
+	}
+	if code, _ := apply(worker, map[string]any{"question_id": ladder, "answer": "sometimes"}, map[string]any{"question_id": years, "answer": "5"}); code != http.StatusBadRequest {
+		t.Errorf("yes/no answered otherwise: status = %d, want 400", code)
+	}
+
+	code, passed := apply(worker, map[string]any{"question_id": ladder, "answer": "Yes"}, map[string]any{"question_id": years, "answer": "5"})
+	if code != http.StatusCreated || passed.Status != models.ApplicationPending {
+		t.Fatalf("passing answers: status = %d, application %+v; want a pending application", code, passed)
+	}
+	code, failed := apply(newcomer, map[string]any{"question_id": ladder, "answer": "no"}, map[string]any{"question_id": years, "answer": "5"})
+	if code != http.StatusCreated || failed.Status != models.ApplicationRejected {
+		t.Fatalf("knocked out: status = %d, application %+v; want a rejected application", code, failed)
+	}
+
+	// The employer sees each applicant's answers and what knocked them out
+	code, body = sendJSON(employer, "GET", "/api/applications/job/"+strconv.Itoa(jobID), nil)
+	if code != http.StatusOK {
+		t.Fatalf("applicants: status = %d; body: %s", code, body)
+	}
+	var list struct {
+		Applications []models.JobApplicant `json:"applications"`
+	}
+	json.Unmarshal(body, &list)
+	knockedOut := map[int]bool{}
+	for _, a := range list.Applications {
+		if len(a.Answers) != 2 || a.Answers[0].Answer != map[int]string{passed.ID: "yes", failed.ID: "no"}[a.ID] {
+			t.Errorf("application %d answers = %+v", a.ID, a.Answers)
+			continue
+		}
+		knockedOut[a.ID] = a.Answers[0].KnockedOut
+	}
+	if knockedOut[passed.ID] || !knockedOut[failed.ID] {
+		t.Errorf("knocked out = %v, want only application %d", knockedOut, failed.ID)
+	}
+}
+
+func TestScreeningQuestionsInMultipartJob(t *testing.T) {
+	employer, _, _ := newDirectoryRouter(t)
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for k, v := range map[string]string{"title": "Tile", "description": "Bathroom", "location": "Town", "expiry_days": "3"} {
+		w.WriteField(k, v)
+	}
+	// Each question is a JSON part of its own
+	for _, q := range []string{
+		`{"prompt": "Which shift?", "kind": "choice", "required": true, "choices": ["Day", "Night"], "reject_choices": ["Night"]}`,
+		`{"prompt": "Anything else?", "kind": "text"}`,
+	} {
+		part, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Disposition": {`form-data; name="questions"`},
+			"Content-Type":        {"application/json"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(q))
+	}
+	w.Close()
+
+	rec := employer.do("POST", "/api/jobs", buf.Bytes(), w.FormDataContentType())
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d; body: %s", rec.Code, rec.Body)
+	}
+	var created struct {
+		Questions []models.Question `json:"questions"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if len(created.Questions) != 2 || len(created.Questions[0].Choices) != 2 || len(created.Questions[0].RejectChoices) != 1 ||
+		created.Questions[1].Position != 2 {
+		t.Errorf("questions = %+v", created.Questions)
+	}
+}
+
+func TestScreeningInvitedWorkers(t *testing.T) {
+	employer, worker, newcomer := newDirectoryRouter(t)
+	jobID, questions := postScreenedJob(t, employer)
+	ladder, years := questions[0].ID, questions[1].ID
+
+	invite := func(jobID, workerID int) string {
+		t.Helper()
+		code, body := sendJSON(employer, "POST", "/api/jobs/"+strconv.Itoa(jobID)+"/invite", map[string]any{"worker_id": workerID})
+		if code != http.StatusCreated {
+			t.Fatalf("invite %d: status = %d; body: %s", workerID, code, body)
+		}
+		var invited struct {
+			Application models.Application `json:"application"`
+		}
+		json.Unmarshal(body, &invited)
+		return "/api/applications/" + strconv.Itoa(invited.Application.ID) + "/invitation"
+	}
+	accept := func(client apiClient, path string, answers ...map[string]any) (int, models.Application) {
+		t.Helper()
+		code, body := sendJSON(client, "PUT", path, map[string]any{"response": "accept", "answers": answers})
+		var accepted struct {
+			Application models.Application `json:"application"`
+		}
+		json.Unmarshal(body, &accepted)
+		return code, accepted.Application
+	}
+
+	// Being invited doesn't excuse the worker from the questions
+	path := invite(jobID, 3)
+	if code, _ := accept(newcomer, path); code != http.StatusBadRequest {
+		t.Errorf("accept without answers: status = %d, want 400", code)
+	}
+	code, passed := accept(newcomer, path, map[string]any{"question_id": ladder, "answer": "yes"}, map[string]any{"question_id": years, "answer": "3"})
+	if code != http.StatusOK || passed.Status != models.ApplicationPending {
+		t.Fatalf("passing answers: status = %d, application %+v; want a pending application", code, passed)
+	}
+
+	code, failed := accept(worker, invite(jobID, 2), map[string]any{"question_id": ladder, "answer": "yes"}, map[string]any{"question_id": years, "answer": "1"})
+	if code != http.StatusOK || failed.Status != models.ApplicationRejected {
+		t.Fatalf("knocked out: status = %d, application %+v; want a rejected application", code, failed)
+	}
+
+	// Declining needs no answers
+	otherJobID, _ := postScreenedJob(t, employer)
+	if code, body := sendJSON(newcomer, "PUT", invite(otherJobID, 3), map[string]any{"response": "decline"}); code != http.StatusOK {
+		t.Errorf("decline: status = %d; body: %s", code, body)
+	}
+}
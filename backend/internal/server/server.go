@@ -0,0 +1,320 @@
+// Package server wires handlers, middleware and routes into a Gin router.
+package server
+
+import (
+	"sync"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/apierror"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/auth"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/config"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/handlers"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/health"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/metrics"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/middleware"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/openapi"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/payments"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/ratelimit"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/recommend"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/storage"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// Deps are the stores and services the handlers need
+type Deps struct {
+	Users        store.UserStore
+	Jobs         store.JobStore
+	Applications store.ApplicationStore
+	Audit        store.AuditStore
+	Documents    store.DocumentStore
+	Availability store.AvailabilityStore
+	Bookings     store.BookingStore
+	Payments     store.PaymentStore
+	Invoices     store.InvoiceStore
+	Blobs        storage.BlobStore
+	// Employers' organizations; their members are checked by
+	// middleware.EmployerOnly
+	Organizations store.OrganizationStore
+	// The public worker directory and workers' skills
+	Workers store.WorkerStore
+	// Funds escrow; see payments.New
+	PaymentProvider payments.Provider
+	Tokens          *auth.Manager
+	Health          *health.Checker
+	// Rate limit buckets; a fresh MemoryStore when nil
+	RateLimits ratelimit.Store
+}
+
+var registerValidators sync.Once
+
+// New builds the router with every route the API serves
+func New(cfg config.Config, deps Deps) (*gin.Engine, error) {
+	// Register custom validation tags (e.g. "role")
+	var err error
+	registerValidators.Do(func() {
+		if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+			err = models.RegisterValidators(v)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	escrow := &payments.Escrow{Payments: deps.Payments, Provider: deps.PaymentProvider}
+
+	// Create handlers
+	authHandler := &handlers.AuthHandler{Users: deps.Users, Tokens: deps.Tokens, Audit: deps.Audit}
+	profileHandler := &handlers.ProfileHandler{Users: deps.Users, Blobs: deps.Blobs, URLTTL: cfg.Storage.URLTTL}
+	jobHandler := &handlers.JobHandler{
+		Jobs:        deps.Jobs,
+		Users:       deps.Users,
+		MaxOpenJobs: cfg.Quota.MaxOpenJobsPerEmployer,
+
+		DefaultCurrency: cfg.Payments.Currency,
+
+		Blobs:              deps.Blobs,
+		URLTTL:             cfg.Storage.URLTTL,
+		MaxAttachments:     cfg.Storage.MaxJobAttachments,
+		MaxAttachmentBytes: cfg.Storage.MaxAttachmentBytes,
+	}
+	applicationHandler := &handlers.ApplicationHandler{
+		Applications:          deps.Applications,
+		Jobs:                  deps.Jobs,
+		Users:                 deps.Users,
+		Availability:          deps.Availability,
+		Bookings:              deps.Bookings,
+		Organizations:         deps.Organizations,
+		Escrow:                escrow,
+		MaxApplicationsPerDay: cfg.Quota.MaxApplicationsPerDay,
+		MatchWeights:          recommend.DefaultMatchWeights(),
+	}
+	workerHandler := &handlers.WorkerHandler{
+		Workers:      deps.Workers,
+		Availability: deps.Availability,
+		Blobs:        deps.Blobs,
+		URLTTL:       cfg.Storage.URLTTL,
+	}
+	recommendationHandler := &handlers.RecommendationHandler{
+		Jobs:          deps.Jobs,
+		Users:         deps.Users,
+		Applications:  deps.Applications,
+		Organizations: deps.Organizations,
+		Weights: recommend.Weights{
+			Category:          cfg.Recommend.CategoryWeight,
+			Distance:          cfg.Recommend.DistanceWeight,
+			Pay:               cfg.Recommend.PayWeight,
+			Freshness:         cfg.Recommend.FreshnessWeight,
+			DistanceHalfKm:    cfg.Recommend.DistanceHalfKm,
+			FreshnessHalfLife: cfg.Recommend.FreshnessHalfLife,
+		},
+	}
+	auditHandler := &handlers.AuditHandler{Audit: deps.Audit}
+	availabilityHandler := &handlers.AvailabilityHandler{Availability: deps.Availability}
+	bookingHandler := &handlers.BookingHandler{Bookings: deps.Bookings, Organizations: deps.Organizations, Escrow: escrow}
+	statementHandler := &handlers.StatementHandler{Invoices: deps.Invoices, Users: deps.Users}
+	paymentHandler := &handlers.PaymentHandler{
+		Payments:      deps.Payments,
+		Bookings:      deps.Bookings,
+		Jobs:          deps.Jobs,
+		Organizations: deps.Organizations,
+		Escrow:        escrow,
+	}
+	organizationHandler := &handlers.OrganizationHandler{Organizations: deps.Organizations, InvitationTTL: cfg.Auth.InvitationTTL}
+	uploadHandler := &handlers.UploadHandler{
+		Users:            deps.Users,
+		Documents:        deps.Documents,
+		Blobs:            deps.Blobs,
+		URLTTL:           cfg.Storage.URLTTL,
+		MaxAvatarBytes:   cfg.Storage.MaxAvatarBytes,
+		MaxDocumentBytes: cfg.Storage.MaxDocumentBytes,
+	}
+
+	docs, err := openapi.Handler()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create Gin router. gin.Default() is not used because its logger and
+	// recovery write plain text; ours write structured logs.
+	router := gin.New()
+
+	// Only the configured proxies may say who the client is; otherwise
+	// anyone could dodge the per-IP rate limits with X-Forwarded-For
+	if err := router.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		return nil, err
+	}
+
+	// Request ID first so every later middleware can log it
+	router.Use(middleware.RequestID())
+
+	// Tracing next so the span covers everything else
+	router.Use(otelgin.Middleware(cfg.Telemetry.ServiceName))
+	if cfg.Telemetry.MetricsEnabled {
+		router.Use(metrics.Middleware())
+	}
+	router.Use(middleware.Logger())
+
+	// Render errors attached with c.Error as {"code", "error", "fields"};
+	// Recovery sits inside it so panics are rendered the same way
+	router.Use(apierror.Middleware())
+	router.Use(middleware.Recovery())
+
+	// CORS middleware
+	router.Use(cors.New(cors.Config{
+		AllowOrigins:     cfg.CORS.AllowedOrigins,
+		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", middleware.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", middleware.RequestIDHeader},
+		AllowCredentials: true,
+	}))
+
+	// Health checks; /health is kept for older monitors
+	router.GET("/health", deps.Health.Livez)
+	router.GET("/livez", deps.Health.Livez)
+	router.GET("/readyz", deps.Health.Readyz)
+
+	if cfg.Telemetry.MetricsEnabled {
+		router.GET("/metrics", metrics.Handler())
+	}
+
+	// API description and docs UI
+	router.GET("/api/openapi.json", docs.Spec)
+	if cfg.Features.DocsUI {
+		router.GET("/api/docs", docs.UI)
+	}
+
+	// Rate limit groups: all of /api per IP, then stricter limits for
+	// login/registration per IP and for writes per user
+	limits := deps.RateLimits
+	if limits == nil {
+		limits = ratelimit.NewMemoryStore()
+	}
+	rateLimit := func(group string, perMinute, burst int) gin.HandlerFunc {
+		if !cfg.RateLimit.Enabled {
+			return func(c *gin.Context) { c.Next() }
+		}
+		return middleware.RateLimit(limits, group, ratelimit.Limit{PerMinute: perMinute, Burst: burst})
+	}
+	apiLimit := rateLimit("api", cfg.RateLimit.RequestsPerMinute, cfg.RateLimit.Burst)
+	authLimit := rateLimit("auth", cfg.RateLimit.AuthRequestsPerMinute, cfg.RateLimit.AuthBurst)
+	writeLimit := rateLimit("write", cfg.RateLimit.WriteRequestsPerMinute, cfg.RateLimit.WriteBurst)
+
+	api := router.Group("/api", apiLimit)
+
+	// Employer routes check the caller's role in the organization that
+	// owns what they act on
+	orgOf := handlers.OrgOf{
+		Organizations: deps.Organizations,
+		Jobs:          deps.Jobs,
+		Applications:  deps.Applications,
+		Bookings:      deps.Bookings,
+	}
+	member := func(resolve middleware.OrgResolver, min models.OrgRole) gin.HandlerFunc {
+		return middleware.EmployerOnly(deps.Organizations, resolve, min)
+	}
+	employer := middleware.RequireRole(models.RoleEmployer, "Only employers can access this resource")
+
+	// Public routes (no authentication required)
+	api.POST("/register", authLimit, authHandler.Register)
+	api.POST("/login", authLimit, authHandler.Login)
+	api.GET("/jobs", jobHandler.GetJobs)       // Anyone can view jobs
+	api.GET("/jobs/:id", jobHandler.GetJob)    // Anyone can view job details
+	api.GET("/files", uploadHandler.ServeFile) // The signature is the credential
+	api.GET("/workers", workerHandler.ListWorkers)
+	api.POST("/payments/webhook", paymentHandler.Webhook)
+
+	// Protected routes (authentication required)
+	protected := api.Group("")
+	protected.Use(middleware.AuthRequired(deps.Tokens))
+	{
+		// Users can be workers and employers at once; tokens carry every
+		// role and switching the active one reissues the token
+		protected.POST("/me/roles", writeLimit, authHandler.AddRole)
+		protected.PUT("/me/active-role", authHandler.SwitchRole)
+
+		// Profile routes
+		protected.GET("/profile/:id", profileHandler.GetProfile)
+		protected.PUT("/profile/:id", profileHandler.UpdateProfile)
+
+		// Uploads, always for the caller's own account
+		protected.PUT("/me/avatar", writeLimit, uploadHandler.UploadAvatar)
+		protected.DELETE("/me/avatar", uploadHandler.DeleteAvatar)
+		protected.GET("/me/documents", uploadHandler.ListDocuments)
+		protected.POST("/me/documents", writeLimit, uploadHandler.UploadDocument)
+		protected.DELETE("/me/documents/:id", uploadHandler.DeleteDocument)
+
+		// Availability, for the caller's own calendar (workers only)
+		protected.GET("/me/availability", middleware.WorkerOnly(), availabilityHandler.GetAvailability)
+		protected.PUT("/me/availability", middleware.WorkerOnly(), writeLimit, availabilityHandler.UpdateAvailability)
+		protected.POST("/me/availability/exceptions", middleware.WorkerOnly(), writeLimit, availabilityHandler.CreateException)
+		protected.DELETE("/me/availability/exceptions/:id", middleware.WorkerOnly(), availabilityHandler.DeleteException)
+		protected.PUT("/me/skills", middleware.WorkerOnly(), writeLimit, workerHandler.UpdateSkills)
+
+		// Open jobs picked for the caller (workers only)
+		protected.GET("/jobs/recommended", middleware.WorkerOnly(), recommendationHandler.Recommended)
+
+		// Job routes (managers of the job's organization)
+		protected.POST("/jobs", member(orgOf.Managed(), models.OrgManager), writeLimit, jobHandler.CreateJob)
+		protected.PUT("/jobs/:id", member(orgOf.Job("id"), models.OrgManager), writeLimit, jobHandler.UpdateJob)
+		protected.DELETE("/jobs/:id", member(orgOf.Job("id"), models.OrgManager), jobHandler.DeleteJob)
+		protected.DELETE("/jobs/:id/attachments/:attachmentId", member(orgOf.Job("id"), models.OrgManager), jobHandler.DeleteAttachment)
+		protected.GET("/jobs/:id/questions", member(orgOf.Job("id"), models.OrgViewer), jobHandler.GetJobQuestions)
+		protected.POST("/jobs/:id/invite", member(orgOf.Job("id"), models.OrgManager), writeLimit, applicationHandler.InviteWorker)
+
+		// Organizations and their teams
+		protected.POST("/organizations", employer, writeLimit, organizationHandler.CreateOrganization)
+		protected.GET("/organizations", organizationHandler.ListOrganizations)
+		protected.GET("/organizations/:id", member(orgOf.Path("id"), models.OrgViewer), organizationHandler.GetOrganization)
+		protected.PUT("/organizations/:id", member(orgOf.Path("id"), models.OrgOwner), organizationHandler.UpdateOrganization)
+		protected.POST("/organizations/:id/jobs", member(orgOf.Path("id"), models.OrgManager), writeLimit, jobHandler.CreateJob)
+		protected.PUT("/organizations/:id/members/:userId", member(orgOf.Path("id"), models.OrgOwner), organizationHandler.UpdateMember)
+		protected.DELETE("/organizations/:id/members/:userId", member(orgOf.Path("id"), models.OrgViewer), organizationHandler.RemoveMember)
+		protected.GET("/organizations/:id/invitations", member(orgOf.Path("id"), models.OrgOwner), organizationHandler.ListInvitations)
+		protected.POST("/organizations/:id/invitations", member(orgOf.Path("id"), models.OrgOwner), writeLimit, organizationHandler.CreateInvitation)
+		protected.DELETE("/organizations/:id/invitations/:invitationId", member(orgOf.Path("id"), models.OrgOwner), organizationHandler.RevokeInvitation)
+		protected.POST("/invitations/accept", employer, organizationHandler.AcceptInvitation)
+
+		// Application routes (workers only)
+		protected.POST("/applications", middleware.WorkerOnly(), writeLimit, applicationHandler.ApplyToJob)
+		protected.GET("/applications/worker/:workerId", middleware.WorkerOnly(), applicationHandler.GetWorkerApplications)
+		protected.PUT("/applications/:id/invitation", middleware.WorkerOnly(), applicationHandler.RespondToInvitation)
+
+		// Application routes (members of the job's organization; managers
+		// decide)
+		protected.GET("/applications/job/:jobId", member(orgOf.Job("jobId"), models.OrgViewer), applicationHandler.GetJobApplications)
+		protected.PUT("/applications/:id", member(orgOf.Application("id"), models.OrgManager), applicationHandler.UpdateApplicationStatus)
+		protected.PUT("/applications/:id/review", member(orgOf.Application("id"), models.OrgManager), applicationHandler.ReviewApplication)
+		protected.POST("/jobs/:id/applications/bulk", member(orgOf.Job("id"), models.OrgManager), applicationHandler.BulkUpdateApplications)
+
+		// Booking routes, for the worker and employer on each booking and
+		// the employer's organization
+		protected.GET("/bookings", bookingHandler.ListBookings)
+		protected.GET("/bookings/:id", bookingHandler.GetBooking)
+		protected.POST("/bookings/:id/check-in", bookingHandler.CheckIn)
+		protected.POST("/bookings/:id/check-out", bookingHandler.CheckOut)
+		protected.POST("/bookings/:id/confirm", bookingHandler.Confirm)
+		protected.POST("/bookings/:id/cancel", bookingHandler.Cancel)
+		protected.POST("/bookings/:id/no-show", member(orgOf.Booking("id"), models.OrgManager), bookingHandler.ReportNoShow)
+		protected.POST("/bookings/:id/rating", member(orgOf.Booking("id"), models.OrgManager), writeLimit, bookingHandler.Rate)
+
+		// Escrow and earnings
+		protected.GET("/bookings/:id/payments", paymentHandler.GetEscrow)
+		protected.POST("/bookings/:id/payments", member(orgOf.Booking("id"), models.OrgManager), writeLimit, paymentHandler.CreatePayment)
+		protected.GET("/me/balance", middleware.WorkerOnly(), paymentHandler.GetBalance)
+
+		// Invoices and statements, as JSON, CSV or PDF
+		protected.GET("/me/statements", statementHandler.GetStatement)
+		protected.GET("/invoices/:id", statementHandler.GetInvoice)
+
+		// Admin routes
+		protected.GET("/admin/audit-events", middleware.AdminOnly(), auditHandler.ListEvents)
+		protected.POST("/admin/bookings/:id/refund", middleware.AdminOnly(), paymentHandler.RefundBooking)
+	}
+
+	return router, nil
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+)
+
+func statementOf(t *testing.T, client apiClient) models.Statement {
+	t.Helper()
+	rec := client.do("GET", "/api/me/statements", nil, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("statement: status %d, body %s", rec.Code, rec.Body)
+	}
+	var st models.Statement
+	if err := json.Unmarshal(rec.Body.Bytes(), &st); err != nil {
+		t.Fatal(err)
+	}
+	return st
+}
+
+func TestCompletionIssuesInvoice(t *testing.T) {
+	employer, worker, _ := newBookingClients(t)
+
+	before := len(statementOf(t, worker).Invoices)
+	for _, action := range []string{"check-in", "check-out", "confirm"} {
+		bookingStatus(t, worker, "/api/bookings/1/"+action, http.StatusOK)
+	}
+	bookingStatus(t, employer, "/api/bookings/1/confirm", http.StatusOK)
+
+	st := statementOf(t, worker)
+	if len(st.Invoices) != before+1 || st.Role != models.RoleWorker {
+		t.Fatalf("statement after completion = %+v", st)
+	}
+	last := st.Invoices[len(st.Invoices)-1]
+	if last.BookingID == nil || *last.BookingID != 1 || last.Amount != 150000 {
+		t.Errorf("invoice = %+v", last)
+	}
+	if got := len(statementOf(t, employer).Invoices); got != before+1 {
+		t.Errorf("employer statement has %d invoices", got)
+	}
+}
+
+func TestStatementDownloads(t *testing.T) {
+	_, worker, _ := newBookingClients(t)
+
+	rec := worker.do("GET", "/api/me/statements?format=csv", nil, "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/csv" {
+		t.Fatalf("csv: %d %v", rec.Code, rec.Header())
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil || len(rows) != 2 || rows[1][6] != "500.00" {
+		t.Errorf("csv rows = %v (%v)", rows, err)
+	}
+
+	rec = worker.do("GET", "/api/invoices/1?format=pdf", nil, "")
+	if rec.Code != http.StatusOK || !bytes.HasPrefix(rec.Body.Bytes(), []byte("%PDF-")) {
+		t.Fatalf("pdf: %d", rec.Code)
+	}
+	if got, want := rec.Header().Get("Content-Disposition"), `attachment; filename="`+statementOf(t, worker).Invoices[0].Number+`.pdf"`; got != want {
+		t.Errorf("Content-Disposition = %q, want %q", got, want)
+	}
+}
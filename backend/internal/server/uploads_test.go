@@ -0,0 +1,339 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/config"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/storage"
+	"github.com/getkin/kin-openapi/openapi3filter"
+)
+
+func init() {
+	// Files served by signed URL
+	openapi3filter.RegisterBodyDecoder("image/jpeg", openapi3filter.FileBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("application/pdf", openapi3filter.FileBodyDecoder)
+}
+
+// multipartBody builds a form with a "file" part and any other fields
+func multipartBody(t *testing.T, name string, data []byte, fields map[string]string) ([]byte, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for k, v := range fields {
+		if err := w.WriteField(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	part, err := w.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes(), w.FormDataContentType()
+}
+
+// testPNG is a w x h image with a transparent half
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w/2; x++ {
+		for y := 0; y < h; y++ {
+			img.Set(x, y, color.NRGBA{R: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decodeJPEG(t *testing.T, data []byte) image.Rectangle {
+	t.Helper()
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("not a JPEG: %v", err)
+	}
+	return img.Bounds()
+}
+
+func TestAvatarUpload(t *testing.T) {
+	client := newClients(t, newFakeDeps(t), token(t, 2, models.RoleWorker))[0]
+
+	body, contentType := multipartBody(t, "me.png", testPNG(t, 1024, 512), nil)
+	rec := client.do("PUT", "/api/me/avatar", body, contentType)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Profile models.User `json:"profile"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Profile.AvatarURL == nil || !strings.HasPrefix(*resp.Profile.AvatarURL, storage.FilesPath+"?") {
+		t.Fatalf("avatar_url = %v", resp.Profile.AvatarURL)
+	}
+
+	// Scaled to 512px and re-encoded, thumbnail cropped square
+	rec = client.do("GET", *resp.Profile.AvatarURL, nil, "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/jpeg" {
+		t.Fatalf("avatar: status %d, type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if b := decodeJPEG(t, rec.Body.Bytes()); b.Dx() != 512 || b.Dy() != 256 {
+		t.Errorf("avatar is %v, want 512x256", b)
+	}
+	rec = client.do("GET", *resp.Profile.AvatarThumbnailURL, nil, "")
+	if b := decodeJPEG(t, rec.Body.Bytes()); b.Dx() != 128 || b.Dy() != 128 {
+		t.Errorf("thumbnail is %v, want 128x128", b)
+	}
+
+	// The profile endpoint signs the same avatar
+	rec = client.do("GET", "/api/profile/2", nil, "")
+	var profile models.User
+	json.Unmarshal(rec.Body.Bytes(), &profile)
+	if profile.AvatarURL == nil || !strings.Contains(*profile.AvatarURL, "signature=") {
+		t.Errorf("profile avatar_url = %v", profile.AvatarURL)
+	}
+
+	// The old blobs go when the avatar is removed
+	rec = client.do("DELETE", "/api/me/avatar", nil, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete: status = %d", rec.Code)
+	}
+	rec = client.do("GET", *resp.Profile.AvatarURL, nil, "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("old avatar: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestAvatarUploadRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   []byte
+		status int
+		code   string
+	}{
+		{"not an image", []byte("just some text"), http.StatusUnsupportedMediaType, "unsupported_media_type"},
+		{"pdf", []byte("%PDF-1.4\n"), http.StatusUnsupportedMediaType, "unsupported_media_type"},
+		{"truncated png", testPNG(t, 64, 64)[:40], http.StatusUnsupportedMediaType, "unsupported_media_type"},
+		{"too large", testPNG(t, 600, 600), http.StatusRequestEntityTooLarge, "payload_too_large"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _, specRouter := newContractRouter(t, func(c *config.Config) { c.Storage.MaxAvatarBytes = 4000 })
+			client := apiClient{t, router, specRouter, token(t, 2, models.RoleWorker)}
+
+			body, contentType := multipartBody(t, "file", tt.file, nil)
+			rec := client.do("PUT", "/api/me/avatar", body, contentType)
+			if rec.Code != tt.status || errorCode(t, rec) != tt.code {
+				t.Errorf("got %d %s, want %d %s", rec.Code, errorCode(t, rec), tt.status, tt.code)
+			}
+		})
+	}
+}
+
+func TestDocumentUpload(t *testing.T) {
+	client := newClients(t, newFakeDeps(t), token(t, 3, models.RoleWorker))[0]
+
+	pdf := []byte("%PDF-1.4\n% test document\n")
+	body, contentType := multipartBody(t, `C:\scans\licence.pdf`, pdf, map[string]string{"kind": "id_proof"})
+	rec := client.do("POST", "/api/me/documents", body, contentType)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d; body: %s", rec.Code, rec.Body)
+	}
+	var doc models.Document
+	json.Unmarshal(rec.Body.Bytes(), &doc)
+	if doc.FileName != "licence.pdf" || doc.ContentType != "application/pdf" || doc.Kind != "id_proof" {
+		t.Errorf("document = %+v", doc)
+	}
+
+	rec = client.do("GET", doc.URL, nil, "")
+	if rec.Code != http.StatusOK || !bytes.Equal(rec.Body.Bytes(), pdf) {
+		t.Errorf("download: status %d, body %q", rec.Code, rec.Body)
+	}
+
+	// Images are re-encoded
+	body, contentType = multipartBody(t, "cert.png", testPNG(t, 40, 20), map[string]string{"kind": "certificate"})
+	rec = client.do("POST", "/api/me/documents", body, contentType)
+	json.Unmarshal(rec.Body.Bytes(), &doc)
+	if rec.Code != http.StatusCreated || doc.ContentType != "image/jpeg" {
+		t.Errorf("image document: status %d, type %q", rec.Code, doc.ContentType)
+	}
+
+	rec = client.do("GET", "/api/me/documents", nil, "")
+	var list struct {
+		Count int `json:"count"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if list.Count != 2 {
+		t.Errorf("count = %d, want 2", list.Count)
+	}
+
+	body, contentType = multipartBody(t, "notes.txt", []byte("hello"), map[string]string{"kind": "other"})
+	if rec := client.do("POST", "/api/me/documents", body, contentType); rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("text document: status = %d, want 415", rec.Code)
+	}
+	body, contentType = multipartBody(t, "x.pdf", pdf, map[string]string{"kind": "passport"})
+	if rec := client.do("POST", "/api/me/documents", body, contentType); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad kind: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestSignedFileURLs(t *testing.T) {
+	client := newClients(t, newFakeDeps(t), "")[0]
+	// Same secret as newFakeDeps
+	signer := storage.NewSigner("test-secret", "")
+
+	valid := signer.URL("documents/2/seed.pdf", time.Minute)
+	tests := []struct {
+		name   string
+		url    string
+		status int
+	}{
+		{"valid", valid, http.StatusOK},
+		{"tampered key", strings.Replace(valid, "seed", "other", 1), http.StatusForbidden},
+		{"expired", signer.URL("documents/2/seed.pdf", -time.Minute), http.StatusForbidden},
+		{"other secret", storage.NewSigner("guess", "").URL("documents/2/seed.pdf", time.Minute), http.StatusForbidden},
+		{"missing file", signer.URL("documents/2/gone.pdf", time.Minute), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := client.do("GET", tt.url, nil, ""); rec.Code != tt.status {
+				t.Errorf("status = %d, want %d; body: %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+}
+
+// jobForm builds a multipart job request with each file in "attachments"
+func jobForm(t *testing.T, fields map[string]string, files ...[]byte) ([]byte, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for k, v := range fields {
+		if err := w.WriteField(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, data := range files {
+		part, err := w.CreateFormFile("attachments", fmt.Sprintf("photo-%d", i+1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(data)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes(), w.FormDataContentType()
+}
+
+func TestJobAttachments(t *testing.T) {
+	router, _, specRouter := newContractRouter(t, func(c *config.Config) { c.Storage.MaxJobAttachments = 2 })
+	client := apiClient{t, router, specRouter, token(t, 1, models.RoleEmployer)}
+	fields := map[string]string{"title": "Tile", "description": "Bathroom", "location": "Town", "expiry_days": "3", "pay_type": "daily", "pay_min": "40000"}
+	pdf := []byte("%PDF-1.4\n% quote\n")
+
+	body, contentType := jobForm(t, fields, testPNG(t, 3000, 1500), pdf)
+	rec := client.do("POST", "/api/jobs", body, contentType)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d; body: %s", rec.Code, rec.Body)
+	}
+	var created struct {
+		Job         models.Job             `json:"job"`
+		Attachments []models.JobAttachment `json:"attachments"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if created.Job.PayMin == nil || *created.Job.PayMin != 40000 || *created.Job.HourlyRate != 5000 || len(created.Attachments) != 2 {
+		t.Fatalf("created %+v", created)
+	}
+
+	// The photo is re-encoded and scaled down, the PDF kept as sent
+	photo, quote := created.Attachments[0], created.Attachments[1]
+	rec = client.do("GET", photo.URL, nil, "")
+	if b := decodeJPEG(t, rec.Body.Bytes()); b.Dx() != 2400 || b.Dy() != 1200 {
+		t.Errorf("photo is %v, want 2400x1200", b)
+	}
+	rec = client.do("GET", quote.URL, nil, "")
+	if !bytes.Equal(rec.Body.Bytes(), pdf) {
+		t.Errorf("pdf = %q", rec.Body)
+	}
+
+	// Anyone viewing the job sees the attachments
+	jobPath := fmt.Sprintf("/api/jobs/%d", created.Job.ID)
+	rec = client.do("GET", jobPath, nil, "")
+	var detail models.JobDetail
+	json.Unmarshal(rec.Body.Bytes(), &detail)
+	if len(detail.Attachments) != 2 || detail.Attachments[0].URL == "" {
+		t.Errorf("job attachments = %+v", detail.Attachments)
+	}
+
+	// A third attachment is over the limit until one is deleted
+	delete(fields, "expiry_days")
+	body, contentType = jobForm(t, fields, pdf)
+	if rec := client.do("PUT", jobPath, body, contentType); rec.Code != http.StatusBadRequest {
+		t.Errorf("third attachment: status = %d, want 400", rec.Code)
+	}
+	rec = client.do("DELETE", fmt.Sprintf("%s/attachments/%d", jobPath, quote.ID), nil, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete attachment: status = %d", rec.Code)
+	}
+	if rec := client.do("GET", quote.URL, nil, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("deleted attachment: status = %d, want 404", rec.Code)
+	}
+	body, contentType = jobForm(t, fields, pdf)
+	if rec := client.do("PUT", jobPath, body, contentType); rec.Code != http.StatusOK {
+		t.Errorf("replacement attachment: status = %d; body: %s", rec.Code, rec.Body)
+	}
+
+	// Deleting the job removes its files
+	if rec := client.do("DELETE", jobPath, nil, ""); rec.Code != http.StatusOK {
+		t.Fatalf("delete job: status = %d", rec.Code)
+	}
+	if rec := client.do("GET", photo.URL, nil, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("photo after job deleted: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestJobAttachmentsRejects(t *testing.T) {
+	fields := map[string]string{"title": "Tile", "description": "Bathroom", "location": "Town", "expiry_days": "3"}
+	tests := []struct {
+		name   string
+		files  [][]byte
+		status int
+	}{
+		{"text file", [][]byte{[]byte("plain text")}, http.StatusUnsupportedMediaType},
+		{"too many", [][]byte{[]byte("%PDF-1.4\n"), []byte("%PDF-1.4\n"), []byte("%PDF-1.4\n")}, http.StatusBadRequest},
+		{"too large", [][]byte{testPNG(t, 600, 600)}, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _, specRouter := newContractRouter(t, func(c *config.Config) {
+				c.Storage.MaxJobAttachments = 2
+				c.Storage.MaxAttachmentBytes = 4000
+			})
+			client := apiClient{t, router, specRouter, token(t, 1, models.RoleEmployer)}
+
+			body, contentType := jobForm(t, fields, tt.files...)
+			if rec := client.do("POST", "/api/jobs", body, contentType); rec.Code != tt.status {
+				t.Errorf("status = %d, want %d; body: %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+}
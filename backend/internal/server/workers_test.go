@@ -0,0 +1,140 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/booking"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+)
+
+// newDirectoryRouter adds worker 3, who hasn't applied to anything, to the
+// fake deps, and marks booking 1 (worker 2 on job 2) completed
+func newDirectoryRouter(t *testing.T) (employer, worker, invitee apiClient) {
+	t.Helper()
+	deps := newFakeDeps(t)
+	location := "North Town"
+	deps.Users.(*fakeUsers).byID[3] = &models.User{ID: 3, Email: "new@example.com", FullName: "Newcomer", UserType: models.RoleWorker,
+		Roles: []models.Role{models.RoleWorker}, Location: &location, CreatedAt: time.Now()}
+	deps.Bookings.(*fakeBookings).byID[1].Status = booking.Completed
+
+	clients := newClients(t, deps, token(t, 1, models.RoleEmployer), token(t, 2, models.RoleWorker), token(t, 3, models.RoleWorker))
+	return clients[0], clients[1], clients[2]
+}
+
+func TestInvitedWorkerAccepts(t *testing.T) {
+	employer, _, invitee := newDirectoryRouter(t)
+
+	code, body := sendJSON(employer, "POST", "/api/jobs/1/invite", map[string]any{"worker_id": 3})
+	if code != http.StatusCreated {
+		t.Fatalf("invite: status = %d; body: %s", code, body)
+	}
+	var invited struct {
+		Application models.Application `json:"application"`
+	}
+	json.Unmarshal(body, &invited)
+	if invited.Application.Status != models.ApplicationInvited || invited.Application.InvitedBy == nil || *invited.Application.InvitedBy != 1 {
+		t.Fatalf("invitation = %+v, want invited by 1", invited.Application)
+	}
+	path := "/api/applications/" + strconv.Itoa(invited.Application.ID)
+
+	if code, _ := sendJSON(employer, "POST", "/api/jobs/1/invite", map[string]any{"worker_id": 3}); code != http.StatusConflict {
+		t.Errorf("invite twice: status = %d, want 409", code)
+	}
+	if code, _ := sendJSON(employer, "PUT", path, map[string]any{"status": "accepted"}); code != http.StatusConflict {
+		t.Errorf("hire before the worker accepts: status = %d, want 409", code)
+	}
+
+	code, body = sendJSON(invitee, "PUT", path+"/invitation", map[string]any{"response": "accept"})
+	if code != http.StatusOK {
+		t.Fatalf("accept: status = %d; body: %s", code, body)
+	}
+	json.Unmarshal(body, &invited)
+	if invited.Application.Status != models.ApplicationPending {
+		t.Errorf("status after accepting = %q, want pending", invited.Application.Status)
+	}
+	if code, _ := sendJSON(invitee, "PUT", path+"/invitation", map[string]any{"response": "decline"}); code != http.StatusConflict {
+		t.Errorf("answer twice: status = %d, want 409", code)
+	}
+
+	if code, body := sendJSON(employer, "PUT", path, map[string]any{"status": "accepted"}); code != http.StatusOK {
+		t.Errorf("hire: status = %d; body: %s", code, body)
+	}
+}
+
+func TestDeclinedInvitationIsClosed(t *testing.T) {
+	employer, _, invitee := newDirectoryRouter(t)
+
+	code, body := sendJSON(employer, "POST", "/api/jobs/1/invite", map[string]any{"worker_id": 3})
+	if code != http.StatusCreated {
+		t.Fatalf("invite: status = %d; body: %s", code, body)
+	}
+	var invited struct {
+		Application models.Application `json:"application"`
+	}
+	json.Unmarshal(body, &invited)
+	path := "/api/applications/" + strconv.Itoa(invited.Application.ID)
+
+	if code, body := sendJSON(invitee, "PUT", path+"/invitation", map[string]any{"response": "decline"}); code != http.StatusOK {
+		t.Fatalf("decline: status = %d; body: %s", code, body)
+	}
+	for _, status := range []string{"accepted", "rejected"} {
+		if code, _ := sendJSON(employer, "PUT", path, map[string]any{"status": status}); code != http.StatusConflict {
+			t.Errorf("%s after declining: status = %d, want 409", status, code)
+		}
+	}
+}
+
+func TestDirectorySearch(t *testing.T) {
+	employer, worker, invitee := newDirectoryRouter(t)
+
+	if code, body := sendJSON(worker, "PUT", "/api/me/skills", map[string]any{"skills": []map[string]any{{"category_id": 2, "experience_years": 4}}}); code != http.StatusOK {
+		t.Fatalf("skills: status = %d; body: %s", code, body)
+	}
+	if code, body := sendJSON(invitee, "PUT", "/api/me/skills", map[string]any{"skills": []map[string]any{{"category_id": 2, "experience_years": 1}}}); code != http.StatusOK {
+		t.Fatalf("skills: status = %d; body: %s", code, body)
+	}
+	if code, body := sendJSON(employer, "POST", "/api/bookings/1/rating", map[string]any{"rating": 5, "review": "Spotless"}); code != http.StatusOK {
+		t.Fatalf("rate: status = %d; body: %s", code, body)
+	}
+	if code, _ := sendJSON(employer, "POST", "/api/bookings/1/rating", map[string]any{"rating": 1}); code != http.StatusConflict {
+		t.Errorf("rate twice: status = %d, want 409", code)
+	}
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"", []int{2, 3}},
+		{"?category_id=2", []int{2, 3}},
+		{"?category_id=2&min_experience=3", []int{2}},
+		{"?category_id=1", nil},
+		{"?location=north", []int{3}},
+		{"?min_rating=4.5", []int{2}},
+		{"?per_page=1&page=2", []int{3}},
+	}
+	for _, tt := range tests {
+		code, body := sendJSON(employer, "GET", "/api/workers"+tt.query, nil)
+		if code != http.StatusOK {
+			t.Fatalf("GET %s: status = %d; body: %s", tt.query, code, body)
+		}
+		var page struct {
+			Workers []map[string]any `json:"workers"`
+		}
+		json.Unmarshal(body, &page)
+		var got []int
+		for _, w := range page.Workers {
+			got = append(got, int(w["id"].(float64)))
+			if _, ok := w["email"]; ok {
+				t.Errorf("GET %s: worker %v has an email", tt.query, w["id"])
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("GET %s: workers = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// LocalStore keeps files in a directory on disk. Its signed URLs point
+// back at the API (FilesPath), which checks them with VerifyURL.
+type LocalStore struct {
+	Dir    string
+	Signer *Signer
+}
+
+func NewLocalStore(dir string, signer *Signer) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &LocalStore{Dir: dir, Signer: signer}, nil
+}
+
+func (s *LocalStore) path(key string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(key)), nil
+}
+
+// Put writes to a temporary file and renames it, so a failed upload never
+// leaves half a file under key
+func (s *LocalStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+// Open infers the content type from the key's extension; keys always get
+// the extension of the type they were stored as
+func (s *LocalStore) Open(ctx context.Context, key string) (io.ReadCloser, Info, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, Info{}, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, Info{}, ErrNotFound
+	}
+	if err != nil {
+		return nil, Info{}, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, Info{}, err
+	}
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return f, Info{ContentType: contentType, Size: stat.Size(), ModTime: stat.ModTime()}, nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStore) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	return s.Signer.URL(key, ttl), nil
+}
+
+// VerifyURL checks the query parameters of a URL from SignedURL
+func (s *LocalStore) VerifyURL(key, expires, signature string) error {
+	return s.Signer.Verify(key, expires, signature)
+}
+
+// URLVerifier is implemented by stores whose signed URLs are served by
+// the API itself rather than by the storage backend
+type URLVerifier interface {
+	VerifyURL(key, expires, signature string) error
+}
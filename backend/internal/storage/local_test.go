@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewLocalStore(t.TempDir(), NewSigner("secret", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := NewKey("documents/7", ".pdf")
+	if err := s.Put(ctx, key, strings.NewReader("%PDF-1.4"), 8, "application/pdf"); err != nil {
+		t.Fatal(err)
+	}
+
+	body, info, err := s.Open(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "%PDF-1.4" || info.Size != 8 || info.ContentType != "application/pdf" {
+		t.Errorf("got %q, %+v", data, info)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Open(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("after delete: %v", err)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Errorf("deleting twice: %v", err)
+	}
+}
+
+func TestInvalidKeys(t *testing.T) {
+	s, err := NewLocalStore(t.TempDir(), NewSigner("secret", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"", "../etc/passwd", "avatars/../../x", "/abs", "a//b", "UPPER", "a/b/", "a.b.c"} {
+		if _, _, err := s.Open(context.Background(), key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Open(%q) = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
+
+func TestSigner(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	s := NewSigner("secret", "https://api.example.com")
+	s.now = func() time.Time { return now }
+
+	signed := s.URL("avatars/1/a.jpg", time.Minute)
+	if !strings.HasPrefix(signed, "https://api.example.com"+FilesPath+"?") {
+		t.Fatalf("url = %s", signed)
+	}
+	u, _ := url.Parse(signed)
+	q := u.Query()
+	if err := s.Verify(q.Get("key"), q.Get("expires"), q.Get("signature")); err != nil {
+		t.Errorf("fresh URL: %v", err)
+	}
+	if err := s.Verify("avatars/2/a.jpg", q.Get("expires"), q.Get("signature")); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("other key: %v", err)
+	}
+	if err := s.Verify(q.Get("key"), "1900000000", q.Get("signature")); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("extended expiry: %v", err)
+	}
+	if err := NewSigner("other", "").Verify(q.Get("key"), q.Get("expires"), q.Get("signature")); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("other secret: %v", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if err := s.Verify(q.Get("key"), q.Get("expires"), q.Get("signature")); !errors.Is(err, ErrURLExpired) {
+		t.Errorf("expired URL: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config points S3Store at a bucket on AWS S3 or any compatible server
+type S3Config struct {
+	// Host and port, e.g. s3.amazonaws.com or localhost:9000 for MinIO
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+}
+
+// S3Store keeps files in an S3-compatible bucket. Signed URLs are
+// presigned GETs, so clients download straight from the bucket and the
+// endpoint must be reachable from them.
+type S3Store struct {
+	Client *minio.Client
+	Bucket string
+}
+
+// NewS3Store connects and creates the bucket if it doesn't exist yet
+func NewS3Store(ctx context.Context, cfg S3Config) (*S3Store, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s3 client: %w", err)
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("s3 bucket %q: %w", cfg.Bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("create s3 bucket %q: %w", cfg.Bucket, err)
+		}
+	}
+	return &S3Store{Client: client, Bucket: cfg.Bucket}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	_, err := s.Client.PutObject(ctx, s.Bucket, key, body, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+func (s *S3Store) Open(ctx context.Context, key string) (io.ReadCloser, Info, error) {
+	if err := checkKey(key); err != nil {
+		return nil, Info{}, err
+	}
+	obj, err := s.Client.GetObject(ctx, s.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, Info{}, s3Error(err)
+	}
+	// GetObject is lazy; Stat makes the request and surfaces NoSuchKey
+	stat, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, Info{}, s3Error(err)
+	}
+	return obj, Info{ContentType: stat.ContentType, Size: stat.Size, ModTime: stat.LastModified}, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	// S3 reports success for missing keys too
+	return s.Client.RemoveObject(ctx, s.Bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *S3Store) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	u, err := s.Client.PresignedGetObject(ctx, s.Bucket, key, ttl, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+func s3Error(err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return ErrNotFound
+	}
+	return err
+}
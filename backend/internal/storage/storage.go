@@ -0,0 +1,96 @@
+// Package storage keeps uploaded files behind BlobStore, so development
+// can use a local directory and production an S3-compatible bucket (MinIO
+// works as a local stand-in for S3).
+//
+// Files are never public. Clients get signed URLs that expire: presigned
+// S3 URLs, or for LocalStore a link to GET /api/files that the server
+// checks with VerifyURL before streaming the file.
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/config"
+)
+
+var (
+	// ErrNotFound is returned for keys that hold no file
+	ErrNotFound = errors.New("blob not found")
+	// ErrInvalidKey is returned for keys NewKey could not have produced
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// BlobStore saves and serves uploaded files by key
+type BlobStore interface {
+	// Put stores size bytes from body under key, replacing any existing file
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Open returns the file's contents; the caller closes it
+	Open(ctx context.Context, key string) (io.ReadCloser, Info, error)
+	// Delete removes the file; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL anyone can GET the file from until ttl passes
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// Info describes a stored file
+type Info struct {
+	ContentType string
+	Size        int64
+	ModTime     time.Time
+}
+
+// Keys look like "avatars/12/3f9c...e1.jpg": lower-case segments with no
+// dot-only parts, so they are safe as both file paths and object names
+var validKey = regexp.MustCompile(`^[a-z0-9_-]+(/[a-z0-9_-]+)*(\.[a-z0-9]+)?$`)
+
+func checkKey(key string) error {
+	if len(key) > 255 || !validKey.MatchString(key) {
+		return ErrInvalidKey
+	}
+	return nil
+}
+
+// NewKey returns a fresh, unguessable key under prefix, e.g.
+// NewKey("documents/7", ".pdf")
+func NewKey(prefix, ext string) string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("storage: crypto/rand failed: " + err.Error())
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + hex.EncodeToString(b) + ext
+}
+
+// New builds the BlobStore cfg selects. signingSecret keys LocalStore's
+// signed URLs.
+func New(ctx context.Context, cfg config.StorageConfig, signingSecret string) (BlobStore, error) {
+	switch cfg.Backend {
+	case "s3":
+		s, err := NewS3Store(ctx, S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			UseSSL:    cfg.S3UseSSL,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case "local":
+		s, err := NewLocalStore(cfg.LocalDir, NewSigner(signingSecret, cfg.PublicURL))
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+}
@@ -0,0 +1,424 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/audit"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/booking"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgApplicationStore is the Postgres implementation of ApplicationStore
+type PgApplicationStore struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewPgApplicationStore(db *pgxpool.Pool, queryTimeout time.Duration) *PgApplicationStore {
+	return &PgApplicationStore{DB: db, QueryTimeout: queryTimeout}
+}
+
+const applicationColumns = `a.id, a.job_id, a.worker_id, a.cover_letter, a.status, a.applied_at, a.updated_at, a.invited_by`
+
+func applicationFields(a *models.Application) []any {
+	return []any{&a.ID, &a.JobID, &a.WorkerID, &a.CoverLetter, &a.Status, &a.AppliedAt, &a.UpdatedAt, &a.InvitedBy}
+}
+
+// Create inserts the application and the worker's screening answers, and
+// fills in its generated fields. One with InvitedBy set is an invitation
+// and starts out invited; one with a knocked out answer starts out
+// rejected.
+func (s *PgApplicationStore) Create(ctx context.Context, app *models.Application, answers ...models.Answer) error {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	status, action := models.ApplicationPending, audit.ApplicationSubmitted
+	switch {
+	case app.InvitedBy != nil:
+		status, action = models.ApplicationInvited, audit.ApplicationInvited
+	case slices.ContainsFunc(answers, func(a models.Answer) bool { return a.KnockedOut }):
+		status, action = models.ApplicationRejected, audit.ApplicationScreenedOut
+	}
+	query := `
+		INSERT INTO applications AS a (job_id, worker_id, cover_letter, status, invited_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + applicationColumns
+
+	return inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, query, app.JobID, app.WorkerID, app.CoverLetter, status, app.InvitedBy).
+			Scan(applicationFields(app)...)
+		if err != nil {
+			return err
+		}
+		if err := insertAnswers(ctx, tx, app.ID, answers); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, action, audit.TargetApplication, app.ID, nil, app)
+	})
+}
+
+func insertAnswers(ctx context.Context, tx pgx.Tx, applicationID int, answers []models.Answer) error {
+	for _, a := range answers {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO application_answers (application_id, question_id, answer, knocked_out)
+			VALUES ($1, $2, $3, $4)`, applicationID, a.QuestionID, a.Answer, a.KnockedOut)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Respond records the worker's answer to an invitation: accepting makes it
+// pending like any application, or rejected if one of the screening
+// answers knocks it out, and declining closes it
+func (s *PgApplicationStore) Respond(ctx context.Context, id, workerID int, accept bool, answers ...models.Answer) (*models.Application, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	status := models.ApplicationDeclined
+	if accept {
+		status = models.ApplicationPending
+		if slices.ContainsFunc(answers, func(a models.Answer) bool { return a.KnockedOut }) {
+			status = models.ApplicationRejected
+		}
+	}
+	action := audit.ApplicationStatusPrefix + status
+	if status == models.ApplicationRejected {
+		action = audit.ApplicationScreenedOut
+	}
+
+	var before, app models.Application
+	err := inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+			SELECT `+applicationColumns+`
+			FROM applications a
+			WHERE a.id = $1 AND a.worker_id = $2
+			FOR UPDATE`, id, workerID,
+		).Scan(applicationFields(&before)...)
+		if err != nil {
+			return err
+		}
+		if before.Status != models.ApplicationInvited {
+			return ErrNotInvited
+		}
+		err = tx.QueryRow(ctx, `
+			UPDATE applications AS a
+			SET status = $1, updated_at = NOW()
+			WHERE a.id = $2
+			RETURNING `+applicationColumns, status, id,
+		).Scan(applicationFields(&app)...)
+		if err != nil {
+			return err
+		}
+		if accept {
+			if err := insertAnswers(ctx, tx, id, answers); err != nil {
+				return err
+			}
+		}
+		return recordChange(ctx, tx, action, audit.TargetApplication, id, &before, &app)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+func (s *PgApplicationStore) Get(ctx context.Context, id int) (*models.Application, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	var app models.Application
+	err := s.DB.QueryRow(ctx, `SELECT `+applicationColumns+` FROM applications a WHERE a.id = $1`, id).
+		Scan(applicationFields(&app)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+func (s *PgApplicationStore) ListByWorker(ctx context.Context, workerID int) ([]models.WorkerApplication, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	query := `
+		SELECT ` + applicationColumns + `,
+		       j.title AS job_title, j.location, j.pay_type, j.currency, j.pay_min, j.pay_max,
+		       u.full_name AS employer_name, j.category_id, j.hourly_rate
+		FROM applications a
+		JOIN jobs j ON a.job_id = j.id
+		JOIN users u ON j.employer_id = u.id
+		WHERE a.worker_id = $1
+		ORDER BY a.applied_at DESC`
+
+	rows, err := s.DB.Query(ctx, query, workerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	apps := []models.WorkerApplication{}
+	for rows.Next() {
+		var a models.WorkerApplication
+		fields := append(applicationFields(&a.Application),
+			&a.JobTitle, &a.Location, &a.PayType, &a.Currency, &a.PayMin, &a.PayMax, &a.EmployerName,
+			&a.CategoryID, &a.HourlyRate)
+		if err := rows.Scan(fields...); err != nil {
+			return nil, err
+		}
+		apps = append(apps, a)
+	}
+	return apps, rows.Err()
+}
+
+func (s *PgApplicationStore) ListByJob(ctx context.Context, jobID int) ([]models.JobApplicant, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	// A worker drops a booking by cancelling it or not turning up
+	query := workerRatings + `
+		SELECT ` + applicationColumns + `,
+		       u.full_name AS worker_name, u.email AS worker_email,
+		       u.phone AS worker_phone, u.location AS worker_location,
+		       u.latitude, u.longitude, r.rating, COALESCE(r.rating_count, 0),
+		       (SELECT COUNT(*) FROM bookings b WHERE b.worker_id = a.worker_id AND b.status = 'completed'),
+		       (SELECT COUNT(*) FROM bookings b WHERE b.worker_id = a.worker_id
+		          AND (b.status = 'no_show' OR b.status = 'cancelled' AND b.cancelled_by = a.worker_id)),
+		       ` + reviewColumns + `
+		FROM applications a
+		JOIN users u ON a.worker_id = u.id
+		LEFT JOIN ratings r ON r.worker_id = a.worker_id
+		LEFT JOIN application_reviews rv ON rv.application_id = a.id
+		WHERE a.job_id = $1
+		ORDER BY a.applied_at DESC`
+
+	rows, err := s.DB.Query(ctx, query, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	apps := []models.JobApplicant{}
+	var workerIDs []int
+	for rows.Next() {
+		var a models.JobApplicant
+		fields := append(applicationFields(&a.Application),
+			&a.WorkerName, &a.WorkerEmail, &a.WorkerPhone, &a.WorkerLocation,
+			&a.WorkerLatitude, &a.WorkerLongitude, &a.WorkerRating, &a.RatingCount,
+			&a.CompletedJobs, &a.DroppedJobs)
+		if err := rows.Scan(append(fields, reviewFields(&a.Review)...)...); err != nil {
+			return nil, err
+		}
+		apps = append(apps, a)
+		workerIDs = append(workerIDs, a.WorkerID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	skills, err := listSkills(ctx, s.DB, workerIDs)
+	if err != nil {
+		return nil, err
+	}
+	answers, err := listAnswers(ctx, s.DB, jobID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range apps {
+		apps[i].WorkerSkills = append([]models.WorkerSkill{}, skills[apps[i].WorkerID]...)
+		apps[i].Answers = append([]models.Answer{}, answers[apps[i].ID]...)
+	}
+	return apps, nil
+}
+
+// listAnswers returns the screening answers of each of the job's
+// applications, in question order
+func listAnswers(ctx context.Context, db querier, jobID int) (map[int][]models.Answer, error) {
+	rows, err := db.Query(ctx, `
+		SELECT aa.application_id, q.id, q.prompt, aa.answer, aa.knocked_out
+		FROM application_answers aa
+		JOIN job_questions q ON q.id = aa.question_id
+		WHERE q.job_id = $1
+		ORDER BY q.position`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	answers := map[int][]models.Answer{}
+	for rows.Next() {
+		var applicationID int
+		var a models.Answer
+		if err := rows.Scan(&applicationID, &a.QuestionID, &a.Prompt, &a.Answer, &a.KnockedOut); err != nil {
+			return nil, err
+		}
+		answers[applicationID] = append(answers[applicationID], a)
+	}
+	return answers, rows.Err()
+}
+
+// reviewColumns reads an application's review from application_reviews
+// rv, which may be missing
+const reviewColumns = `COALESCE(rv.shortlisted, false), COALESCE(rv.tags, '{}'), rv.notes, rv.updated_by, rv.updated_at`
+
+func reviewFields(r *models.ApplicationReview) []any {
+	return []any{&r.Shortlisted, &r.Tags, &r.Notes, &r.UpdatedBy, &r.UpdatedAt}
+}
+
+func (s *PgApplicationStore) Review(ctx context.Context, id int, update ReviewUpdate, userID int) (*models.ApplicationReview, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	var before, after models.ApplicationReview
+	err := inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+			SELECT `+reviewColumns+`
+			FROM applications a
+			LEFT JOIN application_reviews rv ON rv.application_id = a.id
+			WHERE a.id = $1
+			FOR UPDATE OF a`, id,
+		).Scan(reviewFields(&before)...)
+		if err != nil {
+			return err
+		}
+
+		after = before
+		if update.Shortlisted != nil {
+			after.Shortlisted = *update.Shortlisted
+		}
+		if update.Tags != nil {
+			after.Tags = update.Tags
+		}
+		if update.Notes != nil {
+			after.Notes = update.Notes
+			if *update.Notes == "" {
+				after.Notes = nil
+			}
+		}
+		err = tx.QueryRow(ctx, `
+			INSERT INTO application_reviews AS rv (application_id, shortlisted, tags, notes, updated_by)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (application_id) DO UPDATE
+			SET shortlisted = EXCLUDED.shortlisted, tags = EXCLUDED.tags, notes = EXCLUDED.notes,
+			    updated_by = EXCLUDED.updated_by, updated_at = NOW()
+			RETURNING `+reviewColumns,
+			id, after.Shortlisted, after.Tags, after.Notes, userID,
+		).Scan(reviewFields(&after)...)
+		if err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, audit.ApplicationReviewed, audit.TargetApplication, id, &before, &after)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &after, nil
+}
+
+func (s *PgApplicationStore) UpdateStatus(ctx context.Context, id int, status string) (*models.Application, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	query := `
+		UPDATE applications AS a
+		SET status = $1, updated_at = NOW()
+		WHERE a.id = $2
+		RETURNING ` + applicationColumns
+
+	var before, app models.Application
+	err := inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `SELECT `+applicationColumns+` FROM applications a WHERE a.id = $1 FOR UPDATE`, id).
+			Scan(applicationFields(&before)...)
+		if err != nil {
+			return err
+		}
+		// Only the worker moves an invitation on; the employer may still
+		// withdraw it by rejecting
+		if before.Status == models.ApplicationDeclined ||
+			before.Status == models.ApplicationInvited && status != models.ApplicationRejected {
+			return ErrAwaitingWorker
+		}
+		if before.Status == models.ApplicationWithdrawn {
+			return &booking.TransitionError{Reason: "The worker withdrew from this job, so the application can't be changed"}
+		}
+		accepting := status == "accepted" && before.Status != "accepted"
+		if accepting {
+			if err := checkAcceptable(ctx, tx, &before); err != nil {
+				return err
+			}
+		}
+		if status != "accepted" && before.Status == "accepted" {
+			if err := releaseBooking(ctx, tx, id, status); err != nil {
+				return err
+			}
+		}
+		if err := tx.QueryRow(ctx, query, status, id).Scan(applicationFields(&app)...); err != nil {
+			return err
+		}
+		if err := recordChange(ctx, tx, audit.ApplicationStatusPrefix+status, audit.TargetApplication, id, &before, &app); err != nil {
+			return err
+		}
+		if accepting {
+			return bookApplication(ctx, tx, id)
+		}
+		return nil
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+// checkAcceptable fails with ErrScheduleConflict if the worker is already
+// booked for a job overlapping app's
+func checkAcceptable(ctx context.Context, tx pgx.Tx, app *models.Application) error {
+	var startsAt, endsAt *time.Time
+	err := tx.QueryRow(ctx, `SELECT starts_at, ends_at FROM jobs WHERE id = $1`, app.JobID).Scan(&startsAt, &endsAt)
+	if err != nil {
+		return err
+	}
+	if err := lockWorkerSchedule(ctx, tx, app.WorkerID); err != nil {
+		return err
+	}
+	return checkWorkerFree(ctx, tx, app.WorkerID, app.JobID, startsAt, endsAt)
+}
+
+func (s *PgApplicationStore) CountRecentByWorker(ctx context.Context, workerID int, window time.Duration) (int, time.Duration, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	// applied_at has no time zone and defaults to CURRENT_TIMESTAMP, so
+	// compare it with LOCALTIMESTAMP rather than a Go time. Invitations are
+	// the employer's doing, so they never count.
+	query := `
+		SELECT COUNT(*),
+		       COALESCE(EXTRACT(EPOCH FROM MIN(applied_at) + $2::interval - LOCALTIMESTAMP), 0)::float8
+		FROM applications
+		WHERE worker_id = $1
+		  AND invited_by IS NULL
+		  AND applied_at > LOCALTIMESTAMP - $2::interval`
+
+	var count int
+	var nextSlot float64
+	if err := s.DB.QueryRow(ctx, query, workerID, window).Scan(&count, &nextSlot); err != nil {
+		return 0, 0, err
+	}
+	return count, time.Duration(nextSlot * float64(time.Second)), nil
+}
@@ -0,0 +1,191 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/audit"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AuditStore reads and writes audit_events. Mutations in the other stores
+// write their own events inside their transactions; Record is for events
+// with nothing to be atomic with, such as logins.
+type AuditStore interface {
+	Record(ctx context.Context, event *models.AuditEvent) error
+	// List returns one page of matching events, newest first, and the
+	// total number of matches
+	List(ctx context.Context, filter AuditFilter) ([]models.AuditEvent, int, error)
+}
+
+// AuditFilter narrows List; zero fields match everything
+type AuditFilter struct {
+	ActorID    int
+	Action     string
+	TargetType string
+	TargetID   int
+	Since      time.Time
+	Until      time.Time
+	Limit      int
+	Offset     int
+}
+
+// PgAuditStore is the Postgres implementation of AuditStore
+type PgAuditStore struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewPgAuditStore(db *pgxpool.Pool, queryTimeout time.Duration) *PgAuditStore {
+	return &PgAuditStore{DB: db, QueryTimeout: queryTimeout}
+}
+
+const auditColumns = `id, actor_id, action, target_type, target_id, before, after,
+	ip, user_agent, request_id, created_at`
+
+// execer is satisfied by both the pool and a transaction
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+func insertAudit(ctx context.Context, db execer, e *models.AuditEvent) error {
+	_, err := db.Exec(ctx, `
+		INSERT INTO audit_events (actor_id, action, target_type, target_id, before, after, ip, user_agent, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		e.ActorID, e.Action, e.TargetType, e.TargetID, e.Before, e.After, e.IP, e.UserAgent, e.RequestID,
+	)
+	return err
+}
+
+// recordChange writes the audit event for a mutation, taking the actor and
+// client details from ctx. Nothing is written when before and after match.
+func recordChange(ctx context.Context, tx pgx.Tx, action, targetType string, targetID int, before, after any) error {
+	b, a, err := audit.Diff(before, after)
+	if err != nil {
+		return fmt.Errorf("audit diff: %w", err)
+	}
+	if string(a) == "{}" {
+		return nil
+	}
+
+	event := newAuditEvent(ctx, action, targetType, targetID)
+	event.After = a
+	if before != nil {
+		event.Before = b
+	}
+	return insertAudit(ctx, tx, event)
+}
+
+// newAuditEvent fills in who and where from the request in ctx
+func newAuditEvent(ctx context.Context, action, targetType string, targetID int) *models.AuditEvent {
+	req := audit.FromContext(ctx)
+	event := &models.AuditEvent{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   &targetID,
+		IP:         optionalString(req.IP),
+		UserAgent:  optionalString(req.UserAgent),
+		RequestID:  optionalString(req.RequestID),
+	}
+	if req.ActorID != 0 {
+		event.ActorID = &req.ActorID
+	}
+	return event
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// inTx runs fn in a transaction, committing if it returns nil
+func inTx(ctx context.Context, db *pgxpool.Pool, fn func(tx pgx.Tx) error) error {
+	return pgx.BeginFunc(ctx, db, fn)
+}
+
+// Record fills in missing client details from ctx and writes the event
+func (s *PgAuditStore) Record(ctx context.Context, event *models.AuditEvent) error {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	req := audit.FromContext(ctx)
+	if event.IP == nil {
+		event.IP = optionalString(req.IP)
+	}
+	if event.UserAgent == nil {
+		event.UserAgent = optionalString(req.UserAgent)
+	}
+	if event.RequestID == nil {
+		event.RequestID = optionalString(req.RequestID)
+	}
+	return insertAudit(ctx, s.DB, event)
+}
+
+func (s *PgAuditStore) List(ctx context.Context, filter AuditFilter) ([]models.AuditEvent, int, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	var where []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if filter.ActorID != 0 {
+		add("actor_id = $%d", filter.ActorID)
+	}
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if filter.TargetType != "" {
+		add("target_type = $%d", filter.TargetType)
+	}
+	if filter.TargetID != 0 {
+		add("target_id = $%d", filter.TargetID)
+	}
+	if !filter.Since.IsZero() {
+		add("created_at >= $%d", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		add("created_at < $%d", filter.Until)
+	}
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := s.DB.QueryRow(ctx, `SELECT COUNT(*) FROM audit_events`+whereSQL, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := `SELECT ` + auditColumns + ` FROM audit_events` + whereSQL +
+		fmt.Sprintf(` ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+
+	rows, err := s.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	events := []models.AuditEvent{}
+	for rows.Next() {
+		var e models.AuditEvent
+		if err := rows.Scan(
+			&e.ID, &e.ActorID, &e.Action, &e.TargetType, &e.TargetID, &e.Before, &e.After,
+			&e.IP, &e.UserAgent, &e.RequestID, &e.CreatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		events = append(events, e)
+	}
+	return events, total, rows.Err()
+}
@@ -0,0 +1,358 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/audit"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgAvailabilityStore is the Postgres implementation of AvailabilityStore
+type PgAvailabilityStore struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewPgAvailabilityStore(db *pgxpool.Pool, queryTimeout time.Duration) *PgAvailabilityStore {
+	return &PgAvailabilityStore{DB: db, QueryTimeout: queryTimeout}
+}
+
+const (
+	slotColumns      = `id, worker_id, weekday, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI')`
+	exceptionColumns = `id, worker_id, starts_at, ends_at, available, note, created_at`
+)
+
+func scanSlot(row pgx.Row) (*models.AvailabilitySlot, error) {
+	var s models.AvailabilitySlot
+	if err := row.Scan(&s.ID, &s.WorkerID, &s.Weekday, &s.StartTime, &s.EndTime); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func scanException(row pgx.Row) (*models.AvailabilityException, error) {
+	var e models.AvailabilityException
+	err := row.Scan(&e.ID, &e.WorkerID, &e.StartsAt, &e.EndsAt, &e.Available, &e.Note, &e.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// weeklyChange is what the audit log records for a weekly schedule edit
+type weeklyChange struct {
+	Timezone string                    `json:"timezone"`
+	Weekly   []models.AvailabilitySlot `json:"weekly"`
+}
+
+func (s *PgAvailabilityStore) Get(ctx context.Context, workerID int) (*models.Availability, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	return getAvailability(ctx, s.DB, workerID)
+}
+
+// getAvailability loads the worker's weekly slots and the exceptions that
+// haven't ended yet
+func getAvailability(ctx context.Context, db querier, workerID int) (*models.Availability, error) {
+	avail := &models.Availability{WorkerID: workerID}
+	err := db.QueryRow(ctx, `SELECT timezone FROM users WHERE id = $1`, workerID).Scan(&avail.Timezone)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if avail.Weekly, err = listSlots(ctx, db, `WHERE worker_id = $1`, workerID); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT `+exceptionColumns+`
+		FROM worker_availability_exceptions
+		WHERE worker_id = $1 AND ends_at > NOW()
+		ORDER BY starts_at, id`, workerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	avail.Exceptions = []models.AvailabilityException{}
+	for rows.Next() {
+		e, err := scanException(rows)
+		if err != nil {
+			return nil, err
+		}
+		avail.Exceptions = append(avail.Exceptions, *e)
+	}
+	return avail, rows.Err()
+}
+
+func listSlots(ctx context.Context, db querier, where string, args ...any) ([]models.AvailabilitySlot, error) {
+	rows, err := db.Query(ctx, `SELECT `+slotColumns+` FROM worker_availability `+where+` ORDER BY weekday, start_time`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slots := []models.AvailabilitySlot{}
+	for rows.Next() {
+		slot, err := scanSlot(rows)
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, *slot)
+	}
+	return slots, rows.Err()
+}
+
+// ReplaceWeekly swaps the worker's weekly slots and time zone for new ones
+func (s *PgAvailabilityStore) ReplaceWeekly(ctx context.Context, workerID int, timezone string, slots []models.AvailabilitySlot) (*models.Availability, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	var avail *models.Availability
+	err := inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		// Locking the user serialises concurrent edits of the same calendar
+		var before weeklyChange
+		err := tx.QueryRow(ctx, `SELECT timezone FROM users WHERE id = $1 FOR UPDATE`, workerID).Scan(&before.Timezone)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if before.Weekly, err = listSlots(ctx, tx, `WHERE worker_id = $1`, workerID); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM worker_availability WHERE worker_id = $1`, workerID); err != nil {
+			return err
+		}
+		for _, slot := range slots {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO worker_availability (worker_id, weekday, start_time, end_time)
+				VALUES ($1, $2, $3::time, $4::time)`,
+				workerID, slot.Weekday, slot.StartTime, slot.EndTime)
+			if err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(ctx, `UPDATE users SET timezone = $1 WHERE id = $2`, timezone, workerID); err != nil {
+			return err
+		}
+
+		if avail, err = getAvailability(ctx, tx, workerID); err != nil {
+			return err
+		}
+		// Slot IDs are regenerated on every save, so leave them out of
+		// the diff
+		after := weeklyChange{Timezone: avail.Timezone, Weekly: withoutIDs(avail.Weekly)}
+		before.Weekly = withoutIDs(before.Weekly)
+		return recordChange(ctx, tx, audit.AvailabilityUpdated, audit.TargetUser, workerID, &before, &after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return avail, nil
+}
+
+func withoutIDs(slots []models.AvailabilitySlot) []models.AvailabilitySlot {
+	out := make([]models.AvailabilitySlot, len(slots))
+	for i, slot := range slots {
+		slot.ID = 0
+		out[i] = slot
+	}
+	return out
+}
+
+// AddException inserts the exception and fills in its generated fields
+func (s *PgAvailabilityStore) AddException(ctx context.Context, e *models.AvailabilityException) error {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	query := `
+		INSERT INTO worker_availability_exceptions (worker_id, starts_at, ends_at, available, note)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + exceptionColumns
+
+	return inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		created, err := scanException(tx.QueryRow(ctx, query, e.WorkerID, e.StartsAt, e.EndsAt, e.Available, e.Note))
+		if err != nil {
+			return err
+		}
+		*e = *created
+		return recordChange(ctx, tx, audit.AvailabilityExceptionAdded, audit.TargetUser, e.WorkerID, nil, created)
+	})
+}
+
+func (s *PgAvailabilityStore) DeleteException(ctx context.Context, id, workerID int) (*models.AvailabilityException, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	var deleted *models.AvailabilityException
+	err := inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		var err error
+		deleted, err = scanException(tx.QueryRow(ctx, `
+			DELETE FROM worker_availability_exceptions
+			WHERE id = $1 AND worker_id = $2
+			RETURNING `+exceptionColumns, id, workerID))
+		if err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, audit.AvailabilityExceptionDeleted, audit.TargetUser, workerID, deleted, map[string]any{})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// ListByWorkers loads the calendars of several workers, with only the
+// exceptions and active bookings that overlap from-to
+func (s *PgAvailabilityStore) ListByWorkers(ctx context.Context, workerIDs []int, from, to time.Time) (map[int]*models.Availability, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	byWorker := map[int]*models.Availability{}
+	rows, err := s.DB.Query(ctx, `SELECT id, timezone FROM users WHERE id = ANY($1)`, workerIDs)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		a := &models.Availability{Weekly: []models.AvailabilitySlot{}, Exceptions: []models.AvailabilityException{}}
+		if err := rows.Scan(&a.WorkerID, &a.Timezone); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		byWorker[a.WorkerID] = a
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	slots, err := listSlots(ctx, s.DB, `WHERE worker_id = ANY($1)`, workerIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, slot := range slots {
+		if a := byWorker[slot.WorkerID]; a != nil {
+			a.Weekly = append(a.Weekly, slot)
+		}
+	}
+
+	rows, err = s.DB.Query(ctx, `
+		SELECT `+exceptionColumns+`
+		FROM worker_availability_exceptions
+		WHERE worker_id = ANY($1) AND starts_at < $3 AND ends_at > $2
+		ORDER BY starts_at`, workerIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		e, err := scanException(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if a := byWorker[e.WorkerID]; a != nil {
+			a.Exceptions = append(a.Exceptions, *e)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.DB.Query(ctx, `
+		SELECT b.worker_id, j.id, j.starts_at, j.ends_at
+		FROM bookings b
+		JOIN jobs j ON j.id = b.job_id
+		WHERE b.worker_id = ANY($1) AND `+activeBooking+`
+		  AND j.starts_at < $3 AND j.ends_at > $2`, workerIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var workerID int
+		var b models.BookedTime
+		if err := rows.Scan(&workerID, &b.JobID, &b.StartsAt, &b.EndsAt); err != nil {
+			return nil, err
+		}
+		if a := byWorker[workerID]; a != nil {
+			a.Booked = append(a.Booked, b)
+		}
+	}
+	return byWorker, rows.Err()
+}
+
+// lockWorkerSchedule serialises hiring the worker, so two transactions
+// can't each accept them for one of a pair of overlapping jobs. The lock
+// is released when tx ends.
+func lockWorkerSchedule(ctx context.Context, tx pgx.Tx, workerID int) error {
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('worker_schedule'), $1)`, workerID)
+	return err
+}
+
+// checkWorkerFree fails with ErrScheduleConflict if the worker has an
+// active booking for a job other than jobID that overlaps startsAt-endsAt. Call
+// lockWorkerSchedule first.
+func checkWorkerFree(ctx context.Context, tx pgx.Tx, workerID, jobID int, startsAt, endsAt *time.Time) error {
+	if startsAt == nil || endsAt == nil {
+		return nil
+	}
+	var other int
+	err := tx.QueryRow(ctx, `
+		SELECT j.id
+		FROM bookings b
+		JOIN jobs j ON j.id = b.job_id
+		WHERE b.worker_id = $1 AND `+activeBooking+` AND j.id <> $2
+		  AND j.starts_at < $4 AND j.ends_at > $3
+		LIMIT 1`, workerID, jobID, startsAt, endsAt).Scan(&other)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: worker %d is booked for job %d", ErrScheduleConflict, workerID, other)
+}
+
+// checkHiredWorkersFree checks that everyone still booked for job is free
+// at its new time
+func checkHiredWorkersFree(ctx context.Context, tx pgx.Tx, job *models.Job) error {
+	rows, err := tx.Query(ctx, `SELECT b.worker_id FROM bookings b WHERE b.job_id = $1 AND `+activeBooking+` ORDER BY b.worker_id`, job.ID)
+	if err != nil {
+		return err
+	}
+	workers, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return err
+	}
+	for _, workerID := range workers {
+		if err := lockWorkerSchedule(ctx, tx, workerID); err != nil {
+			return err
+		}
+		if err := checkWorkerFree(ctx, tx, workerID, job.ID, job.StartsAt, job.EndsAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,293 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/audit"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/booking"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgBookingStore is the Postgres implementation of BookingStore
+type PgBookingStore struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewPgBookingStore(db *pgxpool.Pool, queryTimeout time.Duration) *PgBookingStore {
+	return &PgBookingStore{DB: db, QueryTimeout: queryTimeout}
+}
+
+const (
+	bookingColumns = `b.id, b.application_id, b.job_id, b.worker_id, b.employer_id, b.status,
+		b.worker_checked_in_at, b.worker_checked_out_at, b.employer_checked_in_at, b.employer_checked_out_at,
+		b.worker_confirmed_at, b.employer_confirmed_at, b.completed_at, b.cancelled_by, b.cancel_reason,
+		b.created_at, b.updated_at, b.worker_rating, b.worker_review, b.worker_rated_at,
+		j.organization_id, j.title, j.starts_at, j.ends_at`
+	bookingFrom = ` FROM bookings b JOIN jobs j ON j.id = b.job_id `
+	// Bookings that still hold the worker's time; see booking.Active
+	activeBooking = `b.status IN ('scheduled', 'in_progress')`
+
+	workExperienceColumns = `id, worker_id, booking_id, job_title, employer_name, description, duration, start_date, end_date, created_at`
+)
+
+func scanBooking(row pgx.Row) (*models.Booking, error) {
+	var b models.Booking
+	err := row.Scan(&b.ID, &b.ApplicationID, &b.JobID, &b.WorkerID, &b.EmployerID, &b.Status,
+		&b.WorkerCheckedInAt, &b.WorkerCheckedOutAt, &b.EmployerCheckedInAt, &b.EmployerCheckedOutAt,
+		&b.WorkerConfirmedAt, &b.EmployerConfirmedAt, &b.CompletedAt, &b.CancelledBy, &b.CancelReason,
+		&b.CreatedAt, &b.UpdatedAt, &b.WorkerRating, &b.WorkerReview, &b.WorkerRatedAt,
+		&b.OrganizationID, &b.JobTitle, &b.StartsAt, &b.EndsAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (s *PgBookingStore) Get(ctx context.Context, id int) (*models.Booking, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	return scanBooking(s.DB.QueryRow(ctx, `SELECT `+bookingColumns+bookingFrom+`WHERE b.id = $1`, id))
+}
+
+func (s *PgBookingStore) GetByApplication(ctx context.Context, applicationID int) (*models.Booking, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	return scanBooking(s.DB.QueryRow(ctx, `SELECT `+bookingColumns+bookingFrom+`WHERE b.application_id = $1`, applicationID))
+}
+
+func (s *PgBookingStore) ListByUser(ctx context.Context, userID int, status string) ([]models.Booking, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	rows, err := s.DB.Query(ctx, `
+		SELECT `+bookingColumns+bookingFrom+`
+		WHERE (b.worker_id = $1 OR b.employer_id = $1
+		       OR j.organization_id IN (SELECT organization_id FROM organization_members WHERE user_id = $1))
+		  AND ($2 = '' OR b.status = $2)
+		ORDER BY b.created_at DESC, b.id DESC`, userID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookings := []models.Booking{}
+	for rows.Next() {
+		b, err := scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, *b)
+	}
+	return bookings, rows.Err()
+}
+
+// Apply locks the booking, applies e to it and saves the result. A
+// booking that falls through ends its application too.
+func (s *PgBookingStore) Apply(ctx context.Context, id int, e booking.Event) (*models.Booking, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	var updated models.Booking
+	err := inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		// The application is locked before the booking, as UpdateStatus
+		// does, so the two can't deadlock
+		_, err := tx.Exec(ctx, `
+			SELECT 1 FROM applications
+			WHERE id = (SELECT application_id FROM bookings WHERE id = $1)
+			FOR UPDATE`, id)
+		if err != nil {
+			return err
+		}
+		before, err := lockBooking(ctx, tx, `b.id = $1`, id)
+		if err != nil {
+			return err
+		}
+		updated = *before
+		if err := booking.Apply(&updated, e); err != nil {
+			return err
+		}
+		if err := saveBooking(ctx, tx, &updated); err != nil {
+			return err
+		}
+		if err := recordChange(ctx, tx, audit.BookingActionPrefix+string(e.Action), audit.TargetBooking, id, before, &updated); err != nil {
+			return err
+		}
+		switch updated.Status {
+		case booking.Completed:
+			if err := releaseEscrow(ctx, tx, &updated); err != nil {
+				return err
+			}
+			return addWorkExperience(ctx, tx, &updated)
+		case booking.Cancelled, booking.NoShow:
+			return endApplication(ctx, tx, &updated)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// endApplication moves the application of a booking that was cancelled
+// or missed out of accepted, so the worker no longer counts as hired:
+// withdrawn when the worker cancelled, rejected otherwise
+func endApplication(ctx context.Context, tx pgx.Tx, b *models.Booking) error {
+	status := models.ApplicationRejected
+	if b.CancelledBy != nil && *b.CancelledBy == b.WorkerID {
+		status = models.ApplicationWithdrawn
+	}
+
+	var before, after models.Application
+	err := tx.QueryRow(ctx, `SELECT `+applicationColumns+` FROM applications a WHERE a.id = $1`, b.ApplicationID).
+		Scan(applicationFields(&before)...)
+	if err != nil {
+		return err
+	}
+	if before.Status != models.ApplicationAccepted {
+		return nil
+	}
+	err = tx.QueryRow(ctx, `
+		UPDATE applications AS a
+		SET status = $1, updated_at = NOW()
+		WHERE a.id = $2
+		RETURNING `+applicationColumns, status, b.ApplicationID,
+	).Scan(applicationFields(&after)...)
+	if err != nil {
+		return err
+	}
+	return recordChange(ctx, tx, audit.ApplicationStatusPrefix+status, audit.TargetApplication, after.ID, &before, &after)
+}
+
+// lockBooking loads the booking matching where FOR UPDATE
+func lockBooking(ctx context.Context, tx pgx.Tx, where string, args ...any) (*models.Booking, error) {
+	return scanBooking(tx.QueryRow(ctx, `SELECT `+bookingColumns+bookingFrom+`WHERE `+where+` FOR UPDATE OF b`, args...))
+}
+
+// saveBooking writes b's lifecycle fields and refreshes its updated_at
+func saveBooking(ctx context.Context, tx pgx.Tx, b *models.Booking) error {
+	return tx.QueryRow(ctx, `
+		UPDATE bookings
+		SET status = $2,
+		    worker_checked_in_at = $3, worker_checked_out_at = $4,
+		    employer_checked_in_at = $5, employer_checked_out_at = $6,
+		    worker_confirmed_at = $7, employer_confirmed_at = $8, completed_at = $9,
+		    cancelled_by = $10, cancel_reason = $11,
+		    worker_rating = $12, worker_review = $13, worker_rated_at = $14, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`,
+		b.ID, b.Status,
+		b.WorkerCheckedInAt, b.WorkerCheckedOutAt,
+		b.EmployerCheckedInAt, b.EmployerCheckedOutAt,
+		b.WorkerConfirmedAt, b.EmployerConfirmedAt, b.CompletedAt,
+		b.CancelledBy, b.CancelReason,
+		b.WorkerRating, b.WorkerReview, b.WorkerRatedAt).Scan(&b.UpdatedAt)
+}
+
+// addWorkExperience puts a completed booking on the worker's record. It
+// runs from the worker's first check-in, or the job's start, to their
+// check-out.
+func addWorkExperience(ctx context.Context, tx pgx.Tx, b *models.Booking) error {
+	startDate := firstTime(b.WorkerCheckedInAt, b.StartsAt, b.EmployerCheckedInAt, b.CompletedAt)
+	endDate := firstTime(b.WorkerCheckedOutAt, b.CompletedAt)
+
+	var exp models.WorkExperience
+	err := tx.QueryRow(ctx, `
+		INSERT INTO work_experience (worker_id, booking_id, job_title, employer_name, description, duration, start_date, end_date)
+		SELECT b.worker_id, b.id, j.title, u.full_name, j.description, j.duration, $2::date, $3::date
+		FROM bookings b
+		JOIN jobs j ON j.id = b.job_id
+		JOIN users u ON u.id = b.employer_id
+		WHERE b.id = $1
+		RETURNING `+workExperienceColumns, b.ID, startDate, endDate).
+		Scan(&exp.ID, &exp.WorkerID, &exp.BookingID, &exp.JobTitle, &exp.EmployerName, &exp.Description,
+			&exp.Duration, &exp.StartDate, &exp.EndDate, &exp.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return recordChange(ctx, tx, audit.WorkExperienceAdded, audit.TargetUser, exp.WorkerID, nil, &exp)
+}
+
+func firstTime(times ...*time.Time) *time.Time {
+	for _, t := range times {
+		if t != nil {
+			return t
+		}
+	}
+	return nil
+}
+
+// bookApplication creates the booking for a newly accepted application, or
+// reschedules the one left from an earlier acceptance
+func bookApplication(ctx context.Context, tx pgx.Tx, applicationID int) error {
+	before, err := lockBooking(ctx, tx, `b.application_id = $1`, applicationID)
+	if errors.Is(err, ErrNotFound) {
+		before = nil
+	} else if err != nil {
+		return err
+	}
+
+	var id int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO bookings (application_id, job_id, worker_id, employer_id)
+		SELECT a.id, a.job_id, a.worker_id, j.employer_id
+		FROM applications a
+		JOIN jobs j ON j.id = a.job_id
+		WHERE a.id = $1
+		ON CONFLICT (application_id) DO UPDATE SET
+		    status = 'scheduled',
+		    worker_checked_in_at = NULL, worker_checked_out_at = NULL,
+		    employer_checked_in_at = NULL, employer_checked_out_at = NULL,
+		    worker_confirmed_at = NULL, employer_confirmed_at = NULL, completed_at = NULL,
+		    cancelled_by = NULL, cancel_reason = NULL, updated_at = NOW()
+		RETURNING id`, applicationID).Scan(&id)
+	if err != nil {
+		return err
+	}
+	after, err := scanBooking(tx.QueryRow(ctx, `SELECT `+bookingColumns+bookingFrom+`WHERE b.id = $1`, id))
+	if err != nil {
+		return err
+	}
+	return recordChange(ctx, tx, audit.BookingCreated, audit.TargetBooking, id, before, after)
+}
+
+// releaseBooking cancels the booking of an application that is no longer
+// accepted. Once the work has started or finished the application can't
+// be changed.
+func releaseBooking(ctx context.Context, tx pgx.Tx, applicationID int, status string) error {
+	before, err := lockBooking(ctx, tx, `b.application_id = $1`, applicationID)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	switch before.Status {
+	case booking.InProgress, booking.Completed:
+		return &booking.TransitionError{Reason: "This worker's booking has already started, so the application can't be " + status}
+	case booking.Scheduled:
+	default:
+		return nil
+	}
+
+	b := *before
+	reason := "Application " + status
+	b.Status, b.CancelReason = booking.Cancelled, &reason
+	if actor := audit.FromContext(ctx).ActorID; actor != 0 {
+		b.CancelledBy = &actor
+	}
+	if err := saveBooking(ctx, tx, &b); err != nil {
+		return err
+	}
+	return recordChange(ctx, tx, audit.BookingActionPrefix+string(booking.Cancel), audit.TargetBooking, b.ID, before, &b)
+}
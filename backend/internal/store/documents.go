@@ -0,0 +1,105 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/audit"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgDocumentStore is the Postgres implementation of DocumentStore
+type PgDocumentStore struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewPgDocumentStore(db *pgxpool.Pool, queryTimeout time.Duration) *PgDocumentStore {
+	return &PgDocumentStore{DB: db, QueryTimeout: queryTimeout}
+}
+
+const documentColumns = `id, user_id, kind, file_name, content_type, size_bytes, blob_key, created_at`
+
+func scanDocument(row pgx.Row) (*models.Document, error) {
+	var d models.Document
+	err := row.Scan(&d.ID, &d.UserID, &d.Kind, &d.FileName, &d.ContentType, &d.SizeBytes, &d.BlobKey, &d.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// Create inserts the document and fills in its generated fields
+func (s *PgDocumentStore) Create(ctx context.Context, doc *models.Document) error {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	query := `
+		INSERT INTO user_documents (user_id, kind, file_name, content_type, size_bytes, blob_key)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + documentColumns
+
+	return inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		created, err := scanDocument(tx.QueryRow(ctx, query,
+			doc.UserID, doc.Kind, doc.FileName, doc.ContentType, doc.SizeBytes, doc.BlobKey,
+		))
+		if err != nil {
+			return err
+		}
+		*doc = *created
+		return recordChange(ctx, tx, audit.DocumentUploaded, audit.TargetDocument, created.ID, nil, created)
+	})
+}
+
+func (s *PgDocumentStore) ListByUser(ctx context.Context, userID int) ([]models.Document, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	rows, err := s.DB.Query(ctx, `
+		SELECT `+documentColumns+`
+		FROM user_documents
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []models.Document{}
+	for rows.Next() {
+		doc, err := scanDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, *doc)
+	}
+	return docs, rows.Err()
+}
+
+func (s *PgDocumentStore) Delete(ctx context.Context, id, userID int) (*models.Document, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	var deleted *models.Document
+	err := inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		var err error
+		deleted, err = scanDocument(tx.QueryRow(ctx, `
+			DELETE FROM user_documents
+			WHERE id = $1 AND user_id = $2
+			RETURNING `+documentColumns, id, userID))
+		if err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, audit.DocumentDeleted, audit.TargetDocument, id, deleted, map[string]any{})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/audit"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/invoice"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgInvoiceStore is the Postgres implementation of InvoiceStore
+type PgInvoiceStore struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewPgInvoiceStore(db *pgxpool.Pool, queryTimeout time.Duration) *PgInvoiceStore {
+	return &PgInvoiceStore{DB: db, QueryTimeout: queryTimeout}
+}
+
+const invoiceColumns = `id, number, booking_id, worker_id, employer_id, worker_name, employer_name, job_title, amount, currency, issued_at`
+
+func scanInvoice(row pgx.Row) (*models.Invoice, error) {
+	var inv models.Invoice
+	err := row.Scan(&inv.ID, &inv.Number, &inv.BookingID, &inv.WorkerID, &inv.EmployerID, &inv.WorkerName,
+		&inv.EmployerName, &inv.JobTitle, &inv.Amount, &inv.Currency, &inv.IssuedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+func (s *PgInvoiceStore) Get(ctx context.Context, id int) (*models.Invoice, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	return scanInvoice(s.DB.QueryRow(ctx, `SELECT `+invoiceColumns+` FROM invoices WHERE id = $1`, id))
+}
+
+func (s *PgInvoiceStore) ListByUser(ctx context.Context, userID int, from, to time.Time) ([]models.Invoice, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	rows, err := s.DB.Query(ctx, `
+		SELECT `+invoiceColumns+` FROM invoices
+		WHERE (worker_id = $1 OR employer_id = $1) AND issued_at >= $2 AND issued_at < $3
+		ORDER BY issued_at, id`, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invoices := []models.Invoice{}
+	for rows.Next() {
+		inv, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, *inv)
+	}
+	return invoices, rows.Err()
+}
+
+// issueInvoice records amount paid to b's worker under the next number
+// for this year. Incrementing the year's counter row locks it until tx
+// ends, so concurrent completions take turns and numbers never repeat or
+// skip; a rolled-back issue gives its number back.
+func issueInvoice(ctx context.Context, tx pgx.Tx, b *models.Booking, currency string, amount int64) error {
+	now := time.Now().UTC()
+	var seq int
+	err := tx.QueryRow(ctx, `
+		INSERT INTO invoice_counters (year, last_number) VALUES ($1, 1)
+		ON CONFLICT (year) DO UPDATE SET last_number = invoice_counters.last_number + 1
+		RETURNING last_number`, now.Year()).Scan(&seq)
+	if err != nil {
+		return err
+	}
+
+	inv, err := scanInvoice(tx.QueryRow(ctx, `
+		INSERT INTO invoices (number, booking_id, worker_id, employer_id, worker_name, employer_name, job_title, amount, currency, issued_at)
+		SELECT $1, b.id, b.worker_id, b.employer_id, w.full_name, e.full_name, j.title, $2, $3, $4
+		FROM bookings b
+		JOIN jobs j ON j.id = b.job_id
+		JOIN users w ON w.id = b.worker_id
+		JOIN users e ON e.id = b.employer_id
+		WHERE b.id = $5
+		RETURNING `+invoiceColumns, invoice.Number(now.Year(), seq), amount, currency, now, b.ID))
+	if err != nil {
+		return err
+	}
+	return recordChange(ctx, tx, audit.InvoiceIssued, audit.TargetInvoice, inv.ID, nil, inv)
+}
@@ -0,0 +1,490 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/audit"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/booking"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/ledger"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/pay"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgJobStore is the Postgres implementation of JobStore
+type PgJobStore struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewPgJobStore(db *pgxpool.Pool, queryTimeout time.Duration) *PgJobStore {
+	return &PgJobStore{DB: db, QueryTimeout: queryTimeout}
+}
+
+const jobColumns = `j.id, j.employer_id, j.title, j.description, j.category_id, j.location,
+	j.pay_type, j.currency, j.pay_min, j.pay_max, j.hourly_rate, j.duration, j.requirements,
+	j.contact_phone, j.contact_email, j.expires_at, j.starts_at, j.ends_at,
+	j.status, j.is_active, j.created_at, j.updated_at, j.organization_id, j.latitude, j.longitude`
+
+const jobDetailsQuery = `
+	SELECT ` + jobColumns + `,
+	       u.full_name AS employer_name,
+	       o.name AS organization_name,
+	       c.name AS category_name
+	FROM jobs j
+	JOIN users u ON j.employer_id = u.id
+	JOIN organizations o ON j.organization_id = o.id
+	LEFT JOIN categories c ON j.category_id = c.id`
+
+func jobFields(j *models.Job) []any {
+	return []any{
+		&j.ID, &j.EmployerID, &j.Title, &j.Description, &j.CategoryID, &j.Location,
+		&j.PayType, &j.Currency, &j.PayMin, &j.PayMax, &j.HourlyRate, &j.Duration, &j.Requirements,
+		&j.ContactPhone, &j.ContactEmail, &j.ExpiresAt, &j.StartsAt, &j.EndsAt,
+		&j.Status, &j.IsActive, &j.CreatedAt, &j.UpdatedAt, &j.OrganizationID, &j.Latitude, &j.Longitude,
+	}
+}
+
+func scanJobWithDetails(row pgx.Row) (*models.JobWithDetails, error) {
+	var j models.JobWithDetails
+	err := row.Scan(append(jobFields(&j.Job), &j.EmployerName, &j.OrganizationName, &j.CategoryName)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+const attachmentColumns = `id, job_id, file_name, content_type, size_bytes, blob_key, created_at`
+
+func scanAttachment(row pgx.Row) (*models.JobAttachment, error) {
+	var a models.JobAttachment
+	err := row.Scan(&a.ID, &a.JobID, &a.FileName, &a.ContentType, &a.SizeBytes, &a.BlobKey, &a.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// insertAttachments adds attachments to a job inside tx
+func insertAttachments(ctx context.Context, tx pgx.Tx, jobID int, attachments []*models.JobAttachment) error {
+	query := `
+		INSERT INTO job_attachments (job_id, file_name, content_type, size_bytes, blob_key)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + attachmentColumns
+
+	for _, a := range attachments {
+		created, err := scanAttachment(tx.QueryRow(ctx, query, jobID, a.FileName, a.ContentType, a.SizeBytes, a.BlobKey))
+		if err != nil {
+			return err
+		}
+		*a = *created
+		if err := recordChange(ctx, tx, audit.JobAttachmentAdded, audit.TargetJob, jobID, nil, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const questionColumns = `id, job_id, position, prompt, kind, required, choices,
+	reject_answer, min_value, max_value, reject_choices`
+
+func questionFields(q *models.Question) []any {
+	return []any{&q.ID, &q.JobID, &q.Position, &q.Prompt, &q.Kind, &q.Required, &q.Choices,
+		&q.RejectAnswer, &q.MinValue, &q.MaxValue, &q.RejectChoices}
+}
+
+// insertQuestions adds a new job's screening questions inside tx
+func insertQuestions(ctx context.Context, tx pgx.Tx, jobID int, questions []models.Question) error {
+	query := `
+		INSERT INTO job_questions (job_id, position, prompt, kind, required, choices,
+			reject_answer, min_value, max_value, reject_choices)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING ` + questionColumns
+
+	for i := range questions {
+		q := &questions[i]
+		err := tx.QueryRow(ctx, query, jobID, q.Position, q.Prompt, q.Kind, q.Required, nonNil(q.Choices),
+			q.RejectAnswer, q.MinValue, q.MaxValue, nonNil(q.RejectChoices),
+		).Scan(questionFields(q)...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nonNil is s, or an empty slice for the NOT NULL array columns
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// Create inserts the job and fills in its generated fields
+func (s *PgJobStore) Create(ctx context.Context, job *models.Job, questions []models.Question, attachments ...*models.JobAttachment) error {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	query := `
+		INSERT INTO jobs AS j (
+			employer_id, title, description, category_id, location,
+			pay_type, currency, pay_min, pay_max, hourly_rate, duration, requirements,
+			contact_phone, contact_email, expires_at, starts_at, ends_at, organization_id,
+			latitude, longitude
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+		RETURNING ` + jobColumns
+
+	return inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, query,
+			job.EmployerID, job.Title, job.Description, job.CategoryID, job.Location,
+			job.PayType, job.Currency, job.PayMin, job.PayMax, pay.HourlyRate(job), job.Duration, job.Requirements,
+			job.ContactPhone, job.ContactEmail, job.ExpiresAt, job.StartsAt, job.EndsAt, job.OrganizationID,
+			job.Latitude, job.Longitude,
+		).Scan(jobFields(job)...)
+		if err != nil {
+			return err
+		}
+		if err := insertQuestions(ctx, tx, job.ID, questions); err != nil {
+			return err
+		}
+		if err := recordChange(ctx, tx, audit.JobCreated, audit.TargetJob, job.ID, nil, job); err != nil {
+			return err
+		}
+		return insertAttachments(ctx, tx, job.ID, attachments)
+	})
+}
+
+func (s *PgJobStore) Get(ctx context.Context, id int) (*models.JobWithDetails, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	return scanJobWithDetails(s.DB.QueryRow(ctx, jobDetailsQuery+` WHERE j.id = $1`, id))
+}
+
+// JobSort orders ListOpen
+type JobSort string
+
+const (
+	SortNewest JobSort = "newest"
+	// By hourly rate, with jobs that have none last. Rates only compare
+	// within a currency, so filter by one to use these.
+	SortRateAsc  JobSort = "rate_asc"
+	SortRateDesc JobSort = "rate_desc"
+)
+
+var jobOrder = map[JobSort]string{
+	SortNewest:   `j.created_at DESC`,
+	SortRateAsc:  `j.hourly_rate ASC NULLS LAST, j.created_at DESC`,
+	SortRateDesc: `j.hourly_rate DESC NULLS LAST, j.created_at DESC`,
+}
+
+// JobFilter narrows and orders ListOpen; zero fields match everything and
+// sort newest first
+type JobFilter struct {
+	PayType  models.PayType
+	Currency string
+	// Bounds on the hourly rate, in minor units; jobs without a rate are
+	// left out when either is set
+	MinRate int64
+	MaxRate int64
+	Sort    JobSort
+}
+
+func (s *PgJobStore) ListOpen(ctx context.Context, filter JobFilter) ([]models.JobWithDetails, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	where := []string{`j.is_active = true`, `j.status = 'open'`, `j.expires_at > NOW()`}
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if filter.PayType != "" {
+		add("j.pay_type = $%d", filter.PayType)
+	}
+	if filter.Currency != "" {
+		add("j.currency = $%d", filter.Currency)
+	}
+	if filter.MinRate != 0 {
+		add("j.hourly_rate >= $%d", filter.MinRate)
+	}
+	if filter.MaxRate != 0 {
+		add("j.hourly_rate <= $%d", filter.MaxRate)
+	}
+	order, ok := jobOrder[filter.Sort]
+	if !ok {
+		order = jobOrder[SortNewest]
+	}
+
+	query := jobDetailsQuery + `
+	WHERE ` + strings.Join(where, " AND ") + `
+	ORDER BY ` + order
+
+	rows, err := s.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []models.JobWithDetails{}
+	for rows.Next() {
+		job, err := scanJobWithDetails(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+func (s *PgJobStore) CountOpenByEmployer(ctx context.Context, employerID int) (int, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	query := `
+		SELECT COUNT(*) FROM jobs
+		WHERE employer_id = $1
+		  AND is_active = true
+		  AND status = 'open'
+		  AND expires_at > NOW()`
+
+	var count int
+	err := s.DB.QueryRow(ctx, query, employerID).Scan(&count)
+	return count, err
+}
+
+func (s *PgJobStore) Update(ctx context.Context, job *models.Job, attachments []*models.JobAttachment, maxAttachments int) error {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	query := `
+		UPDATE jobs AS j
+		SET title = $1, description = $2, category_id = $3, location = $4,
+		    pay_type = $5, currency = $6, pay_min = $7, pay_max = $8, hourly_rate = $9,
+		    duration = $10, requirements = $11, contact_phone = $12, contact_email = $13,
+		    expires_at = $14, starts_at = $15, ends_at = $16, latitude = $17, longitude = $18,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE j.id = $19
+		RETURNING ` + jobColumns
+
+	return inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		// Locking the job serialises concurrent updates, so the
+		// attachment count below can't be raced past the limit
+		var before models.Job
+		err := tx.QueryRow(ctx, `SELECT `+jobColumns+` FROM jobs j WHERE j.id = $1 FOR UPDATE`, job.ID).
+			Scan(jobFields(&before)...)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// Moving the job must not double-book anyone already hired for it
+		if !sameTime(before.StartsAt, job.StartsAt) || !sameTime(before.EndsAt, job.EndsAt) {
+			if err := checkHiredWorkersFree(ctx, tx, job); err != nil {
+				return err
+			}
+		}
+
+		if len(attachments) > 0 {
+			var count int
+			if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM job_attachments WHERE job_id = $1`, job.ID).Scan(&count); err != nil {
+				return err
+			}
+			if count+len(attachments) > maxAttachments {
+				return ErrTooManyAttachments
+			}
+		}
+
+		err = tx.QueryRow(ctx, query,
+			job.Title, job.Description, job.CategoryID, job.Location,
+			job.PayType, job.Currency, job.PayMin, job.PayMax, pay.HourlyRate(job),
+			job.Duration, job.Requirements, job.ContactPhone, job.ContactEmail,
+			job.ExpiresAt, job.StartsAt, job.EndsAt, job.Latitude, job.Longitude, job.ID,
+		).Scan(jobFields(job)...)
+		if err != nil {
+			return err
+		}
+		// updated_at always changes; only audit real edits
+		before.UpdatedAt = job.UpdatedAt
+		if err := recordChange(ctx, tx, audit.JobUpdated, audit.TargetJob, job.ID, &before, job); err != nil {
+			return err
+		}
+		return insertAttachments(ctx, tx, job.ID, attachments)
+	})
+}
+
+func (s *PgJobStore) Delete(ctx context.Context, id int) ([]models.JobAttachment, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	var attachments []models.JobAttachment
+	err := inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		var err error
+		if err := checkUnbooked(ctx, tx, id); err != nil {
+			return err
+		}
+		if attachments, err = listAttachments(ctx, tx, id); err != nil {
+			return err
+		}
+
+		var deleted models.Job
+		err = tx.QueryRow(ctx, `DELETE FROM jobs AS j WHERE j.id = $1 RETURNING `+jobColumns, id).
+			Scan(jobFields(&deleted)...)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, audit.JobDeleted, audit.TargetJob, id, &deleted, map[string]any{})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// checkUnbooked fails with ErrJobBooked if deleting the job would lose
+// track of a booking or its money: deleting cascades to the bookings and
+// cuts payments and ledger transactions loose from them, leaving nothing
+// to release or refund escrow with
+func checkUnbooked(ctx context.Context, tx pgx.Tx, jobID int) error {
+	rows, err := tx.Query(ctx, `SELECT id, status FROM bookings WHERE job_id = $1 ORDER BY id FOR UPDATE`, jobID)
+	if err != nil {
+		return err
+	}
+	bookings, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Booking, error) {
+		var b models.Booking
+		return b, row.Scan(&b.ID, &b.Status)
+	})
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int, len(bookings))
+	for i, b := range bookings {
+		if booking.Active(b.Status) {
+			return ErrJobBooked
+		}
+		held, err := balances(ctx, tx, ledger.Escrow(b.ID))
+		if err != nil {
+			return err
+		}
+		for _, h := range held {
+			if h.Amount != 0 {
+				return ErrJobBooked
+			}
+		}
+		ids[i] = b.ID
+	}
+
+	// A pending charge would otherwise settle into an escrow nobody owns
+	var charging bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM payments WHERE booking_id = ANY($1) AND status = 'pending')`, ids,
+	).Scan(&charging)
+	if err != nil {
+		return err
+	}
+	if charging {
+		return ErrJobBooked
+	}
+	return nil
+}
+
+// querier is satisfied by both the pool and a transaction
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func listAttachments(ctx context.Context, db querier, jobID int) ([]models.JobAttachment, error) {
+	rows, err := db.Query(ctx, `
+		SELECT `+attachmentColumns+`
+		FROM job_attachments
+		WHERE job_id = $1
+		ORDER BY id`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []models.JobAttachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, *a)
+	}
+	return attachments, rows.Err()
+}
+
+func (s *PgJobStore) ListAttachments(ctx context.Context, jobID int) ([]models.JobAttachment, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	return listAttachments(ctx, s.DB, jobID)
+}
+
+func (s *PgJobStore) ListQuestions(ctx context.Context, jobID int) ([]models.Question, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	rows, err := s.DB.Query(ctx, `
+		SELECT `+questionColumns+`
+		FROM job_questions
+		WHERE job_id = $1
+		ORDER BY position`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	questions := []models.Question{}
+	for rows.Next() {
+		var q models.Question
+		if err := rows.Scan(questionFields(&q)...); err != nil {
+			return nil, err
+		}
+		questions = append(questions, q)
+	}
+	return questions, rows.Err()
+}
+
+func (s *PgJobStore) DeleteAttachment(ctx context.Context, jobID, id int) (*models.JobAttachment, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	var deleted *models.JobAttachment
+	err := inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		var err error
+		deleted, err = scanAttachment(tx.QueryRow(ctx, `
+			DELETE FROM job_attachments
+			WHERE id = $1 AND job_id = $2
+			RETURNING `+attachmentColumns, id, jobID))
+		if err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, audit.JobAttachmentDeleted, audit.TargetJob, jobID, deleted, map[string]any{})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
@@ -0,0 +1,125 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/ledger"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// postTransaction writes t and its entries. A transaction whose key has
+// been posted before is skipped, so callers can retry safely. The
+// database checks the entries balance when tx commits.
+func postTransaction(ctx context.Context, tx pgx.Tx, t ledger.Transaction) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	var id int
+	err := tx.QueryRow(ctx, `
+		INSERT INTO ledger_transactions (kind, idempotency_key, currency, booking_id, payment_id, description)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (idempotency_key) DO NOTHING
+		RETURNING id`, t.Kind, t.Key, t.Currency, t.BookingID, t.PaymentID, t.Description).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, e := range t.Entries {
+		accountID, err := accountID(ctx, tx, e.Account, t.Currency)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `INSERT INTO ledger_entries (transaction_id, account_id, amount) VALUES ($1, $2, $3)`,
+			id, accountID, e.Amount)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// accountID returns the account's ID, creating it on first use
+func accountID(ctx context.Context, tx pgx.Tx, code, currency string) (int, error) {
+	var id int
+	err := tx.QueryRow(ctx, `
+		INSERT INTO ledger_accounts (code, currency) VALUES ($1, $2)
+		ON CONFLICT (code, currency) DO UPDATE SET code = EXCLUDED.code
+		RETURNING id`, code, currency).Scan(&id)
+	return id, err
+}
+
+// lockedBalance locks the account, creating it if need be, and returns its
+// balance. Holding the lock until tx ends stops two transactions spending
+// the same money.
+func lockedBalance(ctx context.Context, tx pgx.Tx, code, currency string) (int64, error) {
+	id, err := accountID(ctx, tx, code, currency)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `SELECT id FROM ledger_accounts WHERE id = $1 FOR UPDATE`, id); err != nil {
+		return 0, err
+	}
+	var balance int64
+	err = tx.QueryRow(ctx, `SELECT COALESCE(SUM(amount), 0) FROM ledger_entries WHERE account_id = $1`, id).Scan(&balance)
+	return balance, err
+}
+
+// balances sums an account's entries in each currency it has used
+func balances(ctx context.Context, db querier, code string) ([]models.Balance, error) {
+	rows, err := db.Query(ctx, `
+		SELECT a.currency, COALESCE(SUM(e.amount), 0)::bigint
+		FROM ledger_accounts a
+		LEFT JOIN ledger_entries e ON e.account_id = a.id
+		WHERE a.code = $1
+		GROUP BY a.currency
+		ORDER BY a.currency`, code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.Balance{}
+	for rows.Next() {
+		var b models.Balance
+		if err := rows.Scan(&b.Currency, &b.Amount); err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// releaseEscrow pays everything held for a completed booking to its worker
+// and issues the invoice for it
+func releaseEscrow(ctx context.Context, tx pgx.Tx, b *models.Booking) error {
+	held, err := balances(ctx, tx, ledger.Escrow(b.ID))
+	if err != nil {
+		return err
+	}
+	for _, h := range held {
+		amount, err := lockedBalance(ctx, tx, ledger.Escrow(b.ID), h.Currency)
+		if err != nil {
+			return err
+		}
+		if amount < 0 {
+			return fmt.Errorf("escrow for booking %d is overdrawn by %d %s", b.ID, -amount, h.Currency)
+		}
+		if amount == 0 {
+			continue
+		}
+		if err := postTransaction(ctx, tx, ledger.Release(b.ID, b.WorkerID, h.Currency, amount)); err != nil {
+			return err
+		}
+		if err := issueInvoice(ctx, tx, b, h.Currency, amount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
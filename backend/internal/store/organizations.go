@@ -0,0 +1,392 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/audit"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgOrganizationStore is the Postgres implementation of OrganizationStore
+type PgOrganizationStore struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewPgOrganizationStore(db *pgxpool.Pool, queryTimeout time.Duration) *PgOrganizationStore {
+	return &PgOrganizationStore{DB: db, QueryTimeout: queryTimeout}
+}
+
+const (
+	organizationColumns = `o.id, o.name, o.description, o.website, o.created_at, o.updated_at`
+	memberColumns       = `m.organization_id, m.user_id, u.full_name, u.email, m.role, m.created_at`
+	memberFrom          = ` FROM organization_members m JOIN users u ON u.id = m.user_id `
+	invitationColumns   = `id, organization_id, email, role, invited_by, expires_at, accepted_at, created_at`
+)
+
+func organizationFields(o *models.Organization) []any {
+	return []any{&o.ID, &o.Name, &o.Description, &o.Website, &o.CreatedAt, &o.UpdatedAt}
+}
+
+func scanOrganization(row pgx.Row) (*models.Organization, error) {
+	var o models.Organization
+	err := row.Scan(organizationFields(&o)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func scanMember(row pgx.Row) (*models.Member, error) {
+	var m models.Member
+	err := row.Scan(&m.OrganizationID, &m.UserID, &m.FullName, &m.Email, &m.Role, &m.JoinedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func scanInvitation(row pgx.Row) (*models.Invitation, error) {
+	var inv models.Invitation
+	err := row.Scan(&inv.ID, &inv.OrganizationID, &inv.Email, &inv.Role, &inv.InvitedBy,
+		&inv.ExpiresAt, &inv.AcceptedAt, &inv.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// createOrganization inserts org inside tx with ownerID as its owner.
+// Registering an employer uses it to give them an organization of their
+// own.
+func createOrganization(ctx context.Context, tx pgx.Tx, org *models.Organization, ownerID int) error {
+	created, err := scanOrganization(tx.QueryRow(ctx, `
+		INSERT INTO organizations AS o (name, description, website, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+organizationColumns, org.Name, org.Description, org.Website, ownerID))
+	if err != nil {
+		return err
+	}
+	*org = *created
+	if err := recordChange(ctx, tx, audit.OrganizationCreated, audit.TargetOrganization, org.ID, nil, org); err != nil {
+		return err
+	}
+	_, err = addMember(ctx, tx, org.ID, ownerID, models.OrgOwner)
+	return err
+}
+
+// addMember puts the user on the organization's team inside tx
+func addMember(ctx context.Context, tx pgx.Tx, orgID, userID int, role models.OrgRole) (*models.Member, error) {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO organization_members (organization_id, user_id, role)
+		VALUES ($1, $2, $3)`, orgID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	member, err := scanMember(tx.QueryRow(ctx, `SELECT `+memberColumns+memberFrom+`
+		WHERE m.organization_id = $1 AND m.user_id = $2`, orgID, userID))
+	if err != nil {
+		return nil, err
+	}
+	return member, recordChange(ctx, tx, audit.MemberAdded, audit.TargetOrganization, orgID, nil, member)
+}
+
+func (s *PgOrganizationStore) Create(ctx context.Context, org *models.Organization, ownerID int) error {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	return inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		return createOrganization(ctx, tx, org, ownerID)
+	})
+}
+
+func (s *PgOrganizationStore) Get(ctx context.Context, id int) (*models.Organization, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	return scanOrganization(s.DB.QueryRow(ctx, `SELECT `+organizationColumns+` FROM organizations o WHERE o.id = $1`, id))
+}
+
+func (s *PgOrganizationStore) Update(ctx context.Context, org *models.Organization) error {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	return inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		before, err := scanOrganization(tx.QueryRow(ctx,
+			`SELECT `+organizationColumns+` FROM organizations o WHERE o.id = $1 FOR UPDATE`, org.ID))
+		if err != nil {
+			return err
+		}
+		updated, err := scanOrganization(tx.QueryRow(ctx, `
+			UPDATE organizations AS o
+			SET name = $1, description = $2, website = $3, updated_at = NOW()
+			WHERE o.id = $4
+			RETURNING `+organizationColumns, org.Name, org.Description, org.Website, org.ID))
+		if err != nil {
+			return err
+		}
+		*org = *updated
+		// updated_at always changes; only audit real edits
+		before.UpdatedAt = org.UpdatedAt
+		return recordChange(ctx, tx, audit.OrganizationUpdated, audit.TargetOrganization, org.ID, before, org)
+	})
+}
+
+func (s *PgOrganizationStore) ListByUser(ctx context.Context, userID int) ([]models.Membership, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	rows, err := s.DB.Query(ctx, `
+		SELECT `+organizationColumns+`, m.role
+		FROM organizations o
+		JOIN organization_members m ON m.organization_id = o.id
+		WHERE m.user_id = $1
+		ORDER BY o.name, o.id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships := []models.Membership{}
+	for rows.Next() {
+		var m models.Membership
+		if err := rows.Scan(append(organizationFields(&m.Organization), &m.Role)...); err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, m)
+	}
+	return memberships, rows.Err()
+}
+
+func (s *PgOrganizationStore) Role(ctx context.Context, orgID, userID int) (models.OrgRole, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	var role models.OrgRole
+	err := s.DB.QueryRow(ctx, `
+		SELECT role FROM organization_members
+		WHERE organization_id = $1 AND user_id = $2`, orgID, userID).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return role, err
+}
+
+func (s *PgOrganizationStore) ListMembers(ctx context.Context, orgID int) ([]models.Member, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	rows, err := s.DB.Query(ctx, `SELECT `+memberColumns+memberFrom+`
+		WHERE m.organization_id = $1
+		ORDER BY m.created_at, m.user_id`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.Member{}
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, *m)
+	}
+	return members, rows.Err()
+}
+
+// lockMember locks every membership row of the organization, so
+// concurrent changes can't both remove the last owner, and returns the
+// user's and how many owners there are
+func lockMember(ctx context.Context, tx pgx.Tx, orgID, userID int) (*models.Member, int, error) {
+	var owners int
+	err := tx.QueryRow(ctx, `
+		SELECT COUNT(*) FILTER (WHERE role = 'owner')
+		FROM (SELECT role FROM organization_members WHERE organization_id = $1 FOR UPDATE) team`, orgID).
+		Scan(&owners)
+	if err != nil {
+		return nil, 0, err
+	}
+	member, err := scanMember(tx.QueryRow(ctx, `SELECT `+memberColumns+memberFrom+`
+		WHERE m.organization_id = $1 AND m.user_id = $2`, orgID, userID))
+	if err != nil {
+		return nil, 0, err
+	}
+	return member, owners, nil
+}
+
+func (s *PgOrganizationStore) SetRole(ctx context.Context, orgID, userID int, role models.OrgRole) (*models.Member, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	var updated models.Member
+	err := inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		before, owners, err := lockMember(ctx, tx, orgID, userID)
+		if err != nil {
+			return err
+		}
+		if before.Role == models.OrgOwner && role != models.OrgOwner && owners == 1 {
+			return ErrLastOwner
+		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE organization_members SET role = $1
+			WHERE organization_id = $2 AND user_id = $3`, role, orgID, userID); err != nil {
+			return err
+		}
+		updated = *before
+		updated.Role = role
+		return recordChange(ctx, tx, audit.MemberRoleChanged, audit.TargetOrganization, orgID, before, &updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *PgOrganizationStore) RemoveMember(ctx context.Context, orgID, userID int) error {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	return inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		member, owners, err := lockMember(ctx, tx, orgID, userID)
+		if err != nil {
+			return err
+		}
+		if member.Role == models.OrgOwner && owners == 1 {
+			return ErrLastOwner
+		}
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM organization_members
+			WHERE organization_id = $1 AND user_id = $2`, orgID, userID); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, audit.MemberRemoved, audit.TargetOrganization, orgID, member, map[string]any{})
+	})
+}
+
+func (s *PgOrganizationStore) Invite(ctx context.Context, inv *models.Invitation, tokenHash []byte) error {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	return inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		var member bool
+		err := tx.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1`+memberFrom+`WHERE m.organization_id = $1 AND LOWER(u.email) = LOWER($2))`,
+			inv.OrganizationID, inv.Email).Scan(&member)
+		if err != nil {
+			return err
+		}
+		if member {
+			return ErrAlreadyMember
+		}
+
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM organization_invitations
+			WHERE organization_id = $1 AND LOWER(email) = LOWER($2) AND accepted_at IS NULL`,
+			inv.OrganizationID, inv.Email); err != nil {
+			return err
+		}
+		created, err := scanInvitation(tx.QueryRow(ctx, `
+			INSERT INTO organization_invitations (organization_id, email, role, token_hash, invited_by, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING `+invitationColumns,
+			inv.OrganizationID, strings.TrimSpace(inv.Email), inv.Role, tokenHash, inv.InvitedBy, inv.ExpiresAt))
+		if err != nil {
+			return err
+		}
+		*inv = *created
+		return recordChange(ctx, tx, audit.InvitationCreated, audit.TargetOrganization, inv.OrganizationID, nil, inv)
+	})
+}
+
+func (s *PgOrganizationStore) ListInvitations(ctx context.Context, orgID int) ([]models.Invitation, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	rows, err := s.DB.Query(ctx, `
+		SELECT `+invitationColumns+` FROM organization_invitations
+		WHERE organization_id = $1 AND accepted_at IS NULL
+		ORDER BY created_at DESC, id DESC`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []models.Invitation{}
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, *inv)
+	}
+	return invitations, rows.Err()
+}
+
+func (s *PgOrganizationStore) RevokeInvitation(ctx context.Context, orgID, id int) error {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	return inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		revoked, err := scanInvitation(tx.QueryRow(ctx, `
+			DELETE FROM organization_invitations
+			WHERE id = $1 AND organization_id = $2 AND accepted_at IS NULL
+			RETURNING `+invitationColumns, id, orgID))
+		if err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, audit.InvitationRevoked, audit.TargetOrganization, orgID, revoked, map[string]any{})
+	})
+}
+
+func (s *PgOrganizationStore) AcceptInvitation(ctx context.Context, tokenHash []byte, userID int) (*models.Member, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	var member *models.Member
+	err := inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		inv, err := scanInvitation(tx.QueryRow(ctx, `
+			SELECT `+invitationColumns+` FROM organization_invitations
+			WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > NOW()
+			FOR UPDATE`, tokenHash))
+		if err != nil {
+			return err
+		}
+		var email string
+		if err := tx.QueryRow(ctx, `SELECT email FROM users WHERE id = $1`, userID).Scan(&email); err != nil {
+			return err
+		}
+		if !strings.EqualFold(email, inv.Email) {
+			return ErrInvitationEmail
+		}
+		if _, _, err := lockMember(ctx, tx, inv.OrganizationID, userID); err == nil {
+			return ErrAlreadyMember
+		} else if !errors.Is(err, ErrNotFound) {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE organization_invitations SET accepted_at = NOW() WHERE id = $1`, inv.ID); err != nil {
+			return err
+		}
+		member, err = addMember(ctx, tx, inv.OrganizationID, userID, inv.Role)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
@@ -0,0 +1,239 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/audit"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/booking"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/ledger"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgPaymentStore is the Postgres implementation of PaymentStore
+type PgPaymentStore struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewPgPaymentStore(db *pgxpool.Pool, queryTimeout time.Duration) *PgPaymentStore {
+	return &PgPaymentStore{DB: db, QueryTimeout: queryTimeout}
+}
+
+const paymentColumns = `id, booking_id, provider, provider_ref, amount, currency, status, failure_reason, created_at, updated_at`
+
+func scanPayment(row pgx.Row) (*models.Payment, error) {
+	var p models.Payment
+	err := row.Scan(&p.ID, &p.BookingID, &p.Provider, &p.ProviderRef, &p.Amount, &p.Currency,
+		&p.Status, &p.FailureReason, &p.CreatedAt, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Create inserts the pending payment and fills in its generated fields
+func (s *PgPaymentStore) Create(ctx context.Context, p *models.Payment) error {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	err := inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		created, err := scanPayment(tx.QueryRow(ctx, `
+			INSERT INTO payments (booking_id, provider, amount, currency)
+			VALUES ($1, $2, $3, $4)
+			RETURNING `+paymentColumns, p.BookingID, p.Provider, p.Amount, p.Currency))
+		if err != nil {
+			return err
+		}
+		*p = *created
+		return recordChange(ctx, tx, audit.PaymentCreated, audit.TargetPayment, p.ID, nil, p)
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_payments_live_booking" {
+		return ErrPaymentExists
+	}
+	return err
+}
+
+func (s *PgPaymentStore) GetByProviderRef(ctx context.Context, provider, ref string) (*models.Payment, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	return scanPayment(s.DB.QueryRow(ctx, `SELECT `+paymentColumns+` FROM payments WHERE provider = $1 AND provider_ref = $2`, provider, ref))
+}
+
+func (s *PgPaymentStore) Settle(ctx context.Context, id int, providerRef, status, reason string) (*models.Payment, bool, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	var updated *models.Payment
+	var refundDue bool
+	err := inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		before, err := scanPayment(tx.QueryRow(ctx, `SELECT `+paymentColumns+` FROM payments WHERE id = $1 FOR UPDATE`, id))
+		if err != nil {
+			return err
+		}
+		updated = before
+		if before.Status != "pending" {
+			// A redelivered webhook gets another go at a refund that failed
+			if before.Status == "succeeded" && before.BookingID != nil {
+				b, err := lockBooking(ctx, tx, `b.id = $1`, *before.BookingID)
+				if err != nil {
+					return err
+				}
+				refundDue = !booking.Active(b.Status) && b.Status != booking.Completed
+			}
+			return nil
+		}
+
+		updated, err = scanPayment(tx.QueryRow(ctx, `
+			UPDATE payments
+			SET provider_ref = COALESCE(NULLIF($2, ''), provider_ref), status = $3,
+			    failure_reason = NULLIF($4, ''), updated_at = NOW()
+			WHERE id = $1
+			RETURNING `+paymentColumns, id, providerRef, status, reason))
+		if err != nil {
+			return err
+		}
+		if updated.Status == before.Status {
+			return nil
+		}
+		if err := recordChange(ctx, tx, audit.PaymentStatusPrefix+status, audit.TargetPayment, id, before, updated); err != nil {
+			return err
+		}
+		if status != "succeeded" || updated.BookingID == nil {
+			return nil
+		}
+
+		// The booking may have ended while the charge was pending, when
+		// there was nothing yet to release or refund. The lock stops it
+		// ending between the check and the hold.
+		b, err := lockBooking(ctx, tx, `b.id = $1`, *updated.BookingID)
+		if err != nil {
+			return err
+		}
+		if err := postTransaction(ctx, tx, ledger.Hold(b.ID, id, updated.Provider, updated.Currency, updated.Amount)); err != nil {
+			return err
+		}
+		switch {
+		case b.Status == booking.Completed:
+			return releaseEscrow(ctx, tx, b)
+		case !booking.Active(b.Status):
+			refundDue = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return updated, refundDue, nil
+}
+
+func (s *PgPaymentStore) HeldPayment(ctx context.Context, bookingID int) (*models.Payment, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	return scanPayment(s.DB.QueryRow(ctx, `
+		SELECT `+paymentColumns+` FROM payments
+		WHERE booking_id = $1 AND status = 'succeeded'`, bookingID))
+}
+
+func (s *PgPaymentStore) Refund(ctx context.Context, id int, amount int64) (*models.Payment, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	var updated *models.Payment
+	err := inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		before, err := scanPayment(tx.QueryRow(ctx, `SELECT `+paymentColumns+` FROM payments WHERE id = $1 FOR UPDATE`, id))
+		if err != nil {
+			return err
+		}
+		if before.Status != "succeeded" || before.BookingID == nil {
+			return ErrNothingHeld
+		}
+		held, err := lockedBalance(ctx, tx, ledger.Escrow(*before.BookingID), before.Currency)
+		if err != nil {
+			return err
+		}
+		if amount <= 0 || held < amount {
+			return ErrNothingHeld
+		}
+		if err := postTransaction(ctx, tx, ledger.Refund(*before.BookingID, id, before.Provider, before.Currency, amount)); err != nil {
+			return err
+		}
+
+		updated, err = scanPayment(tx.QueryRow(ctx, `
+			UPDATE payments SET status = 'refunded', updated_at = NOW()
+			WHERE id = $1
+			RETURNING `+paymentColumns, id))
+		if err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, audit.PaymentStatusPrefix+"refunded", audit.TargetPayment, id, before, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *PgPaymentStore) Escrow(ctx context.Context, bookingID int) (*models.Escrow, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	escrow := &models.Escrow{BookingID: bookingID, Payments: []models.Payment{}}
+	var err error
+	if escrow.Held, err = balances(ctx, s.DB, ledger.Escrow(bookingID)); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.Query(ctx, `SELECT `+paymentColumns+` FROM payments WHERE booking_id = $1 ORDER BY created_at, id`, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		escrow.Payments = append(escrow.Payments, *p)
+	}
+	return escrow, rows.Err()
+}
+
+func (s *PgPaymentStore) Balances(ctx context.Context, account string) ([]models.Balance, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	return balances(ctx, s.DB, account)
+}
+
+func (s *PgPaymentStore) WebhookSeen(ctx context.Context, provider, eventID string) (bool, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	var seen bool
+	err := s.DB.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM payment_webhook_events WHERE provider = $1 AND event_id = $2)`,
+		provider, eventID).Scan(&seen)
+	return seen, err
+}
+
+func (s *PgPaymentStore) RecordWebhook(ctx context.Context, provider, eventID, eventType string, payload []byte) error {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	_, err := s.DB.Exec(ctx, `
+		INSERT INTO payment_webhook_events (provider, event_id, event_type, payload)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (provider, event_id) DO NOTHING`, provider, eventID, eventType, payload)
+	return err
+}
@@ -0,0 +1,273 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/booking"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+)
+
+var (
+	// ErrNotFound is returned when a lookup matches no row
+	ErrNotFound = errors.New("not found")
+	// ErrTooManyAttachments is returned when a job would end up with more
+	// attachments than allowed
+	ErrTooManyAttachments = errors.New("too many attachments")
+	// ErrScheduleConflict is returned when hiring a worker, or moving a job
+	// they were hired for, would book them for two overlapping jobs
+	ErrScheduleConflict = errors.New("schedule conflict")
+	// ErrPaymentExists is returned when a booking already has a pending or
+	// succeeded payment
+	ErrPaymentExists = errors.New("booking already has a payment")
+	// ErrNothingHeld is returned when refunding a booking with nothing in
+	// escrow
+	ErrNothingHeld = errors.New("nothing held in escrow")
+	// ErrLastOwner is returned when a change would leave an organization
+	// without an owner
+	ErrLastOwner = errors.New("organization needs an owner")
+	// ErrAlreadyMember is returned when inviting or adding someone who is
+	// already on the organization's team
+	ErrAlreadyMember = errors.New("already a member")
+	// ErrInvitationEmail is returned when accepting an invitation sent to
+	// a different email address
+	ErrInvitationEmail = errors.New("invitation is for another email address")
+	// ErrRoleHeld is returned when adding a role the user already has
+	ErrRoleHeld = errors.New("user already has the role")
+	// ErrRoleNotHeld is returned when switching to a role the user doesn't
+	// have
+	ErrRoleNotHeld = errors.New("user doesn't have the role")
+	// ErrNotInvited is returned when a worker answers an application that
+	// isn't an open invitation
+	ErrNotInvited = errors.New("application is not an open invitation")
+	// ErrAwaitingWorker is returned when an employer accepts an invitation
+	// the worker hasn't accepted, or changes one they declined
+	ErrAwaitingWorker = errors.New("invitation awaits the worker")
+	// ErrJobBooked is returned when deleting a job with a booking under
+	// way or money still in escrow or being charged for it
+	ErrJobBooked = errors.New("job has active bookings or payments")
+)
+
+// UserStore reads and writes users
+type UserStore interface {
+	Create(ctx context.Context, user *models.User) error
+	GetByID(ctx context.Context, id int) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	UpdateProfile(ctx context.Context, id int, update ProfileUpdate) (*models.User, error)
+	// SetAvatar replaces the avatar blob keys and returns the user after
+	// and before the change
+	SetAvatar(ctx context.Context, id int, key, thumbnailKey *string) (updated, before *models.User, err error)
+	// AddRole gives the user another role, failing with ErrRoleHeld if
+	// they have it. New employers get an organization of their own unless
+	// they already belong to one.
+	AddRole(ctx context.Context, id int, role models.Role) (*models.User, error)
+	// SetActiveRole switches the user to one of their roles, failing with
+	// ErrRoleNotHeld for any other
+	SetActiveRole(ctx context.Context, id int, role models.Role) (*models.User, error)
+	// GetProfile returns the user with a summary of each of their roles
+	GetProfile(ctx context.Context, id int) (*models.Profile, error)
+}
+
+// ProfileUpdate holds the user fields a profile edit may change
+type ProfileUpdate struct {
+	FullName string
+	Phone    string
+	Location string
+	Bio      string
+	// Both or neither
+	Latitude  *float64
+	Longitude *float64
+}
+
+// ReviewUpdate changes an application's review; nil fields are kept
+type ReviewUpdate struct {
+	Shortlisted *bool
+	Tags        []string
+	// An empty string clears the notes
+	Notes *string
+}
+
+// JobStore reads and writes job postings
+type JobStore interface {
+	// Create inserts the job, its screening questions and attachments,
+	// filling in their generated fields
+	Create(ctx context.Context, job *models.Job, questions []models.Question, attachments ...*models.JobAttachment) error
+	Get(ctx context.Context, id int) (*models.JobWithDetails, error)
+	// Update saves the job's editable fields and appends attachments; it
+	// fails with ErrTooManyAttachments if the job would have more than
+	// maxAttachments, and ErrScheduleConflict if a new time would
+	// double-book a worker hired for it
+	Update(ctx context.Context, job *models.Job, attachments []*models.JobAttachment, maxAttachments int) error
+	// Delete removes the job, its applications and attachments, and returns
+	// the attachments so their blobs can be deleted too. It fails with
+	// ErrJobBooked while a booking for the job is active or has money in
+	// escrow or a charge under way.
+	Delete(ctx context.Context, id int) ([]models.JobAttachment, error)
+	ListAttachments(ctx context.Context, jobID int) ([]models.JobAttachment, error)
+	DeleteAttachment(ctx context.Context, jobID, id int) (*models.JobAttachment, error)
+	// ListQuestions returns the job's screening questions in order, with
+	// their knockouts
+	ListQuestions(ctx context.Context, jobID int) ([]models.Question, error)
+	// ListOpen returns active, open, unexpired jobs matching filter
+	ListOpen(ctx context.Context, filter JobFilter) ([]models.JobWithDetails, error)
+	// CountOpenByEmployer counts the employer's jobs that ListOpen would show
+	CountOpenByEmployer(ctx context.Context, employerID int) (int, error)
+}
+
+// ApplicationStore reads and writes job applications
+type ApplicationStore interface {
+	// Create inserts the application and the worker's screening answers;
+	// with InvitedBy set it is an invitation, which starts out invited, and
+	// an application with a knocked out answer starts out rejected
+	Create(ctx context.Context, app *models.Application, answers ...models.Answer) error
+	Get(ctx context.Context, id int) (*models.Application, error)
+	// Respond accepts or declines one of the worker's invitations,
+	// storing the screening answers given when accepting; a knocked out
+	// answer rejects it. It fails with ErrNotFound if the application
+	// isn't theirs and ErrNotInvited if it isn't an open invitation.
+	Respond(ctx context.Context, id, workerID int, accept bool, answers ...models.Answer) (*models.Application, error)
+	ListByWorker(ctx context.Context, workerID int) ([]models.WorkerApplication, error)
+	// ListByJob returns the job's applicants, newest first, with each
+	// worker's track record, screening answers and the organization's
+	// review of them
+	ListByJob(ctx context.Context, jobID int) ([]models.JobApplicant, error)
+	// Review changes the organization's private review of an application
+	// on behalf of userID; ErrNotFound if there is no such application
+	Review(ctx context.Context, id int, update ReviewUpdate, userID int) (*models.ApplicationReview, error)
+	// UpdateStatus books the worker when accepting and cancels their
+	// scheduled booking otherwise. It fails with ErrScheduleConflict when
+	// the worker is booked for an overlapping job, a
+	// *booking.TransitionError when their booking has already started or
+	// they withdrew, and ErrAwaitingWorker for invitations the worker
+	// hasn't accepted.
+	UpdateStatus(ctx context.Context, id int, status string) (*models.Application, error)
+	// CountRecentByWorker counts applications the worker made within
+	// window, leaving out invitations, and how long until the oldest of
+	// them drops out of it
+	CountRecentByWorker(ctx context.Context, workerID int, window time.Duration) (count int, nextSlot time.Duration, err error)
+}
+
+// DocumentStore reads and writes user_documents
+type DocumentStore interface {
+	Create(ctx context.Context, doc *models.Document) error
+	ListByUser(ctx context.Context, userID int) ([]models.Document, error)
+	// Delete removes one of the user's documents and returns it so its
+	// blob can be deleted too; ErrNotFound if the user has no such document
+	Delete(ctx context.Context, id, userID int) (*models.Document, error)
+}
+
+// AvailabilityStore reads and writes workers' calendars
+type AvailabilityStore interface {
+	// Get returns the worker's weekly slots and exceptions that haven't
+	// ended yet
+	Get(ctx context.Context, workerID int) (*models.Availability, error)
+	ReplaceWeekly(ctx context.Context, workerID int, timezone string, slots []models.AvailabilitySlot) (*models.Availability, error)
+	AddException(ctx context.Context, e *models.AvailabilityException) error
+	DeleteException(ctx context.Context, id, workerID int) (*models.AvailabilityException, error)
+	// ListByWorkers returns each worker's calendar with the exceptions and
+	// active bookings (Booked) that overlap from-to
+	ListByWorkers(ctx context.Context, workerIDs []int, from, to time.Time) (map[int]*models.Availability, error)
+}
+
+// BookingStore reads bookings and moves them through their lifecycle
+type BookingStore interface {
+	Get(ctx context.Context, id int) (*models.Booking, error)
+	GetByApplication(ctx context.Context, applicationID int) (*models.Booking, error)
+	// ListByUser returns the bookings the user is the worker or employer
+	// on, or that are for jobs of an organization they belong to, newest
+	// first; an empty status means all
+	ListByUser(ctx context.Context, userID int, status string) ([]models.Booking, error)
+	// Apply locks the booking and records e on it with booking.Apply. A
+	// booking that completes adds a work_experience row for the worker in
+	// the same transaction, and one cancelled or missed moves its
+	// application from accepted to withdrawn, when the worker cancelled,
+	// or rejected.
+	Apply(ctx context.Context, id int, e booking.Event) (*models.Booking, error)
+}
+
+// PaymentStore records payments and posts the money they move to the
+// ledger
+type PaymentStore interface {
+	// Create inserts a pending payment; ErrPaymentExists if its booking
+	// already has a pending or succeeded one
+	Create(ctx context.Context, p *models.Payment) error
+	GetByProviderRef(ctx context.Context, provider, ref string) (*models.Payment, error)
+	// Settle records the provider's answer for a pending payment. One that
+	// succeeds is held in its booking's escrow in the same transaction, and
+	// paid to the worker if the booking has completed meanwhile. Payments
+	// that are no longer pending are returned unchanged. refundDue reports
+	// a succeeded payment whose booking was cancelled or missed, which the
+	// caller must refund through the provider.
+	Settle(ctx context.Context, id int, providerRef, status, reason string) (p *models.Payment, refundDue bool, err error)
+	// HeldPayment returns the booking's succeeded payment
+	HeldPayment(ctx context.Context, bookingID int) (*models.Payment, error)
+	// Refund moves amount out of the payment's escrow back to the provider
+	// and marks it refunded; ErrNothingHeld if escrow has less than that
+	Refund(ctx context.Context, id int, amount int64) (*models.Payment, error)
+	Escrow(ctx context.Context, bookingID int) (*models.Escrow, error)
+	// Balances sums a ledger account's entries in each currency
+	Balances(ctx context.Context, account string) ([]models.Balance, error)
+	WebhookSeen(ctx context.Context, provider, eventID string) (bool, error)
+	// RecordWebhook saves a handled webhook event so redeliveries can be
+	// skipped
+	RecordWebhook(ctx context.Context, provider, eventID, eventType string, payload []byte) error
+}
+
+// InvoiceStore reads the invoices issued when bookings complete
+type InvoiceStore interface {
+	Get(ctx context.Context, id int) (*models.Invoice, error)
+	// ListByUser returns the invoices issued in [from, to) where userID is
+	// the worker or the employer
+	ListByUser(ctx context.Context, userID int, from, to time.Time) ([]models.Invoice, error)
+}
+
+// OrganizationStore reads and writes organizations, their members and
+// invitations
+type OrganizationStore interface {
+	// Create inserts the organization with ownerID as its owner
+	Create(ctx context.Context, org *models.Organization, ownerID int) error
+	Get(ctx context.Context, id int) (*models.Organization, error)
+	Update(ctx context.Context, org *models.Organization) error
+	// ListByUser returns the organizations userID belongs to, with their
+	// role in each
+	ListByUser(ctx context.Context, userID int) ([]models.Membership, error)
+	// Role is userID's role in the organization; ErrNotFound if they
+	// aren't a member
+	Role(ctx context.Context, orgID, userID int) (models.OrgRole, error)
+	ListMembers(ctx context.Context, orgID int) ([]models.Member, error)
+	// SetRole changes a member's role and RemoveMember takes them off the
+	// team; both fail with ErrLastOwner rather than leave no owner
+	SetRole(ctx context.Context, orgID, userID int, role models.OrgRole) (*models.Member, error)
+	RemoveMember(ctx context.Context, orgID, userID int) error
+	// Invite saves an invitation, replacing any still open for the same
+	// address; ErrAlreadyMember if that address is on the team
+	Invite(ctx context.Context, inv *models.Invitation, tokenHash []byte) error
+	// ListInvitations returns the invitations not yet accepted
+	ListInvitations(ctx context.Context, orgID int) ([]models.Invitation, error)
+	RevokeInvitation(ctx context.Context, orgID, id int) error
+	// AcceptInvitation adds the user to the organization of the open,
+	// unexpired invitation with tokenHash. It fails with ErrNotFound if
+	// there is none, ErrInvitationEmail if it was sent to someone else and
+	// ErrAlreadyMember if they are on the team.
+	AcceptInvitation(ctx context.Context, tokenHash []byte, userID int) (*models.Member, error)
+}
+
+// WorkerStore reads the public worker directory and workers' skills
+type WorkerStore interface {
+	// List returns a page of workers matching filter, best rated first,
+	// and how many match in all
+	List(ctx context.Context, filter WorkerFilter) ([]models.WorkerCard, int, error)
+	// ReplaceSkills sets the worker's skills to skills; an unknown
+	// category fails with a foreign key violation
+	ReplaceSkills(ctx context.Context, workerID int, skills []models.SkillInput) ([]models.WorkerSkill, error)
+}
+
+// withTimeout bounds a single query by the store's deadline. The request
+// context still applies, so a disconnected client cancels the query too.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,296 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/audit"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgUserStore is the Postgres implementation of UserStore
+type PgUserStore struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewPgUserStore(db *pgxpool.Pool, queryTimeout time.Duration) *PgUserStore {
+	return &PgUserStore{DB: db, QueryTimeout: queryTimeout}
+}
+
+const userColumns = `id, email, password_hash, full_name, user_type, phone, location, bio, avatar_url, created_at,
+	avatar_key, avatar_thumbnail_key, latitude, longitude,
+	ARRAY(SELECT r.role FROM user_roles r WHERE r.user_id = users.id ORDER BY r.role)`
+
+func scanUser(row pgx.Row) (*models.User, error) {
+	var u models.User
+	var roles []string
+	err := row.Scan(
+		&u.ID, &u.Email, &u.PasswordHash, &u.FullName, &u.UserType,
+		&u.Phone, &u.Location, &u.Bio, &u.AvatarURL, &u.CreatedAt,
+		&u.AvatarKey, &u.AvatarThumbnailKey, &u.Latitude, &u.Longitude, &roles,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	u.Roles = make([]models.Role, len(roles))
+	for i, role := range roles {
+		u.Roles[i] = models.Role(role)
+	}
+	return &u, nil
+}
+
+// Create inserts the user and fills in its generated fields
+func (s *PgUserStore) Create(ctx context.Context, user *models.User) error {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	query := `
+		INSERT INTO users (email, password_hash, full_name, user_type, phone, location)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + userColumns
+
+	return inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		created, err := scanUser(tx.QueryRow(ctx, query,
+			user.Email, user.PasswordHash, user.FullName, user.UserType, user.Phone, user.Location,
+		))
+		if err != nil {
+			return err
+		}
+		// users.user_type must be one of the user's roles; the check is
+		// deferred to the end of the transaction
+		if _, err := tx.Exec(ctx, `INSERT INTO user_roles (user_id, role) VALUES ($1, $2)`, created.ID, created.UserType); err != nil {
+			return err
+		}
+		created.Roles = []models.Role{created.UserType}
+		*user = *created
+
+		// Signing up is done by the new user themselves
+		if audit.FromContext(ctx).ActorID == 0 {
+			ctx = audit.WithActor(ctx, created.ID)
+		}
+		if err := recordChange(ctx, tx, audit.UserRegistered, audit.TargetUser, created.ID, nil, created); err != nil {
+			return err
+		}
+		// Employers post jobs as an organization; they start with one of
+		// their own and can invite a team to it
+		if created.UserType == models.RoleEmployer {
+			return createOrganization(ctx, tx, &models.Organization{Name: created.FullName}, created.ID)
+		}
+		return nil
+	})
+}
+
+func (s *PgUserStore) GetByID(ctx context.Context, id int) (*models.User, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	return scanUser(s.DB.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id))
+}
+
+func (s *PgUserStore) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	return scanUser(s.DB.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE email = $1`, email))
+}
+
+func (s *PgUserStore) UpdateProfile(ctx context.Context, id int, update ProfileUpdate) (*models.User, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET full_name = $1, phone = $2, location = $3, bio = $4, latitude = $5, longitude = $6
+		WHERE id = $7
+		RETURNING ` + userColumns
+
+	var updated *models.User
+	err := inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		before, err := scanUser(tx.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1 FOR UPDATE`, id))
+		if err != nil {
+			return err
+		}
+		updated, err = scanUser(tx.QueryRow(ctx, query,
+			update.FullName, update.Phone, update.Location, update.Bio, update.Latitude, update.Longitude, id,
+		))
+		if err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, audit.ProfileUpdated, audit.TargetUser, id, before, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// SetAvatar points the user at new avatar blobs (nil clears them) and
+// returns the user before and after, so the caller can delete the old blobs
+func (s *PgUserStore) SetAvatar(ctx context.Context, id int, key, thumbnailKey *string) (updated, before *models.User, err error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET avatar_key = $1, avatar_thumbnail_key = $2
+		WHERE id = $3
+		RETURNING ` + userColumns
+
+	err = inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		before, err = scanUser(tx.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1 FOR UPDATE`, id))
+		if err != nil {
+			return err
+		}
+		updated, err = scanUser(tx.QueryRow(ctx, query, key, thumbnailKey, id))
+		if err != nil {
+			return err
+		}
+		// The keys are hidden from JSON, so diff them explicitly
+		return recordChange(ctx, tx, audit.AvatarUpdated, audit.TargetUser, id,
+			map[string]any{"avatar_key": before.AvatarKey},
+			map[string]any{"avatar_key": updated.AvatarKey},
+		)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return updated, before, nil
+}
+
+func (s *PgUserStore) AddRole(ctx context.Context, id int, role models.Role) (*models.User, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	var updated *models.User
+	err := inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		before, err := scanUser(tx.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1 FOR UPDATE`, id))
+		if err != nil {
+			return err
+		}
+		if before.HasRole(role) {
+			return ErrRoleHeld
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO user_roles (user_id, role) VALUES ($1, $2)`, id, role); err != nil {
+			return err
+		}
+		updated, err = scanUser(tx.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id))
+		if err != nil {
+			return err
+		}
+		if err := recordChange(ctx, tx, audit.RoleAdded, audit.TargetUser, id,
+			map[string]any{"roles": before.Roles}, map[string]any{"roles": updated.Roles},
+		); err != nil {
+			return err
+		}
+
+		// Like signing up as an employer, unless they were invited to a
+		// team before
+		if role != models.RoleEmployer {
+			return nil
+		}
+		var member bool
+		err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM organization_members WHERE user_id = $1)`, id).Scan(&member)
+		if err != nil || member {
+			return err
+		}
+		return createOrganization(ctx, tx, &models.Organization{Name: updated.FullName}, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *PgUserStore) SetActiveRole(ctx context.Context, id int, role models.Role) (*models.User, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	var updated *models.User
+	err := inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		before, err := scanUser(tx.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1 FOR UPDATE`, id))
+		if err != nil {
+			return err
+		}
+		if !before.HasRole(role) {
+			return ErrRoleNotHeld
+		}
+		updated, err = scanUser(tx.QueryRow(ctx, `UPDATE users SET user_type = $1 WHERE id = $2 RETURNING `+userColumns, role, id))
+		if err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, audit.ActiveRoleSwitched, audit.TargetUser, id, before, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *PgUserStore) GetProfile(ctx context.Context, id int) (*models.Profile, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	user, err := scanUser(s.DB.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id))
+	if err != nil {
+		return nil, err
+	}
+	profile := &models.Profile{User: *user}
+
+	if user.HasRole(models.RoleWorker) {
+		skills, err := listSkills(ctx, s.DB, []int{id})
+		if err != nil {
+			return nil, err
+		}
+		worker := &models.WorkerFacet{Skills: skills[id]}
+		if worker.Skills == nil {
+			worker.Skills = []models.WorkerSkill{}
+		}
+		err = s.DB.QueryRow(ctx,
+			`SELECT COUNT(*) FROM bookings WHERE worker_id = $1 AND status = 'completed'`, id,
+		).Scan(&worker.CompletedJobs)
+		if err != nil {
+			return nil, err
+		}
+		profile.Worker = worker
+	}
+
+	if user.HasRole(models.RoleEmployer) {
+		employer := &models.EmployerFacet{Organizations: []models.Organization{}}
+		rows, err := s.DB.Query(ctx, `
+			SELECT `+organizationColumns+`
+			FROM organizations o
+			JOIN organization_members m ON m.organization_id = o.id
+			WHERE m.user_id = $1
+			ORDER BY o.name, o.id`, id)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			org, err := scanOrganization(rows)
+			if err != nil {
+				return nil, err
+			}
+			employer.Organizations = append(employer.Organizations, *org)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		err = s.DB.QueryRow(ctx, `
+			SELECT (SELECT COUNT(*) FROM jobs WHERE employer_id = $1),
+			       (SELECT COUNT(*) FROM bookings WHERE employer_id = $1)`, id,
+		).Scan(&employer.JobsPosted, &employer.Hires)
+		if err != nil {
+			return nil, err
+		}
+		profile.Employer = employer
+	}
+	return profile, nil
+}
@@ -0,0 +1,220 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/audit"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgWorkerStore is the Postgres implementation of WorkerStore
+type PgWorkerStore struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewPgWorkerStore(db *pgxpool.Pool, queryTimeout time.Duration) *PgWorkerStore {
+	return &PgWorkerStore{DB: db, QueryTimeout: queryTimeout}
+}
+
+// WorkerFilter narrows the directory; zero fields match everything
+type WorkerFilter struct {
+	// Only workers with this skill, with at least MinExperience years in it
+	CategoryID    int
+	MinExperience int
+	// Substring of the worker's location, ignoring case
+	Location  string
+	MinRating float64
+	// When set, leaves out workers who can't be free for the whole window:
+	// those booked or off during it, and those with neither weekly slots
+	// nor extra hours in it. Whether the slots cover it is left to the
+	// caller.
+	AvailableFrom, AvailableTo time.Time
+	// Limit 0 returns every match
+	Limit, Offset int
+}
+
+// workerRatings averages the ratings each worker got on their bookings
+const workerRatings = `
+	WITH ratings AS (
+		SELECT worker_id, AVG(worker_rating)::float8 AS rating, COUNT(*) AS rating_count
+		FROM bookings
+		WHERE worker_rating IS NOT NULL
+		GROUP BY worker_id
+	)`
+
+func (s *PgWorkerStore) List(ctx context.Context, filter WorkerFilter) ([]models.WorkerCard, int, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	where := []string{`EXISTS (SELECT 1 FROM user_roles ur WHERE ur.user_id = u.id AND ur.role = 'worker')`}
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if filter.CategoryID != 0 {
+		args = append(args, filter.CategoryID, filter.MinExperience)
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM worker_skills s
+			WHERE s.worker_id = u.id AND s.category_id = $%d AND COALESCE(s.experience_years, 0) >= $%d)`,
+			len(args)-1, len(args)))
+	}
+	if filter.Location != "" {
+		add(`u.location ILIKE '%%' || $%d || '%%'`, escapeLike(filter.Location))
+	}
+	if filter.MinRating != 0 {
+		add("r.rating >= $%d", filter.MinRating)
+	}
+	if !filter.AvailableFrom.IsZero() {
+		args = append(args, filter.AvailableFrom, filter.AvailableTo)
+		// The calendar itself is checked in Go; this leaves out whoever
+		// can't pass, so fewer calendars are loaded
+		where = append(where, fmt.Sprintf(`NOT EXISTS (
+			SELECT 1 FROM bookings b JOIN jobs j ON j.id = b.job_id
+			WHERE b.worker_id = u.id AND `+activeBooking+`
+			  AND j.starts_at < $%[2]d AND j.ends_at > $%[1]d)
+		AND NOT EXISTS (
+			SELECT 1 FROM worker_availability_exceptions e
+			WHERE e.worker_id = u.id AND NOT e.available
+			  AND e.starts_at < $%[2]d AND e.ends_at > $%[1]d)
+		AND (EXISTS (SELECT 1 FROM worker_availability a WHERE a.worker_id = u.id)
+			OR EXISTS (
+				SELECT 1 FROM worker_availability_exceptions e
+				WHERE e.worker_id = u.id AND e.available
+				  AND e.starts_at < $%[2]d AND e.ends_at > $%[1]d))`, len(args)-1, len(args)))
+	}
+	from := `
+		FROM users u
+		LEFT JOIN ratings r ON r.worker_id = u.id
+		WHERE ` + strings.Join(where, " AND ")
+
+	var total int
+	if err := s.DB.QueryRow(ctx, workerRatings+` SELECT COUNT(*)`+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// LIMIT NULL is no limit
+	var limit any
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+	args = append(args, limit, filter.Offset)
+	query := workerRatings + `
+		SELECT u.id, u.full_name, u.location, u.bio, u.avatar_thumbnail_key,
+		       r.rating, COALESCE(r.rating_count, 0),
+		       (SELECT COUNT(*) FROM bookings b WHERE b.worker_id = u.id AND b.status = 'completed')` + from +
+		fmt.Sprintf(` ORDER BY r.rating DESC NULLS LAST, r.rating_count DESC NULLS LAST, u.id LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+
+	rows, err := s.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	workers := []models.WorkerCard{}
+	var ids []int
+	for rows.Next() {
+		var w models.WorkerCard
+		if err := rows.Scan(
+			&w.ID, &w.FullName, &w.Location, &w.Bio, &w.AvatarThumbnailKey,
+			&w.Rating, &w.RatingCount, &w.CompletedJobs,
+		); err != nil {
+			return nil, 0, err
+		}
+		workers = append(workers, w)
+		ids = append(ids, w.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	skills, err := listSkills(ctx, s.DB, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range workers {
+		workers[i].Skills = skills[workers[i].ID]
+		if workers[i].Skills == nil {
+			workers[i].Skills = []models.WorkerSkill{}
+		}
+	}
+	return workers, total, nil
+}
+
+func (s *PgWorkerStore) ReplaceSkills(ctx context.Context, workerID int, skills []models.SkillInput) ([]models.WorkerSkill, error) {
+	ctx, cancel := withTimeout(ctx, s.QueryTimeout)
+	defer cancel()
+
+	var after []models.WorkerSkill
+	err := inTx(ctx, s.DB, func(tx pgx.Tx) error {
+		before, err := listSkills(ctx, tx, []int{workerID})
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM worker_skills WHERE worker_id = $1`, workerID); err != nil {
+			return err
+		}
+		for _, skill := range skills {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO worker_skills (worker_id, category_id, experience_years)
+				VALUES ($1, $2, $3)
+				ON CONFLICT (worker_id, category_id) DO UPDATE SET experience_years = EXCLUDED.experience_years`,
+				workerID, skill.CategoryID, skill.ExperienceYears)
+			if err != nil {
+				return err
+			}
+		}
+		updated, err := listSkills(ctx, tx, []int{workerID})
+		if err != nil {
+			return err
+		}
+		after = updated[workerID]
+		if after == nil {
+			after = []models.WorkerSkill{}
+		}
+		return recordChange(ctx, tx, audit.SkillsUpdated, audit.TargetUser, workerID,
+			map[string]any{"skills": before[workerID]}, map[string]any{"skills": after})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// listSkills returns the skills of each of workerIDs, by category name
+func listSkills(ctx context.Context, db querier, workerIDs []int) (map[int][]models.WorkerSkill, error) {
+	skills := map[int][]models.WorkerSkill{}
+	if len(workerIDs) == 0 {
+		return skills, nil
+	}
+	rows, err := db.Query(ctx, `
+		SELECT s.worker_id, s.category_id, c.name, COALESCE(s.experience_years, 0)
+		FROM worker_skills s
+		JOIN categories c ON c.id = s.category_id
+		WHERE s.worker_id = ANY($1)
+		ORDER BY c.name`, workerIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var workerID int
+		var skill models.WorkerSkill
+		if err := rows.Scan(&workerID, &skill.CategoryID, &skill.CategoryName, &skill.ExperienceYears); err != nil {
+			return nil, err
+		}
+		skills[workerID] = append(skills[workerID], skill)
+	}
+	return skills, rows.Err()
+}
+
+// escapeLike quotes the LIKE wildcards in s so it matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,20 @@
+package testutil
+
+import (
+	"time"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/auth"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/config"
+)
+
+const testJWTSecret = "test-secret-not-for-production-use"
+
+// Tokens signs the tokens Token returns; pass it to server.Deps
+var Tokens = auth.NewManager(testJWTSecret, time.Hour)
+
+// Config is the configuration tests build routers with
+func Config() config.Config {
+	cfg := config.Default()
+	cfg.Auth.JWTSecret = testJWTSecret
+	return cfg
+}
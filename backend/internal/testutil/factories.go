@@ -0,0 +1,130 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/models"
+	"github.com/Sabari-Vijayan/DBMS-project/internal/store"
+)
+
+// Password is the plain-text password of every user the factory creates
+const Password = "password123"
+
+var (
+	sequence     atomic.Int64
+	passwordHash = sync.OnceValue(func() []byte {
+		hash, err := bcrypt.GenerateFromPassword([]byte(Password), bcrypt.MinCost)
+		if err != nil {
+			panic(err)
+		}
+		return hash
+	})
+)
+
+// Factory inserts fixture rows through the real stores, so fixtures go
+// through the same SQL the handlers use
+type Factory struct {
+	Users         *store.PgUserStore
+	Jobs          *store.PgJobStore
+	Applications  *store.PgApplicationStore
+	Organizations *store.PgOrganizationStore
+}
+
+func NewFactory(pool *pgxpool.Pool) *Factory {
+	return &Factory{
+		Users:         store.NewPgUserStore(pool, 0),
+		Jobs:          store.NewPgJobStore(pool, 0),
+		Applications:  store.NewPgApplicationStore(pool, 0),
+		Organizations: store.NewPgOrganizationStore(pool, 0),
+	}
+}
+
+// User creates a user with a unique email; options can override any field
+func (f *Factory) User(t testing.TB, role models.Role, opts ...func(*models.User)) *models.User {
+	t.Helper()
+	n := sequence.Add(1)
+	user := &models.User{
+		Email:        fmt.Sprintf("%s%d@example.com", role, n),
+		PasswordHash: string(passwordHash()),
+		FullName:     fmt.Sprintf("Test %s %d", role, n),
+		UserType:     role,
+	}
+	for _, opt := range opts {
+		opt(user)
+	}
+	if err := f.Users.Create(context.Background(), user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+func (f *Factory) Worker(t testing.TB, opts ...func(*models.User)) *models.User {
+	t.Helper()
+	return f.User(t, models.RoleWorker, opts...)
+}
+
+func (f *Factory) Employer(t testing.TB, opts ...func(*models.User)) *models.User {
+	t.Helper()
+	return f.User(t, models.RoleEmployer, opts...)
+}
+
+// Job creates an open job that expires in three days, in the employer's
+// first organization unless an option sets one
+func (f *Factory) Job(t testing.TB, employerID int, opts ...func(*models.Job)) *models.Job {
+	t.Helper()
+	n := sequence.Add(1)
+	job := &models.Job{
+		EmployerID:  employerID,
+		Title:       fmt.Sprintf("Test job %d", n),
+		Description: "Fixture job",
+		Location:    "Testville",
+		PayType:     models.PayFixed,
+		Currency:    "INR",
+		ExpiresAt:   time.Now().AddDate(0, 0, 3),
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+	if job.OrganizationID == 0 {
+		orgs, err := f.Organizations.ListByUser(context.Background(), employerID)
+		if err != nil || len(orgs) == 0 {
+			t.Fatalf("find organization of employer %d: %v", employerID, err)
+		}
+		job.OrganizationID = orgs[0].ID
+	}
+	if err := f.Jobs.Create(context.Background(), job, nil); err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	return job
+}
+
+// Application creates a pending application
+func (f *Factory) Application(t testing.TB, jobID, workerID int, opts ...func(*models.Application)) *models.Application {
+	t.Helper()
+	app := &models.Application{JobID: jobID, WorkerID: workerID}
+	for _, opt := range opts {
+		opt(app)
+	}
+	if err := f.Applications.Create(context.Background(), app); err != nil {
+		t.Fatalf("create application: %v", err)
+	}
+	return app
+}
+
+// Token returns a bearer token for the user
+func Token(t testing.TB, user *models.User) string {
+	t.Helper()
+	token, err := Tokens.GenerateToken(user.ID, user.Email, user.UserType, user.Roles)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	return token
+}
@@ -0,0 +1,260 @@
+// Package testutil starts a throwaway Postgres for integration tests and
+// provides factories for the rows those tests need.
+//
+// The database comes from, in order:
+//
+//  1. TEST_DATABASE_URL: an existing server; a fresh database is created
+//     on it for the run and dropped afterwards
+//  2. initdb/pg_ctl from PG_BIN or PATH: a temporary cluster
+//  3. embedded-postgres: downloads and caches Postgres binaries
+//
+// If none of them work, tests that need the database are skipped.
+package testutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Sabari-Vijayan/DBMS-project/internal/db"
+	"github.com/Sabari-Vijayan/DBMS-project/migrations"
+)
+
+// Database is a migrated, disposable Postgres database
+type Database struct {
+	URL  string
+	Pool *pgxpool.Pool
+	stop func() error
+}
+
+var (
+	shared    *Database
+	sharedErr error
+	startOnce sync.Once
+)
+
+// DB returns the package-wide test database, starting it on first use.
+// The test is skipped when no Postgres can be started.
+func DB(t testing.TB) *Database {
+	t.Helper()
+	startOnce.Do(func() {
+		shared, sharedErr = Start(context.Background())
+	})
+	if sharedErr != nil {
+		t.Skipf("integration database unavailable: %v", sharedErr)
+	}
+	return shared
+}
+
+// Stop shuts down the database started by DB; call it from TestMain
+func Stop() {
+	if shared != nil {
+		shared.Close()
+	}
+}
+
+// Start creates and migrates a new database
+func Start(ctx context.Context) (*Database, error) {
+	serverURL, stop, err := startServer()
+	if err != nil {
+		return nil, err
+	}
+
+	dbURL, dropDB, err := createDatabase(ctx, serverURL)
+	if err != nil {
+		stop()
+		return nil, err
+	}
+
+	pool, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		dropDB()
+		stop()
+		return nil, err
+	}
+
+	database := &Database{
+		URL:  dbURL,
+		Pool: pool,
+		stop: func() error {
+			pool.Close()
+			return errors.Join(dropDB(), stop())
+		},
+	}
+
+	if err := db.Migrate(ctx, pool, migrations.FS); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+	if err := db.EnsureRoles(ctx, pool); err != nil {
+		database.Close()
+		return nil, err
+	}
+	return database, nil
+}
+
+func (d *Database) Close() error {
+	return d.stop()
+}
+
+// Reset empties every application table so each test starts clean.
+// Seed data from migrations (categories, roles) is kept.
+func (d *Database) Reset(t testing.TB) {
+	t.Helper()
+	_, err := d.Pool.Exec(context.Background(),
+		`TRUNCATE users, jobs, applications, worker_skills, work_experience, rate_limit_buckets, audit_events, user_documents, job_attachments, worker_availability, worker_availability_exceptions, bookings, payments, ledger_accounts, ledger_transactions, ledger_entries, payment_webhook_events, invoice_counters, invoices, organizations, organization_members, organization_invitations, user_roles RESTART IDENTITY CASCADE`)
+	if err != nil {
+		t.Fatalf("reset database: %v", err)
+	}
+}
+
+// startServer finds or starts a Postgres server and returns a URL for its
+// maintenance database
+func startServer() (string, func() error, error) {
+	if url := os.Getenv("TEST_DATABASE_URL"); url != "" {
+		return url, func() error { return nil }, nil
+	}
+
+	if url, stop, err := startLocalCluster(); err == nil {
+		return url, stop, nil
+	} else if !errors.Is(err, exec.ErrNotFound) {
+		return "", nil, err
+	}
+
+	return startEmbedded()
+}
+
+// startLocalCluster runs initdb and pg_ctl in a temporary directory
+func startLocalCluster() (string, func() error, error) {
+	initdb, err := pgBinary("initdb")
+	if err != nil {
+		return "", nil, err
+	}
+	pgCtl, err := pgBinary("pg_ctl")
+	if err != nil {
+		return "", nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "jobseeker-pg-")
+	if err != nil {
+		return "", nil, err
+	}
+	dataDir := filepath.Join(dir, "data")
+
+	out, err := exec.Command(initdb, "-D", dataDir, "-U", "postgres", "-A", "trust", "--no-sync").CombinedOutput()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("initdb: %v: %s", err, out)
+	}
+
+	port, err := freePort()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", nil, err
+	}
+
+	opts := fmt.Sprintf("-p %d -k %s -c listen_addresses=localhost -c fsync=off", port, dir)
+	out, err = exec.Command(pgCtl, "-D", dataDir, "-o", opts, "-l", filepath.Join(dir, "log"), "-w", "start").CombinedOutput()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("pg_ctl start: %v: %s", err, out)
+	}
+
+	stop := func() error {
+		out, err := exec.Command(pgCtl, "-D", dataDir, "-m", "immediate", "stop").CombinedOutput()
+		os.RemoveAll(dir)
+		if err != nil {
+			return fmt.Errorf("pg_ctl stop: %v: %s", err, out)
+		}
+		return nil
+	}
+	return fmt.Sprintf("postgres://postgres@localhost:%d/postgres?sslmode=disable", port), stop, nil
+}
+
+func pgBinary(name string) (string, error) {
+	if dir := os.Getenv("PG_BIN"); dir != "" {
+		return exec.LookPath(filepath.Join(dir, name))
+	}
+	return exec.LookPath(name)
+}
+
+func startEmbedded() (string, func() error, error) {
+	port, err := freePort()
+	if err != nil {
+		return "", nil, err
+	}
+	dir, err := os.MkdirTemp("", "jobseeker-embedded-pg-")
+	if err != nil {
+		return "", nil, err
+	}
+
+	config := embeddedpostgres.DefaultConfig().
+		Port(uint32(port)).
+		RuntimePath(dir).
+		Logger(nil)
+	pg := embeddedpostgres.NewDatabase(config)
+	if err := pg.Start(); err != nil {
+		os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("embedded postgres: %w", err)
+	}
+
+	stop := func() error {
+		err := pg.Stop()
+		os.RemoveAll(dir)
+		return err
+	}
+	return config.GetConnectionURL() + "?sslmode=disable", stop, nil
+}
+
+// createDatabase makes a uniquely named database so runs never collide,
+// even against a shared TEST_DATABASE_URL server
+func createDatabase(ctx context.Context, serverURL string) (string, func() error, error) {
+	conn, err := pgx.Connect(ctx, serverURL)
+	if err != nil {
+		return "", nil, err
+	}
+	defer conn.Close(ctx)
+
+	name := fmt.Sprintf("jobseeker_test_%d", rand.Uint32())
+	if _, err := conn.Exec(ctx, "CREATE DATABASE "+name); err != nil {
+		return "", nil, err
+	}
+
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return "", nil, err
+	}
+	u.Path = "/" + name
+
+	drop := func() error {
+		conn, err := pgx.Connect(context.Background(), serverURL)
+		if err != nil {
+			return err
+		}
+		defer conn.Close(context.Background())
+		_, err = conn.Exec(context.Background(), "DROP DATABASE IF EXISTS "+name+" WITH (FORCE)")
+		return err
+	}
+	return u.String(), drop, nil
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,20 @@
+-- Roles are the single source of truth for users.user_type.
+-- 001 constrained user_type to handyman/customer/shopkeeper while the API
+-- has always used worker/employer, so every registration was rejected.
+CREATE TABLE roles (
+    name VARCHAR(20) PRIMARY KEY,
+    description TEXT
+);
+
+INSERT INTO roles (name, description) VALUES
+('worker', 'Finds and applies to jobs'),
+('employer', 'Posts jobs and hires workers');
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_user_type_check;
+
+-- Map any rows created under the old constraint
+UPDATE users SET user_type = 'worker' WHERE user_type = 'handyman';
+UPDATE users SET user_type = 'employer' WHERE user_type IN ('customer', 'shopkeeper');
+
+ALTER TABLE users
+    ADD CONSTRAINT users_user_type_fkey FOREIGN KEY (user_type) REFERENCES roles(name);
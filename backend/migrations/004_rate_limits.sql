@@ -0,0 +1,13 @@
+-- Token buckets shared by every API instance (RATE_LIMIT_STORE=postgres)
+CREATE TABLE rate_limit_buckets (
+    key VARCHAR(255) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    -- Whether the last take succeeded; lets one upsert report its outcome
+    allowed BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_rate_limit_buckets_updated ON rate_limit_buckets(updated_at);
+
+-- Quota checks count a worker's recent applications
+CREATE INDEX idx_applications_worker_applied ON applications(worker_id, applied_at);
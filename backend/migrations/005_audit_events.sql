@@ -0,0 +1,25 @@
+-- Who did what to which row, written in the same transaction as the change
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    -- NULL for anonymous actions such as a failed login
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(32) NOT NULL,
+    target_id INTEGER,
+    -- Only the fields that changed; before is NULL for creations
+    before JSONB,
+    after JSONB,
+    ip VARCHAR(64),
+    user_agent TEXT,
+    request_id VARCHAR(128),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_events_created ON audit_events(created_at DESC);
+CREATE INDEX idx_audit_events_actor ON audit_events(actor_id, created_at DESC);
+CREATE INDEX idx_audit_events_target ON audit_events(target_type, target_id, created_at DESC);
+CREATE INDEX idx_audit_events_action ON audit_events(action, created_at DESC);
+
+INSERT INTO roles (name, description) VALUES
+('admin', 'Operates the site; reads the audit log')
+ON CONFLICT (name) DO NOTHING;
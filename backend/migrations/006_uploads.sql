@@ -0,0 +1,20 @@
+-- Blob keys of an uploaded avatar. avatar_url stays for links set by hand;
+-- an uploaded avatar takes precedence and is served by signed URL.
+ALTER TABLE users
+    ADD COLUMN avatar_key VARCHAR(255),
+    ADD COLUMN avatar_thumbnail_key VARCHAR(255);
+
+-- ID proofs, certificates etc. uploaded by users
+CREATE TABLE user_documents (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('id_proof', 'certificate', 'other')),
+    -- As sent by the client, for display only
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    blob_key VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_documents_user ON user_documents(user_id, created_at DESC);
@@ -0,0 +1,43 @@
+-- When the work happens. Both are set or neither; jobs without a schedule
+-- never conflict with anything.
+ALTER TABLE jobs
+    ADD COLUMN starts_at TIMESTAMPTZ,
+    ADD COLUMN ends_at TIMESTAMPTZ,
+    ADD CONSTRAINT jobs_schedule_check CHECK (
+        (starts_at IS NULL AND ends_at IS NULL)
+        OR (starts_at IS NOT NULL AND ends_at > starts_at)
+    );
+
+CREATE INDEX idx_jobs_schedule ON jobs(starts_at, ends_at) WHERE starts_at IS NOT NULL;
+
+-- IANA zone a worker's weekly slots are in, e.g. Asia/Kolkata
+ALTER TABLE users ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- Recurring weekly slots a worker is free in, in their time zone
+CREATE TABLE worker_availability (
+    id SERIAL PRIMARY KEY,
+    worker_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- 0 is Sunday, as in Go's time.Weekday and Postgres's EXTRACT(DOW)
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    start_time TIME NOT NULL,
+    -- May be 24:00 for a slot running to midnight
+    end_time TIME NOT NULL,
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX idx_worker_availability_worker ON worker_availability(worker_id, weekday);
+
+-- One-off changes to the weekly slots: time off, or extra hours
+CREATE TABLE worker_availability_exceptions (
+    id SERIAL PRIMARY KEY,
+    worker_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    -- false blocks the time out, true adds it
+    available BOOLEAN NOT NULL,
+    note VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_worker_availability_exceptions_worker ON worker_availability_exceptions(worker_id, ends_at);
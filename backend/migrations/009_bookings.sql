@@ -0,0 +1,39 @@
+-- The engagement created when an employer accepts an application. It moves
+-- scheduled -> in_progress -> completed, or ends cancelled or no_show.
+CREATE TABLE bookings (
+    id SERIAL PRIMARY KEY,
+    -- Rejecting and re-accepting an application reuses its booking
+    application_id INTEGER NOT NULL UNIQUE REFERENCES applications(id) ON DELETE CASCADE,
+    job_id INTEGER NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    worker_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    employer_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled'
+        CHECK (status IN ('scheduled', 'in_progress', 'completed', 'cancelled', 'no_show')),
+    worker_checked_in_at TIMESTAMPTZ,
+    worker_checked_out_at TIMESTAMPTZ,
+    employer_checked_in_at TIMESTAMPTZ,
+    employer_checked_out_at TIMESTAMPTZ,
+    -- Both sides confirm before a booking completes
+    worker_confirmed_at TIMESTAMPTZ,
+    employer_confirmed_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    cancelled_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    cancel_reason VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_bookings_worker ON bookings(worker_id, status);
+CREATE INDEX idx_bookings_employer ON bookings(employer_id, status);
+CREATE INDEX idx_bookings_job ON bookings(job_id);
+
+-- Experience written when a booking completes; kept if the job is deleted
+ALTER TABLE work_experience
+    ADD COLUMN booking_id INTEGER UNIQUE REFERENCES bookings(id) ON DELETE SET NULL;
+
+-- Applications accepted before bookings existed
+INSERT INTO bookings (application_id, job_id, worker_id, employer_id)
+SELECT a.id, a.job_id, a.worker_id, j.employer_id
+FROM applications a
+JOIN jobs j ON j.id = a.job_id
+WHERE a.status = 'accepted';
@@ -0,0 +1,94 @@
+-- Double-entry ledger. Balances are always SUM(amount) over an account's
+-- entries; no table stores a running total.
+CREATE TABLE ledger_accounts (
+    id SERIAL PRIMARY KEY,
+    -- e.g. escrow:booking:12, worker:7, external:fake
+    code VARCHAR(100) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (code, currency)
+);
+
+-- Charges made through the payment provider to fund escrow
+CREATE TABLE payments (
+    id SERIAL PRIMARY KEY,
+    booking_id INTEGER REFERENCES bookings(id) ON DELETE SET NULL,
+    provider VARCHAR(50) NOT NULL,
+    -- The provider's charge ID, once it has one
+    provider_ref VARCHAR(255),
+    -- Minor units, e.g. paise
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'succeeded', 'failed', 'refunded')),
+    failure_reason VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, provider_ref)
+);
+
+-- At most one live charge per booking
+CREATE UNIQUE INDEX idx_payments_live_booking ON payments(booking_id) WHERE status IN ('pending', 'succeeded');
+
+CREATE TABLE ledger_transactions (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('hold', 'release', 'refund')),
+    -- Makes posting idempotent, e.g. hold:payment:5
+    idempotency_key VARCHAR(100) NOT NULL UNIQUE,
+    currency CHAR(3) NOT NULL,
+    booking_id INTEGER REFERENCES bookings(id) ON DELETE SET NULL,
+    payment_id INTEGER REFERENCES payments(id) ON DELETE SET NULL,
+    description VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE ledger_entries (
+    id SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES ledger_transactions(id),
+    account_id INTEGER NOT NULL REFERENCES ledger_accounts(id),
+    amount BIGINT NOT NULL CHECK (amount <> 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ledger_entries_account ON ledger_entries(account_id);
+CREATE INDEX idx_ledger_entries_transaction ON ledger_entries(transaction_id);
+CREATE INDEX idx_ledger_transactions_booking ON ledger_transactions(booking_id);
+
+-- Every transaction's entries sum to zero, checked at commit so the
+-- entries can be inserted one at a time
+CREATE FUNCTION ledger_check_balanced() RETURNS trigger AS $$
+BEGIN
+    IF (SELECT COALESCE(SUM(amount), 0) FROM ledger_entries WHERE transaction_id = NEW.transaction_id) <> 0 THEN
+        RAISE EXCEPTION 'ledger transaction % does not balance', NEW.transaction_id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_entries_balanced
+    AFTER INSERT ON ledger_entries
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION ledger_check_balanced();
+
+-- Entries are history: corrections are new transactions
+CREATE FUNCTION ledger_reject_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger entries are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ledger_entries_append_only
+    BEFORE UPDATE OR DELETE ON ledger_entries
+    FOR EACH ROW EXECUTE FUNCTION ledger_reject_change();
+
+-- Signed webhooks already handled, so redeliveries are skipped
+CREATE TABLE payment_webhook_events (
+    id SERIAL PRIMARY KEY,
+    provider VARCHAR(50) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, event_id)
+);
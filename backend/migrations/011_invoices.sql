@@ -0,0 +1,29 @@
+-- Last invoice number used in each year. Issuing an invoice increments
+-- its year's row, and the row lock that takes serialises concurrent
+-- issuers, so numbers are sequential with no gaps or duplicates.
+CREATE TABLE invoice_counters (
+    year INTEGER PRIMARY KEY,
+    last_number INTEGER NOT NULL
+);
+
+-- Issued when a booking completes and its escrow is paid to the worker.
+-- Names and the job title are copied so the invoice never changes.
+CREATE TABLE invoices (
+    id SERIAL PRIMARY KEY,
+    -- e.g. INV-2026-000042
+    number VARCHAR(20) NOT NULL UNIQUE,
+    booking_id INTEGER REFERENCES bookings(id) ON DELETE SET NULL,
+    worker_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    employer_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    worker_name VARCHAR(255) NOT NULL,
+    employer_name VARCHAR(255) NOT NULL,
+    job_title VARCHAR(255) NOT NULL,
+    -- Minor units, e.g. paise
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    issued_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (booking_id, currency)
+);
+
+CREATE INDEX idx_invoices_worker ON invoices(worker_id, issued_at);
+CREATE INDEX idx_invoices_employer ON invoices(employer_id, issued_at);
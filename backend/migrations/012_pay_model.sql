@@ -0,0 +1,31 @@
+-- Structured pay. salary_min and salary_max were unit-less decimals; pay
+-- now says what it is for, in which currency, and is kept in integer
+-- minor units (paise for INR).
+ALTER TABLE jobs
+    ADD COLUMN pay_type VARCHAR(10) NOT NULL DEFAULT 'fixed'
+        CHECK (pay_type IN ('hourly', 'daily', 'fixed')),
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'INR' CHECK (currency ~ '^[A-Z]{3}$'),
+    ADD COLUMN pay_min BIGINT CHECK (pay_min > 0),
+    ADD COLUMN pay_max BIGINT CHECK (pay_max > 0),
+    -- The top of the range per hour, in minor units, for sorting and
+    -- filtering the listing. Written by the application (pay.HourlyRate);
+    -- NULL when it can't be worked out.
+    ADD COLUMN hourly_rate BIGINT,
+    ADD CONSTRAINT jobs_pay_range CHECK (pay_min <= pay_max);
+
+-- Existing salaries were shown to workers as per hour. Ranges entered
+-- the wrong way round are swapped, a lone bound is both ends of the range
+-- (LEAST and GREATEST skip NULLs) and a zero minimum is dropped.
+UPDATE jobs SET
+    pay_type = 'hourly',
+    pay_min = CASE WHEN LEAST(salary_min, salary_max) > 0 THEN ROUND(LEAST(salary_min, salary_max) * 100) END,
+    pay_max = ROUND(GREATEST(salary_min, salary_max) * 100),
+    hourly_rate = ROUND(GREATEST(salary_min, salary_max) * 100)
+WHERE GREATEST(salary_min, salary_max) > 0;
+
+ALTER TABLE jobs
+    DROP COLUMN salary_min,
+    DROP COLUMN salary_max;
+
+CREATE INDEX idx_jobs_open_rate ON jobs (currency, hourly_rate)
+    WHERE is_active AND status = 'open';
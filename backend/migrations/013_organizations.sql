@@ -0,0 +1,64 @@
+-- Employers post jobs as an organization, their company profile, and
+-- share it with a team. Owners manage the team, managers post jobs and
+-- hire, viewers can see jobs and applicants.
+CREATE TABLE organizations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    website VARCHAR(255),
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE organization_members (
+    organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('owner', 'manager', 'viewer')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX idx_organization_members_user ON organization_members(user_id);
+
+-- The token is shown once, to the owner who sends the invitation; only its
+-- SHA-256 hash is kept
+CREATE TABLE organization_invitations (
+    id SERIAL PRIMARY KEY,
+    organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('owner', 'manager', 'viewer')),
+    token_hash BYTEA NOT NULL UNIQUE,
+    invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One open invitation per address; inviting again replaces it
+CREATE UNIQUE INDEX idx_organization_invitations_pending
+    ON organization_invitations(organization_id, LOWER(email))
+    WHERE accepted_at IS NULL;
+
+-- Every employer so far gets an organization of their own, named after
+-- them, which they own and which takes over their jobs. created_by is
+-- unique at this point, so it links each employer to theirs.
+INSERT INTO organizations (name, created_by, created_at)
+SELECT full_name, id, COALESCE(created_at, NOW())
+FROM users
+WHERE user_type = 'employer' OR id IN (SELECT employer_id FROM jobs)
+ORDER BY id;
+
+INSERT INTO organization_members (organization_id, user_id, role, created_at)
+SELECT id, created_by, 'owner', created_at FROM organizations;
+
+-- employer_id stays as who posted the job
+ALTER TABLE jobs ADD COLUMN organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE;
+
+UPDATE jobs j SET organization_id = o.id
+FROM organizations o
+WHERE o.created_by = j.employer_id;
+
+ALTER TABLE jobs ALTER COLUMN organization_id SET NOT NULL;
+
+CREATE INDEX idx_jobs_organization ON jobs(organization_id);
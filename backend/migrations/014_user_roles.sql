@@ -0,0 +1,18 @@
+-- A user can be both a worker and an employer. user_roles holds every role
+-- they have; users.user_type becomes the active one, which they switch
+-- between and which must be one of theirs.
+CREATE TABLE user_roles (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL REFERENCES roles(name),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, role)
+);
+
+INSERT INTO user_roles (user_id, role, created_at)
+SELECT id, user_type, COALESCE(created_at, NOW()) FROM users;
+
+-- Deferred so a new user and their first role can be inserted in either
+-- order within one transaction
+ALTER TABLE users
+    ADD CONSTRAINT users_active_role_fkey FOREIGN KEY (id, user_type)
+    REFERENCES user_roles(user_id, role) DEFERRABLE INITIALLY DEFERRED;
@@ -0,0 +1,21 @@
+-- Employers can invite a worker to a job instead of waiting for them to
+-- apply. The invitation is an application in the invited state, which the
+-- worker accepts (it becomes pending, like any application) or declines.
+ALTER TABLE applications DROP CONSTRAINT IF EXISTS applications_status_check;
+ALTER TABLE applications
+    ADD CONSTRAINT applications_status_check
+    CHECK (status IN ('invited', 'declined', 'pending', 'accepted', 'rejected', 'withdrawn'));
+
+-- Who sent the invitation; NULL for applications the worker sent
+ALTER TABLE applications ADD COLUMN invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+-- The employer rates the worker once a booking completes; the directory
+-- averages these
+ALTER TABLE bookings
+    ADD COLUMN worker_rating SMALLINT CHECK (worker_rating BETWEEN 1 AND 5),
+    ADD COLUMN worker_review TEXT,
+    ADD COLUMN worker_rated_at TIMESTAMPTZ;
+
+CREATE INDEX idx_bookings_worker_rating ON bookings(worker_id) WHERE worker_rating IS NOT NULL;
+CREATE INDEX idx_worker_skills_category ON worker_skills(category_id, experience_years);
+CREATE INDEX idx_user_roles_role ON user_roles(role, user_id);
@@ -0,0 +1,12 @@
+-- Optional coordinates, so recommendations can tell how far a job is from
+-- a worker. Without them only the location text is compared.
+ALTER TABLE users
+    ADD COLUMN latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    ADD COLUMN longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+    ADD CONSTRAINT users_coordinates_check CHECK ((latitude IS NULL) = (longitude IS NULL));
+
+ALTER TABLE jobs
+    ADD COLUMN latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    ADD COLUMN longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+    ADD CONSTRAINT jobs_coordinates_check CHECK ((latitude IS NULL) = (longitude IS NULL));
+
@@ -0,0 +1,11 @@
+-- Employers shortlist, tag and take notes on applicants. Reviews belong to
+-- the job's organization and are never shown to the worker.
+CREATE TABLE application_reviews (
+    application_id INTEGER PRIMARY KEY REFERENCES applications(id) ON DELETE CASCADE,
+    shortlisted BOOLEAN NOT NULL DEFAULT false,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    notes TEXT,
+    updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
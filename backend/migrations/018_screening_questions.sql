@@ -0,0 +1,28 @@
+-- Questions an employer asks everyone who applies to a job. Knockouts
+-- reject an application automatically: the yes/no answer in
+-- reject_answer, a number outside min_value..max_value, or one of
+-- reject_choices. Workers never see them.
+CREATE TABLE job_questions (
+    id SERIAL PRIMARY KEY,
+    job_id INTEGER NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    position SMALLINT NOT NULL,
+    prompt VARCHAR(300) NOT NULL,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('yes_no', 'number', 'text', 'choice')),
+    required BOOLEAN NOT NULL DEFAULT false,
+    choices TEXT[] NOT NULL DEFAULT '{}',
+    reject_answer BOOLEAN,
+    min_value DOUBLE PRECISION,
+    max_value DOUBLE PRECISION,
+    reject_choices TEXT[] NOT NULL DEFAULT '{}',
+    UNIQUE (job_id, position),
+    CONSTRAINT job_questions_range_check CHECK (min_value IS NULL OR max_value IS NULL OR min_value <= max_value)
+);
+
+-- Applicants' answers, as the screening check normalised them
+CREATE TABLE application_answers (
+    application_id INTEGER NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    question_id INTEGER NOT NULL REFERENCES job_questions(id) ON DELETE CASCADE,
+    answer TEXT NOT NULL,
+    knocked_out BOOLEAN NOT NULL DEFAULT false,
+    PRIMARY KEY (application_id, question_id)
+);
@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migrations so Go code (the test
+// harness, startup checks) can apply them without a psql binary.
+package migrations
+
+import "embed"
+
+// FS holds every NNN_name.sql file in this directory
+//
+//go:embed *.sql
+var FS embed.FS
@@ -10,7 +10,7 @@ import JobApplications from './components/Jobs/JobApplications';
 import './App.css';
 
 function App() {
-  const { user, logout, isAuthenticated, isEmployer, isWorker, loading } = useAuth();
+  const { user, logout, isAuthenticated, isEmployer, isWorker, loading, roles, addRole, switchRole } = useAuth();
   const [showLogin, setShowLogin] = useState(true);
   const [view, setView] = useState('home');
 
@@ -26,6 +26,16 @@ function App() {
         {isAuthenticated && (
           <div className="user-info">
             <span>Welcome, {user.full_name} ({user.user_type})</span>
+            {roles.filter((role) => role !== user.user_type && role !== 'admin').map((role) => (
+              <button key={role} onClick={() => { switchRole(role); setView('home'); }}>
+                Switch to {role}
+              </button>
+            ))}
+            {['worker', 'employer'].filter((role) => !roles.includes(role)).map((role) => (
+              <button key={role} onClick={() => addRole(role)}>
+                Also work as {role}
+              </button>
+            ))}
             <button onClick={logout} className="logout-btn">Logout</button>
           </div>
         )}
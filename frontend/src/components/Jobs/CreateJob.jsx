@@ -1,8 +1,39 @@
 import { useState } from 'react';
 import { jobAPI } from '../../services/api';
 import { useAuth } from '../../context/AuthContext';
+import { toMinor } from './pay';
 import './Jobs.css';
 
+const emptyQuestion = {
+  prompt: '',
+  kind: 'yes_no',
+  required: false,
+  choices: '',
+  reject_answer: '',
+  min_value: '',
+  max_value: '',
+  reject_choices: '',
+};
+
+const splitList = (text) => text.split(',').map((s) => s.trim()).filter(Boolean);
+
+// toQuestionRequest keeps only the knockout fields that suit the kind
+const toQuestionRequest = (q) => {
+  const question = { prompt: q.prompt, kind: q.kind, required: q.required };
+  if (q.kind === 'yes_no' && q.reject_answer !== '') {
+    question.reject_answer = q.reject_answer === 'yes';
+  }
+  if (q.kind === 'number') {
+    if (q.min_value !== '') question.min_value = Number(q.min_value);
+    if (q.max_value !== '') question.max_value = Number(q.max_value);
+  }
+  if (q.kind === 'choice') {
+    question.choices = splitList(q.choices);
+    question.reject_choices = splitList(q.reject_choices);
+  }
+  return question;
+};
+
 function CreateJob() {
   const { user } = useAuth();
   
@@ -12,14 +43,17 @@ function CreateJob() {
     description: '',
     category_id: '',
     location: '',
-    salary_min: '',
-    salary_max: '',
+    pay_type: 'hourly',
+    currency: 'INR',
+    pay_min: '',
+    pay_max: '',
     duration: '',
     requirements: '',
     contact_phone: '',
     contact_email: '',
     expiry_days: 3,
   });
+  const [questions, setQuestions] = useState([]);
   const [error, setError] = useState('');
   const [success, setSuccess] = useState('');
 
@@ -47,6 +81,10 @@ function CreateJob() {
     });
   };
 
+  const updateQuestion = (index, field, value) => {
+    setQuestions(questions.map((q, i) => (i === index ? { ...q, [field]: value } : q)));
+  };
+
   const handleSubmit = async (e) => {
     e.preventDefault();
     setError('');
@@ -57,12 +95,13 @@ function CreateJob() {
       const jobData = {
         ...formData,
         category_id: formData.category_id ? Number(formData.category_id) : null,
-        salary_min: formData.salary_min ? Number(formData.salary_min) : null,
-        salary_max: formData.salary_max ? Number(formData.salary_max) : null,
+        pay_min: toMinor(formData.pay_min),
+        pay_max: toMinor(formData.pay_max),
         duration: formData.duration || null,
         requirements: formData.requirements || null,
         contact_phone: formData.contact_phone || null,
         contact_email: formData.contact_email || null,
+        questions: questions.map(toQuestionRequest),
       };
 
       const response = await jobAPI.createJob(jobData);
@@ -75,14 +114,17 @@ function CreateJob() {
         description: '',
         category_id: '',
         location: '',
-        salary_min: '',
-        salary_max: '',
+        pay_type: 'hourly',
+        currency: 'INR',
+        pay_min: '',
+        pay_max: '',
         duration: '',
         requirements: '',
         contact_phone: '',
         contact_email: '',
         expiry_days: 3,
       });
+      setQuestions([]);
     } catch (err) {
       setError(err.response?.data?.error || 'Failed to create job');
       console.error('Error:', err.response?.data);
@@ -157,26 +199,51 @@ function CreateJob() {
 
         <div className="form-row">
           <div className="form-group">
-            <label>Salary Min (₹/hour)</label>
+            <label>Pay</label>
+            <select name="pay_type" value={formData.pay_type} onChange={handleChange}>
+              <option value="hourly">Per hour</option>
+              <option value="daily">Per day</option>
+              <option value="fixed">For the whole job</option>
+            </select>
+          </div>
+
+          <div className="form-group">
+            <label>Currency</label>
+            <input
+              type="text"
+              name="currency"
+              value={formData.currency}
+              onChange={(e) => setFormData({ ...formData, currency: e.target.value.toUpperCase() })}
+              maxLength="3"
+              placeholder="INR"
+            />
+          </div>
+        </div>
+
+        <div className="form-row">
+          <div className="form-group">
+            <label>Pay Min</label>
             <input
               type="number"
-              name="salary_min"
-              value={formData.salary_min}
+              name="pay_min"
+              value={formData.pay_min}
               onChange={handleChange}
               placeholder="200"
               min="0"
+              step="0.01"
             />
           </div>
 
           <div className="form-group">
-            <label>Salary Max (₹/hour)</label>
+            <label>Pay Max</label>
             <input
               type="number"
-              name="salary_max"
-              value={formData.salary_max}
+              name="pay_max"
+              value={formData.pay_max}
               onChange={handleChange}
               placeholder="500"
               min="0"
+              step="0.01"
             />
           </div>
         </div>
@@ -243,6 +310,90 @@ function CreateJob() {
           </select>
         </div>
 
+        <div className="form-group screening-questions">
+          <label>Screening Questions</label>
+          <p className="hint">
+            Asked of everyone who applies. Answers that hit a knockout reject the application automatically.
+            Questions can't be changed after posting.
+          </p>
+          {questions.map((q, i) => (
+            <div key={i} className="question-row">
+              <div className="form-row">
+                <input
+                  type="text"
+                  value={q.prompt}
+                  onChange={(e) => updateQuestion(i, 'prompt', e.target.value)}
+                  placeholder="e.g., Do you own a ladder?"
+                  maxLength="300"
+                  required
+                />
+                <select value={q.kind} onChange={(e) => updateQuestion(i, 'kind', e.target.value)}>
+                  <option value="yes_no">Yes / No</option>
+                  <option value="number">Number</option>
+                  <option value="text">Text</option>
+                  <option value="choice">Single choice</option>
+                </select>
+                <label className="checkbox">
+                  <input
+                    type="checkbox"
+                    checked={q.required}
+                    onChange={(e) => updateQuestion(i, 'required', e.target.checked)}
+                  />
+                  Required
+                </label>
+                <button type="button" onClick={() => setQuestions(questions.filter((_, j) => j !== i))}>
+                  Remove
+                </button>
+              </div>
+              {q.kind === 'yes_no' && (
+                <select value={q.reject_answer} onChange={(e) => updateQuestion(i, 'reject_answer', e.target.value)}>
+                  <option value="">No knockout</option>
+                  <option value="no">Reject if the answer is No</option>
+                  <option value="yes">Reject if the answer is Yes</option>
+                </select>
+              )}
+              {q.kind === 'number' && (
+                <div className="form-row">
+                  <input
+                    type="number"
+                    value={q.min_value}
+                    onChange={(e) => updateQuestion(i, 'min_value', e.target.value)}
+                    placeholder="Reject below (optional)"
+                  />
+                  <input
+                    type="number"
+                    value={q.max_value}
+                    onChange={(e) => updateQuestion(i, 'max_value', e.target.value)}
+                    placeholder="Reject above (optional)"
+                  />
+                </div>
+              )}
+              {q.kind === 'choice' && (
+                <div className="form-row">
+                  <input
+                    type="text"
+                    value={q.choices}
+                    onChange={(e) => updateQuestion(i, 'choices', e.target.value)}
+                    placeholder="Choices, comma separated"
+                    required
+                  />
+                  <input
+                    type="text"
+                    value={q.reject_choices}
+                    onChange={(e) => updateQuestion(i, 'reject_choices', e.target.value)}
+                    placeholder="Choices that reject (optional)"
+                  />
+                </div>
+              )}
+            </div>
+          ))}
+          {questions.length < 10 && (
+            <button type="button" onClick={() => setQuestions([...questions, { ...emptyQuestion }])}>
+              Add Question
+            </button>
+          )}
+        </div>
+
         <button type="submit" className="submit-btn">Post Job</button>
       </form>
     </div>
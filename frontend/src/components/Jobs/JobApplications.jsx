@@ -2,21 +2,32 @@ import { useState, useEffect } from 'react';
 import { applicationAPI } from '../../services/api';
 import './Jobs.css';
 
+// The hire or rejection stands even when the money didn't move
+const escrowNotes = {
+  declined: ' The payment was declined; pay again from the booking.',
+  failed: " The payment couldn't be made; pay again from the booking.",
+  refund_failed: ' The refund failed; an admin will return the money held.',
+};
+
 function JobApplications({ jobId, jobTitle }) {
   const [applications, setApplications] = useState([]);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState('');
   const [message, setMessage] = useState('');
+  const [sort, setSort] = useState('applied');
+  const [shortlistedOnly, setShortlistedOnly] = useState(false);
 
   useEffect(() => {
     if (jobId) {
       fetchApplications();
     }
-  }, [jobId]);
+  }, [jobId, sort, shortlistedOnly]);
 
   const fetchApplications = async () => {
     try {
-      const response = await applicationAPI.getJobApplications(jobId);
+      const params = { sort };
+      if (shortlistedOnly) params.shortlisted = true;
+      const response = await applicationAPI.getJobApplications(jobId, params);
       setApplications(response.data.applications || []);
       setLoading(false);
     } catch (err) {
@@ -27,8 +38,8 @@ function JobApplications({ jobId, jobTitle }) {
 
   const handleStatusUpdate = async (applicationId, status) => {
     try {
-      await applicationAPI.updateApplicationStatus(applicationId, status);
-      setMessage(`Application ${status} successfully!`);
+      const response = await applicationAPI.updateApplicationStatus(applicationId, status);
+      setMessage(`Application ${status} successfully!${escrowNotes[response.data.escrow] || ''}`);
       // Refresh applications
       fetchApplications();
       setTimeout(() => setMessage(''), 3000);
@@ -37,11 +48,34 @@ function JobApplications({ jobId, jobTitle }) {
     }
   };
 
+  const toggleShortlist = async (app) => {
+    try {
+      await applicationAPI.reviewApplication(app.id, { shortlisted: !app.review.shortlisted });
+      fetchApplications();
+    } catch (err) {
+      setMessage('Failed to update shortlist');
+    }
+  };
+
+  const rejectAllPending = async () => {
+    const pending = applications.filter((app) => app.status === 'pending').map((app) => app.id);
+    if (pending.length === 0 || !window.confirm(`Reject ${pending.length} pending applications?`)) return;
+    try {
+      const response = await applicationAPI.bulkUpdateStatus(jobId, pending, 'rejected');
+      setMessage(`Rejected ${response.data.updated} applications`);
+      fetchApplications();
+      setTimeout(() => setMessage(''), 3000);
+    } catch (err) {
+      setMessage('Failed to reject applications');
+    }
+  };
+
   const getStatusBadge = (status) => {
     const statusColors = {
       pending: 'badge-pending',
       accepted: 'badge-accepted',
       rejected: 'badge-rejected',
+      withdrawn: 'badge-withdrawn',
     };
     return statusColors[status] || 'badge-pending';
   };
@@ -53,6 +87,26 @@ function JobApplications({ jobId, jobTitle }) {
     <div className="job-applications-container">
       <h2>Applications for: {jobTitle}</h2>
       <p>Total Applications: {applications.length}</p>
+      <div className="job-sort">
+        <label>
+          Sort by{' '}
+          <select value={sort} onChange={(e) => setSort(e.target.value)}>
+            <option value="applied">Newest</option>
+            <option value="match">Best match</option>
+          </select>
+        </label>{' '}
+        <label>
+          <input
+            type="checkbox"
+            checked={shortlistedOnly}
+            onChange={(e) => setShortlistedOnly(e.target.checked)}
+          />{' '}
+          Shortlisted only
+        </label>{' '}
+        <button onClick={rejectAllPending} className="reject-btn">
+          Reject all pending
+        </button>
+      </div>
 
       {message && <div className="success">{message}</div>}
 
@@ -72,6 +126,10 @@ function JobApplications({ jobId, jobTitle }) {
                   {app.worker_location && (
                     <p>📍 {app.worker_location}</p>
                   )}
+                  <p className="match">
+                    Match: {Math.round(app.match.score * 100)}%
+                    {app.match.reasons.length > 0 && ` · ${app.match.reasons.join(' · ')}`}
+                  </p>
                 </div>
                 <span className={`status-badge ${getStatusBadge(app.status)}`}>
                   {app.status.toUpperCase()}
@@ -85,9 +143,26 @@ function JobApplications({ jobId, jobTitle }) {
                 </div>
               )}
 
+              {app.answers?.length > 0 && (
+                <div className="screening-answers">
+                  <strong>Screening Answers:</strong>
+                  <ul>
+                    {app.answers.map((a) => (
+                      <li key={a.question_id} className={a.knocked_out ? 'knocked-out' : ''}>
+                        {a.prompt} <strong>{a.answer}</strong>
+                        {a.knocked_out && ' ✗ knockout'}
+                      </li>
+                    ))}
+                  </ul>
+                </div>
+              )}
+
               <p className="applied-date">
                 Applied on: {new Date(app.applied_at).toLocaleString()}
               </p>
+              <button onClick={() => toggleShortlist(app)} className="view-btn">
+                {app.review.shortlisted ? '★ Shortlisted' : '☆ Shortlist'}
+              </button>
 
               {app.status === 'pending' && (
                 <div className="action-buttons">
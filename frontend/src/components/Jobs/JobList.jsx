@@ -1,5 +1,7 @@
 import { useState, useEffect } from 'react';
-import { jobAPI, applicationAPI } from '../../services/api';
+import { jobAPI, applicationAPI, errorCode, fieldErrors } from '../../services/api';
+import { formatPay } from './pay';
+import ScreeningQuestions, { toAnswerRequests } from './ScreeningQuestions';
 import './Jobs.css';
 import { useAuth } from '../../context/AuthContext';
 
@@ -11,16 +13,28 @@ function JobList({ workerId }) {
   const [selectedJob, setSelectedJob] = useState(null);
   const [showApplicationForm, setShowApplicationForm] = useState(false);
   const [coverLetter, setCoverLetter] = useState('');
+  const [questions, setQuestions] = useState([]);
+  const [answers, setAnswers] = useState({});
   const [applicationMessage, setApplicationMessage] = useState('');
+  const [sort, setSort] = useState('newest');
+  // Rates only compare within a currency, so sorting by rate needs one
+  const [currency, setCurrency] = useState('INR');
+  const byRate = sort === 'rate_asc' || sort === 'rate_desc';
 
   useEffect(() => {
+    if (byRate && !/^[A-Z]{3}$/.test(currency)) return;
     fetchJobs();
-  }, []);
+  }, [sort, currency]);
 
   const fetchJobs = async () => {
     try {
-      const response = await jobAPI.getAllJobs();
-      setJobs(response.data.jobs || []);
+      if (sort === 'recommended') {
+        const response = await jobAPI.getRecommended();
+        setJobs((response.data.recommendations || []).map((r) => ({ ...r.job, reasons: r.reasons })));
+      } else {
+        const response = await jobAPI.getAllJobs(byRate ? { sort, currency } : { sort });
+        setJobs(response.data.jobs || []);
+      }
       setLoading(false);
     } catch (err) {
       setError('Failed to load jobs');
@@ -38,10 +52,19 @@ function JobList({ workerId }) {
     setSelectedJob(null);
     setShowApplicationForm(false);
     setCoverLetter('');
+    setAnswers({});
     setApplicationMessage('');
   };
 
-  const handleApply = () => {
+  // Screening questions are only on the job's detail
+  const handleApply = async () => {
+    try {
+      const response = await jobAPI.getJob(selectedJob.id);
+      setQuestions(response.data.questions || []);
+    } catch (err) {
+      setQuestions([]);
+    }
+    setAnswers({});
     setShowApplicationForm(true);
   };
 
@@ -52,13 +75,19 @@ function JobList({ workerId }) {
     }
 
     try {
-      await applicationAPI.applyToJob({
+      const response = await applicationAPI.applyToJob({
         job_id: selectedJob.id,
         //worker_id: workerId,
         cover_letter: coverLetter,
+        answers: toAnswerRequests(answers),
       });
-      setApplicationMessage('Application submitted successfully!');
+      setApplicationMessage(
+        response.data.application?.status === 'rejected'
+          ? response.data.message
+          : 'Application submitted successfully!'
+      );
       setCoverLetter('');
+      setAnswers({});
       setShowApplicationForm(false);
       
       // Refresh to show updated status
@@ -66,7 +95,12 @@ function JobList({ workerId }) {
         setApplicationMessage('');
       }, 3000);
     } catch (err) {
-      setApplicationMessage(err.response?.data?.error || 'Failed to submit application');
+      if (errorCode(err) === 'already_applied') {
+        setApplicationMessage('You have already applied to this job');
+        return;
+      }
+      const [field] = fieldErrors(err);
+      setApplicationMessage(field?.message || err.response?.data?.error || 'Failed to submit application');
     }
   };
 
@@ -76,6 +110,28 @@ function JobList({ workerId }) {
   return (
     <div className="job-list-container">
       <h2>Available Jobs ({jobs.length})</h2>
+      <div className="job-sort">
+        <label>
+          Sort by{' '}
+          <select value={sort} onChange={(e) => setSort(e.target.value)}>
+            {user?.user_type === 'worker' && <option value="recommended">Recommended for you</option>}
+            <option value="newest">Newest</option>
+            <option value="rate_desc">Highest hourly rate</option>
+            <option value="rate_asc">Lowest hourly rate</option>
+          </select>
+        </label>
+        {byRate && (
+          <label>
+            {' '}in{' '}
+            <input
+              value={currency}
+              onChange={(e) => setCurrency(e.target.value.toUpperCase())}
+              maxLength={3}
+              size={3}
+            />
+          </label>
+        )}
+      </div>
 
       {jobs.length === 0 ? (
         <p>No jobs available at the moment.</p>
@@ -84,15 +140,16 @@ function JobList({ workerId }) {
           {jobs.map((job) => (
             <div key={job.id} className="job-card">
               <h3>{job.title}</h3>
-              <p className="employer">Posted by: {job.employer_name}</p>
+              <p className="employer">Posted by: {job.organization_name}</p>
               <p className="location">📍 {job.location}</p>
               {job.category_name && <span className="category">{job.category_name}</span>}
-              {job.salary_min && job.salary_max && (
-                <p className="salary">
-                  ₹{job.salary_min} - ₹{job.salary_max} per hour
-                </p>
+              {formatPay(job) && (
+                <p className="salary">{formatPay(job)}</p>
               )}
               <p className="description">{job.description.substring(0, 100)}...</p>
+              {job.reasons?.length > 0 && (
+                <p className="reasons">{job.reasons.join(' · ')}</p>
+              )}
               <p className="expires">
                 Expires: {new Date(job.expires_at).toLocaleDateString()}
               </p>
@@ -110,13 +167,13 @@ function JobList({ workerId }) {
           <div className="modal-content" onClick={(e) => e.stopPropagation()}>
             <button className="close-btn" onClick={closeJobDetails}>×</button>
             <h2>{selectedJob.title}</h2>
-            <p><strong>Employer:</strong> {selectedJob.employer_name}</p>
+            <p><strong>Employer:</strong> {selectedJob.organization_name}</p>
             <p><strong>Location:</strong> {selectedJob.location}</p>
             {selectedJob.category_name && (
               <p><strong>Category:</strong> {selectedJob.category_name}</p>
             )}
-            {selectedJob.salary_min && selectedJob.salary_max && (
-              <p><strong>Salary:</strong> ₹{selectedJob.salary_min} - ₹{selectedJob.salary_max} per hour</p>
+            {formatPay(selectedJob) && (
+              <p><strong>Pay:</strong> {formatPay(selectedJob)}</p>
             )}
             {selectedJob.duration && (
               <p><strong>Duration:</strong> {selectedJob.duration}</p>
@@ -153,6 +210,7 @@ function JobList({ workerId }) {
             ) : (
               <div className="application-form">
                 <h3>Submit Your Application</h3>
+                <ScreeningQuestions questions={questions} answers={answers} onChange={setAnswers} />
                 <textarea
                   value={coverLetter}
                   onChange={(e) => setCoverLetter(e.target.value)}
@@ -1,11 +1,17 @@
 import { useState, useEffect } from 'react';
-import { applicationAPI } from '../../services/api';
+import { applicationAPI, jobAPI } from '../../services/api';
+import { formatPay } from './pay';
+import ScreeningQuestions, { toAnswerRequests } from './ScreeningQuestions';
 import './Jobs.css';
 
 function MyApplications({ workerId }) {
   const [applications, setApplications] = useState([]);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState('');
+  // The invitation being accepted, while its screening questions are open
+  const [accepting, setAccepting] = useState(null);
+  const [questions, setQuestions] = useState([]);
+  const [answers, setAnswers] = useState({});
 
   useEffect(() => {
     if (workerId) {
@@ -24,8 +30,38 @@ function MyApplications({ workerId }) {
     }
   };
 
+  const respond = async (applicationId, response, given = {}) => {
+    try {
+      await applicationAPI.respondToInvitation(applicationId, response, toAnswerRequests(given));
+      setAccepting(null);
+      fetchApplications();
+    } catch (err) {
+      setError(err.response?.data?.error || 'Failed to answer the invitation');
+    }
+  };
+
+  // Accepting asks the job's screening questions first, if it has any
+  const accept = async (app) => {
+    let jobQuestions = [];
+    try {
+      const response = await jobAPI.getJob(app.job_id);
+      jobQuestions = response.data.questions || [];
+    } catch (err) {
+      jobQuestions = [];
+    }
+    if (jobQuestions.length === 0) {
+      respond(app.id, 'accept');
+      return;
+    }
+    setQuestions(jobQuestions);
+    setAnswers({});
+    setAccepting(app.id);
+  };
+
   const getStatusBadge = (status) => {
     const statusColors = {
+      invited: 'badge-pending',
+      declined: 'badge-withdrawn',
       pending: 'badge-pending',
       accepted: 'badge-accepted',
       rejected: 'badge-rejected',
@@ -55,8 +91,8 @@ function MyApplications({ workerId }) {
               </div>
               <p><strong>Employer:</strong> {app.employer_name}</p>
               <p><strong>Location:</strong> {app.location}</p>
-              {app.salary_min && app.salary_max && (
-                <p><strong>Salary:</strong> ₹{app.salary_min} - ₹{app.salary_max} per hour</p>
+              {formatPay(app) && (
+                <p><strong>Pay:</strong> {formatPay(app)}</p>
               )}
               {app.cover_letter && (
                 <div className="cover-letter-preview">
@@ -64,9 +100,27 @@ function MyApplications({ workerId }) {
                   <p>{app.cover_letter}</p>
                 </div>
               )}
-              <p className="applied-date">
-                Applied on: {new Date(app.applied_at).toLocaleString()}
-              </p>
+              {app.status === 'invited' ? (
+                <div className="invitation-actions">
+                  <p>The employer invited you to this job.</p>
+                  {accepting === app.id ? (
+                    <>
+                      <ScreeningQuestions questions={questions} answers={answers} onChange={setAnswers} />
+                      <button onClick={() => respond(app.id, 'accept', answers)}>Accept</button>
+                      <button onClick={() => setAccepting(null)}>Cancel</button>
+                    </>
+                  ) : (
+                    <>
+                      <button onClick={() => accept(app)}>Accept</button>
+                      <button onClick={() => respond(app.id, 'decline')}>Decline</button>
+                    </>
+                  )}
+                </div>
+              ) : (
+                <p className="applied-date">
+                  {app.invited_by ? 'Invited on' : 'Applied on'}: {new Date(app.applied_at).toLocaleString()}
+                </p>
+              )}
             </div>
           ))}
         </div>
@@ -0,0 +1,54 @@
+// Inputs for a job's screening questions, asked when applying and when
+// accepting an invitation. answers maps question id to what was typed.
+function ScreeningQuestions({ questions, answers, onChange }) {
+  const setAnswer = (questionId, answer) => {
+    onChange({ ...answers, [questionId]: answer });
+  };
+
+  return questions.map((q) => (
+    <div key={q.id} className="form-group screening-question">
+      <label>
+        {q.prompt}
+        {q.required && ' *'}
+      </label>
+      {q.kind === 'yes_no' && (
+        <select value={answers[q.id] || ''} onChange={(e) => setAnswer(q.id, e.target.value)}>
+          <option value="">Choose...</option>
+          <option value="yes">Yes</option>
+          <option value="no">No</option>
+        </select>
+      )}
+      {q.kind === 'number' && (
+        <input
+          type="number"
+          value={answers[q.id] || ''}
+          onChange={(e) => setAnswer(q.id, e.target.value)}
+        />
+      )}
+      {q.kind === 'text' && (
+        <input
+          type="text"
+          value={answers[q.id] || ''}
+          onChange={(e) => setAnswer(q.id, e.target.value)}
+          maxLength="1000"
+        />
+      )}
+      {q.kind === 'choice' && (
+        <select value={answers[q.id] || ''} onChange={(e) => setAnswer(q.id, e.target.value)}>
+          <option value="">Choose...</option>
+          {q.choices.map((c) => (
+            <option key={c} value={c}>{c}</option>
+          ))}
+        </select>
+      )}
+    </div>
+  ));
+}
+
+// The answers as the API takes them, leaving out unanswered questions
+export const toAnswerRequests = (answers) =>
+  Object.entries(answers)
+    .filter(([, answer]) => answer !== '')
+    .map(([questionId, answer]) => ({ question_id: Number(questionId), answer: String(answer) }));
+
+export default ScreeningQuestions;
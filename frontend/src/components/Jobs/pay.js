@@ -0,0 +1,19 @@
+// Job pay comes from the API in minor units (paise for INR)
+
+const per = { hourly: 'per hour', daily: 'per day', fixed: 'for the job' };
+
+export const formatMoney = (minor, currency) =>
+  new Intl.NumberFormat(undefined, { style: 'currency', currency }).format(minor / 100);
+
+// e.g. "₹200.00 - ₹500.00 per hour"; null when the job has no pay
+export const formatPay = ({ pay_type, currency, pay_min, pay_max }) => {
+  if (pay_min == null && pay_max == null) return null;
+  const range = [pay_min, pay_max]
+    .filter((amount, i, both) => amount != null && (i === 0 || amount !== both[0]))
+    .map((amount) => formatMoney(amount, currency))
+    .join(' - ');
+  return `${range} ${per[pay_type] || ''}`.trim();
+};
+
+// Amount typed in the form (major units) to minor units
+export const toMinor = (value) => (value === '' ? null : Math.round(Number(value) * 100));
@@ -46,7 +46,8 @@ function Profile({ userId }) {
     e.preventDefault();
     try {
       const response = await profileAPI.updateProfile(userId, formData);
-      setProfile(response.data.profile);
+      // The edit response has no worker/employer summaries; keep ours
+      setProfile({ ...profile, ...response.data.profile });
       setIsEditing(false);
       alert('Profile updated successfully!');
     } catch (err) {
@@ -71,7 +72,7 @@ function Profile({ userId }) {
             <strong>Email:</strong> {profile.email}
           </div>
           <div className="profile-field">
-            <strong>User Type:</strong> {profile.user_type}
+            <strong>Roles:</strong> {(profile.roles || [profile.user_type]).join(', ')}
           </div>
           <div className="profile-field">
             <strong>Phone:</strong> {profile.phone || 'Not provided'}
@@ -85,6 +86,20 @@ function Profile({ userId }) {
           <div className="profile-field">
             <strong>Member Since:</strong> {new Date(profile.created_at).toLocaleDateString()}
           </div>
+          {profile.worker && (
+            <div className="profile-field">
+              <strong>As a worker:</strong> {profile.worker.completed_jobs} jobs completed
+              {profile.worker.skills.length > 0 &&
+                ` · ${profile.worker.skills.map((s) => `${s.category_name} (${s.experience_years}y)`).join(', ')}`}
+            </div>
+          )}
+          {profile.employer && (
+            <div className="profile-field">
+              <strong>As an employer:</strong> {profile.employer.jobs_posted} jobs posted, {profile.employer.hires} hires
+              {profile.employer.organizations.length > 0 &&
+                ` · ${profile.employer.organizations.map((o) => o.name).join(', ')}`}
+            </div>
+          )}
           
           <button onClick={() => setIsEditing(true)}>Edit Profile</button>
         </div>
@@ -20,19 +20,20 @@ export const AuthProvider = ({ children }) => {
     setLoading(false);
   }, []);
 
+  const saveSession = ({ token, user }) => {
+    // Save to state
+    setToken(token);
+    setUser(user);
+
+    // Save to localStorage
+    localStorage.setItem('token', token);
+    localStorage.setItem('user', JSON.stringify(user));
+  };
+
   const login = async (email, password) => {
     try {
       const response = await authAPI.login({ email, password });
-      const { token, user } = response.data;
-      
-      // Save to state
-      setToken(token);
-      setUser(user);
-      
-      // Save to localStorage
-      localStorage.setItem('token', token);
-      localStorage.setItem('user', JSON.stringify(user));
-      
+      saveSession(response.data);
       return { success: true };
     } catch (error) {
       return { 
@@ -54,6 +55,21 @@ export const AuthProvider = ({ children }) => {
     }
   };
 
+  // Give the account another role, or make one of its roles the active
+  // one; either way the server reissues the token
+  const changeRole = async (request, role) => {
+    try {
+      const response = await request(role);
+      saveSession(response.data);
+      return { success: true };
+    } catch (error) {
+      return {
+        success: false,
+        error: error.response?.data?.error || 'Could not change role'
+      };
+    }
+  };
+
   const logout = () => {
     setToken(null);
     setUser(null);
@@ -69,6 +85,10 @@ export const AuthProvider = ({ children }) => {
     register,
     logout,
     isAuthenticated: !!token,
+    addRole: (role) => changeRole(authAPI.addRole, role),
+    switchRole: (role) => changeRole(authAPI.switchRole, role),
+    // Sessions saved before accounts could hold several roles have no list
+    roles: user?.roles || (user ? [user.user_type] : []),
     isEmployer: user?.user_type === 'employer',
     isWorker: user?.user_type === 'worker',
   };
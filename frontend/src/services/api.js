@@ -37,9 +37,19 @@ api.interceptors.response.use(
   }
 );
 
+// Stable error code from the API (e.g. 'already_applied'); branch on this,
+// not on the human-readable `error` text
+export const errorCode = (error) => error.response?.data?.code;
+
+// Per-field validation errors: [{ field, rule, param, message }]
+export const fieldErrors = (error) => error.response?.data?.fields || [];
+
 export const authAPI = {
   register: (userData) => api.post('/register', userData),
   login: (credentials) => api.post('/login', credentials),
+  // Both respond like login, with a new token
+  addRole: (role) => api.post('/me/roles', { role }),
+  switchRole: (role) => api.put('/me/active-role', { role }),
 };
 
 export const profileAPI = {
@@ -49,16 +59,40 @@ export const profileAPI = {
 
 export const jobAPI = {
   createJob: (jobData) => api.post('/jobs', jobData),
-  getAllJobs: () => api.get('/jobs'),
+  // params: pay_type, currency, min_rate, max_rate, sort
+  getAllJobs: (params) => api.get('/jobs', { params }),
+  // With its attachments and screening questions
   getJob: (jobId) => api.get(`/jobs/${jobId}`),
+  // Members of the job's organization; questions with their knockouts
+  getQuestions: (jobId) => api.get(`/jobs/${jobId}/questions`),
+  // Workers only; each result is { job, score, factors, reasons }
+  getRecommended: (limit) => api.get('/jobs/recommended', { params: { limit } }),
 };
 
 export const applicationAPI = {
+  // answers: [{ question_id, answer }] for the job's screening questions
   applyToJob: (applicationData) => api.post('/applications', applicationData),
   getWorkerApplications: (workerId) => api.get(`/applications/worker/${workerId}`),
-  getJobApplications: (jobId) => api.get(`/applications/job/${jobId}`),
+  // params: available, shortlisted, tag, sort ('applied' or 'match')
+  getJobApplications: (jobId, params) => api.get(`/applications/job/${jobId}`, { params }),
   updateApplicationStatus: (applicationId, status) => 
     api.put(`/applications/${applicationId}`, { status }),
+  // Employers invite; the worker answers 'accept', with the job's
+  // screening answers, or 'decline'
+  inviteWorker: (jobId, workerId) => api.post(`/jobs/${jobId}/invite`, { worker_id: workerId }),
+  respondToInvitation: (applicationId, response, answers = []) =>
+    api.put(`/applications/${applicationId}/invitation`, { response, answers }),
+  // review: any of shortlisted, tags, notes; private to the employer
+  reviewApplication: (applicationId, review) => api.put(`/applications/${applicationId}/review`, review),
+  bulkUpdateStatus: (jobId, applicationIds, status) =>
+    api.post(`/jobs/${jobId}/applications/bulk`, { application_ids: applicationIds, status }),
+};
+
+export const workerAPI = {
+  // params: category_id, min_experience, location, min_rating,
+  // available_from, available_to, page, per_page
+  searchWorkers: (params) => api.get('/workers', { params }),
+  updateSkills: (skills) => api.put('/me/skills', { skills }),
 };
 
 export default api;